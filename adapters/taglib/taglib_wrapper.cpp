@@ -329,6 +329,34 @@ int taglib_read(const FILENAME_CHAR_T *filename, unsigned long id) {
   return 0;
 }
 
+// Write the given key/value pairs to the file's tags, using TagLib's
+// PropertyMap interface so the right underlying format (ID3v2, Vorbis
+// comments, MP4 atoms, ...) is used for each file type. An empty value
+// removes the property. Returns 0 on success.
+int taglib_write(const FILENAME_CHAR_T *filename, const char **keys, const char **values, int count) {
+  TagLib::FileRef f(filename);
+
+  if (f.isNull() || !f.file() || !f.file()->isValid()) {
+    return TAGLIB_ERR_PARSE;
+  }
+
+  TagLib::PropertyMap props = f.file()->properties();
+  for (int i = 0; i < count; i++) {
+    TagLib::String key(keys[i], TagLib::String::UTF8);
+    TagLib::String value(values[i], TagLib::String::UTF8);
+    props.erase(key);
+    if (!value.isEmpty()) {
+      props.insert(key, TagLib::StringList(value));
+    }
+  }
+  f.file()->setProperties(props);
+
+  if (!f.file()->save()) {
+    return TAGLIB_ERR_SAVE;
+  }
+  return 0;
+}
+
 // Detect if the file has cover art. Returns 1 if the file has cover art, 0 otherwise.
 char has_cover(const TagLib::FileRef f) {
   char hasCover = 0;
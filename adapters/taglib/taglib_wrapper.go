@@ -75,6 +75,51 @@ func Read(filename string) (tags map[string][]string, err error) {
 	return m, nil
 }
 
+// Write updates the file's tags with the given key/value pairs, keeping all
+// other tags intact. Keys are TagLib PropertyMap names (TITLE, ARTIST, ...),
+// and an empty value removes the tag. TagLib picks the right representation
+// (ID3v2, Vorbis comments, MP4 atoms, ...) for the file type
+func Write(filename string, tags map[string]string) (err error) {
+	// Do not crash on failures in the C code/library
+	debug.SetPanicOnFault(true)
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("taglib: recovered from panic when writing tags", "file", filename, "error", r)
+			err = fmt.Errorf("taglib: recovered from panic: %s", r)
+		}
+	}()
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	fp := getFilename(filename)
+	defer C.free(unsafe.Pointer(fp))
+
+	keys := make([]*C.char, 0, len(tags))
+	values := make([]*C.char, 0, len(tags))
+	defer func() {
+		for i := range keys {
+			C.free(unsafe.Pointer(keys[i]))
+			C.free(unsafe.Pointer(values[i]))
+		}
+	}()
+	for k, v := range tags {
+		keys = append(keys, C.CString(k))
+		values = append(values, C.CString(v))
+	}
+
+	log.Trace("taglib: writing tags", "filename", filename, "tags", tags)
+	res := C.taglib_write(fp, &keys[0], &values[0], C.int(len(keys)))
+	switch res {
+	case C.TAGLIB_ERR_PARSE:
+		return fmt.Errorf("cannot parse media file: %s", filename)
+	case C.TAGLIB_ERR_SAVE:
+		return fmt.Errorf("cannot save media file: %s", filename)
+	}
+	return nil
+}
+
 type tagMap map[string][]string
 
 var allMaps sync.Map
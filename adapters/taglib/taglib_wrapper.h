@@ -1,5 +1,6 @@
 #define TAGLIB_ERR_PARSE -1
 #define TAGLIB_ERR_AUDIO_PROPS -2
+#define TAGLIB_ERR_SAVE -3
 
 #ifdef __cplusplus
 extern "C" {
@@ -17,6 +18,7 @@ extern void goPutInt(unsigned long id, char *key, int val);
 extern void goPutLyrics(unsigned long id, char *lang, char *val);
 extern void goPutLyricLine(unsigned long id, char *lang, char *text, int time);
 int taglib_read(const FILENAME_CHAR_T *filename, unsigned long id);
+int taglib_write(const FILENAME_CHAR_T *filename, const char **keys, const char **values, int count);
 char* taglib_version();
 
 #ifdef __cplusplus
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"net/http"
+
+	"github.com/navidrome/navidrome/core/ffmpeg"
+	"github.com/navidrome/navidrome/server/airplay"
+)
+
+// AirPlay - simple manual instantiation since it doesn't need wire injection
+var airplayInstance *airplay.AirPlay
+
+func GetAirPlay() *airplay.AirPlay {
+	if airplayInstance == nil {
+		airplayInstance = airplay.NewAirPlay()
+	}
+	return airplayInstance
+}
+
+func CreateAirPlayRouter() http.Handler {
+	ds := CreateDataStore()
+	airplayService := GetAirPlay()
+	api := airplay.NewAPI(airplayService, ds, ffmpeg.New())
+	return api.Router()
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"net/http"
+
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/core/ffmpeg"
+	"github.com/navidrome/navidrome/server/chromecast"
+)
+
+// Chromecast - simple manual instantiation since it doesn't need wire injection
+var chromecastInstance *chromecast.Chromecast
+
+func GetChromecast() *chromecast.Chromecast {
+	if chromecastInstance == nil {
+		chromecastInstance = chromecast.NewChromecast()
+	}
+	return chromecastInstance
+}
+
+func CreateChromecastRouter() http.Handler {
+	ds := CreateDataStore()
+	chromecastService := GetChromecast()
+	streamer := core.NewMediaStreamer(ds, ffmpeg.New(), core.GetTranscodingCache())
+	api := chromecast.NewAPI(chromecastService, ds, streamer)
+	return api.Router()
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/core/fingerprint"
+	"github.com/navidrome/navidrome/db"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fpAlbumID   string
+	fpLibraryID int
+	fpWriteTags bool
+	fpDryRun    bool
+	fpMinScore  float64
+)
+
+func init() {
+	fingerprintCmd.Flags().StringVarP(&fpAlbumID, "album", "a", "", "identify all tracks of the album with this ID")
+	fingerprintCmd.Flags().IntVarP(&fpLibraryID, "library", "l", 0, "identify all tracks of the library with this ID")
+	fingerprintCmd.Flags().BoolVar(&fpWriteTags, "write-tags", false, "write matches to the file tags instead of the database")
+	fingerprintCmd.Flags().BoolVar(&fpDryRun, "dry-run", false, "only report matches, don't change anything")
+	fingerprintCmd.Flags().Float64Var(&fpMinScore, "min-score", 0.9, "minimum score for a match to be applied")
+	rootCmd.AddCommand(fingerprintCmd)
+}
+
+var fingerprintCmd = &cobra.Command{
+	Use:   "fingerprint [path]",
+	Short: "Identify files using audio fingerprints",
+	Long: "Fingerprint the files under a path, in an album or in a whole library, look them up on AcoustID " +
+		"and apply matches scoring at least --min-score. Useful for headless servers and scripted cleanups",
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var path string
+		if len(args) > 0 {
+			path = args[0]
+		}
+		runFingerprint(cmd.Context(), path)
+	},
+}
+
+func runFingerprint(ctx context.Context, path string) {
+	sqlDB := db.Db()
+	defer db.Db().Close()
+	ds := persistence.New(sqlDB)
+
+	svc := fingerprint.NewService(ds)
+	if !svc.IsEnabled() {
+		log.Fatal(ctx, "Fingerprinting is not available. Check Fingerprint.Enabled, Fingerprint.AcoustIDApiKey and that fpcalc is installed")
+	}
+
+	filter, err := fingerprintFilter(ctx, ds, path)
+	if err != nil {
+		log.Fatal(ctx, "Invalid fingerprint target", err)
+	}
+
+	mfs, err := ds.MediaFile(ctx).GetAll(model.QueryOptions{Filters: filter, Sort: "path"})
+	if err != nil {
+		log.Fatal(ctx, "Error loading files to fingerprint", err)
+	}
+	if len(mfs) == 0 {
+		log.Warn(ctx, "No files found for the given target")
+		return
+	}
+	log.Info(ctx, "Starting identification", "files", len(mfs), "minScore", fpMinScore,
+		"writeTags", fpWriteTags, "dryRun", fpDryRun)
+
+	start := time.Now()
+	matched := 0
+	applied := 0
+	failed := 0
+	for _, mf := range mfs {
+		if ctx.Err() != nil {
+			break
+		}
+		matches, err := identifyWithRetry(ctx, svc, &mf)
+		switch {
+		case errors.Is(err, fingerprint.ErrNoMatch):
+			log.Info(ctx, "No match", "path", mf.Path)
+			continue
+		case err != nil:
+			log.Warn(ctx, "Failed to identify file", "path", mf.Path, err)
+			failed++
+			continue
+		}
+		// Identify may return no candidates without an explicit ErrNoMatch
+		if len(matches) == 0 {
+			log.Info(ctx, "No match", "path", mf.Path)
+			continue
+		}
+
+		best := matches[0]
+		if best.Score < fpMinScore {
+			log.Info(ctx, "Match below minimum score", "path", mf.Path, "title", best.Title,
+				"artist", best.Artist, "score", best.Score)
+			continue
+		}
+		matched++
+		log.Info(ctx, "Match found", "path", mf.Path, "title", best.Title, "artist", best.Artist,
+			"album", best.Album, "score", best.Score)
+		if fpDryRun {
+			continue
+		}
+
+		if fpWriteTags {
+			err = fingerprint.WriteBack(ctx, &mf, best)
+		} else {
+			err = fingerprint.ApplyToDB(ctx, ds, &mf, best)
+		}
+		if err != nil {
+			log.Warn(ctx, "Error applying match", "path", mf.Path, err)
+			failed++
+			continue
+		}
+		applied++
+	}
+
+	log.Info(ctx, "Finished identification", "processed", len(mfs), "matched", matched,
+		"applied", applied, "failed", failed, "duration", time.Since(start))
+	if fpWriteTags && applied > 0 {
+		log.Info(ctx, "Tags were changed on disk - run a scan to import the new metadata")
+	}
+}
+
+// fingerprintFilter translates the command line target (a path, an album ID or
+// a library ID) into a media_file filter. Exactly one target must be given
+func fingerprintFilter(ctx context.Context, ds model.DataStore, path string) (squirrel.Sqlizer, error) {
+	targets := 0
+	for _, set := range []bool{path != "", fpAlbumID != "", fpLibraryID != 0} {
+		if set {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return nil, errors.New("specify exactly one of a path, --album or --library")
+	}
+
+	notMissing := squirrel.Eq{"missing": false}
+	switch {
+	case fpAlbumID != "":
+		return squirrel.And{notMissing, squirrel.Eq{"album_id": fpAlbumID}}, nil
+	case fpLibraryID != 0:
+		return squirrel.And{notMissing, squirrel.Eq{"library_id": fpLibraryID}}, nil
+	}
+
+	// Paths in the database are relative to their library, so find the library
+	// containing the given path first
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	libs, err := ds.Library(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, lib := range libs {
+		if absPath == lib.Path {
+			return squirrel.And{notMissing, squirrel.Eq{"library_id": lib.ID}}, nil
+		}
+		if strings.HasPrefix(absPath, lib.Path+string(filepath.Separator)) {
+			rel := filepath.ToSlash(strings.TrimPrefix(absPath, lib.Path+string(filepath.Separator)))
+			return squirrel.And{
+				notMissing,
+				squirrel.Eq{"library_id": lib.ID},
+				squirrel.Or{squirrel.Eq{"path": rel}, squirrel.Like{"path": rel + "/%"}},
+			}, nil
+		}
+	}
+	return nil, errors.New("path is not inside any library: " + absPath)
+}
+
+// identifyWithRetry wraps Service.Identify with the same backoff used by batch
+// jobs, so a long run survives the occasional server-side throttle
+func identifyWithRetry(ctx context.Context, svc fingerprint.Service, mf *model.MediaFile) ([]fingerprint.MatchResult, error) {
+	matches, err := svc.Identify(ctx, mf)
+	for errors.Is(err, fingerprint.ErrRateLimited) && ctx.Err() == nil {
+		log.Info(ctx, "Rate limited - backing off", "path", mf.Path)
+		select {
+		case <-ctx.Done():
+		case <-time.After(10 * time.Second):
+		}
+		matches, err = svc.Identify(ctx, mf)
+	}
+	return matches, err
+}
@@ -84,6 +84,8 @@ func runNavidrome(ctx context.Context) {
 	g.Go(scheduleDBOptimizer(ctx))
 	g.Go(startPluginManager(ctx))
 	g.Go(startSonosCast(ctx))
+	g.Go(startChromecast(ctx))
+	g.Go(startAirPlay(ctx))
 	g.Go(runInitialScan(ctx))
 	if conf.Server.Scanner.Enabled {
 		g.Go(startScanWatcher(ctx))
@@ -135,6 +137,12 @@ func startServer(ctx context.Context) func() error {
 		if conf.Server.SonosCast.Enabled {
 			a.MountRouter("Sonos Cast API", consts.URLPathSonosCast, CreateSonosCastRouter())
 		}
+		if conf.Server.Chromecast.Enabled {
+			a.MountRouter("Chromecast API", consts.URLPathChromecast, CreateChromecastRouter())
+		}
+		if conf.Server.AirPlay.Enabled {
+			a.MountRouter("AirPlay API", consts.URLPathAirPlay, CreateAirPlayRouter())
+		}
 		return a.Run(ctx, conf.Server.Address, conf.Server.Port, conf.Server.TLSCert, conf.Server.TLSKey)
 	}
 }
@@ -360,6 +368,30 @@ func startSonosCast(ctx context.Context) func() error {
 	}
 }
 
+// startChromecast starts the Chromecast service for device discovery
+func startChromecast(ctx context.Context) func() error {
+	return func() error {
+		if !conf.Server.Chromecast.Enabled {
+			log.Debug("Chromecast is DISABLED")
+			return nil
+		}
+		log.Info(ctx, "Starting Chromecast service")
+		return GetChromecast().Start(ctx)
+	}
+}
+
+// startAirPlay starts the AirPlay service for receiver discovery
+func startAirPlay(ctx context.Context) func() error {
+	return func() error {
+		if !conf.Server.AirPlay.Enabled {
+			log.Debug("AirPlay is DISABLED")
+			return nil
+		}
+		log.Info(ctx, "Starting AirPlay service")
+		return GetAirPlay().Start(ctx)
+	}
+}
+
 // TODO: Implement some struct tags to map flags to viper
 func init() {
 	cobra.OnInitialize(func() {
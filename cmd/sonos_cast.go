@@ -3,22 +3,31 @@ package cmd
 import (
 	"net/http"
 
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/core/agents"
+	"github.com/navidrome/navidrome/core/artwork"
+	"github.com/navidrome/navidrome/core/external"
+	"github.com/navidrome/navidrome/core/ffmpeg"
+	"github.com/navidrome/navidrome/core/scrobbler"
+	"github.com/navidrome/navidrome/server/events"
 	"github.com/navidrome/navidrome/server/sonos_cast"
 )
 
-// Sonos Cast - simple manual instantiation since it doesn't need wire injection
-var sonosCastInstance *sonos_cast.SonosCast
-
+// Sonos Cast - singleton instantiation since it doesn't need wire injection.
+// The Subsonic jukebox adapter shares the same instance
 func GetSonosCast() *sonos_cast.SonosCast {
-	if sonosCastInstance == nil {
-		sonosCastInstance = sonos_cast.NewSonosCast()
-	}
-	return sonosCastInstance
+	return sonos_cast.GetInstance()
 }
 
 func CreateSonosCastRouter() http.Handler {
 	ds := CreateDataStore()
 	sonosService := GetSonosCast()
-	api := sonos_cast.NewAPI(sonosService, ds)
+	fFmpeg := ffmpeg.New()
+	streamer := core.NewMediaStreamer(ds, fFmpeg, core.GetTranscodingCache())
+	provider := external.NewProvider(ds, agents.GetAgents(ds, getPluginManager()))
+	artworkService := artwork.NewArtwork(ds, artwork.GetImageCache(), fFmpeg, provider)
+	share := core.NewShare(ds)
+	plays := scrobbler.GetPlayTracker(ds, events.GetBroker(), getPluginManager())
+	api := sonos_cast.NewAPI(sonosService, ds, streamer, artworkService, share, plays)
 	return api.Router()
 }
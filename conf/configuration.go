@@ -2,6 +2,7 @@ package conf
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -104,6 +105,8 @@ type configOptions struct {
 	ListenBrainz                    listenBrainzOptions `json:",omitzero"`
 	DLNA                            dlnaOptions         `json:",omitzero"`
 	SonosCast                       sonosCastOptions    `json:",omitzero"`
+	Chromecast                      chromecastOptions   `json:",omitzero"`
+	AirPlay                         airplayOptions      `json:",omitzero"`
 	Fingerprint                     fingerprintOptions  `json:",omitzero"`
 	EnableScrobbleHistory           bool
 	Tags                            map[string]TagConf `json:",omitempty"`
@@ -192,25 +195,70 @@ type listenBrainzOptions struct {
 }
 
 type fingerprintOptions struct {
-	Enabled        bool
-	AcoustIDApiKey string
-	FpcalcPath     string
-	CacheResults   bool
-	AutoIdentify   bool
-	BatchSize      int
+	Enabled            bool
+	AcoustIDApiKey     string
+	FpcalcPath         string
+	CacheResults       bool
+	AutoIdentify       bool
+	BatchSize          int
+	WriteBackup        bool
+	Submit             bool
+	SubmitUserKey      string
+	PreferredCountries string
+	PreferredFormats   string
+	ApplyMode          string  // Values: "tags", "db"
+	AutoApplyScore     float64 // Minimum score for automatic application, 0 disables it
+	FetchCoverArt      bool
 }
 
 type dlnaOptions struct {
-	Enabled          bool
-	ServerName       string
-	Interface        string
-	TranscodeProfile string
+	Enabled             bool
+	ServerName          string
+	Interface           string // Comma-separated interface names (or IPs) to advertise on; empty means all
+	TranscodeProfile    string
+	AnnounceInterval    time.Duration // How often SSDP alive notifications are sent
+	CacheMaxAge         time.Duration // Advertised max-age; how long devices may cache our presence
+	User                string        // The user DLNA browsing runs as; empty means the first admin
+	AllowedIPs          string        // Comma-separated CIDR ranges allowed to use the server; empty means all
+	DeniedIPs           string        // Comma-separated CIDR ranges denied access; wins over AllowedIPs
+	DiscGrouping        bool          // Present multi-disc albums as disc sub-containers
+	ReplayGainProfiles  string        // Comma-separated renderer profile names whose transcodes get ReplayGain applied
+	AlphabetIndex       bool          // Split Artists and Albums into A-Z sub-containers
+	HiResMode           string        // How hi-res content is served: passthrough (default), downsample or lpcm
+	HiResProfileModes   string        // Per-profile overrides, e.g. "Samsung TV:lpcm,Sonos:downsample"
+	MaxPageSize         int           // Upper bound for Browse/Search RequestedCount; also the default page size
+	DebugSSDP           bool          // Log all SSDP traffic and subscription activity, with rate summaries
+	Libraries           string        // Comma-separated library IDs or names exposed over DLNA; empty means all
+	PublicPlaylistsOnly bool          // Only expose public playlists over the unauthenticated DLNA tree
 }
 
 type sonosCastOptions struct {
+	Enabled            bool
+	DiscoveryInterval  time.Duration
+	Interface          string
+	SSDPMX             int
+	SSDPTimeout        time.Duration
+	SOAPTimeout        time.Duration // Per-request timeout for SOAP control calls
+	DeviceFetchTimeout time.Duration // Timeout for fetching device descriptions during discovery
+	StreamFormat       string
+	MaxBitRate         int
+	MaxSampleRate      int    // Tracks above this sample rate are transcoded (0 disables the check)
+	ForceTranscoding   bool   // Transcode every stream to StreamFormat, regardless of sample rate
+	JukeboxRoom        string // Sonos room (name or UUID) targeted by the Subsonic Jukebox API
+}
+
+type chromecastOptions struct {
+	Enabled           bool
+	DiscoveryInterval time.Duration
+	Interface         string
+	MDNSTimeout       time.Duration
+}
+
+type airplayOptions struct {
 	Enabled           bool
 	DiscoveryInterval time.Duration
-	StreamFormat      string
+	Interface         string
+	MDNSTimeout       time.Duration
 }
 
 type httpHeaderOptions struct {
@@ -349,6 +397,8 @@ func Load(noConfigDump bool) {
 		validateBackupSchedule,
 		validatePlaylistsPath,
 		validatePurgeMissingOption,
+		validateSonosCastOptions,
+		validateDLNAOptions,
 	)
 	if err != nil {
 		os.Exit(1)
@@ -500,6 +550,69 @@ func validatePurgeMissingOption() error {
 	return nil
 }
 
+func validateSonosCastOptions() error {
+	if !Server.SonosCast.Enabled {
+		return nil
+	}
+	// SSDP MX must be between 1 and 5 per the UPnP spec - devices may ignore
+	// searches with out-of-range values
+	if Server.SonosCast.SSDPMX < 1 || Server.SonosCast.SSDPMX > 5 {
+		log.Warn("Invalid SonosCast.SSDPMX, using default", "value", Server.SonosCast.SSDPMX, "default", 2)
+		Server.SonosCast.SSDPMX = 2
+	}
+	// Non-positive timeouts would make every request fail instantly. Reset
+	// them to their defaults instead of refusing to start
+	for _, t := range []struct {
+		name  string
+		value *time.Duration
+		def   time.Duration
+	}{
+		{"SonosCast.SSDPTimeout", &Server.SonosCast.SSDPTimeout, 3 * time.Second},
+		{"SonosCast.SOAPTimeout", &Server.SonosCast.SOAPTimeout, 10 * time.Second},
+		{"SonosCast.DeviceFetchTimeout", &Server.SonosCast.DeviceFetchTimeout, 5 * time.Second},
+	} {
+		if *t.value <= 0 {
+			log.Warn("Invalid "+t.name+", using default", "value", *t.value, "default", t.def)
+			*t.value = t.def
+		}
+	}
+	return nil
+}
+
+func validateDLNAOptions() error {
+	if !Server.DLNA.Enabled {
+		return nil
+	}
+	if Server.DLNA.ServerName == "" {
+		Server.DLNA.ServerName = "Navidrome"
+	}
+	if Server.DLNA.AnnounceInterval <= 0 {
+		log.Warn("Invalid DLNA.AnnounceInterval, using default", "value", Server.DLNA.AnnounceInterval,
+			"default", 30*time.Minute)
+		Server.DLNA.AnnounceInterval = 30 * time.Minute
+	}
+	// Devices drop the server from their list once max-age expires, so it must
+	// comfortably outlast the announcement interval
+	if Server.DLNA.CacheMaxAge < Server.DLNA.AnnounceInterval {
+		log.Warn("DLNA.CacheMaxAge shorter than the announce interval, adjusting",
+			"value", Server.DLNA.CacheMaxAge, "adjusted", 2*Server.DLNA.AnnounceInterval)
+		Server.DLNA.CacheMaxAge = 2 * Server.DLNA.AnnounceInterval
+	}
+	// Unknown interface names are almost always a typo - fail fast instead of
+	// silently advertising on nothing
+	for _, name := range strings.Split(Server.DLNA.Interface, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || net.ParseIP(name) != nil {
+			continue
+		}
+		if _, err := net.InterfaceByName(name); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "FATAL: Invalid DLNA.Interface %q: %s\n", name, err)
+			return err
+		}
+	}
+	return nil
+}
+
 func validateScanSchedule() error {
 	if Server.Scanner.Schedule == "0" || Server.Scanner.Schedule == "" {
 		Server.Scanner.Schedule = ""
@@ -650,15 +763,54 @@ func setViperDefaults() {
 	viper.SetDefault("dlna.servername", "Navidrome")
 	viper.SetDefault("dlna.interface", "")
 	viper.SetDefault("dlna.transcodeprofile", "auto")
+	viper.SetDefault("dlna.announceinterval", 30*time.Minute)
+	viper.SetDefault("dlna.cachemaxage", time.Hour)
+	viper.SetDefault("dlna.user", "")
+	viper.SetDefault("dlna.allowedips", "")
+	viper.SetDefault("dlna.deniedips", "")
+	viper.SetDefault("dlna.discgrouping", false)
+	viper.SetDefault("dlna.replaygainprofiles", "")
+	viper.SetDefault("dlna.alphabetindex", false)
+	viper.SetDefault("dlna.hiresmode", "")
+	viper.SetDefault("dlna.hiresprofilemodes", "")
+	viper.SetDefault("dlna.maxpagesize", 500)
+	viper.SetDefault("dlna.debugssdp", false)
+	viper.SetDefault("dlna.libraries", "")
+	viper.SetDefault("dlna.publicplaylistsonly", false)
 	viper.SetDefault("sonoscast.enabled", false)
 	viper.SetDefault("sonoscast.discoveryinterval", 5*time.Minute)
+	viper.SetDefault("sonoscast.interface", "")
+	viper.SetDefault("sonoscast.ssdpmx", 2)
+	viper.SetDefault("sonoscast.ssdptimeout", 3*time.Second)
+	viper.SetDefault("sonoscast.soaptimeout", 10*time.Second)
+	viper.SetDefault("sonoscast.devicefetchtimeout", 5*time.Second)
 	viper.SetDefault("sonoscast.streamformat", "flac")
+	viper.SetDefault("sonoscast.maxbitrate", 0)
+	viper.SetDefault("sonoscast.maxsamplerate", 48000)
+	viper.SetDefault("sonoscast.forcetranscoding", false)
+	viper.SetDefault("sonoscast.jukeboxroom", "")
+	viper.SetDefault("chromecast.enabled", false)
+	viper.SetDefault("chromecast.discoveryinterval", 5*time.Minute)
+	viper.SetDefault("chromecast.interface", "")
+	viper.SetDefault("chromecast.mdnstimeout", 3*time.Second)
+	viper.SetDefault("airplay.enabled", false)
+	viper.SetDefault("airplay.discoveryinterval", 5*time.Minute)
+	viper.SetDefault("airplay.interface", "")
+	viper.SetDefault("airplay.mdnstimeout", 3*time.Second)
 	viper.SetDefault("fingerprint.enabled", false)
 	viper.SetDefault("fingerprint.acoustidapikey", "")
 	viper.SetDefault("fingerprint.fpcalcpath", "")
 	viper.SetDefault("fingerprint.cacheresults", true)
 	viper.SetDefault("fingerprint.autoidentify", false)
 	viper.SetDefault("fingerprint.batchsize", 100)
+	viper.SetDefault("fingerprint.writebackup", false)
+	viper.SetDefault("fingerprint.submit", false)
+	viper.SetDefault("fingerprint.submituserkey", "")
+	viper.SetDefault("fingerprint.preferredcountries", "")
+	viper.SetDefault("fingerprint.preferredformats", "")
+	viper.SetDefault("fingerprint.applymode", "tags")
+	viper.SetDefault("fingerprint.autoapplyscore", 0.0)
+	viper.SetDefault("fingerprint.fetchcoverart", false)
 	viper.SetDefault("enablescrobblehistory", true)
 	viper.SetDefault("httpheaders.frameoptions", "DENY")
 	viper.SetDefault("backup.path", "")
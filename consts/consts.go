@@ -17,6 +17,8 @@ const (
 	LastScanErrorKey              = "LastScanError"
 	LastScanTypeKey               = "LastScanType"
 	LastScanStartTimeKey          = "LastScanStartTime"
+	DLNAServerUUIDKey             = "DLNAServerUUID"
+	DLNABootIDKey                 = "DLNABootID"
 
 	UIAuthorizationHeader  = "X-ND-Authorization"
 	UIClientUniqueIDHeader = "X-ND-Client-Unique-Id"
@@ -43,6 +45,8 @@ const (
 	URLPathPublicImages = URLPathPublic + "/img"
 	URLPathDLNA         = "/dlna"
 	URLPathSonosCast    = "/api/cast/sonos"
+	URLPathChromecast   = "/api/cast/chromecast"
+	URLPathAirPlay      = "/api/cast/airplay"
 
 	// DefaultUILoginBackgroundURL uses Navidrome curated background images collection,
 	// available at https://unsplash.com/collections/20072696/navidrome
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/navidrome/navidrome/log"
@@ -14,8 +15,9 @@ import (
 )
 
 const (
-	// AcoustID API endpoint
-	acoustIDURL = "https://api.acoustid.org/v2/lookup"
+	// AcoustID API endpoints
+	acoustIDURL       = "https://api.acoustid.org/v2/lookup"
+	acoustIDSubmitURL = "https://api.acoustid.org/v2/submit"
 
 	// AcoustID rate limit: 3 requests per second
 	acoustIDRateLimit = 3
@@ -30,6 +32,7 @@ type AcoustIDClient struct {
 	apiKey     string
 	httpClient *http.Client
 	limiter    *rate.Limiter
+	cache      *responseCache
 }
 
 // AcoustIDResponse represents the response from AcoustID API
@@ -84,8 +87,9 @@ func NewAcoustIDClient(apiKey string) *AcoustIDClient {
 		httpClient: &http.Client{
 			Timeout: acoustIDTimeout,
 		},
-		// Rate limit: 3 requests per second
-		limiter: rate.NewLimiter(rate.Every(time.Second/acoustIDRateLimit), acoustIDBurst),
+		// Process-wide limiter, shared by all client instances
+		limiter: acoustIDLimiter,
+		cache:   newResponseCache("acoustid", acoustIDCacheTTL),
 	}
 }
 
@@ -94,8 +98,28 @@ func (c *AcoustIDClient) IsConfigured() bool {
 	return c.apiKey != ""
 }
 
-// Lookup queries AcoustID for recordings matching the given fingerprint
+// Lookup queries AcoustID for recordings matching the given fingerprint. The
+// call goes through the shared lookup queue, which serializes all external
+// requests process-wide. Responses are cached on disk, and hits are served
+// without touching the queue or the rate limiter
 func (c *AcoustIDClient) Lookup(ctx context.Context, fingerprint string, duration int) (*AcoustIDResponse, error) {
+	cacheKey := fmt.Sprintf("lookup:%d:%s", duration, fingerprint)
+	var response *AcoustIDResponse
+	if c.cache.get(ctx, cacheKey, &response) {
+		return response, nil
+	}
+	err := GetLookupQueue().Do(ctx, func() error {
+		var err error
+		response, err = c.lookup(ctx, fingerprint, duration)
+		return err
+	})
+	if err == nil {
+		c.cache.put(ctx, cacheKey, response)
+	}
+	return response, err
+}
+
+func (c *AcoustIDClient) lookup(ctx context.Context, fingerprint string, duration int) (*AcoustIDResponse, error) {
 	if !c.IsConfigured() {
 		return nil, fmt.Errorf("acoustid API key not configured")
 	}
@@ -153,3 +177,86 @@ func (c *AcoustIDClient) Lookup(ctx context.Context, fingerprint string, duratio
 
 	return &response, nil
 }
+
+// AcoustIDSubmission is one fingerprint/MBID pair to be contributed back to
+// the AcoustID database
+type AcoustIDSubmission struct {
+	Fingerprint    string
+	Duration       int
+	MbzRecordingID string
+}
+
+// Submit posts a batch of fingerprints of already-identified files to the
+// AcoustID submission API. Submissions require a user API key in addition to
+// the application key, and go through the shared lookup queue like lookups
+func (c *AcoustIDClient) Submit(ctx context.Context, userKey string, submissions []AcoustIDSubmission) error {
+	return GetLookupQueue().Do(ctx, func() error {
+		return c.submit(ctx, userKey, submissions)
+	})
+}
+
+func (c *AcoustIDClient) submit(ctx context.Context, userKey string, submissions []AcoustIDSubmission) error {
+	if !c.IsConfigured() {
+		return fmt.Errorf("acoustid API key not configured")
+	}
+	if userKey == "" {
+		return fmt.Errorf("acoustid user key not configured")
+	}
+	if len(submissions) == 0 {
+		return nil
+	}
+
+	// Wait for rate limiter
+	if err := c.limiter.Wait(ctx); err != nil {
+		if ctx.Err() == context.DeadlineExceeded || ctx.Err() == context.Canceled {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: %s", ErrRateLimited, err)
+	}
+
+	params := url.Values{
+		"client": {c.apiKey},
+		"user":   {userKey},
+	}
+	for i, sub := range submissions {
+		suffix := "." + strconv.Itoa(i)
+		params.Set("fingerprint"+suffix, sub.Fingerprint)
+		params.Set("duration"+suffix, strconv.Itoa(sub.Duration))
+		params.Set("mbid"+suffix, sub.MbzRecordingID)
+	}
+
+	log.Debug(ctx, "AcoustID submit", "submissions", len(submissions))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, acoustIDSubmitURL,
+		strings.NewReader(params.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", "Navidrome/1.0 (https://navidrome.org)")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("acoustid request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("acoustid returned status %d", resp.StatusCode)
+	}
+
+	var response AcoustIDResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode acoustid response: %w", err)
+	}
+
+	if response.Status != "ok" {
+		if response.Error != nil {
+			return response.Error
+		}
+		return fmt.Errorf("acoustid returned status: %s", response.Status)
+	}
+
+	return nil
+}
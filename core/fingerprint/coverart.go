@@ -0,0 +1,129 @@
+package fingerprint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+const (
+	// Cover Art Archive front cover endpoints (they redirect to the actual
+	// image). Release and release-group MBIDs are different namespaces, so the
+	// right endpoint for the candidate's ID kind must be used
+	coverArtReleaseURL      = "https://coverartarchive.org/release/%s/front"
+	coverArtReleaseGroupURL = "https://coverartarchive.org/release-group/%s/front"
+
+	// Covers can be large, so allow more time than the metadata lookups
+	coverArtTimeout = 30 * time.Second
+)
+
+// FetchCoverArt downloads the front cover of the matched release from the
+// Cover Art Archive and saves it next to the album, where the scanner picks it
+// up like any other external cover. Albums that already have an image file in
+// their folder are left alone, and a missing cover on the archive side is not
+// an error - the match itself is still good
+func FetchCoverArt(ctx context.Context, mf *model.MediaFile, match MatchResult) error {
+	if len(match.Releases) == 0 || match.Releases[0].MusicBrainzID == "" {
+		return nil
+	}
+	dir := filepath.Dir(mf.AbsolutePath())
+	if hasImageFile(dir) {
+		return nil
+	}
+
+	mbid := match.Releases[0].MusicBrainzID
+	endpoint := coverArtReleaseURL
+	if match.Releases[0].IsReleaseGroup {
+		endpoint = coverArtReleaseGroupURL
+	}
+	requestURL := fmt.Sprintf(endpoint, mbid)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", musicBrainzUserAgent)
+
+	client := &http.Client{Timeout: coverArtTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("coverartarchive request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		log.Debug(ctx, "No cover art available for release", "mbid", mbid)
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("coverartarchive returned status %d", resp.StatusCode)
+	}
+
+	ext := ".jpg"
+	if resp.Header.Get("Content-Type") == "image/png" {
+		ext = ".png"
+	}
+	coverPath := filepath.Join(dir, "cover"+ext)
+	if err := saveCover(dir, coverPath, resp.Body); err != nil {
+		return fmt.Errorf("saving cover to %s: %w", coverPath, err)
+	}
+
+	log.Info(ctx, "Downloaded cover art for matched release", "mbid", mbid, "path", coverPath)
+	return nil
+}
+
+// fetchCoverArtIfEnabled runs FetchCoverArt when Fingerprint.FetchCoverArt is
+// set. Failures are logged but not propagated - the metadata fix has already
+// landed at this point, and a missing cover shouldn't undo it
+func fetchCoverArtIfEnabled(ctx context.Context, mf *model.MediaFile, match MatchResult) {
+	if !conf.Server.Fingerprint.FetchCoverArt {
+		return
+	}
+	if err := FetchCoverArt(ctx, mf, match); err != nil {
+		log.Warn(ctx, "Error fetching cover art for matched release", "path", mf.Path, err)
+	}
+}
+
+// hasImageFile returns true if the folder already contains an image, so
+// existing (possibly hand-picked) covers are never overwritten
+func hasImageFile(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && model.IsImageFile(entry.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// saveCover writes the image through a temp file and renames it into place,
+// so the scanner never sees a partially downloaded cover
+func saveCover(dir, path string, r io.Reader) error {
+	tmp, err := os.CreateTemp(dir, ".cover-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
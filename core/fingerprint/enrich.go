@@ -0,0 +1,77 @@
+package fingerprint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/navidrome/navidrome/model"
+)
+
+// Apply modes for accepted matches. In "tags" mode the corrected metadata is
+// written to the files and picked up by a rescan; in "db" mode only the
+// database is updated, for libraries that live on read-only network shares
+const (
+	ApplyModeTags = "tags"
+	ApplyModeDB   = "db"
+)
+
+// ApplyToDB applies an accepted match to the database records directly,
+// updating MusicBrainz IDs and canonical names on the media file and its album
+// and artist, without touching the files
+func ApplyToDB(ctx context.Context, ds model.DataStore, mf *model.MediaFile, match MatchResult) error {
+	err := ds.WithTx(func(tx model.DataStore) error {
+		if match.Title != "" {
+			mf.Title = match.Title
+		}
+		if match.MusicBrainzID != "" {
+			mf.MbzRecordingID = match.MusicBrainzID
+		}
+		if err := tx.MediaFile(ctx).Put(mf); err != nil {
+			return fmt.Errorf("updating media file %s: %w", mf.ID, err)
+		}
+
+		if mf.AlbumID != "" && (match.Album != "" || len(match.Releases) > 0) {
+			album, err := tx.Album(ctx).Get(mf.AlbumID)
+			if err != nil {
+				return fmt.Errorf("loading album %s: %w", mf.AlbumID, err)
+			}
+			if match.Album != "" {
+				album.Name = match.Album
+			}
+			if len(match.Releases) > 0 && match.Releases[0].MusicBrainzID != "" {
+				// MbzAlbumID holds a release MBID; AcoustID-derived candidates
+				// are release groups and go into the release-group field
+				if match.Releases[0].IsReleaseGroup {
+					album.MbzReleaseGroupID = match.Releases[0].MusicBrainzID
+				} else {
+					album.MbzAlbumID = match.Releases[0].MusicBrainzID
+				}
+			}
+			if err := tx.Album(ctx).Put(album); err != nil {
+				return fmt.Errorf("updating album %s: %w", mf.AlbumID, err)
+			}
+		}
+
+		if mf.ArtistID != "" && (match.Artist != "" || match.MusicBrainzArtistID != "") {
+			artist, err := tx.Artist(ctx).Get(mf.ArtistID)
+			if err != nil {
+				return fmt.Errorf("loading artist %s: %w", mf.ArtistID, err)
+			}
+			if match.Artist != "" {
+				artist.Name = match.Artist
+			}
+			if match.MusicBrainzArtistID != "" {
+				artist.MbzArtistID = match.MusicBrainzArtistID
+			}
+			if err := tx.Artist(ctx).Put(artist, "name", "mbz_artist_id"); err != nil {
+				return fmt.Errorf("updating artist %s: %w", mf.ArtistID, err)
+			}
+		}
+		return nil
+	}, "fingerprint: apply match")
+	if err != nil {
+		return err
+	}
+	fetchCoverArtIfEnabled(ctx, mf, match)
+	return nil
+}
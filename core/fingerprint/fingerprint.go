@@ -3,10 +3,14 @@
 package fingerprint
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"slices"
 
 	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
 )
 
 var (
@@ -30,13 +34,32 @@ type FingerprintResult struct {
 
 // MatchResult represents a single match from fingerprint lookup
 type MatchResult struct {
-	AcoustID    string  `json:"acoustid"`
-	MusicBrainzID string  `json:"musicbrainz_id"`
-	Score       float64 `json:"score"`
-	Title       string  `json:"title"`
-	Artist      string  `json:"artist"`
-	Album       string  `json:"album"`
-	ReleaseDate string  `json:"release_date,omitempty"`
+	AcoustID            string             `json:"acoustid"`
+	MusicBrainzID       string             `json:"musicbrainz_id"`
+	Score               float64            `json:"score"`
+	Title               string             `json:"title"`
+	Artist              string             `json:"artist"`
+	Album               string             `json:"album"`
+	ReleaseDate         string             `json:"release_date,omitempty"`
+	Releases            []ReleaseCandidate `json:"releases,omitempty"`
+	MusicBrainzArtistID string             `json:"musicbrainz_artist_id,omitempty"`
+}
+
+// ReleaseCandidate is one release (album) a matched recording appears on.
+// AcoustID only returns release groups, not concrete releases, so candidates
+// built straight from its response carry a release-group MBID and are flagged
+// as such - the two kinds of ID live in different namespaces and must not be
+// mixed up when they are saved or used to fetch cover art
+type ReleaseCandidate struct {
+	MusicBrainzID string `json:"musicbrainz_id"`
+	Title         string `json:"title"`
+	Type          string `json:"type,omitempty"`
+	Date          string `json:"date,omitempty"`
+	Country       string `json:"country,omitempty"`
+	Status        string `json:"status,omitempty"`
+	// IsReleaseGroup marks MusicBrainzID as a release-group MBID instead of a
+	// release MBID
+	IsReleaseGroup bool `json:"is_release_group,omitempty"`
 }
 
 // Service provides audio fingerprinting functionality
@@ -50,19 +73,21 @@ type Service interface {
 	// Lookup searches for matches using the given fingerprint
 	Lookup(ctx context.Context, fingerprint string, duration int) ([]MatchResult, error)
 
-	// Identify generates a fingerprint and looks up matches in one call
-	Identify(ctx context.Context, filePath string) ([]MatchResult, error)
+	// Identify generates a fingerprint for the file and looks up matches in one
+	// call, using the database cache when Fingerprint.CacheResults is enabled
+	Identify(ctx context.Context, mf *model.MediaFile) ([]MatchResult, error)
 }
 
 // service implements the Service interface
 type service struct {
+	ds          model.DataStore
 	chromaprint *ChromaprintWrapper
 	acoustid    *AcoustIDClient
 	musicbrainz *MusicBrainzClient
 }
 
 // NewService creates a new fingerprint service
-func NewService() Service {
+func NewService(ds model.DataStore) Service {
 	if !conf.Server.Fingerprint.Enabled {
 		return &disabledService{}
 	}
@@ -72,6 +97,7 @@ func NewService() Service {
 	musicbrainz := NewMusicBrainzClient()
 
 	return &service{
+		ds:          ds,
 		chromaprint: chromaprint,
 		acoustid:    acoustid,
 		musicbrainz: musicbrainz,
@@ -114,28 +140,114 @@ func (s *service) Lookup(ctx context.Context, fingerprint string, duration int)
 				Score:         result.Score,
 				Title:         recording.Title,
 			}
-			// Get artist name from the first artist
+			// Get artist name and MBID from the first artist
 			if len(recording.Artists) > 0 {
 				match.Artist = recording.Artists[0].Name
+				match.MusicBrainzArtistID = recording.Artists[0].ID
+			}
+			// Keep all release groups the recording appears on as candidates,
+			// so the right album can be picked when accepting the match
+			for _, release := range recording.Releases {
+				match.Releases = append(match.Releases, ReleaseCandidate{
+					MusicBrainzID:  release.ID,
+					Title:          release.Title,
+					Type:           release.Type,
+					IsReleaseGroup: true,
+				})
+			}
+			if len(match.Releases) > 0 {
+				match.Album = match.Releases[0].Title
 			}
 			matches = append(matches, match)
 		}
 	}
 
+	// Best matches first
+	slices.SortStableFunc(matches, func(a, b MatchResult) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	// When the top recording appears on many releases, rank them with the
+	// configured preferences instead of keeping an arbitrary first entry
+	if len(matches) > 0 && len(matches[0].Releases) > 1 {
+		s.applyReleasePreferences(ctx, &matches[0])
+	}
+
 	return matches, nil
 }
 
-func (s *service) Identify(ctx context.Context, filePath string) ([]MatchResult, error) {
+func (s *service) Identify(ctx context.Context, mf *model.MediaFile) ([]MatchResult, error) {
 	if !s.IsEnabled() {
 		return nil, ErrDisabled
 	}
 
-	fp, err := s.Generate(ctx, filePath)
+	fp, err := s.fingerprintFor(ctx, mf)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.Lookup(ctx, fp.Fingerprint, fp.Duration)
+	matches, err := s.Lookup(ctx, fp.Fingerprint, fp.Duration)
+	if err != nil {
+		return nil, err
+	}
+	s.saveBestMatch(ctx, mf.ID, matches)
+	return matches, nil
+}
+
+// cacheEnabled returns true when results should be persisted to the database
+func (s *service) cacheEnabled() bool {
+	return s.ds != nil && conf.Server.Fingerprint.CacheResults
+}
+
+// fingerprintFor returns the cached fingerprint when the file hasn't changed
+// since it was generated, running fpcalc (and caching the result) otherwise
+func (s *service) fingerprintFor(ctx context.Context, mf *model.MediaFile) (*FingerprintResult, error) {
+	if s.cacheEnabled() {
+		cached, err := s.ds.Fingerprint(ctx).GetFingerprint(mf.ID)
+		if err == nil && !cached.UpdatedAt.Before(mf.UpdatedAt) {
+			return &FingerprintResult{Duration: cached.Duration, Fingerprint: cached.Fingerprint}, nil
+		}
+	}
+
+	fp, err := s.Generate(ctx, mf.AbsolutePath())
+	if err != nil {
+		return nil, err
+	}
+	if s.cacheEnabled() {
+		err = s.ds.Fingerprint(ctx).PutFingerprint(&model.Fingerprint{
+			MediaFileID: mf.ID,
+			Fingerprint: fp.Fingerprint,
+			Duration:    fp.Duration,
+		})
+		if err != nil {
+			log.Warn(ctx, "Error caching fingerprint", "id", mf.ID, err)
+		}
+	}
+	return fp, nil
+}
+
+// saveBestMatch persists the top-ranked match, so it survives restarts and the
+// file is not looked up again by later scans
+func (s *service) saveBestMatch(ctx context.Context, mediaFileID string, matches []MatchResult) {
+	if !s.cacheEnabled() || len(matches) == 0 {
+		return
+	}
+	best := matches[0]
+	m := &model.AcoustIDMatch{
+		MediaFileID:    mediaFileID,
+		AcoustID:       best.AcoustID,
+		MbzRecordingID: best.MusicBrainzID,
+		Score:          best.Score,
+	}
+	// Only a concrete release MBID belongs in MbzReleaseID - AcoustID-derived
+	// candidates are release groups (unless applyReleasePreferences already
+	// swapped in the recording's real releases)
+	if len(best.Releases) > 0 && !best.Releases[0].IsReleaseGroup {
+		m.MbzReleaseID = best.Releases[0].MusicBrainzID
+	}
+	if err := s.ds.Fingerprint(ctx).PutMatch(m); err != nil {
+		log.Warn(ctx, "Error saving AcoustID match", "id", mediaFileID, err)
+	}
 }
 
 // disabledService is a no-op implementation when fingerprinting is disabled
@@ -148,6 +260,6 @@ func (d *disabledService) Generate(ctx context.Context, filePath string) (*Finge
 func (d *disabledService) Lookup(ctx context.Context, fingerprint string, duration int) ([]MatchResult, error) {
 	return nil, ErrDisabled
 }
-func (d *disabledService) Identify(ctx context.Context, filePath string) ([]MatchResult, error) {
+func (d *disabledService) Identify(ctx context.Context, mf *model.MediaFile) ([]MatchResult, error) {
 	return nil, ErrDisabled
 }
@@ -0,0 +1,210 @@
+package fingerprint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/id"
+	"github.com/navidrome/navidrome/server/events"
+	"github.com/navidrome/navidrome/utils/singleton"
+)
+
+// Kinds of targets a batch identification job can cover
+const (
+	JobKindAlbum   = "album"
+	JobKindFolder  = "folder"
+	JobKindLibrary = "library"
+)
+
+// ErrJobRunning is returned when a batch identification job is already in flight
+var ErrJobRunning = errors.New("an identification job is already running")
+
+// rateLimitBackoff is how long a job waits before retrying a file after the
+// AcoustID server rejects a request despite the client-side rate limiter
+const rateLimitBackoff = 10 * time.Second
+
+// JobStatus is a snapshot of a batch identification job
+type JobStatus struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	TargetID  string    `json:"targetId"`
+	Running   bool      `json:"running"`
+	Total     int       `json:"total"`
+	Processed int       `json:"processed"`
+	Matched   int       `json:"matched"`
+	Failed    int       `json:"failed"`
+	Error     string    `json:"error,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// JobRunner runs batch identification jobs in the background, fingerprinting
+// every file in an album, folder or library and storing candidate matches for
+// review. Progress is broadcast through the events channel after each file.
+// Only one job runs at a time, since all lookups share the same AcoustID rate
+// limit and a large run can already take hours on its own
+type JobRunner struct {
+	mu     sync.Mutex
+	status JobStatus
+	cancel context.CancelFunc
+}
+
+// GetJobRunner returns the shared JobRunner instance
+func GetJobRunner() *JobRunner {
+	return singleton.GetInstance(func() *JobRunner {
+		return &JobRunner{}
+	})
+}
+
+// Start launches a job covering the given target, returning its ID. The job
+// keeps running after the originating request finishes, until it processes all
+// files or Cancel is called
+func (j *JobRunner) Start(ctx context.Context, ds model.DataStore, kind, targetID string) (string, error) {
+	svc := NewService(ds)
+	if !svc.IsEnabled() {
+		return "", ErrDisabled
+	}
+
+	var filter squirrel.Eq
+	switch kind {
+	case JobKindAlbum:
+		filter = squirrel.Eq{"album_id": targetID}
+	case JobKindFolder:
+		filter = squirrel.Eq{"folder_id": targetID}
+	case JobKindLibrary:
+		filter = squirrel.Eq{"library_id": targetID}
+	default:
+		return "", fmt.Errorf("invalid identification job kind %q", kind)
+	}
+
+	mfs, err := ds.MediaFile(ctx).GetAll(model.QueryOptions{Filters: filter})
+	if err != nil {
+		return "", err
+	}
+	if len(mfs) == 0 {
+		return "", model.ErrNotFound
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status.Running {
+		return "", ErrJobRunning
+	}
+
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	j.cancel = cancel
+	j.status = JobStatus{
+		ID:        id.NewRandom(),
+		Kind:      kind,
+		TargetID:  targetID,
+		Running:   true,
+		Total:     len(mfs),
+		StartedAt: time.Now(),
+	}
+	// Jobs are batch work: their lookups yield to interactive requests
+	go j.run(WithBatchPriority(jobCtx), svc, mfs)
+
+	log.Info(ctx, "Started identification job", "jobId", j.status.ID, "kind", kind,
+		"targetId", targetID, "files", len(mfs))
+	return j.status.ID, nil
+}
+
+// Cancel stops the running job, if any. The job finishes the file it is
+// currently processing and then reports a final (non-running) status
+func (j *JobRunner) Cancel() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status.Running && j.cancel != nil {
+		j.cancel()
+	}
+}
+
+// Status returns a snapshot of the current (or last finished) job
+func (j *JobRunner) Status() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+func (j *JobRunner) run(ctx context.Context, svc Service, mfs model.MediaFiles) {
+	store := GetMatchStore()
+	for _, mf := range mfs {
+		if ctx.Err() != nil {
+			break
+		}
+		matches, err := svc.Identify(ctx, &mf)
+		for errors.Is(err, ErrRateLimited) {
+			// The server is throttling us beyond the client-side limiter:
+			// back off and retry the same file instead of burning through
+			// the rest of the batch
+			log.Debug(ctx, "Identification job rate limited - backing off", "path", mf.Path,
+				"backoff", rateLimitBackoff)
+			select {
+			case <-ctx.Done():
+			case <-time.After(rateLimitBackoff):
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			matches, err = svc.Identify(ctx, &mf)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		j.mu.Lock()
+		j.status.Processed++
+		switch {
+		case errors.Is(err, ErrNoMatch):
+		case err != nil:
+			log.Debug(ctx, "Identification job failed to identify file", "path", mf.Path, err)
+			j.status.Failed++
+		default:
+			store.Put(mf.ID, mf.Path, matches)
+			j.status.Matched++
+		}
+		j.mu.Unlock()
+		j.broadcast(ctx)
+	}
+	j.finish(ctx)
+}
+
+func (j *JobRunner) finish(ctx context.Context) {
+	j.mu.Lock()
+	j.status.Running = false
+	if ctx.Err() != nil {
+		j.status.Error = "cancelled"
+	}
+	status := j.status
+	if j.cancel != nil {
+		j.cancel()
+		j.cancel = nil
+	}
+	j.mu.Unlock()
+
+	log.Info(ctx, "Identification job finished", "jobId", status.ID, "processed", status.Processed,
+		"matched", status.Matched, "failed", status.Failed, "duration", time.Since(status.StartedAt))
+	j.broadcast(context.WithoutCancel(ctx))
+}
+
+func (j *JobRunner) broadcast(ctx context.Context) {
+	j.mu.Lock()
+	status := j.status
+	j.mu.Unlock()
+	events.GetBroker().SendBroadcastMessage(ctx, &events.IdentifyProgress{
+		JobID:     status.ID,
+		Kind:      status.Kind,
+		TargetID:  status.TargetID,
+		Running:   status.Running,
+		Total:     status.Total,
+		Processed: status.Processed,
+		Matched:   status.Matched,
+		Failed:    status.Failed,
+		Error:     status.Error,
+	})
+}
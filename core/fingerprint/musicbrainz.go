@@ -30,6 +30,7 @@ const (
 type MusicBrainzClient struct {
 	httpClient *http.Client
 	limiter    *rate.Limiter
+	cache      *responseCache
 }
 
 // MBRecording represents a recording from MusicBrainz
@@ -65,6 +66,12 @@ type MBRelease struct {
 	Date        string         `json:"date,omitempty"`
 	Country     string         `json:"country,omitempty"`
 	ReleaseGroup *MBReleaseGroup `json:"release-group,omitempty"`
+	Media        []MBMedia       `json:"media,omitempty"`
+}
+
+// MBMedia represents a medium of a release (CD, Vinyl, Digital Media, ...)
+type MBMedia struct {
+	Format string `json:"format,omitempty"`
 }
 
 // MBReleaseGroup represents a release group in MusicBrainz
@@ -99,13 +106,32 @@ func NewMusicBrainzClient() *MusicBrainzClient {
 		httpClient: &http.Client{
 			Timeout: musicBrainzTimeout,
 		},
-		// Rate limit: 1 request per second (slightly slower to be safe)
-		limiter: rate.NewLimiter(rate.Every(1100*time.Millisecond), musicBrainzBurst),
+		// Process-wide limiter, shared by all client instances
+		limiter: musicBrainzLimiter,
+		cache:   newResponseCache("musicbrainz", musicBrainzCacheTTL),
 	}
 }
 
-// GetRecording fetches detailed recording information from MusicBrainz
+// GetRecording fetches detailed recording information from MusicBrainz. Like
+// all client calls, it goes through the shared lookup queue, with responses
+// cached on disk keyed by MBID
 func (c *MusicBrainzClient) GetRecording(ctx context.Context, mbid string) (*MBRecording, error) {
+	var recording *MBRecording
+	if c.cache.get(ctx, "recording:"+mbid, &recording) {
+		return recording, nil
+	}
+	err := GetLookupQueue().Do(ctx, func() error {
+		var err error
+		recording, err = c.getRecording(ctx, mbid)
+		return err
+	})
+	if err == nil {
+		c.cache.put(ctx, "recording:"+mbid, recording)
+	}
+	return recording, err
+}
+
+func (c *MusicBrainzClient) getRecording(ctx context.Context, mbid string) (*MBRecording, error) {
 	// Wait for rate limiter
 	if err := c.limiter.Wait(ctx); err != nil {
 		if ctx.Err() == context.DeadlineExceeded || ctx.Err() == context.Canceled {
@@ -115,7 +141,7 @@ func (c *MusicBrainzClient) GetRecording(ctx context.Context, mbid string) (*MBR
 	}
 
 	// Build request URL with includes
-	requestURL := fmt.Sprintf("%s/recording/%s?fmt=json&inc=artists+releases+release-groups+tags",
+	requestURL := fmt.Sprintf("%s/recording/%s?fmt=json&inc=artists+releases+release-groups+media+tags",
 		musicBrainzURL, mbid)
 
 	log.Debug(ctx, "MusicBrainz lookup", "mbid", mbid)
@@ -161,6 +187,22 @@ func (c *MusicBrainzClient) GetRecording(ctx context.Context, mbid string) (*MBR
 
 // GetArtist fetches detailed artist information from MusicBrainz
 func (c *MusicBrainzClient) GetArtist(ctx context.Context, mbid string) (*MBArtist, error) {
+	var artist *MBArtist
+	if c.cache.get(ctx, "artist:"+mbid, &artist) {
+		return artist, nil
+	}
+	err := GetLookupQueue().Do(ctx, func() error {
+		var err error
+		artist, err = c.getArtist(ctx, mbid)
+		return err
+	})
+	if err == nil {
+		c.cache.put(ctx, "artist:"+mbid, artist)
+	}
+	return artist, err
+}
+
+func (c *MusicBrainzClient) getArtist(ctx context.Context, mbid string) (*MBArtist, error) {
 	// Wait for rate limiter
 	if err := c.limiter.Wait(ctx); err != nil {
 		if ctx.Err() == context.DeadlineExceeded || ctx.Err() == context.Canceled {
@@ -211,6 +253,22 @@ func (c *MusicBrainzClient) GetArtist(ctx context.Context, mbid string) (*MBArti
 
 // GetRelease fetches detailed release information from MusicBrainz
 func (c *MusicBrainzClient) GetRelease(ctx context.Context, mbid string) (*MBRelease, error) {
+	var release *MBRelease
+	if c.cache.get(ctx, "release:"+mbid, &release) {
+		return release, nil
+	}
+	err := GetLookupQueue().Do(ctx, func() error {
+		var err error
+		release, err = c.getRelease(ctx, mbid)
+		return err
+	})
+	if err == nil {
+		c.cache.put(ctx, "release:"+mbid, release)
+	}
+	return release, err
+}
+
+func (c *MusicBrainzClient) getRelease(ctx context.Context, mbid string) (*MBRelease, error) {
 	// Wait for rate limiter
 	if err := c.limiter.Wait(ctx); err != nil {
 		if ctx.Err() == context.DeadlineExceeded || ctx.Err() == context.Canceled {
@@ -0,0 +1,111 @@
+package fingerprint
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+)
+
+// Release selection mirrors Picard's behavior: when a recording appears on
+// many releases, official releases in the user's preferred countries and
+// formats are ranked first, with the original (earliest) release breaking
+// ties, instead of picking an arbitrary first entry.
+
+// applyReleasePreferences replaces the match's release candidates with the
+// recording's full release list from MusicBrainz, ranked by the configured
+// preferences. Failures leave the AcoustID-derived candidates untouched
+func (s *service) applyReleasePreferences(ctx context.Context, match *MatchResult) {
+	recording, err := s.musicbrainz.GetRecording(ctx, match.MusicBrainzID)
+	if err != nil {
+		log.Debug(ctx, "Could not rank releases for match", "mbid", match.MusicBrainzID, err)
+		return
+	}
+	if len(recording.Releases) == 0 {
+		return
+	}
+
+	sorted := sortReleasesByPreference(recording.Releases)
+	candidates := make([]ReleaseCandidate, 0, len(sorted))
+	for _, rel := range sorted {
+		candidate := ReleaseCandidate{
+			MusicBrainzID: rel.ID,
+			Title:         rel.Title,
+			Date:          rel.Date,
+			Country:       rel.Country,
+			Status:        rel.Status,
+		}
+		if rel.ReleaseGroup != nil {
+			candidate.Type = rel.ReleaseGroup.PrimaryType
+		}
+		candidates = append(candidates, candidate)
+	}
+	match.Releases = candidates
+	match.Album = sorted[0].Title
+	if match.ReleaseDate == "" {
+		match.ReleaseDate = sorted[0].Date
+	}
+}
+
+// releaseScore ranks a release against the configured preferences. Higher is
+// better
+func releaseScore(rel MBRelease) int {
+	score := 0
+	if strings.EqualFold(rel.Status, "Official") {
+		score += 1000
+	}
+	score += preferenceBonus(conf.Server.Fingerprint.PreferredCountries, rel.Country, 500)
+	for _, media := range rel.Media {
+		if bonus := preferenceBonus(conf.Server.Fingerprint.PreferredFormats, media.Format, 250); bonus > 0 {
+			score += bonus
+			break
+		}
+	}
+	return score
+}
+
+// preferenceBonus returns a bonus when value appears in the comma-separated
+// preference list, decreasing with its position so earlier entries win
+func preferenceBonus(preferences, value string, base int) int {
+	if preferences == "" || value == "" {
+		return 0
+	}
+	for i, pref := range strings.Split(preferences, ",") {
+		if strings.EqualFold(strings.TrimSpace(pref), value) {
+			bonus := base - i*10
+			if bonus < 10 {
+				bonus = 10
+			}
+			return bonus
+		}
+	}
+	return 0
+}
+
+// sortReleasesByPreference orders releases best-first, using the score and
+// falling back to the earliest release date (undated releases sort last)
+func sortReleasesByPreference(releases []MBRelease) []MBRelease {
+	sorted := slices.Clone(releases)
+	slices.SortStableFunc(sorted, func(a, b MBRelease) int {
+		if c := cmp.Compare(releaseScore(b), releaseScore(a)); c != 0 {
+			return c
+		}
+		return cmp.Compare(sortableDate(a.Date), sortableDate(b.Date))
+	})
+	return sorted
+}
+
+// sortableDate normalizes a MusicBrainz date ("2001", "2001-05", "2001-05-21")
+// so incomplete dates compare correctly and empty dates sort last
+func sortableDate(date string) string {
+	if date == "" {
+		return "9999-99-99"
+	}
+	for len(date) < len("2001-05-21") {
+		date += "-99"
+	}
+	return date
+}
@@ -0,0 +1,116 @@
+package fingerprint
+
+import (
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/conf/configtest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Release ranking", func() {
+	BeforeEach(func() {
+		DeferCleanup(configtest.SetupConfig())
+	})
+
+	Describe("releaseScore", func() {
+		It("prefers official releases", func() {
+			official := MBRelease{Status: "Official"}
+			bootleg := MBRelease{Status: "Bootleg"}
+			Expect(releaseScore(official)).To(BeNumerically(">", releaseScore(bootleg)))
+		})
+
+		It("matches the status case-insensitively", func() {
+			Expect(releaseScore(MBRelease{Status: "official"})).To(Equal(1000))
+		})
+
+		It("rewards preferred countries", func() {
+			conf.Server.Fingerprint.PreferredCountries = "GB,US"
+			gb := MBRelease{Country: "GB"}
+			us := MBRelease{Country: "US"}
+			de := MBRelease{Country: "DE"}
+			Expect(releaseScore(gb)).To(BeNumerically(">", releaseScore(us)))
+			Expect(releaseScore(us)).To(BeNumerically(">", releaseScore(de)))
+		})
+
+		It("rewards preferred formats, counting each release once", func() {
+			conf.Server.Fingerprint.PreferredFormats = "CD"
+			single := MBRelease{Media: []MBMedia{{Format: "CD"}}}
+			double := MBRelease{Media: []MBMedia{{Format: "CD"}, {Format: "CD"}}}
+			Expect(releaseScore(double)).To(Equal(releaseScore(single)))
+		})
+
+		It("scores zero without preferences", func() {
+			Expect(releaseScore(MBRelease{Status: "Bootleg", Country: "GB"})).To(Equal(0))
+		})
+	})
+
+	Describe("preferenceBonus", func() {
+		It("decreases with the position in the preference list", func() {
+			Expect(preferenceBonus("GB,US,DE", "GB", 500)).To(Equal(500))
+			Expect(preferenceBonus("GB,US,DE", "US", 500)).To(Equal(490))
+			Expect(preferenceBonus("GB, us ,DE", "US", 500)).To(Equal(490))
+		})
+
+		It("never drops below the minimum bonus", func() {
+			prefs := "A,B,C,D,E,F,G,H,I,J,K,L,M,N,O,P,Q,R,S,T,U,V,W,X,Y,Z"
+			Expect(preferenceBonus(prefs, "Z", 250)).To(Equal(10))
+		})
+
+		It("returns zero for values not in the list", func() {
+			Expect(preferenceBonus("GB,US", "DE", 500)).To(BeZero())
+			Expect(preferenceBonus("", "GB", 500)).To(BeZero())
+			Expect(preferenceBonus("GB", "", 500)).To(BeZero())
+		})
+	})
+
+	Describe("sortReleasesByPreference", func() {
+		It("ranks official releases in preferred countries first", func() {
+			conf.Server.Fingerprint.PreferredCountries = "GB"
+			releases := []MBRelease{
+				{ID: "promo", Status: "Promotion", Country: "GB"},
+				{ID: "us", Status: "Official", Country: "US"},
+				{ID: "gb", Status: "Official", Country: "GB"},
+			}
+			sorted := sortReleasesByPreference(releases)
+			Expect(sorted[0].ID).To(Equal("gb"))
+			Expect(sorted[1].ID).To(Equal("us"))
+			Expect(sorted[2].ID).To(Equal("promo"))
+		})
+
+		It("breaks ties with the earliest release date", func() {
+			releases := []MBRelease{
+				{ID: "reissue", Status: "Official", Date: "2010-03-01"},
+				{ID: "original", Status: "Official", Date: "1984"},
+			}
+			sorted := sortReleasesByPreference(releases)
+			Expect(sorted[0].ID).To(Equal("original"))
+		})
+
+		It("sorts undated releases last", func() {
+			releases := []MBRelease{
+				{ID: "undated", Status: "Official"},
+				{ID: "dated", Status: "Official", Date: "1999"},
+			}
+			sorted := sortReleasesByPreference(releases)
+			Expect(sorted[0].ID).To(Equal("dated"))
+		})
+
+		It("does not modify the input slice", func() {
+			releases := []MBRelease{
+				{ID: "b", Status: "Bootleg"},
+				{ID: "a", Status: "Official"},
+			}
+			sortReleasesByPreference(releases)
+			Expect(releases[0].ID).To(Equal("b"))
+		})
+	})
+
+	Describe("sortableDate", func() {
+		It("pads incomplete dates and sorts empty dates last", func() {
+			Expect(sortableDate("2001")).To(Equal("2001-99-99"))
+			Expect(sortableDate("2001-05")).To(Equal("2001-05-99"))
+			Expect(sortableDate("2001-05-21")).To(Equal("2001-05-21"))
+			Expect(sortableDate("")).To(Equal("9999-99-99"))
+		})
+	})
+})
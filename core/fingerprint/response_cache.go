@@ -0,0 +1,92 @@
+package fingerprint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+)
+
+// TTLs for cached external responses. AcoustID lookups expire sooner, since
+// new submissions keep improving their results; MusicBrainz entities are
+// mostly static
+const (
+	acoustIDCacheTTL    = 7 * 24 * time.Hour
+	musicBrainzCacheTTL = 30 * 24 * time.Hour
+)
+
+// responseCache stores JSON API responses on disk, one file per key, expiring
+// them by modification time. It lets re-identification and repeated album
+// lookups skip the strict external rate limits entirely - hits are served
+// before a request even enters the lookup queue
+type responseCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// newResponseCache creates a cache rooted at CacheFolder/fingerprint/<name>
+func newResponseCache(name string, ttl time.Duration) *responseCache {
+	return &responseCache{
+		dir: filepath.Join(conf.Server.CacheFolder, "fingerprint", name),
+		ttl: ttl,
+	}
+}
+
+func (c *responseCache) enabled() bool {
+	return conf.Server.Fingerprint.CacheResults && conf.Server.CacheFolder != ""
+}
+
+// path maps a key to a file name. Keys are hashed, so they can safely contain
+// raw fingerprints or any other unbounded data
+func (c *responseCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// get loads the cached value for key into dest, returning false on a miss,
+// an expired entry or any error - callers just fall back to the network
+func (c *responseCache) get(ctx context.Context, key string, dest any) bool {
+	if !c.enabled() {
+		return false
+	}
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > c.ttl {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		log.Debug(ctx, "Discarding unreadable cached response", "path", path, err)
+		_ = os.Remove(path)
+		return false
+	}
+	return true
+}
+
+// put stores value under key. Failures only cost a future cache miss, so they
+// are logged and otherwise ignored
+func (c *responseCache) put(ctx context.Context, key string, value any) {
+	if !c.enabled() {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		log.Debug(ctx, "Error creating response cache folder", "dir", c.dir, err)
+		return
+	}
+	if err := os.WriteFile(c.path(key), data, 0600); err != nil {
+		log.Debug(ctx, "Error writing cached response", "key", key, err)
+	}
+}
@@ -0,0 +1,74 @@
+package fingerprint
+
+import (
+	"sync"
+	"time"
+
+	"github.com/navidrome/navidrome/utils/singleton"
+)
+
+// PendingMatch holds the candidate matches found for one file, waiting for a
+// user to review and accept them
+type PendingMatch struct {
+	MediaFileID string        `json:"mediaFileId"`
+	Path        string        `json:"path"`
+	Matches     []MatchResult `json:"matches"`
+	CreatedAt   time.Time     `json:"createdAt"`
+}
+
+// MatchStore keeps the pending matches produced by automatic identification,
+// so they can be reviewed later instead of requiring action per file during
+// the scan. It is kept in memory - matches are recreated by the next scan
+type MatchStore struct {
+	mu      sync.RWMutex
+	matches map[string]*PendingMatch
+}
+
+// GetMatchStore returns the shared pending-match store
+func GetMatchStore() *MatchStore {
+	return singleton.GetInstance(func() *MatchStore {
+		return &MatchStore{matches: map[string]*PendingMatch{}}
+	})
+}
+
+// Put stores the matches found for a media file, replacing any previous ones
+func (s *MatchStore) Put(mediaFileID, path string, matches []MatchResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.matches[mediaFileID] = &PendingMatch{
+		MediaFileID: mediaFileID,
+		Path:        path,
+		Matches:     matches,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Get returns the pending match for a media file, or nil when there is none
+func (s *MatchStore) Get(mediaFileID string) *PendingMatch {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.matches[mediaFileID]
+}
+
+// Has reports whether a media file already has pending matches
+func (s *MatchStore) Has(mediaFileID string) bool {
+	return s.Get(mediaFileID) != nil
+}
+
+// All returns all pending matches
+func (s *MatchStore) All() []*PendingMatch {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	all := make([]*PendingMatch, 0, len(s.matches))
+	for _, m := range s.matches {
+		all = append(all, m)
+	}
+	return all
+}
+
+// Delete removes the pending match for a media file, after it was reviewed
+func (s *MatchStore) Delete(mediaFileID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.matches, mediaFileID)
+}
@@ -0,0 +1,87 @@
+package fingerprint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// submitChunkSize is how many fingerprints go into a single submission request
+const submitChunkSize = 25
+
+// SubmitFingerprints fingerprints up to batchSize files that already carry a
+// MusicBrainz recording ID and posts them to the AcoustID submission API,
+// contributing back to the database the lookup feature relies on. It returns
+// how many fingerprints were submitted
+func SubmitFingerprints(ctx context.Context, ds model.DataStore, batchSize int) (int, error) {
+	svc, ok := NewService(ds).(*service)
+	if !ok || !svc.IsEnabled() {
+		return 0, ErrDisabled
+	}
+	userKey := conf.Server.Fingerprint.SubmitUserKey
+	if !svc.acoustid.IsConfigured() || userKey == "" {
+		return 0, fmt.Errorf("acoustid submission requires an API key and a user key")
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	// Submissions are batch work: they yield to interactive requests
+	ctx = WithBatchPriority(ctx)
+
+	mfs, err := ds.Fingerprint(ctx).GetSubmittable(batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("loading files for submission: %w", err)
+	}
+	if len(mfs) == 0 {
+		return 0, nil
+	}
+
+	submitted := 0
+	var pending []AcoustIDSubmission
+	var pendingIDs []string
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		if err := svc.acoustid.Submit(ctx, userKey, pending); err != nil {
+			return err
+		}
+		if err := ds.Fingerprint(ctx).MarkSubmitted(pendingIDs); err != nil {
+			log.Warn(ctx, "Error marking fingerprints as submitted", err)
+		}
+		submitted += len(pending)
+		pending = nil
+		pendingIDs = nil
+		return nil
+	}
+
+	for _, mf := range mfs {
+		if ctx.Err() != nil {
+			break
+		}
+		fp, err := svc.fingerprintFor(ctx, &mf)
+		if err != nil {
+			log.Debug(ctx, "Skipping file in AcoustID submission", "path", mf.Path, err)
+			continue
+		}
+		pending = append(pending, AcoustIDSubmission{
+			Fingerprint:    fp.Fingerprint,
+			Duration:       fp.Duration,
+			MbzRecordingID: mf.MbzRecordingID,
+		})
+		pendingIDs = append(pendingIDs, mf.ID)
+		if len(pending) >= submitChunkSize {
+			if err := flush(); err != nil {
+				return submitted, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return submitted, err
+	}
+	return submitted, nil
+}
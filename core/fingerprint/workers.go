@@ -0,0 +1,98 @@
+package fingerprint
+
+import (
+	"context"
+	"time"
+
+	"github.com/navidrome/navidrome/utils/singleton"
+	"golang.org/x/time/rate"
+)
+
+// Process-wide rate limiters, shared by all client instances. Per-client
+// limiters would let concurrent scanners or multiple goroutines exceed the
+// service limits, since each instance would count requests on its own
+var (
+	acoustIDLimiter = rate.NewLimiter(rate.Every(time.Second/acoustIDRateLimit), acoustIDBurst)
+	// Rate limit: 1 request per second (slightly slower to be safe)
+	musicBrainzLimiter = rate.NewLimiter(rate.Every(1100*time.Millisecond), musicBrainzBurst)
+)
+
+type priorityCtxKey string
+
+const batchPriorityKey priorityCtxKey = "batchPriority"
+
+// WithBatchPriority marks the context as batch work (scans, jobs), so its
+// lookups yield to interactive requests with a user waiting on them
+func WithBatchPriority(ctx context.Context) context.Context {
+	return context.WithValue(ctx, batchPriorityKey, true)
+}
+
+func isBatch(ctx context.Context) bool {
+	batch, _ := ctx.Value(batchPriorityKey).(bool)
+	return batch
+}
+
+type lookupTask struct {
+	fn   func() error
+	done chan error
+}
+
+// LookupQueue serializes all external AcoustID/MusicBrainz calls through a
+// single worker, so the rate limits are respected process-wide no matter how
+// many goroutines are looking up files. Interactive requests are taken from
+// the queue before batch work
+type LookupQueue struct {
+	interactive chan *lookupTask
+	batch       chan *lookupTask
+}
+
+// GetLookupQueue returns the shared lookup queue
+func GetLookupQueue() *LookupQueue {
+	return singleton.GetInstance(func() *LookupQueue {
+		q := &LookupQueue{
+			interactive: make(chan *lookupTask),
+			batch:       make(chan *lookupTask),
+		}
+		go q.worker()
+		return q
+	})
+}
+
+// Do runs fn in the shared worker, blocking until it finishes. Batch contexts
+// (see WithBatchPriority) wait for interactive requests to drain first
+func (q *LookupQueue) Do(ctx context.Context, fn func() error) error {
+	t := &lookupTask{fn: fn, done: make(chan error, 1)}
+	queue := q.interactive
+	if isBatch(ctx) {
+		queue = q.batch
+	}
+	select {
+	case queue <- t:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-t.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *LookupQueue) worker() {
+	for {
+		// Interactive requests jump ahead of queued batch work
+		select {
+		case t := <-q.interactive:
+			t.done <- t.fn()
+			continue
+		default:
+		}
+		select {
+		case t := <-q.interactive:
+			t.done <- t.fn()
+		case t := <-q.batch:
+			t.done <- t.fn()
+		}
+	}
+}
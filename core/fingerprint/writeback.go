@@ -0,0 +1,87 @@
+package fingerprint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/navidrome/navidrome/adapters/taglib"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// WriteBack writes an accepted match to the file's tags, so the fix lives in
+// the file itself and not just in the database. Only non-empty values are
+// written, existing tags are never blanked out. When Fingerprint.WriteBackup
+// is enabled, a copy of the original file is kept next to it before the first
+// write
+func WriteBack(ctx context.Context, mf *model.MediaFile, match MatchResult) error {
+	path := mf.AbsolutePath()
+
+	if conf.Server.Fingerprint.WriteBackup {
+		if err := backupFile(path); err != nil {
+			return fmt.Errorf("backing up %s: %w", path, err)
+		}
+	}
+
+	tags := map[string]string{}
+	if match.Title != "" {
+		tags["TITLE"] = match.Title
+	}
+	if match.Artist != "" {
+		tags["ARTIST"] = match.Artist
+	}
+	if match.Album != "" {
+		tags["ALBUM"] = match.Album
+	}
+	if match.MusicBrainzID != "" {
+		tags["MUSICBRAINZ_TRACKID"] = match.MusicBrainzID
+	}
+	if len(match.Releases) > 0 && match.Releases[0].MusicBrainzID != "" {
+		// MUSICBRAINZ_ALBUMID holds a release MBID; AcoustID-derived
+		// candidates are release groups and use their own tag
+		if match.Releases[0].IsReleaseGroup {
+			tags["MUSICBRAINZ_RELEASEGROUPID"] = match.Releases[0].MusicBrainzID
+		} else {
+			tags["MUSICBRAINZ_ALBUMID"] = match.Releases[0].MusicBrainzID
+		}
+	}
+	if len(tags) == 0 {
+		return fmt.Errorf("match for %s has no tags to write", mf.ID)
+	}
+
+	log.Info(ctx, "Writing accepted match to file tags", "path", mf.Path, "title", match.Title,
+		"artist", match.Artist, "mbzRecordingId", match.MusicBrainzID)
+	if err := taglib.Write(path, tags); err != nil {
+		return err
+	}
+	fetchCoverArtIfEnabled(ctx, mf, match)
+	return nil
+}
+
+// backupFile copies the file to `<path>.bak`, unless a backup already exists.
+// Keeping the first backup preserves the original tags even if a match is
+// accepted more than once
+func backupFile(path string) error {
+	backupPath := path + ".bak"
+	if _, err := os.Stat(backupPath); err == nil {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
@@ -22,6 +22,9 @@ type Metrics interface {
 	WriteAfterScanMetrics(ctx context.Context, success bool)
 	RecordRequest(ctx context.Context, endpoint, method, client string, status int32, elapsed int64)
 	RecordPluginRequest(ctx context.Context, plugin, method string, ok bool, elapsed int64)
+	RecordDLNARequest(ctx context.Context, action string, ok bool, elapsed int64)
+	RecordDLNASearchResponse(ctx context.Context, st string)
+	RecordDLNAStreamedBytes(ctx context.Context, bytes int64)
 	GetHandler() http.Handler
 }
 
@@ -88,6 +91,27 @@ func (m *metrics) RecordPluginRequest(_ context.Context, plugin, method string,
 	getPrometheusMetrics().pluginRequestDuration.With(pluginLatencyLabel).Observe(float64(elapsed))
 }
 
+func (m *metrics) RecordDLNARequest(_ context.Context, action string, ok bool, elapsed int64) {
+	dlnaLabel := prometheus.Labels{
+		"action": action,
+		"ok":     strconv.FormatBool(ok),
+	}
+	getPrometheusMetrics().dlnaRequestCounter.With(dlnaLabel).Inc()
+
+	dlnaLatencyLabel := prometheus.Labels{
+		"action": action,
+	}
+	getPrometheusMetrics().dlnaRequestDuration.With(dlnaLatencyLabel).Observe(float64(elapsed))
+}
+
+func (m *metrics) RecordDLNASearchResponse(_ context.Context, st string) {
+	getPrometheusMetrics().dlnaMSearchCounter.With(prometheus.Labels{"st": st}).Inc()
+}
+
+func (m *metrics) RecordDLNAStreamedBytes(_ context.Context, bytes int64) {
+	getPrometheusMetrics().dlnaStreamedBytes.Add(float64(bytes))
+}
+
 func (m *metrics) GetHandler() http.Handler {
 	r := chi.NewRouter()
 
@@ -115,6 +139,10 @@ type prometheusMetrics struct {
 	httpRequestDuration   *prometheus.SummaryVec
 	pluginRequestCounter  *prometheus.CounterVec
 	pluginRequestDuration *prometheus.SummaryVec
+	dlnaRequestCounter    *prometheus.CounterVec
+	dlnaRequestDuration   *prometheus.SummaryVec
+	dlnaMSearchCounter    *prometheus.CounterVec
+	dlnaStreamedBytes     prometheus.Counter
 }
 
 // Prometheus' metrics requires initialization. But not more than once
@@ -180,6 +208,34 @@ var getPrometheusMetrics = sync.OnceValue(func() *prometheusMetrics {
 			},
 			[]string{"plugin", "method"},
 		),
+		dlnaRequestCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "dlna_request_count",
+				Help: "DLNA SOAP actions by action/status",
+			},
+			[]string{"action", "ok"},
+		),
+		dlnaRequestDuration: prometheus.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Name:       "dlna_request_latency",
+				Help:       "Latency (in ms) of DLNA SOAP actions, including DIDL rendering",
+				Objectives: quartilesToEstimate,
+			},
+			[]string{"action"},
+		),
+		dlnaMSearchCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "dlna_msearch_count",
+				Help: "SSDP M-SEARCH requests answered, by search target",
+			},
+			[]string{"st"},
+		),
+		dlnaStreamedBytes: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "dlna_streamed_bytes",
+				Help: "Total bytes served by the DLNA stream endpoint",
+			},
+		),
 	}
 
 	prometheus.DefaultRegisterer.MustRegister(
@@ -191,6 +247,10 @@ var getPrometheusMetrics = sync.OnceValue(func() *prometheusMetrics {
 		instance.httpRequestDuration,
 		instance.pluginRequestCounter,
 		instance.pluginRequestDuration,
+		instance.dlnaRequestCounter,
+		instance.dlnaRequestDuration,
+		instance.dlnaMSearchCounter,
+		instance.dlnaStreamedBytes,
 	)
 
 	return instance
@@ -237,4 +297,10 @@ func (n noopMetrics) RecordRequest(context.Context, string, string, string, int3
 
 func (n noopMetrics) RecordPluginRequest(context.Context, string, string, bool, int64) {}
 
+func (n noopMetrics) RecordDLNARequest(context.Context, string, bool, int64) {}
+
+func (n noopMetrics) RecordDLNASearchResponse(context.Context, string) {}
+
+func (n noopMetrics) RecordDLNAStreamedBytes(context.Context, int64) {}
+
 func (n noopMetrics) GetHandler() http.Handler { return nil }
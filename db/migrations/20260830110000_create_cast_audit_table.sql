@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE cast_audit(
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL
+        REFERENCES user(id)
+            ON DELETE CASCADE
+            ON UPDATE CASCADE,
+    device_id VARCHAR(255) NOT NULL,
+    room_name VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(255) NOT NULL,
+    item_type VARCHAR(255) NOT NULL DEFAULT '',
+    item_id VARCHAR(255) NOT NULL DEFAULT '',
+    item_name VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME
+);
+CREATE INDEX cast_audit_created_at ON cast_audit(created_at);
+CREATE INDEX cast_audit_user_id ON cast_audit(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE cast_audit;
+-- +goose StatementEnd
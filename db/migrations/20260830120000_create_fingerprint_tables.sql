@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE fingerprint(
+    media_file_id VARCHAR(255) NOT NULL PRIMARY KEY
+        REFERENCES media_file(id)
+            ON DELETE CASCADE
+            ON UPDATE CASCADE,
+    fingerprint TEXT NOT NULL,
+    duration INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME
+);
+CREATE TABLE acoustid_match(
+    media_file_id VARCHAR(255) NOT NULL PRIMARY KEY
+        REFERENCES media_file(id)
+            ON DELETE CASCADE
+            ON UPDATE CASCADE,
+    acoust_id VARCHAR(255) NOT NULL DEFAULT '',
+    mbz_recording_id VARCHAR(255) NOT NULL DEFAULT '',
+    mbz_release_id VARCHAR(255) NOT NULL DEFAULT '',
+    score REAL NOT NULL DEFAULT 0,
+    looked_up_at DATETIME
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE fingerprint;
+DROP TABLE acoustid_match;
+-- +goose StatementEnd
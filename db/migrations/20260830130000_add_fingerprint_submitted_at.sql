@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE fingerprint ADD COLUMN submitted_at DATETIME;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE fingerprint DROP COLUMN submitted_at;
+-- +goose StatementEnd
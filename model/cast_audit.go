@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// CastAuditEntry records one cast or control action against a cast device, so
+// multi-user households can see who took over which room
+type CastAuditEntry struct {
+	ID        string    `structs:"id"         json:"id"`
+	UserID    string    `structs:"user_id"    json:"userId"`
+	UserName  string    `structs:"-"          json:"userName,omitempty"`
+	DeviceID  string    `structs:"device_id"  json:"deviceId"`
+	RoomName  string    `structs:"room_name"  json:"roomName"`
+	Action    string    `structs:"action"     json:"action"`
+	ItemType  string    `structs:"item_type"  json:"itemType,omitempty"`
+	ItemID    string    `structs:"item_id"    json:"itemId,omitempty"`
+	ItemName  string    `structs:"item_name"  json:"itemName,omitempty"`
+	CreatedAt time.Time `structs:"created_at" json:"createdAt"`
+}
+
+type CastAuditEntries []CastAuditEntry
+
+type CastAuditRepository interface {
+	ResourceRepository
+	Record(entry *CastAuditEntry) error
+	GetAll(options ...QueryOptions) (CastAuditEntries, error)
+	CountAll(options ...QueryOptions) (int64, error)
+}
@@ -39,6 +39,8 @@ type DataStore interface {
 	UserProps(ctx context.Context) UserPropsRepository
 	ScrobbleBuffer(ctx context.Context) ScrobbleBufferRepository
 	Scrobble(ctx context.Context) ScrobbleRepository
+	CastAudit(ctx context.Context) CastAuditRepository
+	Fingerprint(ctx context.Context) FingerprintRepository
 
 	Resource(ctx context.Context, model interface{}) ResourceRepository
 
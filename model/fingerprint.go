@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Fingerprint is the chromaprint fingerprint generated for a media file,
+// cached so repeated scans don't re-run fpcalc over unchanged files
+type Fingerprint struct {
+	MediaFileID string     `structs:"media_file_id" json:"mediaFileId"`
+	Fingerprint string     `structs:"fingerprint"   json:"-"`
+	Duration    int        `structs:"duration"      json:"duration"`
+	UpdatedAt   time.Time  `structs:"updated_at"    json:"updatedAt"`
+	SubmittedAt *time.Time `structs:"submitted_at"  json:"submittedAt,omitempty"`
+}
+
+// AcoustIDMatch is the best match found for a media file in a previous AcoustID
+// lookup, kept so matches survive restarts and unchanged files are not looked
+// up again
+type AcoustIDMatch struct {
+	MediaFileID    string    `structs:"media_file_id"    json:"mediaFileId"`
+	AcoustID       string    `structs:"acoust_id"        json:"acoustId"`
+	MbzRecordingID string    `structs:"mbz_recording_id" json:"mbzRecordingId,omitempty"`
+	MbzReleaseID   string    `structs:"mbz_release_id"   json:"mbzReleaseId,omitempty"`
+	Score          float64   `structs:"score"            json:"score"`
+	LookedUpAt     time.Time `structs:"looked_up_at"     json:"lookedUpAt"`
+}
+
+type FingerprintRepository interface {
+	GetFingerprint(mediaFileID string) (*Fingerprint, error)
+	PutFingerprint(fp *Fingerprint) error
+	GetMatch(mediaFileID string) (*AcoustIDMatch, error)
+	PutMatch(m *AcoustIDMatch) error
+	DeleteMatch(mediaFileID string) error
+
+	// GetSubmittable returns files tagged with a MusicBrainz recording ID whose
+	// fingerprints were not yet submitted to AcoustID
+	GetSubmittable(max int) (MediaFiles, error)
+	MarkSubmitted(mediaFileIDs []string) error
+}
@@ -11,4 +11,5 @@ type Genres []Genre
 
 type GenreRepository interface {
 	GetAll(...QueryOptions) (Genres, error)
+	CountAll(...QueryOptions) (int64, error)
 }
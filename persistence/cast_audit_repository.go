@@ -0,0 +1,90 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/deluan/rest"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/id"
+	"github.com/pocketbase/dbx"
+)
+
+type castAuditRepository struct {
+	sqlRepository
+}
+
+func NewCastAuditRepository(ctx context.Context, db dbx.Builder) model.CastAuditRepository {
+	r := &castAuditRepository{}
+	r.ctx = ctx
+	r.db = db
+	r.tableName = "cast_audit"
+	r.registerModel(&model.CastAuditEntry{}, map[string]filterFunc{
+		"user_id":   eqFilter,
+		"device_id": eqFilter,
+		"action":    eqFilter,
+		"room_name": containsFilter("room_name"),
+	})
+	return r
+}
+
+func (r *castAuditRepository) Record(entry *model.CastAuditEntry) error {
+	entry.ID = id.NewRandom()
+	entry.UserID = loggedUser(r.ctx).ID
+	entry.CreatedAt = time.Now()
+	values, _ := toSQLArgs(*entry)
+	insert := Insert(r.tableName).SetMap(values)
+	_, err := r.executeSQL(insert)
+	return err
+}
+
+func (r *castAuditRepository) CountAll(options ...model.QueryOptions) (int64, error) {
+	sql := r.newSelect()
+	return r.count(sql, options...)
+}
+
+func (r *castAuditRepository) Get(id string) (*model.CastAuditEntry, error) {
+	sel := r.selectEntry().Where(Eq{"cast_audit.id": id})
+	res := model.CastAuditEntry{}
+	err := r.queryOne(sel, &res)
+	return &res, err
+}
+
+func (r *castAuditRepository) GetAll(options ...model.QueryOptions) (model.CastAuditEntries, error) {
+	sel := r.selectEntry(options...)
+	res := model.CastAuditEntries{}
+	err := r.queryAll(sel, &res)
+	return res, err
+}
+
+// selectEntry builds the base query, resolving the user's display name so the
+// listing doesn't need a lookup per entry
+func (r *castAuditRepository) selectEntry(options ...model.QueryOptions) SelectBuilder {
+	return r.newSelect(options...).
+		Columns("cast_audit.*", "user.name as user_name").
+		LeftJoin("user on user.id = cast_audit.user_id")
+}
+
+func (r *castAuditRepository) Count(options ...rest.QueryOptions) (int64, error) {
+	return r.CountAll(r.parseRestOptions(r.ctx, options...))
+}
+
+func (r *castAuditRepository) EntityName() string {
+	return "cast_audit"
+}
+
+func (r *castAuditRepository) NewInstance() interface{} {
+	return &model.CastAuditEntry{}
+}
+
+func (r *castAuditRepository) Read(id string) (interface{}, error) {
+	return r.Get(id)
+}
+
+func (r *castAuditRepository) ReadAll(options ...rest.QueryOptions) (interface{}, error) {
+	return r.GetAll(r.parseRestOptions(r.ctx, options...))
+}
+
+var _ model.CastAuditRepository = (*castAuditRepository)(nil)
+var _ rest.Repository = (*castAuditRepository)(nil)
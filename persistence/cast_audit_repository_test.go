@@ -0,0 +1,97 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/id"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pocketbase/dbx"
+)
+
+var _ = Describe("CastAuditRepository", func() {
+	var repo *castAuditRepository
+	var ctx context.Context
+	var userID string
+	var db dbx.Builder
+
+	BeforeEach(func() {
+		userID = id.NewRandom()
+		ctx = request.WithUser(log.NewContext(GinkgoT().Context()), model.User{ID: userID, UserName: "johndoe", IsAdmin: true})
+		db = GetDBXBuilder()
+		repo = NewCastAuditRepository(ctx, db).(*castAuditRepository)
+
+		_, err := db.Insert("user", dbx.Params{
+			"id":         userID,
+			"user_name":  "johndoe",
+			"name":       "John Doe",
+			"password":   "pw",
+			"created_at": time.Now(),
+			"updated_at": time.Now(),
+		}).Execute()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_, _ = db.Delete("cast_audit", dbx.HashExp{"user_id": userID}).Execute()
+		_, _ = db.Delete("user", dbx.HashExp{"id": userID}).Execute()
+	})
+
+	Describe("Record", func() {
+		It("fills in the ID, user and timestamp", func() {
+			entry := model.CastAuditEntry{DeviceID: "RINCON_1", RoomName: "Living Room", Action: "play"}
+			Expect(repo.Record(&entry)).To(Succeed())
+
+			Expect(entry.ID).ToNot(BeEmpty())
+			Expect(entry.UserID).To(Equal(userID))
+			Expect(entry.CreatedAt).ToNot(BeZero())
+		})
+	})
+
+	Describe("Get", func() {
+		It("returns a recorded entry with the user's display name resolved", func() {
+			entry := model.CastAuditEntry{DeviceID: "RINCON_1", RoomName: "Living Room", Action: "play",
+				ItemType: "track", ItemID: "track-1", ItemName: "So What"}
+			Expect(repo.Record(&entry)).To(Succeed())
+
+			got, err := repo.Get(entry.ID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got.DeviceID).To(Equal("RINCON_1"))
+			Expect(got.RoomName).To(Equal("Living Room"))
+			Expect(got.Action).To(Equal("play"))
+			Expect(got.ItemName).To(Equal("So What"))
+			Expect(got.UserName).To(Equal("John Doe"))
+		})
+
+		It("errors when missing", func() {
+			_, err := repo.Get("notanid")
+			Expect(err).To(MatchError(model.ErrNotFound))
+		})
+	})
+
+	Describe("CountAll and GetAll", func() {
+		BeforeEach(func() {
+			for _, action := range []string{"play", "pause", "volume"} {
+				entry := model.CastAuditEntry{DeviceID: "RINCON_1", RoomName: "Kitchen", Action: action}
+				Expect(repo.Record(&entry)).To(Succeed())
+			}
+		})
+
+		It("counts all recorded entries", func() {
+			Expect(repo.CountAll()).To(Equal(int64(3)))
+		})
+
+		It("filters entries", func() {
+			entries, err := repo.GetAll(model.QueryOptions{Filters: squirrel.Eq{"action": "play"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Action).To(Equal("play"))
+			Expect(entries[0].RoomName).To(Equal("Kitchen"))
+		})
+	})
+})
@@ -0,0 +1,98 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	. "github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/model"
+	"github.com/pocketbase/dbx"
+)
+
+// fingerprintRepository covers both fingerprint caching tables: `fingerprint`
+// holds the chromaprint output per media file, and `acoustid_match` the best
+// match found in the last AcoustID lookup. Both are keyed by media_file ID
+type fingerprintRepository struct {
+	sqlRepository
+}
+
+func NewFingerprintRepository(ctx context.Context, db dbx.Builder) model.FingerprintRepository {
+	r := &fingerprintRepository{}
+	r.ctx = ctx
+	r.db = db
+	r.tableName = "fingerprint"
+	return r
+}
+
+func (r *fingerprintRepository) GetFingerprint(mediaFileID string) (*model.Fingerprint, error) {
+	sel := r.newSelect().Columns("*").Where(Eq{"media_file_id": mediaFileID})
+	res := model.Fingerprint{}
+	err := r.queryOne(sel, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *fingerprintRepository) PutFingerprint(fp *model.Fingerprint) error {
+	fp.UpdatedAt = time.Now()
+	values, _ := toSQLArgs(*fp)
+	ins := Insert(r.tableName).SetMap(values).
+		Suffix(`on conflict(media_file_id) do update set fingerprint = excluded.fingerprint,
+			duration = excluded.duration, updated_at = excluded.updated_at, submitted_at = null`)
+	_, err := r.executeSQL(ins)
+	return err
+}
+
+func (r *fingerprintRepository) GetMatch(mediaFileID string) (*model.AcoustIDMatch, error) {
+	sel := Select("*").From("acoustid_match").Where(Eq{"media_file_id": mediaFileID})
+	res := model.AcoustIDMatch{}
+	err := r.queryOne(sel, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *fingerprintRepository) PutMatch(m *model.AcoustIDMatch) error {
+	m.LookedUpAt = time.Now()
+	values, _ := toSQLArgs(*m)
+	ins := Insert("acoustid_match").SetMap(values).
+		Suffix(`on conflict(media_file_id) do update set acoust_id = excluded.acoust_id,
+			mbz_recording_id = excluded.mbz_recording_id, mbz_release_id = excluded.mbz_release_id,
+			score = excluded.score, looked_up_at = excluded.looked_up_at`)
+	_, err := r.executeSQL(ins)
+	return err
+}
+
+func (r *fingerprintRepository) GetSubmittable(max int) (model.MediaFiles, error) {
+	sel := Select("media_file.*").From("media_file").
+		LeftJoin("fingerprint f on f.media_file_id = media_file.id").
+		Where(And{
+			Eq{"media_file.missing": false},
+			NotEq{"media_file.mbz_recording_id": ""},
+			Expr("(f.media_file_id is null or f.submitted_at is null)"),
+		}).
+		Limit(uint64(max))
+	var res dbMediaFiles
+	err := r.queryAll(sel, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res.toModels(), nil
+}
+
+func (r *fingerprintRepository) MarkSubmitted(mediaFileIDs []string) error {
+	upd := Update(r.tableName).Set("submitted_at", time.Now()).
+		Where(Eq{"media_file_id": mediaFileIDs})
+	_, err := r.executeSQL(upd)
+	return err
+}
+
+func (r *fingerprintRepository) DeleteMatch(mediaFileID string) error {
+	del := Delete("acoustid_match").Where(Eq{"media_file_id": mediaFileID})
+	_, err := r.executeSQL(del)
+	return err
+}
+
+var _ model.FingerprintRepository = (*fingerprintRepository)(nil)
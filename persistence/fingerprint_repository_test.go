@@ -0,0 +1,126 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/id"
+	"github.com/navidrome/navidrome/model/request"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pocketbase/dbx"
+)
+
+var _ = Describe("FingerprintRepository", func() {
+	var repo model.FingerprintRepository
+	var ctx context.Context
+	var fileID string
+	var db dbx.Builder
+
+	// submittableIDs collapses GetSubmittable results to their IDs, since the
+	// shared test DB holds other media files that may also qualify
+	submittableIDs := func() []string {
+		files, err := repo.GetSubmittable(1000)
+		Expect(err).ToNot(HaveOccurred())
+		var ids []string
+		for _, f := range files {
+			ids = append(ids, f.ID)
+		}
+		return ids
+	}
+
+	BeforeEach(func() {
+		fileID = id.NewRandom()
+		ctx = request.WithUser(log.NewContext(GinkgoT().Context()), model.User{ID: "userid", UserName: "userid", IsAdmin: true})
+		db = GetDBXBuilder()
+		repo = NewFingerprintRepository(ctx, db)
+
+		_, err := db.Insert("media_file", dbx.Params{
+			"id":               fileID,
+			"path":             "tests/fixtures/test.mp3",
+			"mbz_recording_id": "mbz-recording-1",
+			"created_at":       time.Now(),
+			"updated_at":       time.Now(),
+		}).Execute()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_, _ = db.Delete("fingerprint", dbx.HashExp{"media_file_id": fileID}).Execute()
+		_, _ = db.Delete("acoustid_match", dbx.HashExp{"media_file_id": fileID}).Execute()
+		_, _ = db.Delete("media_file", dbx.HashExp{"id": fileID}).Execute()
+	})
+
+	Describe("Fingerprints", func() {
+		It("round-trips a fingerprint", func() {
+			fp := model.Fingerprint{MediaFileID: fileID, Fingerprint: "AQADtEmi", Duration: 215}
+			Expect(repo.PutFingerprint(&fp)).To(Succeed())
+
+			got, err := repo.GetFingerprint(fileID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got.Fingerprint).To(Equal("AQADtEmi"))
+			Expect(got.Duration).To(Equal(215))
+			Expect(got.UpdatedAt).ToNot(BeZero())
+			Expect(got.SubmittedAt).To(BeNil())
+		})
+
+		It("replaces the fingerprint and clears the submission mark on conflict", func() {
+			Expect(repo.PutFingerprint(&model.Fingerprint{MediaFileID: fileID, Fingerprint: "AQADtEmi", Duration: 215})).To(Succeed())
+			Expect(repo.MarkSubmitted([]string{fileID})).To(Succeed())
+
+			got, err := repo.GetFingerprint(fileID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got.SubmittedAt).ToNot(BeNil())
+
+			// A re-scan computes a new fingerprint, which must be submitted again
+			Expect(repo.PutFingerprint(&model.Fingerprint{MediaFileID: fileID, Fingerprint: "AQADnEW0", Duration: 230})).To(Succeed())
+			got, err = repo.GetFingerprint(fileID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got.Fingerprint).To(Equal("AQADnEW0"))
+			Expect(got.SubmittedAt).To(BeNil())
+		})
+
+		It("errors when there is no fingerprint for the file", func() {
+			_, err := repo.GetFingerprint(fileID)
+			Expect(err).To(MatchError(model.ErrNotFound))
+		})
+	})
+
+	Describe("AcoustID matches", func() {
+		It("round-trips a match, updating on conflict", func() {
+			match := model.AcoustIDMatch{MediaFileID: fileID, AcoustID: "acoustid-1",
+				MbzRecordingID: "mbz-recording-1", MbzReleaseID: "mbz-release-1", Score: 0.87}
+			Expect(repo.PutMatch(&match)).To(Succeed())
+
+			Expect(repo.PutMatch(&model.AcoustIDMatch{MediaFileID: fileID, AcoustID: "acoustid-2", Score: 0.99})).To(Succeed())
+
+			got, err := repo.GetMatch(fileID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got.AcoustID).To(Equal("acoustid-2"))
+			Expect(got.Score).To(Equal(0.99))
+			Expect(got.LookedUpAt).ToNot(BeZero())
+		})
+
+		It("deletes a match", func() {
+			Expect(repo.PutMatch(&model.AcoustIDMatch{MediaFileID: fileID, AcoustID: "acoustid-1", Score: 0.87})).To(Succeed())
+			Expect(repo.DeleteMatch(fileID)).To(Succeed())
+
+			_, err := repo.GetMatch(fileID)
+			Expect(err).To(MatchError(model.ErrNotFound))
+		})
+	})
+
+	Describe("GetSubmittable", func() {
+		It("returns files with a recording MBID and no submitted fingerprint", func() {
+			Expect(submittableIDs()).To(ContainElement(fileID))
+
+			Expect(repo.PutFingerprint(&model.Fingerprint{MediaFileID: fileID, Fingerprint: "AQADtEmi", Duration: 215})).To(Succeed())
+			Expect(submittableIDs()).To(ContainElement(fileID))
+
+			Expect(repo.MarkSubmitted([]string{fileID})).To(Succeed())
+			Expect(submittableIDs()).ToNot(ContainElement(fileID))
+		})
+	})
+})
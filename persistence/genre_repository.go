@@ -31,6 +31,15 @@ func (r *genreRepository) GetAll(opt ...model.QueryOptions) (model.Genres, error
 	return res, err
 }
 
+func (r *genreRepository) CountAll(opt ...model.QueryOptions) (int64, error) {
+	sq := Select().From(r.tableName)
+	if r.tagFilter != nil {
+		sq = sq.Where(Eq{"tag.tag_name": *r.tagFilter})
+	}
+	sq = r.applyLibraryFiltering(sq)
+	return r.count(sq, opt...)
+}
+
 // Override ResourceRepository methods to return Genre objects instead of Tag objects
 
 func (r *genreRepository) Read(id string) (interface{}, error) {
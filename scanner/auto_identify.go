@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core/fingerprint"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// runAutoIdentify fingerprints files with missing or poor metadata at the end
+// of a scan, storing candidate matches for later review. Only a batch of files
+// is processed per scan, and lookups go through the AcoustID rate limiter, so
+// a large untagged library doesn't hammer the API or delay the scan forever
+func (s *scannerImpl) runAutoIdentify(ctx context.Context, state *scanState) func() error {
+	return func() error {
+		if !conf.Server.Fingerprint.AutoIdentify {
+			return nil
+		}
+		svc := fingerprint.NewService(s.ds)
+		if !svc.IsEnabled() {
+			log.Debug(ctx, "Scanner: Fingerprint.AutoIdentify is set, but fingerprinting is not available")
+			return nil
+		}
+
+		// Lookups from the scanner yield to interactive requests
+		ctx := fingerprint.WithBatchPriority(ctx)
+
+		batchSize := conf.Server.Fingerprint.BatchSize
+		if batchSize <= 0 {
+			batchSize = 100
+		}
+
+		// Files without a title, artist or MusicBrainz recording ID are the
+		// ones fingerprinting can actually help with
+		mfs, err := s.ds.MediaFile(ctx).GetAll(model.QueryOptions{
+			Filters: squirrel.And{
+				squirrel.Eq{"missing": false},
+				squirrel.Or{
+					squirrel.Eq{"title": ""},
+					squirrel.Eq{"artist": ""},
+					squirrel.Eq{"mbz_recording_id": ""},
+				},
+			},
+			Max: batchSize,
+		})
+		if err != nil {
+			log.Warn(ctx, "Scanner: Error loading files for fingerprinting", err)
+			return nil
+		}
+		if len(mfs) == 0 {
+			return nil
+		}
+
+		start := time.Now()
+		store := fingerprint.GetMatchStore()
+		identified := 0
+		applied := 0
+		for _, mf := range mfs {
+			if ctx.Err() != nil {
+				break
+			}
+			// Keep matches from a previous scan that are still unreviewed
+			if store.Has(mf.ID) {
+				continue
+			}
+			// Skip files already matched by a previous run - those survive
+			// restarts in the acoustid_match table
+			if _, err := s.ds.Fingerprint(ctx).GetMatch(mf.ID); err == nil {
+				continue
+			}
+
+			matches, err := svc.Identify(ctx, &mf)
+			switch {
+			case errors.Is(err, fingerprint.ErrRateLimited):
+				log.Warn(ctx, "Scanner: AcoustID rate limit hit - stopping automatic identification for this scan", err)
+				return nil
+			case errors.Is(err, fingerprint.ErrNoMatch):
+				continue
+			case err != nil:
+				log.Debug(ctx, "Scanner: Failed to identify file", "path", mf.Path, err)
+				continue
+			}
+			// Identify may return no candidates without an explicit ErrNoMatch
+			if len(matches) == 0 {
+				continue
+			}
+
+			// High-confidence matches can be applied to the database right
+			// away. Everything below the threshold goes to the review queue,
+			// so scanning never silently applies questionable metadata. Files
+			// are never modified without an explicit user action
+			threshold := conf.Server.Fingerprint.AutoApplyScore
+			if threshold > 0 && matches[0].Score >= threshold {
+				if err := fingerprint.ApplyToDB(ctx, s.ds, &mf, matches[0]); err != nil {
+					log.Warn(ctx, "Scanner: Error applying high-confidence match", "path", mf.Path, err)
+				} else {
+					applied++
+					continue
+				}
+			}
+
+			store.Put(mf.ID, mf.Path, matches)
+			identified++
+		}
+
+		log.Info(ctx, "Scanner: Finished automatic identification", "processed", len(mfs),
+			"identified", identified, "applied", applied, "duration", time.Since(start))
+		return nil
+	}
+}
@@ -162,6 +162,12 @@ func (s *scannerImpl) scanFolders(ctx context.Context, fullScan bool, targets []
 
 		// Optimize DB
 		s.runOptimize(ctx),
+
+		// Fingerprint files with missing metadata, storing matches for review
+		s.runAutoIdentify(ctx, &state),
+
+		// Contribute fingerprints of correctly tagged files back to AcoustID
+		s.runSubmitFingerprints(ctx, &state),
 	)
 	if err != nil {
 		log.Error(ctx, "Scanner: Finished with error", "duration", time.Since(startTime), err)
@@ -0,0 +1,33 @@
+package scanner
+
+import (
+	"context"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core/fingerprint"
+	"github.com/navidrome/navidrome/log"
+)
+
+// runSubmitFingerprints contributes fingerprints of correctly tagged files
+// back to AcoustID at the end of a scan. Like runAutoIdentify, it only handles
+// a batch per scan and never fails the scan itself
+func (s *scannerImpl) runSubmitFingerprints(ctx context.Context, state *scanState) func() error {
+	return func() error {
+		if !conf.Server.Fingerprint.Submit {
+			return nil
+		}
+
+		start := time.Now()
+		submitted, err := fingerprint.SubmitFingerprints(ctx, s.ds, conf.Server.Fingerprint.BatchSize)
+		if err != nil {
+			log.Warn(ctx, "Scanner: Error submitting fingerprints to AcoustID", "submitted", submitted, err)
+			return nil
+		}
+		if submitted > 0 {
+			log.Info(ctx, "Scanner: Submitted fingerprints to AcoustID", "submitted", submitted,
+				"duration", time.Since(start))
+		}
+		return nil
+	}
+}
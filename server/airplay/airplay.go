@@ -0,0 +1,243 @@
+package airplay
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+)
+
+// AirPlay is the main service for AirPlay (RAOP) receiver control
+type AirPlay struct {
+	discovery *Discovery
+	sessions  map[string]*raopSession // keyed by device UUID
+	running   bool
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+	mu        sync.RWMutex
+}
+
+// NewAirPlay creates a new AirPlay service
+func NewAirPlay() *AirPlay {
+	return &AirPlay{
+		discovery: NewDiscovery(),
+		sessions:  make(map[string]*raopSession),
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start begins the AirPlay service with periodic discovery
+func (s *AirPlay) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	log.Info(ctx, "Starting AirPlay service")
+
+	// Initial discovery
+	if _, err := s.discovery.Scan(ctx); err != nil {
+		log.Error(ctx, "AirPlay discovery failed", err)
+	}
+
+	// Start periodic discovery. A non-positive interval disables it entirely,
+	// leaving only the initial scan and manual refreshes
+	interval := conf.Server.AirPlay.DiscoveryInterval
+	if interval <= 0 {
+		log.Info(ctx, "AirPlay periodic discovery is DISABLED")
+		return nil
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.discovery.Scan(ctx); err != nil {
+					log.Error(ctx, "AirPlay discovery failed", err)
+				}
+			case <-s.stopCh:
+				log.Info(ctx, "AirPlay discovery stopped")
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown stops the AirPlay service and tears down all active sessions
+func (s *AirPlay) Shutdown() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	sessions := make([]*raopSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.sessions = make(map[string]*raopSession)
+	s.mu.Unlock()
+
+	for _, session := range sessions {
+		session.cancel()
+		<-session.done
+	}
+
+	close(s.stopCh)
+	s.wg.Wait()
+}
+
+// RefreshDevices forces a new mDNS discovery scan
+func (s *AirPlay) RefreshDevices(ctx context.Context) error {
+	_, err := s.discovery.Scan(ctx)
+	return err
+}
+
+// GetDevices returns all discovered AirPlay receivers
+func (s *AirPlay) GetDevices() []*AirPlayDevice {
+	return s.discovery.GetDevices()
+}
+
+// GetDevice returns a specific device by UUID
+func (s *AirPlay) GetDevice(uuid string) (*AirPlayDevice, bool) {
+	return s.discovery.GetDevice(uuid)
+}
+
+// CastStream starts streaming PCM audio to a device, replacing any session
+// already playing there. The pcm reader must produce 44.1kHz/16-bit/stereo
+// big-endian samples; it is closed when the stream ends
+func (s *AirPlay) CastStream(ctx context.Context, uuid string, pcm io.ReadCloser, track *Track, volume int) error {
+	device, ok := s.discovery.GetDevice(uuid)
+	if !ok {
+		_ = pcm.Close()
+		return ErrDeviceNotFound
+	}
+
+	// One stream per receiver - stop whatever is already playing there
+	s.stopSession(uuid)
+
+	session, err := startRAOPSession(ctx, device)
+	if err != nil {
+		_ = pcm.Close()
+		return err
+	}
+	session.track = track
+
+	if volume > 0 {
+		if err := session.setVolume(volume); err != nil {
+			log.Warn(ctx, "Failed to set AirPlay volume", "device", device.Name, err)
+		}
+	}
+
+	// The session streams on its own context so it outlives the HTTP request
+	// that started it
+	streamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	session.cancel = cancel
+
+	s.mu.Lock()
+	s.sessions[uuid] = session
+	s.mu.Unlock()
+
+	go func() {
+		defer close(session.done)
+		defer func() { _ = pcm.Close() }()
+
+		err := session.stream(streamCtx, pcm)
+		if err != nil && streamCtx.Err() == nil {
+			log.Error(streamCtx, "AirPlay stream failed", "device", device.Name, "track", track.Title, err)
+		} else {
+			log.Debug(streamCtx, "AirPlay stream finished", "device", device.Name, "track", track.Title)
+		}
+		session.teardown()
+
+		s.mu.Lock()
+		if s.sessions[uuid] == session {
+			delete(s.sessions, uuid)
+		}
+		s.mu.Unlock()
+	}()
+
+	log.Info(ctx, "Started AirPlay stream", "device", device.Name, "track", track.Title)
+	return nil
+}
+
+// Stop tears down the active session on a device
+func (s *AirPlay) Stop(ctx context.Context, uuid string) error {
+	if _, ok := s.discovery.GetDevice(uuid); !ok {
+		return ErrDeviceNotFound
+	}
+	if !s.stopSession(uuid) {
+		return ErrNoSession
+	}
+	return nil
+}
+
+// stopSession cancels and waits out the session on a device, if any
+func (s *AirPlay) stopSession(uuid string) bool {
+	s.mu.Lock()
+	session, ok := s.sessions[uuid]
+	if ok {
+		delete(s.sessions, uuid)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+	session.cancel()
+	<-session.done
+	return true
+}
+
+// SetVolume sets the volume (0-100) on a device's active session
+func (s *AirPlay) SetVolume(ctx context.Context, uuid string, volume int) error {
+	if volume < 0 || volume > 100 {
+		return ErrInvalidVolume
+	}
+	if _, ok := s.discovery.GetDevice(uuid); !ok {
+		return ErrDeviceNotFound
+	}
+
+	s.mu.RLock()
+	session, ok := s.sessions[uuid]
+	s.mu.RUnlock()
+	if !ok {
+		return ErrNoSession
+	}
+	return session.setVolume(volume)
+}
+
+// GetPlaybackState returns the locally tracked state of a device
+func (s *AirPlay) GetPlaybackState(ctx context.Context, uuid string) (*PlaybackState, error) {
+	if _, ok := s.discovery.GetDevice(uuid); !ok {
+		return nil, ErrDeviceNotFound
+	}
+
+	s.mu.RLock()
+	session, ok := s.sessions[uuid]
+	s.mu.RUnlock()
+
+	if !ok {
+		return &PlaybackState{State: "STOPPED"}, nil
+	}
+	return &PlaybackState{
+		State:        "PLAYING",
+		CurrentTrack: session.track,
+		Volume:       session.volume,
+	}, nil
+}
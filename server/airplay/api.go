@@ -0,0 +1,237 @@
+package airplay
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/core/ffmpeg"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	"github.com/navidrome/navidrome/server"
+)
+
+// pcmCommand converts any input file to the raw PCM stream RAOP requires
+const pcmCommand = "ffmpeg -i %s -f s16be -ar 44100 -ac 2 -"
+
+// API handles REST API endpoints for AirPlay. It exposes the same REST shape
+// as the Sonos Cast and Chromecast APIs, so clients can drive mixed speaker
+// fleets through one UX
+type API struct {
+	airplay *AirPlay
+	ds      model.DataStore
+	ffmpeg  ffmpeg.FFmpeg
+}
+
+// NewAPI creates a new AirPlay API handler
+func NewAPI(airplay *AirPlay, ds model.DataStore, ffmpeg ffmpeg.FFmpeg) *API {
+	return &API{
+		airplay: airplay,
+		ds:      ds,
+		ffmpeg:  ffmpeg,
+	}
+}
+
+// Router returns the chi router with all AirPlay endpoints. Unlike the other
+// cast backends there is no stream endpoint: RAOP is a push protocol, so the
+// server sends audio to the receiver instead of the receiver pulling it
+func (a *API) Router() http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(server.Authenticator(a.ds))
+	r.Use(server.JWTRefresher)
+
+	// Device endpoints
+	r.Get("/devices", a.getDevices)
+	r.Post("/devices/refresh", a.refreshDevices)
+
+	r.Route("/devices/{id}", func(r chi.Router) {
+		r.Get("/", a.getDevice)
+		r.Get("/state", a.getDeviceState)
+
+		// Playback control
+		r.Post("/stop", a.stop)
+
+		// Volume control
+		r.Post("/volume", a.setVolume)
+
+		// Cast media
+		r.Post("/cast", a.castMedia)
+	})
+
+	return r
+}
+
+// getDevices returns all discovered AirPlay receivers
+func (a *API) getDevices(w http.ResponseWriter, r *http.Request) {
+	a.sendJSON(w, http.StatusOK, a.airplay.GetDevices())
+}
+
+// refreshDevices forces a new mDNS discovery
+func (a *API) refreshDevices(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := a.airplay.RefreshDevices(ctx); err != nil {
+		a.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.sendJSON(w, http.StatusOK, a.airplay.GetDevices())
+}
+
+// getDevice returns a specific device by UUID
+func (a *API) getDevice(w http.ResponseWriter, r *http.Request) {
+	deviceID := chi.URLParam(r, "id")
+	device, ok := a.airplay.GetDevice(deviceID)
+	if !ok {
+		a.sendError(w, http.StatusNotFound, "device not found")
+		return
+	}
+	a.sendJSON(w, http.StatusOK, device)
+}
+
+// getDeviceState returns the current session state of a device
+func (a *API) getDeviceState(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	state, err := a.airplay.GetPlaybackState(ctx, deviceID)
+	if err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, state)
+}
+
+// stop tears down the active session on a device
+func (a *API) stop(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	if err := a.airplay.Stop(ctx, deviceID); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else if err == ErrNoSession {
+			a.sendError(w, http.StatusBadRequest, "nothing is playing on this device")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "stopped"})
+}
+
+// setVolume sets the volume of a device's active session
+func (a *API) setVolume(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	var req VolumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := a.airplay.SetVolume(ctx, deviceID, req.Volume); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else if err == ErrInvalidVolume {
+			a.sendError(w, http.StatusBadRequest, err.Error())
+		} else if err == ErrNoSession {
+			a.sendError(w, http.StatusBadRequest, "nothing is playing on this device")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, map[string]int{"volume": req.Volume})
+}
+
+// castRequest is the request body for casting media. RAOP streams one track
+// per session, so unlike the other backends there is no queue to fill
+type castRequest struct {
+	TrackID string `json:"trackId"`
+	Volume  int    `json:"volume"` // optional initial volume, 0 keeps the receiver's current level
+}
+
+// castMedia starts streaming a track to a device
+func (a *API) castMedia(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	var req castRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.TrackID == "" {
+		a.sendError(w, http.StatusBadRequest, "trackId is required")
+		return
+	}
+	if req.Volume < 0 || req.Volume > 100 {
+		a.sendError(w, http.StatusBadRequest, "volume must be between 0 and 100")
+		return
+	}
+
+	track, err := a.ds.MediaFile(ctx).Get(req.TrackID)
+	if err != nil {
+		log.Error(ctx, "Failed to get track from database", err, "trackID", req.TrackID)
+		a.sendError(w, http.StatusNotFound, "track not found")
+		return
+	}
+
+	// Make sure the requesting user's libraries include this track
+	user, ok := request.UserFrom(ctx)
+	if !ok || !user.HasLibraryAccess(track.LibraryID) {
+		log.Warn(ctx, "Cast denied - track not in user's libraries", "username", user.UserName,
+			"trackID", req.TrackID, "libraryID", track.LibraryID)
+		a.sendError(w, http.StatusForbidden, ErrNotAuthorized.Error())
+		return
+	}
+
+	// RAOP wants raw PCM, so every track goes through ffmpeg regardless of
+	// its source format
+	pcm, err := a.ffmpeg.Transcode(ctx, pcmCommand, track.AbsolutePath(), 0, 0)
+	if err != nil {
+		log.Error(ctx, "Failed to start PCM transcode for AirPlay", err, "track", track.Title)
+		a.sendError(w, http.StatusInternalServerError, "failed to start transcoding")
+		return
+	}
+
+	err = a.airplay.CastStream(ctx, deviceID, pcm, &Track{
+		ID:       track.ID,
+		Title:    track.Title,
+		Artist:   track.Artist,
+		Album:    track.Album,
+		Duration: track.Duration,
+	}, req.Volume)
+	if err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else if err == ErrAuthRequired {
+			a.sendError(w, http.StatusBadRequest, err.Error())
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "playing", "trackId": track.ID})
+}
+
+// sendJSON sends a JSON response
+func (a *API) sendJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Error("Failed to encode JSON response", err)
+	}
+}
+
+// sendError sends an error response
+func (a *API) sendError(w http.ResponseWriter, status int, message string) {
+	a.sendJSON(w, status, map[string]string{"error": message})
+}
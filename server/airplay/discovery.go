@@ -0,0 +1,106 @@
+package airplay
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/utils/mdns"
+)
+
+const (
+	raopService        = "_raop._tcp.local."
+	defaultMDNSTimeout = 3 * time.Second
+)
+
+// Discovery handles AirPlay receiver discovery via mDNS
+type Discovery struct {
+	cache *DeviceCache
+}
+
+// NewDiscovery creates a new AirPlay discovery service
+func NewDiscovery() *Discovery {
+	return &Discovery{
+		cache: NewDeviceCache(),
+	}
+}
+
+// Scan performs an mDNS query for RAOP receivers and collects responses
+// until the timeout expires
+func (d *Discovery) Scan(ctx context.Context) ([]*AirPlayDevice, error) {
+	log.Debug(ctx, "Starting AirPlay mDNS discovery scan")
+
+	entries, err := mdns.Query(ctx, raopService, localUDPAddr(ctx), mdnsTimeout())
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []*AirPlayDevice
+	for _, entry := range entries {
+		// RAOP instance names have the form "<MAC>@<Friendly Name>"
+		uuid, name, found := strings.Cut(entry.Instance, "@")
+		if !found {
+			uuid, name = entry.Instance, entry.Instance
+		}
+		device := &AirPlayDevice{
+			IP:       entry.IP,
+			Port:     entry.Port,
+			UUID:     uuid,
+			Name:     name,
+			Model:    entry.TXT["am"],
+			Password: entry.TXT["pw"] == "true" || entry.TXT["pk"] != "",
+			LastSeen: time.Now(),
+		}
+		devices = append(devices, device)
+		d.cache.Set(device)
+		log.Debug(ctx, "Found AirPlay device", "name", device.Name, "model", device.Model,
+			"ip", device.IP, "port", device.Port, "password", device.Password)
+	}
+
+	log.Info(ctx, "AirPlay discovery complete", "devicesFound", len(devices))
+	return devices, nil
+}
+
+// GetDevices returns all cached devices
+func (d *Discovery) GetDevices() []*AirPlayDevice {
+	return d.cache.GetAll()
+}
+
+// GetDevice returns a specific device by UUID
+func (d *Discovery) GetDevice(uuid string) (*AirPlayDevice, bool) {
+	return d.cache.Get(uuid)
+}
+
+// localUDPAddr returns the local address to bind the mDNS socket to.
+// AirPlay.Interface may be an IP address or an interface name; when empty
+// (or unresolvable) the socket binds to all interfaces
+func localUDPAddr(ctx context.Context) *net.UDPAddr {
+	name := conf.Server.AirPlay.Interface
+	if name == "" {
+		return &net.UDPAddr{IP: net.IPv4zero, Port: 0}
+	}
+	if ip := net.ParseIP(name); ip != nil {
+		return &net.UDPAddr{IP: ip, Port: 0}
+	}
+	if iface, err := net.InterfaceByName(name); err == nil {
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+				return &net.UDPAddr{IP: ipNet.IP, Port: 0}
+			}
+		}
+	}
+	log.Warn(ctx, "Could not resolve AirPlay.Interface - binding to all interfaces", "interface", name)
+	return &net.UDPAddr{IP: net.IPv4zero, Port: 0}
+}
+
+// mdnsTimeout returns how long to wait for mDNS responses
+func mdnsTimeout() time.Duration {
+	if t := conf.Server.AirPlay.MDNSTimeout; t > 0 {
+		return t
+	}
+	return defaultMDNSTimeout
+}
@@ -0,0 +1,26 @@
+package airplay
+
+import "errors"
+
+var (
+	// ErrDeviceNotFound is returned when a device UUID is not in the cache
+	ErrDeviceNotFound = errors.New("airplay device not found")
+
+	// ErrNoDevices is returned when no AirPlay receivers are available
+	ErrNoDevices = errors.New("no airplay devices discovered")
+
+	// ErrAuthRequired is returned for receivers that demand a password or
+	// device pairing, which this sender does not support
+	ErrAuthRequired = errors.New("airplay device requires a password or pairing")
+
+	// ErrInvalidVolume is returned when volume is out of range
+	ErrInvalidVolume = errors.New("volume must be between 0 and 100")
+
+	// ErrNoSession is returned when a control command is sent to a device
+	// without an active streaming session
+	ErrNoSession = errors.New("no active airplay session on device")
+
+	// ErrNotAuthorized is returned when the user's libraries don't include
+	// the requested content
+	ErrNotAuthorized = errors.New("user does not have access to this content")
+)
@@ -0,0 +1,354 @@
+package airplay
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+)
+
+// RAOP audio parameters. The protocol streams 44.1kHz/16-bit stereo PCM
+// wrapped in ALAC "verbatim" (uncompressed) frames, 352 frames per packet
+const (
+	sampleRate      = 44100
+	framesPerPacket = 352
+	bytesPerFrame   = 4 // 16-bit stereo
+	packetPCMSize   = framesPerPacket * bytesPerFrame
+
+	// latencyFrames is the buffer the receiver is told to keep before a
+	// timestamp becomes audible
+	latencyFrames = 11025
+
+	syncInterval = 126 // packets between sync packets, roughly one per second
+)
+
+// raopSession is one active RAOP stream to a receiver. The sender owns the
+// whole session: RTSP control connection, RTP audio/control/timing sockets
+// and the pacing loop feeding PCM to the device
+type raopSession struct {
+	device *AirPlayDevice
+	client *rtspClient
+
+	dataConn    *net.UDPConn
+	controlConn *net.UDPConn
+	timingConn  *net.UDPConn
+	dataAddr    *net.UDPAddr
+	controlAddr *net.UDPAddr
+
+	seq     uint16
+	rtpTime uint32
+	ssrc    uint32
+
+	track  *Track
+	volume int
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startRAOPSession performs the RTSP handshake (OPTIONS, ANNOUNCE, SETUP,
+// RECORD) and leaves the session ready to stream audio
+func startRAOPSession(ctx context.Context, device *AirPlayDevice) (*raopSession, error) {
+	if device.Password {
+		return nil, ErrAuthRequired
+	}
+
+	sessionID := rand.Uint32()
+	client, err := dialRTSP(ctx, device, "*")
+	if err != nil {
+		return nil, err
+	}
+	localIP := client.localIP()
+	client.uri = fmt.Sprintf("rtsp://%s/%d", localIP, sessionID)
+
+	s := &raopSession{
+		device:  device,
+		client:  client,
+		seq:     uint16(rand.Uint32()),
+		rtpTime: rand.Uint32(),
+		ssrc:    rand.Uint32(),
+		done:    make(chan struct{}),
+	}
+
+	cleanup := func() {
+		s.closeSockets()
+		client.close()
+	}
+
+	// Local sockets for the receiver's control and timing traffic
+	if s.controlConn, err = net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero}); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to open control socket: %w", err)
+	}
+	if s.timingConn, err = net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero}); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to open timing socket: %w", err)
+	}
+
+	if _, err = client.do("OPTIONS", nil, "", nil); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	// ANNOUNCE declares an unencrypted ALAC stream. Receivers that insist on
+	// RSA encryption (older AirPort Express firmware) will reject this
+	sdp := fmt.Sprintf("v=0\r\n"+
+		"o=iTunes %d 0 IN IP4 %s\r\n"+
+		"s=iTunes\r\n"+
+		"c=IN IP4 %s\r\n"+
+		"t=0 0\r\n"+
+		"m=audio 0 RTP/AVP 96\r\n"+
+		"a=rtpmap:96 AppleLossless\r\n"+
+		"a=fmtp:96 %d 0 16 40 10 14 2 255 0 0 %d\r\n",
+		sessionID, localIP, device.IP, framesPerPacket, sampleRate)
+	if _, err = client.do("ANNOUNCE", nil, "application/sdp", []byte(sdp)); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	controlPort := s.controlConn.LocalAddr().(*net.UDPAddr).Port
+	timingPort := s.timingConn.LocalAddr().(*net.UDPAddr).Port
+	transport := fmt.Sprintf("RTP/AVP/UDP;unicast;interleaved=0-1;mode=record;control_port=%d;timing_port=%d",
+		controlPort, timingPort)
+	resp, err := client.do("SETUP", map[string]string{"Transport": transport}, "", nil)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	params := transportParams(resp.headers["transport"])
+	serverPort := atoiDefault(params["server_port"], 6000)
+	remoteControlPort := atoiDefault(params["control_port"], 6001)
+
+	s.dataAddr = &net.UDPAddr{IP: net.ParseIP(device.IP), Port: serverPort}
+	s.controlAddr = &net.UDPAddr{IP: net.ParseIP(device.IP), Port: remoteControlPort}
+	if s.dataConn, err = net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero}); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to open audio socket: %w", err)
+	}
+
+	// Receivers start sending timing queries right after SETUP and refuse to
+	// play until they get answers
+	go s.timingResponder(ctx)
+
+	record := map[string]string{
+		"Range":    "npt=0-",
+		"RTP-Info": fmt.Sprintf("seq=%d;rtptime=%d", s.seq, s.rtpTime),
+	}
+	if _, err = client.do("RECORD", record, "", nil); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// stream paces the PCM reader out to the receiver in real time. It blocks
+// until the stream ends, the context is cancelled or the device fails
+func (s *raopSession) stream(ctx context.Context, pcm io.Reader) error {
+	buf := make([]byte, packetPCMSize)
+	packets := 0
+	first := true
+
+	// Pace against the wall clock rather than sleeping per packet, so
+	// scheduling jitter doesn't accumulate into buffer underruns
+	start := time.Now()
+	sent := 0 // frames handed to the receiver
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if packets%syncInterval == 0 {
+			s.sendSync(first)
+		}
+
+		n, err := io.ReadFull(pcm, buf)
+		if n == 0 {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+		// Zero-pad the last short packet
+		for i := n; i < packetPCMSize; i++ {
+			buf[i] = 0
+		}
+
+		if sendErr := s.sendAudioPacket(buf, first); sendErr != nil {
+			return fmt.Errorf("failed to send audio packet: %w", sendErr)
+		}
+		first = false
+		packets++
+		sent += framesPerPacket
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+
+		elapsed := time.Duration(sent-latencyFrames) * time.Second / sampleRate
+		if sleep := elapsed - time.Since(start); sleep > 0 {
+			time.Sleep(sleep)
+		}
+	}
+}
+
+// sendAudioPacket wraps one block of PCM into an RTP packet carrying an ALAC
+// verbatim frame
+func (s *raopSession) sendAudioPacket(pcm []byte, first bool) error {
+	packet := make([]byte, 0, 12+packetPCMSize+8)
+
+	// RTP header
+	packet = append(packet, 0x80, 0x60)
+	if first {
+		packet[1] |= 0x80 // marker bit on the first packet of the stream
+	}
+	packet = binary.BigEndian.AppendUint16(packet, s.seq)
+	packet = binary.BigEndian.AppendUint32(packet, s.rtpTime)
+	packet = binary.BigEndian.AppendUint32(packet, s.ssrc)
+
+	packet = append(packet, alacVerbatimFrame(pcm)...)
+
+	_, err := s.dataConn.WriteToUDP(packet, s.dataAddr)
+	s.seq++
+	s.rtpTime += framesPerPacket
+	return err
+}
+
+// sendSync sends a timing sync packet on the control channel, anchoring the
+// RTP clock to NTP time so the receiver can schedule playback
+func (s *raopSession) sendSync(first bool) {
+	packet := make([]byte, 0, 20)
+	flags := byte(0x80)
+	if first {
+		flags |= 0x10 // extension bit marks the first sync of a stream
+	}
+	packet = append(packet, flags, 0xd4, 0x00, 0x07)
+	packet = binary.BigEndian.AppendUint32(packet, s.rtpTime-latencyFrames)
+	packet = binary.BigEndian.AppendUint64(packet, ntpNow())
+	packet = binary.BigEndian.AppendUint32(packet, s.rtpTime)
+	_, _ = s.controlConn.WriteToUDP(packet, s.controlAddr)
+}
+
+// timingResponder answers the receiver's NTP-style timing queries for the
+// lifetime of the session
+func (s *raopSession) timingResponder(ctx context.Context) {
+	buf := make([]byte, 32)
+	for {
+		n, addr, err := s.timingConn.ReadFromUDP(buf)
+		if err != nil {
+			return // socket closed on teardown
+		}
+		if n < 32 || buf[1]&0x7F != 0x52 {
+			continue
+		}
+
+		now := ntpNow()
+		reply := make([]byte, 0, 32)
+		reply = append(reply, 0x80, 0xd3, buf[2], buf[3])
+		reply = append(reply, buf[24:32]...) // origin = request transmit time
+		reply = binary.BigEndian.AppendUint64(reply, now)
+		reply = binary.BigEndian.AppendUint64(reply, now)
+		if _, err := s.timingConn.WriteToUDP(reply, addr); err != nil {
+			log.Trace(ctx, "Failed to answer AirPlay timing query", "device", s.device.Name, err)
+		}
+	}
+}
+
+// setVolume sends the receiver an absolute volume. RAOP volume is in dB
+// attenuation from -30 (quietest) to 0 (loudest), with -144 meaning mute
+func (s *raopSession) setVolume(volume int) error {
+	db := -144.0
+	if volume > 0 {
+		db = -30 + 30*float64(volume)/100
+	}
+	body := fmt.Sprintf("volume: %f\r\n", db)
+	_, err := s.client.do("SET_PARAMETER", nil, "text/parameters", []byte(body))
+	if err == nil {
+		s.volume = volume
+	}
+	return err
+}
+
+// teardown stops the stream and releases all session resources
+func (s *raopSession) teardown() {
+	_, _ = s.client.do("FLUSH", map[string]string{
+		"RTP-Info": fmt.Sprintf("seq=%d;rtptime=%d", s.seq, s.rtpTime),
+	}, "", nil)
+	_, _ = s.client.do("TEARDOWN", nil, "", nil)
+	s.closeSockets()
+	s.client.close()
+}
+
+// closeSockets closes whichever UDP sockets have been opened so far
+func (s *raopSession) closeSockets() {
+	for _, conn := range []*net.UDPConn{s.dataConn, s.controlConn, s.timingConn} {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}
+}
+
+// alacVerbatimFrame wraps raw 16-bit big-endian PCM in an ALAC frame header
+// with the "escape" bit set, meaning the samples are stored uncompressed
+func alacVerbatimFrame(pcm []byte) []byte {
+	w := bitWriter{buf: make([]byte, 0, len(pcm)+8)}
+	w.write(1, 3) // channel tag: stereo pair
+	w.write(0, 4) // reserved
+	w.write(0, 1) // no explicit sample count
+	w.write(0, 2) // reserved
+	w.write(1, 1) // escape: verbatim samples follow
+	for i := 0; i+1 < len(pcm); i += 2 {
+		w.write(uint32(pcm[i])<<8|uint32(pcm[i+1]), 16)
+	}
+	return w.bytes()
+}
+
+// bitWriter packs values MSB-first, as the ALAC bitstream requires
+type bitWriter struct {
+	buf  []byte
+	cur  uint64
+	bits uint
+}
+
+func (w *bitWriter) write(value uint32, n uint) {
+	w.cur = w.cur<<n | uint64(value)&(1<<n-1)
+	w.bits += n
+	for w.bits >= 8 {
+		w.bits -= 8
+		w.buf = append(w.buf, byte(w.cur>>w.bits))
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.bits > 0 {
+		w.buf = append(w.buf, byte(w.cur<<(8-w.bits)))
+	}
+	return w.buf
+}
+
+// ntpNow returns the current time in 64-bit NTP format (seconds since 1900
+// in the high word, fraction in the low word)
+func ntpNow() uint64 {
+	now := time.Now()
+	secs := uint64(now.Unix()) + 2208988800 // Unix epoch offset to 1900
+	frac := uint64(now.Nanosecond()) << 32 / 1000000000
+	return secs<<32 | frac
+}
+
+// atoiDefault parses an integer, falling back to a default on error
+func atoiDefault(s string, def int) int {
+	var v int
+	if _, err := fmt.Sscanf(s, "%d", &v); err != nil {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,154 @@
+package airplay
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const rtspTimeout = 10 * time.Second
+
+// rtspClient is a minimal RTSP/1.0 client, covering only what the RAOP
+// handshake needs (OPTIONS, ANNOUNCE, SETUP, RECORD, SET_PARAMETER, FLUSH
+// and TEARDOWN)
+type rtspClient struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	uri     string
+	cseq    int
+	session string
+}
+
+// rtspResponse is a parsed RTSP response
+type rtspResponse struct {
+	status  int
+	headers map[string]string
+	body    []byte
+}
+
+// dialRTSP opens an RTSP connection to an AirPlay receiver
+func dialRTSP(ctx context.Context, device *AirPlayDevice, uri string) (*rtspClient, error) {
+	dialer := &net.Dialer{Timeout: rtspTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", device.IP, device.Port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to airplay device: %w", err)
+	}
+	return &rtspClient{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		uri:    uri,
+	}, nil
+}
+
+// do sends one RTSP request and reads its response
+func (c *rtspClient) do(method string, headers map[string]string, contentType string, body []byte) (*rtspResponse, error) {
+	c.cseq++
+
+	var req strings.Builder
+	fmt.Fprintf(&req, "%s %s RTSP/1.0\r\n", method, c.uri)
+	fmt.Fprintf(&req, "CSeq: %d\r\n", c.cseq)
+	fmt.Fprintf(&req, "User-Agent: iTunes/11.0 (Navidrome)\r\n")
+	if c.session != "" {
+		fmt.Fprintf(&req, "Session: %s\r\n", c.session)
+	}
+	for key, value := range headers {
+		fmt.Fprintf(&req, "%s: %s\r\n", key, value)
+	}
+	if len(body) > 0 {
+		fmt.Fprintf(&req, "Content-Type: %s\r\n", contentType)
+		fmt.Fprintf(&req, "Content-Length: %d\r\n", len(body))
+	}
+	req.WriteString("\r\n")
+
+	_ = c.conn.SetDeadline(time.Now().Add(rtspTimeout))
+	if _, err := c.conn.Write(append([]byte(req.String()), body...)); err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", method, err)
+	}
+
+	resp, err := c.readResponse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+	if session, ok := resp.headers["session"]; ok && c.session == "" {
+		// Some receivers append ";timeout=..." to the session ID
+		c.session, _, _ = strings.Cut(session, ";")
+	}
+	if resp.status != 200 {
+		return resp, fmt.Errorf("%s rejected with status %d", method, resp.status)
+	}
+	return resp, nil
+}
+
+// readResponse parses one RTSP response off the wire
+func (c *rtspClient) readResponse() (*rtspResponse, error) {
+	statusLine, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.SplitN(strings.TrimSpace(statusLine), " ", 3)
+	if len(parts) < 2 || !strings.HasPrefix(parts[0], "RTSP/") {
+		return nil, fmt.Errorf("malformed status line: %q", statusLine)
+	}
+	status, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed status code: %q", parts[1])
+	}
+
+	resp := &rtspResponse{status: status, headers: make(map[string]string)}
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			resp.headers[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+	}
+
+	if lengthStr, ok := resp.headers["content-length"]; ok {
+		length, err := strconv.Atoi(lengthStr)
+		if err != nil || length < 0 {
+			return nil, fmt.Errorf("malformed content length: %q", lengthStr)
+		}
+		resp.body = make([]byte, length)
+		if _, err := io.ReadFull(c.reader, resp.body); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// close shuts the RTSP connection down
+func (c *rtspClient) close() {
+	_ = c.conn.Close()
+}
+
+// localIP returns the local address of the RTSP connection, used in the SDP
+// and stream URIs so the receiver talks back to the right interface
+func (c *rtspClient) localIP() string {
+	if addr, ok := c.conn.LocalAddr().(*net.TCPAddr); ok {
+		return addr.IP.String()
+	}
+	return ""
+}
+
+// transportParams parses the key=value pairs of a Transport header
+func transportParams(header string) map[string]string {
+	params := make(map[string]string)
+	for _, part := range strings.Split(header, ";") {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			params[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return params
+}
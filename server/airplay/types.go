@@ -0,0 +1,81 @@
+package airplay
+
+import (
+	"sync"
+	"time"
+)
+
+// AirPlayDevice represents a discovered AirPlay (RAOP) receiver
+type AirPlayDevice struct {
+	IP       string    `json:"ip"`
+	Port     int       `json:"port"`
+	UUID     string    `json:"uuid"` // MAC address part of the RAOP instance name
+	Name     string    `json:"name"`
+	Model    string    `json:"model"`    // "am" TXT field (e.g. AppleTV3,2 or ShairportSync)
+	Password bool      `json:"password"` // receiver requires a password or device pairing
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// PlaybackState represents the state of an active RAOP session. AirPlay is a
+// push protocol - the sender owns the session, so state is tracked locally
+// instead of being polled from the receiver
+type PlaybackState struct {
+	State        string `json:"state"` // PLAYING, STOPPED
+	CurrentTrack *Track `json:"currentTrack,omitempty"`
+	Volume       int    `json:"volume"` // 0-100
+}
+
+// Track represents the currently streaming track
+type Track struct {
+	ID       string  `json:"id"`
+	Title    string  `json:"title"`
+	Artist   string  `json:"artist"`
+	Album    string  `json:"album"`
+	Duration float32 `json:"duration"` // seconds
+}
+
+// VolumeRequest is the request body for volume control
+type VolumeRequest struct {
+	Volume int `json:"volume"` // 0-100
+}
+
+// DeviceCache holds discovered devices with thread-safe access
+type DeviceCache struct {
+	mu      sync.RWMutex
+	devices map[string]*AirPlayDevice // keyed by UUID
+}
+
+func NewDeviceCache() *DeviceCache {
+	return &DeviceCache{
+		devices: make(map[string]*AirPlayDevice),
+	}
+}
+
+func (c *DeviceCache) Set(device *AirPlayDevice) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.devices[device.UUID] = device
+}
+
+func (c *DeviceCache) Get(uuid string) (*AirPlayDevice, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	d, ok := c.devices[uuid]
+	return d, ok
+}
+
+func (c *DeviceCache) GetAll() []*AirPlayDevice {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	result := make([]*AirPlayDevice, 0, len(c.devices))
+	for _, d := range c.devices {
+		result = append(result, d)
+	}
+	return result
+}
+
+func (c *DeviceCache) Remove(uuid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.devices, uuid)
+}
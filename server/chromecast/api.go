@@ -0,0 +1,313 @@
+package chromecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/core/auth"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	"github.com/navidrome/navidrome/server"
+)
+
+// API handles REST API endpoints for Chromecast. It exposes the same REST
+// shape as the Sonos Cast API, so clients can drive mixed speaker fleets
+// through one UX
+type API struct {
+	chromecast *Chromecast
+	ds         model.DataStore
+	streamer   core.MediaStreamer
+}
+
+// NewAPI creates a new Chromecast API handler
+func NewAPI(chromecast *Chromecast, ds model.DataStore, streamer core.MediaStreamer) *API {
+	return &API{
+		chromecast: chromecast,
+		ds:         ds,
+		streamer:   streamer,
+	}
+}
+
+// Router returns the chi router with all Chromecast endpoints
+func (a *API) Router() http.Handler {
+	r := chi.NewRouter()
+
+	// Stream endpoint - devices can't authenticate, so access is granted by
+	// the signed expiring token embedded in the URL instead
+	r.Get("/stream/{token}", a.handleStream)
+
+	// All remaining endpoints require a logged-in user
+	r.Group(func(r chi.Router) {
+		r.Use(server.Authenticator(a.ds))
+		r.Use(server.JWTRefresher)
+
+		// Device endpoints
+		r.Get("/devices", a.getDevices)
+		r.Post("/devices/refresh", a.refreshDevices)
+
+		r.Route("/devices/{id}", func(r chi.Router) {
+			r.Get("/", a.getDevice)
+			r.Get("/state", a.getDeviceState)
+
+			// Playback control
+			r.Post("/play", a.play)
+			r.Post("/pause", a.pause)
+			r.Post("/stop", a.stop)
+
+			// Volume control
+			r.Post("/volume", a.setVolume)
+			r.Post("/mute", a.setMute)
+
+			// Cast media
+			r.Post("/cast", a.castMedia)
+		})
+	})
+
+	return r
+}
+
+// getDevices returns all discovered Cast devices
+func (a *API) getDevices(w http.ResponseWriter, r *http.Request) {
+	a.sendJSON(w, http.StatusOK, a.chromecast.GetDevices())
+}
+
+// refreshDevices forces a new mDNS discovery
+func (a *API) refreshDevices(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := a.chromecast.RefreshDevices(ctx); err != nil {
+		a.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.sendJSON(w, http.StatusOK, a.chromecast.GetDevices())
+}
+
+// getDevice returns a specific device by UUID
+func (a *API) getDevice(w http.ResponseWriter, r *http.Request) {
+	deviceID := chi.URLParam(r, "id")
+	device, ok := a.chromecast.GetDevice(deviceID)
+	if !ok {
+		a.sendError(w, http.StatusNotFound, "device not found")
+		return
+	}
+	a.sendJSON(w, http.StatusOK, device)
+}
+
+// getDeviceState returns the current playback state of a device
+func (a *API) getDeviceState(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	state, err := a.chromecast.GetPlaybackState(ctx, deviceID)
+	if err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, state)
+}
+
+// play resumes playback
+func (a *API) play(w http.ResponseWriter, r *http.Request) {
+	a.transportCommand(w, r, a.chromecast.Play, "playing")
+}
+
+// pause pauses playback
+func (a *API) pause(w http.ResponseWriter, r *http.Request) {
+	a.transportCommand(w, r, a.chromecast.Pause, "paused")
+}
+
+// stop stops playback
+func (a *API) stop(w http.ResponseWriter, r *http.Request) {
+	a.transportCommand(w, r, a.chromecast.Stop, "stopped")
+}
+
+// transportCommand runs a simple per-device transport action and maps its
+// errors to HTTP statuses
+func (a *API) transportCommand(w http.ResponseWriter, r *http.Request,
+	command func(context.Context, string) error, status string) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	if err := command(ctx, deviceID); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else if err == ErrNoMediaSession {
+			a.sendError(w, http.StatusBadRequest, "nothing is playing on this device")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": status})
+}
+
+// setVolume sets the device volume
+func (a *API) setVolume(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	var req VolumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := a.chromecast.SetVolume(ctx, deviceID, req.Volume); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else if err == ErrInvalidVolume {
+			a.sendError(w, http.StatusBadRequest, err.Error())
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, map[string]int{"volume": req.Volume})
+}
+
+// setMute sets the device mute state
+func (a *API) setMute(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	var req struct {
+		Muted bool `json:"muted"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := a.chromecast.SetMute(ctx, deviceID, req.Muted); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, map[string]bool{"muted": req.Muted})
+}
+
+// castRequest is the request body for casting media
+type castRequest struct {
+	TrackIds []string `json:"trackIds"`
+}
+
+// castMedia loads the requested tracks on a device and starts playback
+func (a *API) castMedia(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	var req castRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.TrackIds) == 0 {
+		a.sendError(w, http.StatusBadRequest, "trackIds is required")
+		return
+	}
+
+	items := make([]MediaItem, 0, len(req.TrackIds))
+	for _, trackID := range req.TrackIds {
+		item, err := a.buildMediaItem(ctx, trackID)
+		if err != nil {
+			if err == ErrNotAuthorized {
+				a.sendError(w, http.StatusForbidden, err.Error())
+			} else {
+				a.sendError(w, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		items = append(items, *item)
+	}
+
+	if err := a.chromecast.CastMedia(ctx, deviceID, items); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	a.sendJSON(w, http.StatusOK, map[string]any{"status": "playing", "tracks": len(items)})
+}
+
+// buildMediaItem looks up a track and builds the stream URL and metadata the
+// Cast media receiver needs
+func (a *API) buildMediaItem(ctx context.Context, trackID string) (*MediaItem, error) {
+	track, err := a.ds.MediaFile(ctx).Get(trackID)
+	if err != nil {
+		log.Error(ctx, "Failed to get track from database", err, "trackID", trackID)
+		return nil, fmt.Errorf("track not found: %w", err)
+	}
+
+	// Make sure the requesting user's libraries include this track before
+	// handing out a stream URL for it
+	user, ok := request.UserFrom(ctx)
+	if !ok || !user.HasLibraryAccess(track.LibraryID) {
+		log.Warn(ctx, "Cast denied - track not in user's libraries", "username", user.UserName,
+			"trackID", trackID, "libraryID", track.LibraryID)
+		return nil, ErrNotAuthorized
+	}
+
+	baseURL := a.chromecast.GetStreamBaseURL()
+
+	// The default media receiver handles FLAC/MP3/AAC/OGG/WAV natively, but
+	// hi-res material above 96kHz/24-bit is not guaranteed - transcode it
+	format := ""
+	if track.SampleRate > 96000 {
+		log.Debug(ctx, "Hi-res audio detected - will transcode for Cast compatibility",
+			"track", track.Title, "sampleRate", track.SampleRate)
+		format = "flac"
+	}
+
+	token := encodeStreamToken(trackID, format)
+	streamURL := fmt.Sprintf("%s%s/stream/%s", baseURL, consts.URLPathChromecast, token)
+
+	imageURL := ""
+	if track.HasCoverArt {
+		artToken, _ := auth.CreatePublicToken(map[string]any{"id": "al-" + track.AlbumID})
+		imageURL = fmt.Sprintf("%s%s/%s", baseURL, consts.URLPathPublicImages, artToken)
+	}
+
+	mimeType := track.ContentType()
+	if mimeType == "" {
+		mimeType = "audio/flac"
+	}
+
+	return &MediaItem{
+		URL:         streamURL,
+		ContentType: mimeType,
+		Title:       track.Title,
+		Artist:      track.Artist,
+		Album:       track.Album,
+		ImageURL:    imageURL,
+		Duration:    track.Duration,
+	}, nil
+}
+
+// sendJSON sends a JSON response
+func (a *API) sendJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Error("Failed to encode JSON response", err)
+	}
+}
+
+// sendError sends an error response
+func (a *API) sendError(w http.ResponseWriter, status int, message string) {
+	a.sendJSON(w, status, map[string]string{"error": message})
+}
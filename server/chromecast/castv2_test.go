@@ -0,0 +1,72 @@
+package chromecast
+
+import (
+	"encoding/binary"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CastMessage codec", func() {
+	It("round-trips a message", func() {
+		msg := castMessage{
+			sourceID:      senderID,
+			destinationID: receiverID,
+			namespace:     nsReceiver,
+			payload:       `{"type":"GET_STATUS","requestId":1}`,
+		}
+		decoded, err := unmarshalCastMessage(marshalCastMessage(msg))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded).To(Equal(msg))
+	})
+
+	It("round-trips empty and non-ASCII payloads", func() {
+		msg := castMessage{
+			sourceID:      senderID,
+			destinationID: "transport-7",
+			namespace:     nsMedia,
+			payload:       `{"title":"Café – Niño 🎵"}`,
+		}
+		decoded, err := unmarshalCastMessage(marshalCastMessage(msg))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded).To(Equal(msg))
+
+		decoded, err = unmarshalCastMessage(marshalCastMessage(castMessage{}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded).To(Equal(castMessage{}))
+	})
+
+	It("skips unknown varint fields", func() {
+		// A future protocol revision may add fields; unknown varints must be
+		// skipped, not treated as corruption
+		frame := marshalCastMessage(castMessage{namespace: nsHeartbeat, payload: `{"type":"PING"}`})
+		frame = appendProtoVarint(frame, 7, 42)
+		decoded, err := unmarshalCastMessage(frame)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded.namespace).To(Equal(nsHeartbeat))
+		Expect(decoded.payload).To(Equal(`{"type":"PING"}`))
+	})
+
+	It("rejects a truncated string field", func() {
+		frame := marshalCastMessage(castMessage{payload: `{"type":"PONG"}`})
+		_, err := unmarshalCastMessage(frame[:len(frame)-3])
+		Expect(err).To(MatchError(ContainSubstring("invalid length")))
+	})
+
+	It("rejects a length that overruns the frame", func() {
+		var frame []byte
+		frame = binary.AppendUvarint(frame, 6<<3|2) // payload_utf8
+		frame = binary.AppendUvarint(frame, 1000)
+		frame = append(frame, "short"...)
+		_, err := unmarshalCastMessage(frame)
+		Expect(err).To(MatchError(ContainSubstring("invalid length")))
+	})
+
+	It("rejects unexpected wire types", func() {
+		var frame []byte
+		frame = binary.AppendUvarint(frame, 2<<3|5) // source_id as fixed32
+		frame = append(frame, 0, 0, 0, 0)
+		_, err := unmarshalCastMessage(frame)
+		Expect(err).To(MatchError(ContainSubstring("unexpected wire type")))
+	})
+})
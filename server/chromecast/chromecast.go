@@ -0,0 +1,480 @@
+package chromecast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+)
+
+// Chromecast is the main service for Google Cast device control
+type Chromecast struct {
+	discovery *Discovery
+	running   bool
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+	mu        sync.RWMutex
+}
+
+// NewChromecast creates a new Chromecast service
+func NewChromecast() *Chromecast {
+	return &Chromecast{
+		discovery: NewDiscovery(),
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start begins the Chromecast service with periodic discovery
+func (s *Chromecast) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	log.Info(ctx, "Starting Chromecast service")
+
+	// Initial discovery
+	if _, err := s.discovery.Scan(ctx); err != nil {
+		log.Error(ctx, "Chromecast discovery failed", err)
+	}
+
+	// Start periodic discovery. A non-positive interval disables it entirely,
+	// leaving only the initial scan and manual refreshes
+	interval := conf.Server.Chromecast.DiscoveryInterval
+	if interval <= 0 {
+		log.Info(ctx, "Chromecast periodic discovery is DISABLED")
+		return nil
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.discovery.Scan(ctx); err != nil {
+					log.Error(ctx, "Chromecast discovery failed", err)
+				}
+			case <-s.stopCh:
+				log.Info(ctx, "Chromecast discovery stopped")
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown stops the Chromecast service
+func (s *Chromecast) Shutdown() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	s.mu.Unlock()
+
+	close(s.stopCh)
+	s.wg.Wait()
+}
+
+// RefreshDevices forces a new mDNS discovery scan
+func (s *Chromecast) RefreshDevices(ctx context.Context) error {
+	_, err := s.discovery.Scan(ctx)
+	return err
+}
+
+// GetDevices returns all discovered Cast devices
+func (s *Chromecast) GetDevices() []*ChromecastDevice {
+	return s.discovery.GetDevices()
+}
+
+// GetDevice returns a specific device by UUID
+func (s *Chromecast) GetDevice(uuid string) (*ChromecastDevice, bool) {
+	return s.discovery.GetDevice(uuid)
+}
+
+// connect looks up a device and opens a CASTV2 session to it
+func (s *Chromecast) connect(ctx context.Context, uuid string) (*castClient, error) {
+	device, ok := s.discovery.GetDevice(uuid)
+	if !ok {
+		return nil, ErrDeviceNotFound
+	}
+	return newCastClient(ctx, device)
+}
+
+// receiverStatus is the "status" object of a RECEIVER_STATUS message
+type receiverStatus struct {
+	Applications []struct {
+		AppID       string `json:"appId"`
+		TransportID string `json:"transportId"`
+		DisplayName string `json:"displayName"`
+	} `json:"applications"`
+	Volume struct {
+		Level float64 `json:"level"`
+		Muted bool    `json:"muted"`
+	} `json:"volume"`
+}
+
+// mediaStatus is one entry of the "status" array of a MEDIA_STATUS message
+type mediaStatus struct {
+	MediaSessionID int     `json:"mediaSessionId"`
+	PlayerState    string  `json:"playerState"`
+	CurrentTime    float64 `json:"currentTime"`
+	Media          struct {
+		ContentID   string  `json:"contentId"`
+		ContentType string  `json:"contentType"`
+		Duration    float64 `json:"duration"`
+		Metadata    struct {
+			Title     string `json:"title"`
+			Artist    string `json:"artist"`
+			AlbumName string `json:"albumName"`
+			Images    []struct {
+				URL string `json:"url"`
+			} `json:"images"`
+		} `json:"metadata"`
+	} `json:"media"`
+}
+
+// getReceiverStatus asks the platform receiver for its current status
+func (s *Chromecast) getReceiverStatus(ctx context.Context, c *castClient) (*receiverStatus, error) {
+	resp, err := c.request(ctx, receiverID, nsReceiver, map[string]any{"type": "GET_STATUS"})
+	if err != nil {
+		return nil, err
+	}
+	var parsed struct {
+		Status receiverStatus `json:"status"`
+	}
+	if err := json.Unmarshal(resp, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse receiver status: %w", err)
+	}
+	return &parsed.Status, nil
+}
+
+// ensureMediaApp makes sure the default media receiver app is running and
+// returns its transport ID, opening a virtual connection to it
+func (s *Chromecast) ensureMediaApp(ctx context.Context, c *castClient) (string, error) {
+	status, err := s.getReceiverStatus(ctx, c)
+	if err != nil {
+		return "", err
+	}
+
+	transportID := ""
+	for _, app := range status.Applications {
+		if app.AppID == defaultMediaReceiverAppID {
+			transportID = app.TransportID
+			break
+		}
+	}
+
+	if transportID == "" {
+		log.Debug(ctx, "Launching default media receiver", "device", c.device.Name)
+		resp, err := c.request(ctx, receiverID, nsReceiver,
+			map[string]any{"type": "LAUNCH", "appId": defaultMediaReceiverAppID})
+		if err != nil {
+			return "", fmt.Errorf("failed to launch media receiver: %w", err)
+		}
+		var parsed struct {
+			Status receiverStatus `json:"status"`
+		}
+		if err := json.Unmarshal(resp, &parsed); err != nil {
+			return "", fmt.Errorf("failed to parse launch response: %w", err)
+		}
+		for _, app := range parsed.Status.Applications {
+			if app.AppID == defaultMediaReceiverAppID {
+				transportID = app.TransportID
+				break
+			}
+		}
+		if transportID == "" {
+			return "", fmt.Errorf("media receiver did not start")
+		}
+	}
+
+	// Media commands go to the app's transport, which needs its own
+	// virtual connection
+	if err := c.send(transportID, nsConnection, map[string]any{"type": "CONNECT"}); err != nil {
+		return "", err
+	}
+	return transportID, nil
+}
+
+// mediaItemPayload converts a MediaItem to the LOAD/QUEUE_LOAD media object
+func mediaItemPayload(item MediaItem) map[string]any {
+	metadata := map[string]any{
+		"metadataType": 3, // MusicTrackMediaMetadata
+		"title":        item.Title,
+		"artist":       item.Artist,
+		"albumName":    item.Album,
+	}
+	if item.ImageURL != "" {
+		metadata["images"] = []map[string]any{{"url": item.ImageURL}}
+	}
+	media := map[string]any{
+		"contentId":   item.URL,
+		"contentType": item.ContentType,
+		"streamType":  "BUFFERED",
+		"metadata":    metadata,
+	}
+	if item.Duration > 0 {
+		media["duration"] = item.Duration
+	}
+	return media
+}
+
+// CastMedia loads one or more tracks on the device's default media receiver
+// and starts playback. Multiple items are loaded as a queue
+func (s *Chromecast) CastMedia(ctx context.Context, uuid string, items []MediaItem) error {
+	if len(items) == 0 {
+		return fmt.Errorf("nothing to cast")
+	}
+
+	c, err := s.connect(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	transportID, err := s.ensureMediaApp(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	var payload map[string]any
+	if len(items) == 1 {
+		payload = map[string]any{
+			"type":     "LOAD",
+			"media":    mediaItemPayload(items[0]),
+			"autoplay": true,
+		}
+	} else {
+		queueItems := make([]map[string]any, 0, len(items))
+		for _, item := range items {
+			queueItems = append(queueItems, map[string]any{
+				"media":    mediaItemPayload(item),
+				"autoplay": true,
+			})
+		}
+		payload = map[string]any{
+			"type":       "QUEUE_LOAD",
+			"items":      queueItems,
+			"startIndex": 0,
+			"repeatMode": "REPEAT_OFF",
+		}
+	}
+
+	resp, err := c.request(ctx, transportID, nsMedia, payload)
+	if err != nil {
+		return fmt.Errorf("failed to load media: %w", err)
+	}
+
+	// The device answers LOAD errors as a typed message, not a fault
+	var parsed struct {
+		Type string `json:"type"`
+	}
+	_ = json.Unmarshal(resp, &parsed)
+	if parsed.Type == "LOAD_FAILED" || parsed.Type == "INVALID_REQUEST" {
+		return fmt.Errorf("cast device rejected media load: %s", parsed.Type)
+	}
+
+	log.Info(ctx, "Successfully loaded media on Cast device", "device", c.device.Name, "tracks", len(items))
+	return nil
+}
+
+// getMediaStatus fetches the active media session, if any
+func (s *Chromecast) getMediaStatus(ctx context.Context, c *castClient, transportID string) (*mediaStatus, error) {
+	resp, err := c.request(ctx, transportID, nsMedia, map[string]any{"type": "GET_STATUS"})
+	if err != nil {
+		return nil, err
+	}
+	var parsed struct {
+		Status []mediaStatus `json:"status"`
+	}
+	if err := json.Unmarshal(resp, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse media status: %w", err)
+	}
+	if len(parsed.Status) == 0 {
+		return nil, ErrNoMediaSession
+	}
+	return &parsed.Status[0], nil
+}
+
+// mediaCommand sends a transport command (PLAY, PAUSE, STOP) to the current
+// media session
+func (s *Chromecast) mediaCommand(ctx context.Context, uuid, command string) error {
+	c, err := s.connect(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	transportID, err := s.ensureMediaApp(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	status, err := s.getMediaStatus(ctx, c, transportID)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.request(ctx, transportID, nsMedia,
+		map[string]any{"type": command, "mediaSessionId": status.MediaSessionID})
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", command, err)
+	}
+	return nil
+}
+
+// Play resumes playback on a device
+func (s *Chromecast) Play(ctx context.Context, uuid string) error {
+	return s.mediaCommand(ctx, uuid, "PLAY")
+}
+
+// Pause pauses playback on a device
+func (s *Chromecast) Pause(ctx context.Context, uuid string) error {
+	return s.mediaCommand(ctx, uuid, "PAUSE")
+}
+
+// Stop stops playback on a device
+func (s *Chromecast) Stop(ctx context.Context, uuid string) error {
+	return s.mediaCommand(ctx, uuid, "STOP")
+}
+
+// SetVolume sets the device volume (0-100)
+func (s *Chromecast) SetVolume(ctx context.Context, uuid string, volume int) error {
+	if volume < 0 || volume > 100 {
+		return ErrInvalidVolume
+	}
+
+	c, err := s.connect(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	_, err = c.request(ctx, receiverID, nsReceiver, map[string]any{
+		"type":   "SET_VOLUME",
+		"volume": map[string]any{"level": float64(volume) / 100},
+	})
+	if err != nil {
+		return fmt.Errorf("SetVolume failed: %w", err)
+	}
+	log.Debug(ctx, "Set Cast volume", "device", c.device.Name, "volume", volume)
+	return nil
+}
+
+// SetMute sets the device mute state
+func (s *Chromecast) SetMute(ctx context.Context, uuid string, mute bool) error {
+	c, err := s.connect(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	_, err = c.request(ctx, receiverID, nsReceiver, map[string]any{
+		"type":   "SET_VOLUME",
+		"volume": map[string]any{"muted": mute},
+	})
+	if err != nil {
+		return fmt.Errorf("SetMute failed: %w", err)
+	}
+	log.Debug(ctx, "Set Cast mute", "device", c.device.Name, "muted", mute)
+	return nil
+}
+
+// GetPlaybackState returns the current receiver and media status of a device
+func (s *Chromecast) GetPlaybackState(ctx context.Context, uuid string) (*PlaybackState, error) {
+	c, err := s.connect(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	status, err := s.getReceiverStatus(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &PlaybackState{
+		State:  "IDLE",
+		Volume: int(math.Round(status.Volume.Level * 100)),
+		Muted:  status.Volume.Muted,
+	}
+
+	// Media status is only available while the media receiver is running
+	transportID := ""
+	for _, app := range status.Applications {
+		if app.AppID == defaultMediaReceiverAppID {
+			transportID = app.TransportID
+			state.AppName = app.DisplayName
+			break
+		}
+	}
+	if transportID == "" {
+		return state, nil
+	}
+
+	if err := c.send(transportID, nsConnection, map[string]any{"type": "CONNECT"}); err != nil {
+		return state, nil
+	}
+	media, err := s.getMediaStatus(ctx, c, transportID)
+	if err != nil {
+		// No session loaded is a normal idle state, not an error
+		return state, nil
+	}
+
+	state.State = media.PlayerState
+	state.Position = media.CurrentTime
+	if media.Media.ContentID != "" {
+		track := &Track{
+			URI:      media.Media.ContentID,
+			Title:    media.Media.Metadata.Title,
+			Artist:   media.Media.Metadata.Artist,
+			Album:    media.Media.Metadata.AlbumName,
+			Duration: media.Media.Duration,
+		}
+		if len(media.Media.Metadata.Images) > 0 {
+			track.AlbumArt = media.Media.Metadata.Images[0].URL
+		}
+		state.CurrentTrack = track
+	}
+
+	return state, nil
+}
+
+// GetStreamBaseURL returns the base URL Cast devices stream from.
+// This needs to be an absolute URL reachable from the LAN
+func (s *Chromecast) GetStreamBaseURL() string {
+	// Use configured BaseURL if set (should be LAN-accessible HTTP URL)
+	if conf.Server.BaseURL != "" {
+		return conf.Server.BaseURL
+	}
+
+	port := conf.Server.Port
+	if port == 0 {
+		port = 4533
+	}
+
+	address := conf.Server.Address
+	if address == "" || address == "0.0.0.0" {
+		log.Warn("Chromecast: BaseURL not configured and Address is 0.0.0.0. Set ND_BASEURL to your LAN-accessible URL (e.g., http://192.168.1.x:4533)")
+		address = "127.0.0.1"
+	}
+
+	return fmt.Sprintf("http://%s:%d", address, port)
+}
@@ -0,0 +1,108 @@
+package chromecast
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/utils/mdns"
+)
+
+const (
+	googleCastService  = "_googlecast._tcp.local."
+	defaultMDNSTimeout = 3 * time.Second
+	defaultCastPort    = 8009
+)
+
+// Discovery handles Google Cast device discovery via mDNS
+type Discovery struct {
+	cache *DeviceCache
+}
+
+// NewDiscovery creates a new Chromecast discovery service
+func NewDiscovery() *Discovery {
+	return &Discovery{
+		cache: NewDeviceCache(),
+	}
+}
+
+// Scan performs an mDNS query for Google Cast devices and collects responses
+// until the timeout expires
+func (d *Discovery) Scan(ctx context.Context) ([]*ChromecastDevice, error) {
+	log.Debug(ctx, "Starting Chromecast mDNS discovery scan")
+
+	entries, err := mdns.Query(ctx, googleCastService, localUDPAddr(ctx), mdnsTimeout())
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []*ChromecastDevice
+	for _, entry := range entries {
+		// The TXT record carries the device identity: "id" is the stable
+		// UUID, "fn" the friendly name and "md" the model
+		if entry.TXT["id"] == "" {
+			continue
+		}
+		device := &ChromecastDevice{
+			IP:       entry.IP,
+			Port:     entry.Port,
+			UUID:     entry.TXT["id"],
+			Name:     entry.TXT["fn"],
+			Model:    entry.TXT["md"],
+			LastSeen: time.Now(),
+		}
+		if device.Port == 0 {
+			device.Port = defaultCastPort
+		}
+		devices = append(devices, device)
+		d.cache.Set(device)
+		log.Debug(ctx, "Found Cast device", "name", device.Name, "model", device.Model,
+			"ip", device.IP, "port", device.Port)
+	}
+
+	log.Info(ctx, "Chromecast discovery complete", "devicesFound", len(devices))
+	return devices, nil
+}
+
+// GetDevices returns all cached devices
+func (d *Discovery) GetDevices() []*ChromecastDevice {
+	return d.cache.GetAll()
+}
+
+// GetDevice returns a specific device by UUID
+func (d *Discovery) GetDevice(uuid string) (*ChromecastDevice, bool) {
+	return d.cache.Get(uuid)
+}
+
+// localUDPAddr returns the local address to bind the mDNS socket to.
+// Chromecast.Interface may be an IP address or an interface name; when empty
+// (or unresolvable) the socket binds to all interfaces
+func localUDPAddr(ctx context.Context) *net.UDPAddr {
+	name := conf.Server.Chromecast.Interface
+	if name == "" {
+		return &net.UDPAddr{IP: net.IPv4zero, Port: 0}
+	}
+	if ip := net.ParseIP(name); ip != nil {
+		return &net.UDPAddr{IP: ip, Port: 0}
+	}
+	if iface, err := net.InterfaceByName(name); err == nil {
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+				return &net.UDPAddr{IP: ipNet.IP, Port: 0}
+			}
+		}
+	}
+	log.Warn(ctx, "Could not resolve Chromecast.Interface - binding to all interfaces", "interface", name)
+	return &net.UDPAddr{IP: net.IPv4zero, Port: 0}
+}
+
+// mdnsTimeout returns how long to wait for mDNS responses
+func mdnsTimeout() time.Duration {
+	if t := conf.Server.Chromecast.MDNSTimeout; t > 0 {
+		return t
+	}
+	return defaultMDNSTimeout
+}
@@ -0,0 +1,22 @@
+package chromecast
+
+import "errors"
+
+var (
+	// ErrDeviceNotFound is returned when a device UUID is not in the cache
+	ErrDeviceNotFound = errors.New("chromecast device not found")
+
+	// ErrNoDevices is returned when no Cast devices are available
+	ErrNoDevices = errors.New("no chromecast devices discovered")
+
+	// ErrInvalidVolume is returned when volume is out of range
+	ErrInvalidVolume = errors.New("volume must be between 0 and 100")
+
+	// ErrNoMediaSession is returned when a transport command is sent while
+	// nothing is loaded on the device
+	ErrNoMediaSession = errors.New("no active media session on device")
+
+	// ErrNotAuthorized is returned when the user's libraries don't include
+	// the requested content
+	ErrNotAuthorized = errors.New("user does not have access to this content")
+)
@@ -0,0 +1,126 @@
+package chromecast
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/navidrome/navidrome/core/auth"
+	"github.com/navidrome/navidrome/log"
+)
+
+// streamTokenTTL is how long a signed stream URL stays valid. Queued tracks
+// can start playing long after they were enqueued, so this needs to cover a
+// full listening session
+const streamTokenTTL = 8 * time.Hour
+
+// castStreamInfo is the payload of a signed stream token
+type castStreamInfo struct {
+	id     string
+	format string
+}
+
+// encodeStreamToken creates a short-lived signed token granting access to a
+// single track, optionally transcoded to the given format
+func encodeStreamToken(trackID, format string) string {
+	claims := map[string]any{"id": trackID}
+	if format != "" {
+		claims["f"] = format
+	}
+	token, _ := auth.CreateExpiringPublicToken(time.Now().Add(streamTokenTTL), claims)
+	return token
+}
+
+// decodeStreamToken validates a signed stream token (including expiry) and
+// extracts the track info from it
+func decodeStreamToken(tokenString string) (castStreamInfo, error) {
+	// Stream URLs can arrive before auth.Init has run - fail instead of
+	// panicking, like the Sonos token decoders do
+	if auth.TokenAuth == nil {
+		return castStreamInfo{}, errors.New("token auth not initialized")
+	}
+	token, err := auth.TokenAuth.Decode(tokenString)
+	if err != nil {
+		return castStreamInfo{}, err
+	}
+	if token == nil {
+		return castStreamInfo{}, errors.New("unauthorized")
+	}
+	err = jwt.Validate(token, jwt.WithRequiredClaim("id"))
+	if err != nil {
+		return castStreamInfo{}, err
+	}
+	claims, err := token.AsMap(context.Background())
+	if err != nil {
+		return castStreamInfo{}, err
+	}
+	id, ok := claims["id"].(string)
+	if !ok {
+		return castStreamInfo{}, errors.New("invalid id type")
+	}
+	info := castStreamInfo{id: id}
+	info.format, _ = claims["f"].(string)
+	return info, nil
+}
+
+// handleStream serves the audio for a signed stream token. It is the only
+// unauthenticated endpoint in the Chromecast router - the token itself is
+// the authorization
+func (a *API) handleStream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	info, err := decodeStreamToken(chi.URLParam(r, "token"))
+	if err != nil {
+		log.Warn(ctx, "Rejected Cast stream request with invalid token", err)
+		http.Error(w, "invalid or expired stream token", http.StatusForbidden)
+		return
+	}
+
+	stream, err := a.streamer.NewStream(ctx, info.id, info.format, 0, 0)
+	if err != nil {
+		log.Error(ctx, "Error starting Cast stream", "id", info.id, err)
+		http.Error(w, "invalid request", http.StatusInternalServerError)
+		return
+	}
+
+	// Make sure the stream will be closed at the end, to avoid leakage
+	defer func() {
+		if err := stream.Close(); err != nil && log.IsGreaterOrEqualTo(log.LevelDebug) {
+			log.Error("Error closing Cast stream", "id", info.id, "file", stream.Name(), err)
+		}
+	}()
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Content-Duration", strconv.FormatFloat(float64(stream.Duration()), 'G', -1, 32))
+
+	if stream.Seekable() {
+		// Raw files are seekable - ServeContent provides the Content-Length
+		// and Range support the default media receiver needs for scrubbing
+		http.ServeContent(w, r, stream.Name(), stream.ModTime(), stream)
+		return
+	}
+
+	// Transcoded streams have no known size, so ranges can't be supported.
+	// Send an estimated length so the receiver can still show a progress bar
+	w.Header().Set("Accept-Ranges", "none")
+	w.Header().Set("Content-Type", stream.ContentType())
+	w.Header().Set("Content-Length", strconv.Itoa(stream.EstimatedContentLength()))
+
+	if r.Method == http.MethodHead {
+		go func() { _, _ = io.Copy(io.Discard, stream) }()
+		return
+	}
+
+	c, err := io.Copy(w, stream)
+	if log.IsGreaterOrEqualTo(log.LevelDebug) {
+		if err != nil {
+			log.Error(ctx, "Error sending Cast transcoded stream", "id", info.id, err)
+		} else {
+			log.Trace(ctx, "Success sending Cast transcoded stream", "id", info.id, "size", c)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package chromecast
+
+import (
+	"sync"
+	"time"
+)
+
+// ChromecastDevice represents a discovered Google Cast device
+type ChromecastDevice struct {
+	IP       string    `json:"ip"`
+	Port     int       `json:"port"`
+	UUID     string    `json:"uuid"` // "id" field from the mDNS TXT record
+	Name     string    `json:"name"` // friendly name ("fn" TXT field)
+	Model    string    `json:"model"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// PlaybackState represents the current playback state of a Cast device
+type PlaybackState struct {
+	State        string  `json:"state"` // PLAYING, PAUSED, BUFFERING, IDLE
+	AppName      string  `json:"appName,omitempty"`
+	CurrentTrack *Track  `json:"currentTrack,omitempty"`
+	Volume       int     `json:"volume"` // 0-100
+	Muted        bool    `json:"muted"`
+	Position     float64 `json:"position,omitempty"` // seconds
+}
+
+// Track represents the currently playing media item
+type Track struct {
+	URI      string  `json:"uri"`
+	Title    string  `json:"title"`
+	Artist   string  `json:"artist"`
+	Album    string  `json:"album"`
+	AlbumArt string  `json:"albumArt"`
+	Duration float64 `json:"duration"` // seconds
+}
+
+// MediaItem holds everything needed to load one track on a Cast device
+type MediaItem struct {
+	URL         string
+	ContentType string
+	Title       string
+	Artist      string
+	Album       string
+	ImageURL    string
+	Duration    float32
+}
+
+// VolumeRequest is the request body for volume control
+type VolumeRequest struct {
+	Volume int `json:"volume"` // 0-100
+}
+
+// DeviceCache holds discovered devices with thread-safe access
+type DeviceCache struct {
+	mu      sync.RWMutex
+	devices map[string]*ChromecastDevice // keyed by UUID
+}
+
+func NewDeviceCache() *DeviceCache {
+	return &DeviceCache{
+		devices: make(map[string]*ChromecastDevice),
+	}
+}
+
+func (c *DeviceCache) Set(device *ChromecastDevice) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.devices[device.UUID] = device
+}
+
+func (c *DeviceCache) Get(uuid string) (*ChromecastDevice, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	d, ok := c.devices[uuid]
+	return d, ok
+}
+
+func (c *DeviceCache) GetAll() []*ChromecastDevice {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	result := make([]*ChromecastDevice, 0, len(c.devices))
+	for _, d := range c.devices {
+		result = append(result, d)
+	}
+	return result
+}
+
+func (c *DeviceCache) Remove(uuid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.devices, uuid)
+}
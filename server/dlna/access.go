@@ -0,0 +1,67 @@
+package dlna
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+)
+
+// ipAllowed decides whether a client IP may use the DLNA server, based on the
+// DLNA.AllowedIPs and DLNA.DeniedIPs options (comma-separated CIDR ranges).
+// The denylist wins over the allowlist; an empty allowlist admits everyone
+// not explicitly denied
+func ipAllowed(remoteAddr string) bool {
+	ip := remoteAddr
+	if net.ParseIP(ip) == nil {
+		ip, _, _ = net.SplitHostPort(remoteAddr)
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	if ipInRanges(parsed, conf.Server.DLNA.DeniedIPs) {
+		return false
+	}
+	if conf.Server.DLNA.AllowedIPs != "" {
+		return ipInRanges(parsed, conf.Server.DLNA.AllowedIPs)
+	}
+	return true
+}
+
+// ipInRanges reports whether ip falls in any of the comma-separated CIDR
+// ranges. Bare IPs are accepted as single-address ranges; invalid entries
+// are skipped
+func ipInRanges(ip net.IP, ranges string) bool {
+	for _, cidr := range strings.Split(ranges, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			if single := net.ParseIP(cidr); single != nil && single.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if _, ipnet, err := net.ParseCIDR(cidr); err == nil && ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessControl is an HTTP middleware rejecting requests from IPs outside the
+// configured ranges
+func (r *Router) accessControl(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !ipAllowed(req.RemoteAddr) {
+			log.Debug(req.Context(), "Rejected DLNA request from restricted IP", "remoteAddr", req.RemoteAddr)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
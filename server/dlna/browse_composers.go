@@ -0,0 +1,95 @@
+package dlna
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+)
+
+// browseComposers returns the artists with a composer role, for the
+// classical-friendly "Composers" view
+func (r *Router) browseComposers(ctx context.Context, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	// The composer role stats live in the library_artist join, same as the
+	// artist repository's own role filter
+	opts := model.QueryOptions{
+		Sort:    "name",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: squirrel.Expr("JSON_EXTRACT(library_artist.stats, '$.composer.m') IS NOT NULL"),
+	}
+
+	artists, err := r.ds.Artist(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get composers", err)
+		return didl, 0
+	}
+	total, err := r.ds.Artist(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count composers", err)
+		total = int64(len(artists))
+	}
+
+	for _, artist := range artists {
+		didl.Containers = append(didl.Containers, Container{
+			ID:         "composer/" + artist.ID,
+			ParentID:   "music/composers",
+			Restricted: "1",
+			Title:      artist.Name,
+			Class:      classMusicArtist,
+		})
+	}
+
+	return didl, int(total)
+}
+
+// browseComposerAlbums returns the albums a composer participated in
+func (r *Router) browseComposerAlbums(ctx context.Context, artistID string, startIndex, count int, sortCriteria string) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	// Composers are participants, not album artists - match against the
+	// composer branch of the participants JSON document
+	opts := model.QueryOptions{
+		Sort:    "name",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: persistence.Exists(`json_tree(participants, '$.composer')`, squirrel.Eq{"value": artistID}),
+	}
+	opts = applySortCriteria(opts, sortCriteria, albumSortColumns)
+
+	albums, err := r.ds.Album(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get composer albums", "artistID", artistID, err)
+		return didl, 0
+	}
+	total, err := r.ds.Album(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count composer albums", "artistID", artistID, err)
+		total = int64(len(albums))
+	}
+
+	for _, album := range albums {
+		didl.Containers = append(didl.Containers, Container{
+			ID:          "album/" + album.ID,
+			ParentID:    "composer/" + artistID,
+			Restricted:  "1",
+			Title:       album.Name,
+			Class:       classMusicAlbum,
+			AlbumArtURI: r.getAlbumArtURL(album.ID),
+		})
+	}
+
+	return didl, int(total)
+}
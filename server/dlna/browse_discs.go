@@ -0,0 +1,114 @@
+package dlna
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// browseAlbumDiscs presents a multi-disc album as one sub-container per disc,
+// so box sets stay navigable on renderers. Returns ok=false when disc
+// grouping is disabled or the album has a single disc, in which case the
+// album is browsed flat as usual
+func (r *Router) browseAlbumDiscs(ctx context.Context, albumID string, startIndex, count int) (DIDLLite, int, bool) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	if !conf.Server.DLNA.DiscGrouping {
+		return didl, 0, false
+	}
+
+	album, err := r.ds.Album(ctx).Get(albumID)
+	if err != nil {
+		log.Error(ctx, "Failed to get album", "albumID", albumID, err)
+		return didl, 0, false
+	}
+	if len(album.Discs) < 2 {
+		return didl, 0, false
+	}
+
+	discNumbers := make([]int, 0, len(album.Discs))
+	for number := range album.Discs {
+		discNumbers = append(discNumbers, number)
+	}
+	sort.Ints(discNumbers)
+
+	total := len(discNumbers)
+	end := startIndex + count
+	if end > total {
+		end = total
+	}
+
+	if startIndex < total {
+		for _, number := range discNumbers[startIndex:end] {
+			title := album.Discs[number]
+			if title == "" {
+				title = fmt.Sprintf("Disc %d", number)
+			}
+			didl.Containers = append(didl.Containers, Container{
+				ID:          fmt.Sprintf("disc/%s/%d", albumID, number),
+				ParentID:    "album/" + albumID,
+				Restricted:  "1",
+				Title:       title,
+				Class:       classStorageFolder,
+				AlbumArtURI: r.getAlbumArtURL(albumID),
+			})
+		}
+	}
+
+	return didl, total, true
+}
+
+// browseDiscTracks returns the tracks of one disc of an album
+func (r *Router) browseDiscTracks(ctx context.Context, discID string, startIndex, count int, sortCriteria string) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	albumID, number, ok := strings.Cut(discID, "/")
+	if !ok {
+		return didl, 0
+	}
+	discNumber, err := strconv.Atoi(number)
+	if err != nil {
+		return didl, 0
+	}
+
+	opts := model.QueryOptions{
+		Sort:    "track_number",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: squirrel.And{squirrel.Eq{"album_id": albumID}, squirrel.Eq{"disc_number": discNumber}},
+	}
+	opts = applySortCriteria(opts, sortCriteria, trackSortColumns)
+
+	tracks, err := r.ds.MediaFile(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get disc tracks", "albumID", albumID, "disc", discNumber, err)
+		return didl, 0
+	}
+
+	total, err := r.ds.MediaFile(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count disc tracks", "albumID", albumID, "disc", discNumber, err)
+		total = int64(len(tracks))
+	}
+
+	for _, track := range tracks {
+		didl.Items = append(didl.Items, r.mediaFileToItem(ctx, &track, "disc/"+discID))
+	}
+
+	return didl, int(total)
+}
@@ -0,0 +1,167 @@
+package dlna
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+)
+
+// annotationContext returns a context carrying the user DLNA browsing runs
+// as: the DLNA.User option, or the first admin when unset. Repositories join
+// the annotation table on the context user, so without one the dynamic views
+// would come up empty. It is applied to every ContentDirectory request
+func (r *Router) annotationContext(ctx context.Context) context.Context {
+	var user *model.User
+	var err error
+	if username := conf.Server.DLNA.User; username != "" {
+		user, err = r.ds.User(ctx).FindByUsername(username)
+	} else {
+		user, err = r.ds.User(ctx).FindFirstAdmin()
+	}
+	if err != nil {
+		log.Warn(ctx, "Failed to resolve user for DLNA dynamic containers", "user", conf.Server.DLNA.User, err)
+		return ctx
+	}
+	return request.WithUser(ctx, *user)
+}
+
+// browseRecentlyAdded returns the most recently added albums
+func (r *Router) browseRecentlyAdded(ctx context.Context, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	opts := model.QueryOptions{
+		Sort:   "recently_added",
+		Order:  "desc",
+		Offset: startIndex,
+		Max:    count,
+	}
+
+	albums, err := r.ds.Album(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get recently added albums", err)
+		return didl, 0
+	}
+	total, err := r.ds.Album(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count recently added albums", err)
+		total = int64(len(albums))
+	}
+
+	for _, album := range albums {
+		didl.Containers = append(didl.Containers, Container{
+			ID:          "album/" + album.ID,
+			ParentID:    "music/recentlyAdded",
+			Restricted:  "1",
+			Title:       album.Name,
+			Class:       classMusicAlbum,
+			AlbumArtURI: r.getAlbumArtURL(album.ID),
+		})
+	}
+
+	return didl, int(total)
+}
+
+// browseRecentlyPlayed returns the most recently played tracks
+func (r *Router) browseRecentlyPlayed(ctx context.Context, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	opts := model.QueryOptions{
+		Sort:    "play_date",
+		Order:   "desc",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: squirrel.Gt{"play_count": 0},
+	}
+
+	tracks, err := r.ds.MediaFile(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get recently played tracks", err)
+		return didl, 0
+	}
+	total, err := r.ds.MediaFile(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count recently played tracks", err)
+		total = int64(len(tracks))
+	}
+
+	for _, track := range tracks {
+		didl.Items = append(didl.Items, r.mediaFileToItem(ctx, &track, "music/recentlyPlayed"))
+	}
+
+	return didl, int(total)
+}
+
+// browseStarred returns the starred albums followed by the starred tracks,
+// paginated across both
+func (r *Router) browseStarred(ctx context.Context, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	filters := squirrel.Eq{"starred": true}
+
+	albumTotal, err := r.ds.Album(ctx).CountAll(model.QueryOptions{Filters: filters})
+	if err != nil {
+		log.Error(ctx, "Failed to count starred albums", err)
+		return didl, 0
+	}
+	trackTotal, err := r.ds.MediaFile(ctx).CountAll(model.QueryOptions{Filters: filters})
+	if err != nil {
+		log.Error(ctx, "Failed to count starred tracks", err)
+		return didl, 0
+	}
+	total := int(albumTotal) + int(trackTotal)
+
+	remaining := count
+
+	if startIndex < int(albumTotal) && remaining > 0 {
+		albums, err := r.ds.Album(ctx).GetAll(model.QueryOptions{
+			Sort: "starred_at", Order: "desc", Offset: startIndex, Max: remaining, Filters: filters,
+		})
+		if err != nil {
+			log.Error(ctx, "Failed to get starred albums", err)
+			return didl, 0
+		}
+		for _, album := range albums {
+			didl.Containers = append(didl.Containers, Container{
+				ID:          "album/" + album.ID,
+				ParentID:    "music/starred",
+				Restricted:  "1",
+				Title:       album.Name,
+				Class:       classMusicAlbum,
+				AlbumArtURI: r.getAlbumArtURL(album.ID),
+			})
+		}
+		remaining -= len(albums)
+	}
+
+	trackStart := max(startIndex-int(albumTotal), 0)
+	if trackStart < int(trackTotal) && remaining > 0 {
+		tracks, err := r.ds.MediaFile(ctx).GetAll(model.QueryOptions{
+			Sort: "starred_at", Order: "desc", Offset: trackStart, Max: remaining, Filters: filters,
+		})
+		if err != nil {
+			log.Error(ctx, "Failed to get starred tracks", err)
+			return didl, 0
+		}
+		for _, track := range tracks {
+			didl.Items = append(didl.Items, r.mediaFileToItem(ctx, &track, "music/starred"))
+		}
+	}
+
+	return didl, total
+}
@@ -0,0 +1,234 @@
+package dlna
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// classPhoto is the UPnP class for folder images
+const classPhoto = "object.item.imageItem.photo"
+
+// browseFolders returns the root folders of all libraries - the top level of
+// the "Folders" view. Root folders carry the library name, since their own
+// name is just "."
+func (r *Router) browseFolders(ctx context.Context, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	opts := model.QueryOptions{
+		Sort:    "name",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: squirrel.And{squirrel.Eq{"parent_id": ""}, squirrel.Eq{"missing": false}},
+	}
+
+	folders, err := r.ds.Folder(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get root folders", err)
+		return didl, 0
+	}
+
+	total, err := r.ds.Folder(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count root folders", err)
+		total = int64(len(folders))
+	}
+
+	libNames := r.libraryNames(ctx)
+	for _, folder := range folders {
+		name := libNames[folder.LibraryID]
+		if name == "" {
+			name = folder.Name
+		}
+		didl.Containers = append(didl.Containers, Container{
+			ID:         "folder/" + folder.ID,
+			ParentID:   "music/folders",
+			Restricted: "1",
+			Title:      name,
+			Class:      classStorageFolder,
+		})
+	}
+
+	return didl, int(total)
+}
+
+// libraryNames maps library IDs to their names
+func (r *Router) libraryNames(ctx context.Context) map[int]string {
+	libs, err := r.ds.Library(ctx).GetAll()
+	if err != nil {
+		log.Warn(ctx, "Failed to get libraries", err)
+		return nil
+	}
+	names := make(map[int]string, len(libs))
+	for _, lib := range libs {
+		names[lib.ID] = lib.Name
+	}
+	return names
+}
+
+// browseFolderChildren returns the contents of one folder, mirroring the
+// on-disk hierarchy: subfolders first, then the folder's tracks, then its
+// images, paginated across all three
+func (r *Router) browseFolderChildren(ctx context.Context, folderID string, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	folder, err := r.ds.Folder(ctx).Get(folderID)
+	if err != nil {
+		log.Error(ctx, "Failed to get folder", "folderID", folderID, err)
+		return didl, 0
+	}
+
+	parentID := "folder/" + folderID
+	subFilters := squirrel.And{squirrel.Eq{"parent_id": folderID}, squirrel.Eq{"missing": false}}
+	trackFilters := squirrel.Eq{"folder_id": folderID}
+
+	folderTotal, err := r.ds.Folder(ctx).CountAll(model.QueryOptions{Filters: subFilters})
+	if err != nil {
+		log.Error(ctx, "Failed to count subfolders", "folderID", folderID, err)
+		return didl, 0
+	}
+	trackTotal, err := r.ds.MediaFile(ctx).CountAll(model.QueryOptions{Filters: trackFilters})
+	if err != nil {
+		log.Error(ctx, "Failed to count folder tracks", "folderID", folderID, err)
+		return didl, 0
+	}
+	images := slices.Clone(folder.ImageFiles)
+	slices.Sort(images)
+	total := int(folderTotal) + int(trackTotal) + len(images)
+
+	remaining := count
+
+	// Subfolders
+	if startIndex < int(folderTotal) && remaining > 0 {
+		subfolders, err := r.ds.Folder(ctx).GetAll(model.QueryOptions{
+			Sort: "name", Offset: startIndex, Max: remaining, Filters: subFilters,
+		})
+		if err != nil {
+			log.Error(ctx, "Failed to get subfolders", "folderID", folderID, err)
+			return didl, 0
+		}
+		for _, sub := range subfolders {
+			didl.Containers = append(didl.Containers, Container{
+				ID:         "folder/" + sub.ID,
+				ParentID:   parentID,
+				Restricted: "1",
+				Title:      sub.Name,
+				Class:      classStorageFolder,
+			})
+		}
+		remaining -= len(subfolders)
+	}
+
+	// Tracks
+	trackStart := max(startIndex-int(folderTotal), 0)
+	if trackStart < int(trackTotal) && remaining > 0 {
+		tracks, err := r.ds.MediaFile(ctx).GetAll(model.QueryOptions{
+			Sort: "path", Offset: trackStart, Max: remaining, Filters: trackFilters,
+		})
+		if err != nil {
+			log.Error(ctx, "Failed to get folder tracks", "folderID", folderID, err)
+			return didl, 0
+		}
+		for _, track := range tracks {
+			didl.Items = append(didl.Items, r.mediaFileToItem(ctx, &track, parentID))
+		}
+		remaining -= len(tracks)
+	}
+
+	// Images
+	imageStart := max(startIndex-int(folderTotal)-int(trackTotal), 0)
+	for _, name := range images[min(imageStart, len(images)):] {
+		if remaining <= 0 {
+			break
+		}
+		didl.Items = append(didl.Items, r.folderImageToItem(folderID, parentID, name))
+		remaining--
+	}
+
+	return didl, total
+}
+
+// folderImageToItem builds a DIDL item for one image file in a folder
+func (r *Router) folderImageToItem(folderID, parentID, name string) Item {
+	url := r.getFolderImageURL(folderID, name)
+	return Item{
+		ID:         "folderimage/" + folderID + "/" + name,
+		ParentID:   parentID,
+		Restricted: "1",
+		Title:      name,
+		Class:      classPhoto,
+		Resources: []Res{{
+			ProtocolInfo: "http-get:*:" + imageMimeType(name) + ":*",
+			URL:          url,
+		}},
+	}
+}
+
+// getFolderImageURL returns the URL serving one image file from a folder
+func (r *Router) getFolderImageURL(folderID, name string) string {
+	baseURL := conf.Server.BaseURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://%s:%d", getLocalIP(), r.httpPort)
+	}
+	return fmt.Sprintf("%s/dlna/folderimage/%s/%s", baseURL, folderID, name)
+}
+
+// imageMimeType returns the MIME type of an image file, by extension
+func imageMimeType(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".png":
+		return "image/png"
+	case ".gif":
+		return "image/gif"
+	case ".webp":
+		return "image/webp"
+	default:
+		return "image/jpeg"
+	}
+}
+
+// handleFolderImage serves an image file from a library folder. Only files
+// the scanner recorded for the folder are served - nothing else on disk is
+// reachable through this endpoint
+func (r *Router) handleFolderImage(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	folderID := chi.URLParam(req, "folderID")
+	name := chi.URLParam(req, "name")
+
+	folder, err := r.ds.Folder(ctx).Get(folderID)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	if !slices.Contains(folder.ImageFiles, name) {
+		http.NotFound(w, req)
+		return
+	}
+
+	lib, err := r.ds.Library(ctx).Get(folder.LibraryID)
+	if err != nil {
+		log.Error(ctx, "Failed to get library for folder image", "folderID", folderID, err)
+		http.NotFound(w, req)
+		return
+	}
+
+	w.Header().Set("Content-Type", imageMimeType(name))
+	http.ServeFile(w, req, filepath.Join(lib.Path, folder.Path, folder.Name, name))
+}
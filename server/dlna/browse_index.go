@@ -0,0 +1,140 @@
+package dlna
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// indexBuckets are the alphabetical sub-containers presented under Artists
+// and Albums when DLNA.AlphabetIndex is enabled. "#" collects everything
+// that doesn't start with a letter
+var indexBuckets = func() []string {
+	buckets := []string{"#"}
+	for c := 'A'; c <= 'Z'; c++ {
+		buckets = append(buckets, string(c))
+	}
+	return buckets
+}()
+
+// letterFilter matches rows whose sort column starts with the given letter.
+// The order_* columns are pre-normalized by the scanner, so a prefix match is
+// enough
+func letterFilter(column, letter string) squirrel.Sqlizer {
+	if letter == "#" {
+		return squirrel.Expr(column + " NOT GLOB '[A-Za-z]*'")
+	}
+	return squirrel.Like{column: letter + "%"}
+}
+
+// browseIndex lists the letter containers themselves
+func (r *Router) browseIndex(idPrefix, parentID string, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	for _, letter := range indexBuckets {
+		didl.Containers = append(didl.Containers, Container{
+			ID:         idPrefix + letter,
+			ParentID:   parentID,
+			Restricted: "1",
+			Title:      letter,
+			Class:      classStorageFolder,
+		})
+	}
+
+	total := len(didl.Containers)
+	end := startIndex + count
+	if end > total {
+		end = total
+	}
+	if startIndex < total {
+		didl.Containers = didl.Containers[startIndex:end]
+	} else {
+		didl.Containers = nil
+	}
+	return didl, total
+}
+
+// browseIndexedArtists lists the artists of one letter bucket
+func (r *Router) browseIndexedArtists(ctx context.Context, letter string, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	opts := model.QueryOptions{
+		Sort:    "name",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: letterFilter("order_artist_name", letter),
+	}
+
+	artists, err := r.ds.Artist(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get artists for letter", "letter", letter, err)
+		return didl, 0
+	}
+	total, err := r.ds.Artist(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count artists for letter", "letter", letter, err)
+		total = int64(len(artists))
+	}
+
+	for _, artist := range artists {
+		didl.Containers = append(didl.Containers, Container{
+			ID:         "artist/" + artist.ID,
+			ParentID:   "artistindex/" + letter,
+			Restricted: "1",
+			Title:      artist.Name,
+			Class:      classMusicArtist,
+		})
+	}
+
+	return didl, int(total)
+}
+
+// browseIndexedAlbums lists the albums of one letter bucket
+func (r *Router) browseIndexedAlbums(ctx context.Context, letter string, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	opts := model.QueryOptions{
+		Sort:    "name",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: letterFilter("order_album_name", letter),
+	}
+
+	albums, err := r.ds.Album(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get albums for letter", "letter", letter, err)
+		return didl, 0
+	}
+	total, err := r.ds.Album(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count albums for letter", "letter", letter, err)
+		total = int64(len(albums))
+	}
+
+	for _, album := range albums {
+		didl.Containers = append(didl.Containers, Container{
+			ID:          "album/" + album.ID,
+			ParentID:    "albumindex/" + letter,
+			Restricted:  "1",
+			Title:       album.Name,
+			Class:       classMusicAlbum,
+			AlbumArtURI: r.getAlbumArtURL(album.ID),
+		})
+	}
+
+	return didl, int(total)
+}
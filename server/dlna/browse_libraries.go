@@ -0,0 +1,262 @@
+package dlna
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// libraryVisible reports whether a library may be exposed over DLNA, per the
+// DLNA.Libraries option (comma-separated library IDs or names). An empty
+// option exposes all libraries
+func libraryVisible(lib model.Library) bool {
+	if conf.Server.DLNA.Libraries == "" {
+		return true
+	}
+	for _, entry := range strings.Split(conf.Server.DLNA.Libraries, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == strconv.Itoa(lib.ID) || strings.EqualFold(entry, lib.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// libraryContainers returns one top-level container per library, so users
+// with separate Music/Audiobooks libraries see them distinctly. Returns nil
+// when there is a single library - the combined "music" tree covers it
+func (r *Router) libraryContainers(ctx context.Context) []Container {
+	libs, err := r.ds.Library(ctx).GetAll()
+	if err != nil {
+		log.Warn(ctx, "Failed to get libraries", err)
+		return nil
+	}
+	if len(libs) < 2 {
+		return nil
+	}
+
+	containers := make([]Container, 0, len(libs))
+	for _, lib := range libs {
+		if !libraryVisible(lib) {
+			continue
+		}
+		containers = append(containers, Container{
+			ID:         fmt.Sprintf("library/%d", lib.ID),
+			ParentID:   "0",
+			Restricted: "1",
+			Title:      lib.Name,
+			Class:      classStorageFolder,
+			ChildCount: 4,
+		})
+	}
+	return containers
+}
+
+// browseLibrary dispatches browses inside one library container. The object
+// ID is "library/<id>" for the category list, or "library/<id>/<section>"
+// for a category, with every child browse scoped by library_id
+func (r *Router) browseLibrary(ctx context.Context, objectID string, startIndex, count int, sortCriteria string) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	libPart, section, _ := strings.Cut(strings.TrimPrefix(objectID, "library/"), "/")
+	libID, err := strconv.Atoi(libPart)
+	if err != nil {
+		return didl, 0
+	}
+
+	// Guessing object IDs must not get around the DLNA.Libraries restriction
+	lib, err := r.ds.Library(ctx).Get(libID)
+	if err != nil || !libraryVisible(*lib) {
+		return didl, 0
+	}
+	parentID := fmt.Sprintf("library/%d", libID)
+
+	switch section {
+	case "":
+		containers := []Container{
+			{ID: parentID + "/artists", ParentID: parentID, Restricted: "1", Title: "Artists", Class: classStorageFolder},
+			{ID: parentID + "/albums", ParentID: parentID, Restricted: "1", Title: "Albums", Class: classStorageFolder},
+			{ID: parentID + "/genres", ParentID: parentID, Restricted: "1", Title: "Genres", Class: classStorageFolder},
+			{ID: parentID + "/folders", ParentID: parentID, Restricted: "1", Title: "Folders", Class: classStorageFolder},
+		}
+		end := startIndex + count
+		if end > len(containers) {
+			end = len(containers)
+		}
+		if startIndex < len(containers) {
+			didl.Containers = containers[startIndex:end]
+		}
+		return didl, len(containers)
+	case "artists":
+		return r.browseLibraryArtists(ctx, libID, startIndex, count)
+	case "albums":
+		return r.browseLibraryAlbums(ctx, libID, startIndex, count, sortCriteria)
+	case "genres":
+		return r.browseLibraryGenres(ctx, libID, startIndex, count)
+	case "folders":
+		return r.browseLibraryFolders(ctx, libID, startIndex, count)
+	}
+
+	return didl, 0
+}
+
+// browseLibraryArtists returns the artists of one library
+func (r *Router) browseLibraryArtists(ctx context.Context, libID, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	// Artist queries join library_artist, same as the repository's own
+	// library_id filter mapping
+	opts := model.QueryOptions{
+		Sort:    "name",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: squirrel.Eq{"library_artist.library_id": libID},
+	}
+
+	artists, err := r.ds.Artist(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get library artists", "libraryID", libID, err)
+		return didl, 0
+	}
+	total, err := r.ds.Artist(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count library artists", "libraryID", libID, err)
+		total = int64(len(artists))
+	}
+
+	parentID := fmt.Sprintf("library/%d/artists", libID)
+	for _, artist := range artists {
+		didl.Containers = append(didl.Containers, Container{
+			ID:         "artist/" + artist.ID,
+			ParentID:   parentID,
+			Restricted: "1",
+			Title:      artist.Name,
+			Class:      classMusicArtist,
+		})
+	}
+
+	return didl, int(total)
+}
+
+// browseLibraryAlbums returns the albums of one library
+func (r *Router) browseLibraryAlbums(ctx context.Context, libID, startIndex, count int, sortCriteria string) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	opts := model.QueryOptions{
+		Sort:    "name",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: squirrel.Eq{"library_id": libID},
+	}
+	opts = applySortCriteria(opts, sortCriteria, albumSortColumns)
+
+	albums, err := r.ds.Album(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get library albums", "libraryID", libID, err)
+		return didl, 0
+	}
+	total, err := r.ds.Album(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count library albums", "libraryID", libID, err)
+		total = int64(len(albums))
+	}
+
+	parentID := fmt.Sprintf("library/%d/albums", libID)
+	for _, album := range albums {
+		didl.Containers = append(didl.Containers, Container{
+			ID:          "album/" + album.ID,
+			ParentID:    parentID,
+			Restricted:  "1",
+			Title:       album.Name,
+			Class:       classMusicAlbum,
+			AlbumArtURI: r.getAlbumArtURL(album.ID),
+		})
+	}
+
+	return didl, int(total)
+}
+
+// browseLibraryGenres returns the genres with tracks in one library
+func (r *Router) browseLibraryGenres(ctx context.Context, libID, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	// Genre queries join library_tag, same as the repository's own
+	// library_id filter mapping
+	opts := model.QueryOptions{
+		Sort:    "name",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: squirrel.Eq{"library_tag.library_id": libID},
+	}
+
+	genres, err := r.ds.Genre(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get library genres", "libraryID", libID, err)
+		return didl, 0
+	}
+	total, err := r.ds.Genre(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count library genres", "libraryID", libID, err)
+		total = int64(len(genres))
+	}
+
+	parentID := fmt.Sprintf("library/%d/genres", libID)
+	for _, genre := range genres {
+		didl.Containers = append(didl.Containers, Container{
+			ID:         "genre/" + genre.ID,
+			ParentID:   parentID,
+			Restricted: "1",
+			Title:      genre.Name,
+			Class:      classMusicGenre,
+		})
+	}
+
+	return didl, int(total)
+}
+
+// browseLibraryFolders returns the root folder contents of one library
+func (r *Router) browseLibraryFolders(ctx context.Context, libID, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	roots, err := r.ds.Folder(ctx).GetAll(model.QueryOptions{
+		Filters: squirrel.And{
+			squirrel.Eq{"library_id": libID},
+			squirrel.Eq{"parent_id": ""},
+			squirrel.Eq{"missing": false},
+		},
+	})
+	if err != nil || len(roots) == 0 {
+		if err != nil {
+			log.Error(ctx, "Failed to get library root folder", "libraryID", libID, err)
+		}
+		return didl, 0
+	}
+
+	return r.browseFolderChildren(ctx, roots[0].ID, startIndex, count)
+}
@@ -0,0 +1,66 @@
+package dlna
+
+import (
+	"context"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// browseRadio lists the internet radio stations configured in Navidrome as
+// audioBroadcast items. The station's stream URL is exposed directly, so the
+// renderer tunes the station itself
+func (r *Router) browseRadio(ctx context.Context, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	opts := model.QueryOptions{
+		Sort:   "name",
+		Offset: startIndex,
+		Max:    count,
+	}
+
+	radios, err := r.ds.Radio(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get radio stations", err)
+		return didl, 0
+	}
+	total, err := r.ds.Radio(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count radio stations", err)
+		total = int64(len(radios))
+	}
+
+	for _, radio := range radios {
+		didl.Items = append(didl.Items, Item{
+			ID:         "radio/" + radio.ID,
+			ParentID:   "music/radio",
+			Restricted: "1",
+			Title:      radio.Name,
+			Class:      classAudioBroadcast,
+			Resources: []Res{{
+				ProtocolInfo: radioProtocolInfo(radio.StreamUrl),
+				URL:          radio.StreamUrl,
+			}},
+		})
+	}
+
+	return didl, int(total)
+}
+
+// radioProtocolInfo guesses the protocolInfo of a radio stream from the URL's
+// extension. Most stations don't reveal their format in the URL, so the
+// fallback is a wildcard MIME type - renderers then sniff the stream
+func radioProtocolInfo(streamURL string) string {
+	ext := path.Ext(strings.SplitN(streamURL, "?", 2)[0])
+	if mimeType := mime.TypeByExtension(ext); strings.HasPrefix(mimeType, "audio/") {
+		return "http-get:*:" + mimeType + ":*"
+	}
+	return "http-get:*:*:*"
+}
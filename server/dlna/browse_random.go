@@ -0,0 +1,82 @@
+package dlna
+
+import (
+	"context"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// browseRandomAlbums returns a random selection of albums. The selection is
+// regenerated on every browse, so renderers without a shuffle UI get a fresh
+// batch each time they re-enter the container
+func (r *Router) browseRandomAlbums(ctx context.Context, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	opts := model.QueryOptions{
+		Sort:   "random",
+		Offset: startIndex,
+		Max:    count,
+	}
+
+	albums, err := r.ds.Album(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get random albums", err)
+		return didl, 0
+	}
+	total, err := r.ds.Album(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count albums", err)
+		total = int64(len(albums))
+	}
+
+	for _, album := range albums {
+		didl.Containers = append(didl.Containers, Container{
+			ID:          "album/" + album.ID,
+			ParentID:    "music/randomAlbums",
+			Restricted:  "1",
+			Title:       album.Name,
+			Class:       classMusicAlbum,
+			AlbumArtURI: r.getAlbumArtURL(album.ID),
+		})
+	}
+
+	return didl, int(total)
+}
+
+// browseRandomTracks returns a random selection of tracks, regenerated on
+// every browse
+func (r *Router) browseRandomTracks(ctx context.Context, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	opts := model.QueryOptions{
+		Sort:   "random",
+		Offset: startIndex,
+		Max:    count,
+	}
+
+	tracks, err := r.ds.MediaFile(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get random tracks", err)
+		return didl, 0
+	}
+	total, err := r.ds.MediaFile(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count tracks", err)
+		total = int64(len(tracks))
+	}
+
+	for _, track := range tracks {
+		didl.Items = append(didl.Items, r.mediaFileToItem(ctx, &track, "music/randomTracks"))
+	}
+
+	return didl, int(total)
+}
@@ -0,0 +1,120 @@
+package dlna
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// browseDecades returns one container per decade with albums in it, spanning
+// the oldest to the newest album year in the library
+func (r *Router) browseDecades(ctx context.Context, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	first, last, ok := r.albumYearRange(ctx)
+	if !ok {
+		return didl, 0
+	}
+
+	var decades []int
+	for decade := first / 10 * 10; decade <= last; decade += 10 {
+		decades = append(decades, decade)
+	}
+
+	total := len(decades)
+	end := startIndex + count
+	if end > total {
+		end = total
+	}
+
+	if startIndex < total {
+		for _, decade := range decades[startIndex:end] {
+			didl.Containers = append(didl.Containers, Container{
+				ID:         fmt.Sprintf("decade/%d", decade),
+				ParentID:   "music/years",
+				Restricted: "1",
+				Title:      fmt.Sprintf("%ds", decade),
+				Class:      classStorageFolder,
+			})
+		}
+	}
+
+	return didl, total
+}
+
+// albumYearRange returns the years of the oldest and newest dated albums.
+// ok is false when the library has no dated albums at all
+func (r *Router) albumYearRange(ctx context.Context) (first, last int, ok bool) {
+	dated := squirrel.Gt{"max_year": 0}
+
+	oldest, err := r.ds.Album(ctx).GetAll(model.QueryOptions{Sort: "max_year", Max: 1, Filters: dated})
+	if err != nil || len(oldest) == 0 {
+		if err != nil {
+			log.Error(ctx, "Failed to get oldest album", err)
+		}
+		return 0, 0, false
+	}
+	newest, err := r.ds.Album(ctx).GetAll(model.QueryOptions{Sort: "max_year", Order: "desc", Max: 1, Filters: dated})
+	if err != nil || len(newest) == 0 {
+		if err != nil {
+			log.Error(ctx, "Failed to get newest album", err)
+		}
+		return 0, 0, false
+	}
+
+	return oldest[0].MaxYear, newest[0].MaxYear, true
+}
+
+// browseDecadeAlbums returns the albums with a year inside one decade
+func (r *Router) browseDecadeAlbums(ctx context.Context, decadeID string, startIndex, count int, sortCriteria string) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	decade, err := strconv.Atoi(decadeID)
+	if err != nil {
+		return didl, 0
+	}
+
+	opts := model.QueryOptions{
+		Sort:    "max_year",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: squirrel.And{squirrel.GtOrEq{"max_year": decade}, squirrel.Lt{"max_year": decade + 10}},
+	}
+	opts = applySortCriteria(opts, sortCriteria, albumSortColumns)
+
+	albums, err := r.ds.Album(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get decade albums", "decade", decade, err)
+		return didl, 0
+	}
+	total, err := r.ds.Album(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count decade albums", "decade", decade, err)
+		total = int64(len(albums))
+	}
+
+	for _, album := range albums {
+		didl.Containers = append(didl.Containers, Container{
+			ID:          "album/" + album.ID,
+			ParentID:    "decade/" + decadeID,
+			Restricted:  "1",
+			Title:       album.Name,
+			Class:       classMusicAlbum,
+			AlbumArtURI: r.getAlbumArtURL(album.ID),
+		})
+	}
+
+	return didl, int(total)
+}
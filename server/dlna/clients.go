@@ -0,0 +1,68 @@
+package dlna
+
+import (
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Client is a renderer that recently issued requests against the DLNA server
+type Client struct {
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	Profile   string    `json:"profile,omitempty"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// clientTTL is how long a renderer stays listed after its last request
+const clientTTL = time.Hour
+
+// The registry is package-level, so the native API can list clients without
+// holding a reference to the Router, which only exists when DLNA is enabled
+var (
+	clientsMu sync.Mutex
+	clients   = map[string]*Client{}
+)
+
+// trackClient records renderer activity from an incoming HTTP request
+func trackClient(req *http.Request, profile *rendererProfile) {
+	ip := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	ua := req.Header.Get("User-Agent")
+
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	key := ip + "|" + ua
+	c, ok := clients[key]
+	if !ok {
+		c = &Client{IP: ip, UserAgent: ua}
+		clients[key] = c
+	}
+	c.Profile = profile.name()
+	c.LastSeen = time.Now()
+}
+
+// ActiveClients returns the renderers seen recently, most recent first.
+// Stale entries are pruned on the way
+func ActiveClients() []Client {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	cutoff := time.Now().Add(-clientTTL)
+	active := make([]Client, 0, len(clients))
+	for key, c := range clients {
+		if c.LastSeen.Before(cutoff) {
+			delete(clients, key)
+			continue
+		}
+		active = append(active, *c)
+	}
+	slices.SortFunc(active, func(a, b Client) int {
+		return b.LastSeen.Compare(a.LastSeen)
+	})
+	return active
+}
@@ -41,19 +41,30 @@ type GetCurrentConnectionInfoResponse struct {
 // Supported audio protocol info strings for DLNA
 // Format: protocol:network:contentFormat:additionalInfo
 const (
+	// Byte-based seeking (OP=01) plus the standard streaming flags. Formats
+	// without these in their additional info get scrubbing disabled by many
+	// renderers, so every format - lossless included - advertises them
+	dlnaSeekableInfo = "DLNA.ORG_OP=01;DLNA.ORG_FLAGS=01700000000000000000000000000000"
+
 	// Common audio formats
-	protoInfoMP3       = "http-get:*:audio/mpeg:DLNA.ORG_PN=MP3;DLNA.ORG_OP=01;DLNA.ORG_FLAGS=01700000000000000000000000000000"
-	protoInfoFLAC      = "http-get:*:audio/flac:*"
-	protoInfoWAV       = "http-get:*:audio/wav:*"
-	protoInfoWAVPCM    = "http-get:*:audio/L16:DLNA.ORG_PN=LPCM;DLNA.ORG_OP=01;DLNA.ORG_FLAGS=01700000000000000000000000000000"
-	protoInfoAAC       = "http-get:*:audio/aac:*"
-	protoInfoM4A       = "http-get:*:audio/mp4:DLNA.ORG_PN=AAC_ISO_320;DLNA.ORG_OP=01;DLNA.ORG_FLAGS=01700000000000000000000000000000"
-	protoInfoOGG       = "http-get:*:audio/ogg:*"
-	protoInfoOPUS      = "http-get:*:audio/opus:*"
-	protoInfoWMA       = "http-get:*:audio/x-ms-wma:DLNA.ORG_PN=WMABASE;DLNA.ORG_OP=01;DLNA.ORG_FLAGS=01700000000000000000000000000000"
+	protoInfoMP3    = "http-get:*:audio/mpeg:DLNA.ORG_PN=MP3;" + dlnaSeekableInfo
+	protoInfoFLAC   = "http-get:*:audio/flac:" + dlnaSeekableInfo
+	protoInfoWAV    = "http-get:*:audio/wav:" + dlnaSeekableInfo
+	protoInfoWAVPCM = "http-get:*:audio/L16:DLNA.ORG_PN=LPCM;" + dlnaSeekableInfo
+	protoInfoLPCM24 = "http-get:*:audio/L24:DLNA.ORG_PN=LPCM;" + dlnaSeekableInfo
+	protoInfoAAC    = "http-get:*:audio/aac:" + dlnaSeekableInfo
+	protoInfoM4A    = "http-get:*:audio/mp4:DLNA.ORG_PN=AAC_ISO_320;" + dlnaSeekableInfo
+	protoInfoOGG    = "http-get:*:audio/ogg:" + dlnaSeekableInfo
+	protoInfoOPUS   = "http-get:*:audio/opus:" + dlnaSeekableInfo
+	protoInfoWMA    = "http-get:*:audio/x-ms-wma:DLNA.ORG_PN=WMABASE;" + dlnaSeekableInfo
 
 	// Generic audio catch-all
-	protoInfoGenericAudio = "http-get:*:audio/*:*"
+	protoInfoGenericAudio = "http-get:*:audio/*:" + dlnaSeekableInfo
+
+	// Artwork thumbnails. TVs and car head units only show covers exposed as
+	// res entries with these standard image profiles
+	protoInfoJPEGTN = "http-get:*:image/jpeg:DLNA.ORG_PN=JPEG_TN"
+	protoInfoJPEGSM = "http-get:*:image/jpeg:DLNA.ORG_PN=JPEG_SM"
 )
 
 // handleGetProtocolInfo returns the supported protocols for streaming
@@ -64,12 +75,15 @@ func (r *Router) handleGetProtocolInfo(ctx context.Context) (*GetProtocolInfoRes
 		protoInfoFLAC,
 		protoInfoWAV,
 		protoInfoWAVPCM,
+		protoInfoLPCM24,
 		protoInfoAAC,
 		protoInfoM4A,
 		protoInfoOGG,
 		protoInfoOPUS,
 		protoInfoWMA,
 		protoInfoGenericAudio,
+		protoInfoJPEGTN,
+		protoInfoJPEGSM,
 	}
 
 	return &GetProtocolInfoResponse{
@@ -123,6 +137,8 @@ func GetProtocolInfoForMimeType(mimeType string) string {
 		return protoInfoWAV
 	case "audio/L16":
 		return protoInfoWAVPCM
+	case "audio/L24":
+		return protoInfoLPCM24
 	case "audio/aac", "audio/x-aac":
 		return protoInfoAAC
 	case "audio/mp4", "audio/x-m4a", "audio/m4a":
@@ -3,15 +3,18 @@ package dlna
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
-	"html"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
 )
 
 // Browse request/response structures
@@ -80,18 +83,23 @@ type Container struct {
 
 // Item represents a DIDL-Lite item (media file)
 type Item struct {
-	ID          string   `xml:"id,attr"`
-	ParentID    string   `xml:"parentID,attr"`
-	Restricted  string   `xml:"restricted,attr"`
-	Title       string   `xml:"dc:title"`
-	Creator     string   `xml:"dc:creator,omitempty"`
-	Album       string   `xml:"upnp:album,omitempty"`
-	Artist      string   `xml:"upnp:artist,omitempty"`
-	Genre       string   `xml:"upnp:genre,omitempty"`
-	Class       string   `xml:"upnp:class"`
-	AlbumArtURI string   `xml:"upnp:albumArtURI,omitempty"`
-	Resources   []Res    `xml:"res,omitempty"`
-	TrackNumber int      `xml:"upnp:originalTrackNumber,omitempty"`
+	ID                   string   `xml:"id,attr"`
+	ParentID             string   `xml:"parentID,attr"`
+	Restricted           string   `xml:"restricted,attr"`
+	Title                string   `xml:"dc:title"`
+	Creator              string   `xml:"dc:creator,omitempty"`
+	Album                string   `xml:"upnp:album,omitempty"`
+	Artist               string   `xml:"upnp:artist,omitempty"`
+	AlbumArtist          string   `xml:"upnp:albumArtist,omitempty"`
+	Date                 string   `xml:"dc:date,omitempty"`
+	Genres               []string `xml:"upnp:genre,omitempty"`
+	Contributors         []string `xml:"dc:contributor,omitempty"`
+	Class                string   `xml:"upnp:class"`
+	AlbumArtURI          string   `xml:"upnp:albumArtURI,omitempty"`
+	LastPlaybackPosition string   `xml:"upnp:lastPlaybackPosition,omitempty"`
+	Resources            []Res    `xml:"res,omitempty"`
+	TrackNumber          int      `xml:"upnp:originalTrackNumber,omitempty"`
+	DiscNumber           int      `xml:"upnp:originalDiscNumber,omitempty"`
 }
 
 // Res represents a resource element
@@ -102,18 +110,24 @@ type Res struct {
 	Bitrate      int    `xml:"bitrate,attr,omitempty"`
 	SampleFreq   int    `xml:"sampleFrequency,attr,omitempty"`
 	Channels     int    `xml:"nrAudioChannels,attr,omitempty"`
+	Resolution   string `xml:"resolution,attr,omitempty"`
 	URL          string `xml:",chardata"`
 }
 
 // UPnP object classes
 const (
-	classContainer        = "object.container"
-	classStorageFolder    = "object.container.storageFolder"
-	classMusicAlbum       = "object.container.album.musicAlbum"
-	classMusicArtist      = "object.container.person.musicArtist"
-	classMusicGenre       = "object.container.genre.musicGenre"
-	classMusicTrack       = "object.item.audioItem.musicTrack"
+	classContainer         = "object.container"
+	classStorageFolder     = "object.container.storageFolder"
+	classMusicAlbum        = "object.container.album.musicAlbum"
+	classMusicArtist       = "object.container.person.musicArtist"
+	classMusicGenre        = "object.container.genre.musicGenre"
+	classMusicTrack        = "object.item.audioItem.musicTrack"
+	classAudioBroadcast    = "object.item.audioItem.audioBroadcast"
+	classAudioBook         = "object.item.audioItem.audioBook"
 	classPlaylistContainer = "object.container.playlistContainer"
+	// Vendor extension marking smart playlists, so control points can treat
+	// their contents as dynamic
+	classSmartPlaylist = "object.container.playlistContainer.smartPlaylist"
 )
 
 // handleBrowse handles the ContentDirectory Browse action
@@ -138,40 +152,61 @@ func (r *Router) handleBrowse(ctx context.Context, body []byte) (*BrowseResponse
 		"startIndex", req.StartingIndex,
 		"count", req.RequestedCount)
 
-	// Handle default count
-	if req.RequestedCount == 0 {
-		req.RequestedCount = 100
+	// Clamp and validate paging before anything reaches the database
+	var err error
+	req.StartingIndex, req.RequestedCount, err = validatePaging(req.StartingIndex, req.RequestedCount)
+	if err != nil {
+		return nil, err
 	}
 
-	// Build DIDL-Lite response based on ObjectID
-	didl := DIDLLite{
-		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
-		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
-		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	// Samsung TVs browse the audio container ID advertised by X_GetFeatureList
+	if req.ObjectID == "A_A" {
+		req.ObjectID = "music"
 	}
 
+	// TVs re-request the same page repeatedly while scrolling, so rendered
+	// responses are served from a short-lived cache. The container's UpdateID
+	// is part of the key, so library changes invalidate stale entries. The
+	// random containers are regenerated on every browse by design
+	if strings.HasPrefix(req.ObjectID, "music/random") {
+		return r.renderBrowse(ctx, req)
+	}
+	key := fmt.Sprintf("%s|%s|%d|%d|%s|%s|%s|%d",
+		req.ObjectID, req.BrowseFlag, req.StartingIndex, req.RequestedCount,
+		req.Filter, req.SortCriteria, rendererProfileFrom(ctx).name(), r.containerUpdateID(req.ObjectID))
+	return r.browseCache.GetWithLoader(key, func(string) (*BrowseResponse, time.Duration, error) {
+		response, err := r.renderBrowse(ctx, req)
+		return response, 0, err
+	})
+}
+
+// renderBrowse builds and marshals the DIDL-Lite response for one Browse
+// request
+func (r *Router) renderBrowse(ctx context.Context, req BrowseRequest) (*BrowseResponse, error) {
+	var didl DIDLLite
 	var total int
 
 	switch req.BrowseFlag {
 	case "BrowseMetadata":
 		didl, total = r.browseMetadata(ctx, req.ObjectID)
 	case "BrowseDirectChildren":
-		didl, total = r.browseDirectChildren(ctx, req.ObjectID, req.StartingIndex, req.RequestedCount)
+		didl, total = r.browseDirectChildren(ctx, req.ObjectID, req.StartingIndex, req.RequestedCount, req.SortCriteria)
 	default:
 		return nil, fmt.Errorf("invalid BrowseFlag: %s", req.BrowseFlag)
 	}
 
-	// Marshal DIDL-Lite to XML
+	// Strip properties the client did not ask for, then marshal to XML
+	didl = applyDIDLFilter(didl, req.Filter)
 	didlXML, err := xml.Marshal(didl)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal DIDL-Lite: %w", err)
 	}
 
 	return &BrowseResponse{
-		Result:         html.EscapeString(string(didlXML)),
+		Result:         string(didlXML),
 		NumberReturned: len(didl.Containers) + len(didl.Items),
 		TotalMatches:   total,
-		UpdateID:       r.getUpdateID(),
+		UpdateID:       r.containerUpdateID(req.ObjectID),
 	}, nil
 }
 
@@ -208,6 +243,46 @@ func (r *Router) browseMetadata(ctx context.Context, objectID string) (DIDLLite,
 		didl.Containers = []Container{
 			{ID: "music/playlists", ParentID: "music", Restricted: "1", Title: "Playlists", Class: classStorageFolder},
 		}
+	case "music/folders":
+		didl.Containers = []Container{
+			{ID: "music/folders", ParentID: "music", Restricted: "1", Title: "Folders", Class: classStorageFolder},
+		}
+	case "music/recentlyAdded":
+		didl.Containers = []Container{
+			{ID: "music/recentlyAdded", ParentID: "music", Restricted: "1", Title: "Recently Added", Class: classStorageFolder},
+		}
+	case "music/recentlyPlayed":
+		didl.Containers = []Container{
+			{ID: "music/recentlyPlayed", ParentID: "music", Restricted: "1", Title: "Recently Played", Class: classStorageFolder},
+		}
+	case "music/starred":
+		didl.Containers = []Container{
+			{ID: "music/starred", ParentID: "music", Restricted: "1", Title: "Starred", Class: classStorageFolder},
+		}
+	case "music/randomAlbums":
+		didl.Containers = []Container{
+			{ID: "music/randomAlbums", ParentID: "music", Restricted: "1", Title: "Random Albums", Class: classStorageFolder},
+		}
+	case "music/randomTracks":
+		didl.Containers = []Container{
+			{ID: "music/randomTracks", ParentID: "music", Restricted: "1", Title: "Random Tracks", Class: classStorageFolder},
+		}
+	case "music/composers":
+		didl.Containers = []Container{
+			{ID: "music/composers", ParentID: "music", Restricted: "1", Title: "Composers", Class: classStorageFolder},
+		}
+	case "music/years":
+		didl.Containers = []Container{
+			{ID: "music/years", ParentID: "music", Restricted: "1", Title: "Years", Class: classStorageFolder},
+		}
+	case "music/radio":
+		didl.Containers = []Container{
+			{ID: "music/radio", ParentID: "music", Restricted: "1", Title: "Radio", Class: classStorageFolder},
+		}
+	case "music/continueListening":
+		didl.Containers = []Container{
+			{ID: "music/continueListening", ParentID: "music", Restricted: "1", Title: "Continue Listening", Class: classStorageFolder},
+		}
 	default:
 		// Handle specific artist/album/track IDs
 		// This will be expanded in Phase 2
@@ -218,7 +293,7 @@ func (r *Router) browseMetadata(ctx context.Context, objectID string) (DIDLLite,
 }
 
 // browseDirectChildren returns children of a container
-func (r *Router) browseDirectChildren(ctx context.Context, objectID string, startIndex, count int) (DIDLLite, int) {
+func (r *Router) browseDirectChildren(ctx context.Context, objectID string, startIndex, count int, sortCriteria string) (DIDLLite, int) {
 	didl := DIDLLite{
 		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
 		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
@@ -227,11 +302,23 @@ func (r *Router) browseDirectChildren(ctx context.Context, objectID string, star
 
 	switch objectID {
 	case "0":
-		// Root - show Music folder
+		// Root - the combined Music folder, plus one container per library
+		// when there is more than one
 		didl.Containers = []Container{
-			{ID: "music", ParentID: "0", Restricted: "1", Title: "Music", Class: classStorageFolder, ChildCount: 4},
+			{ID: "music", ParentID: "0", Restricted: "1", Title: "Music", Class: classStorageFolder, ChildCount: 14},
+		}
+		didl.Containers = append(didl.Containers, r.libraryContainers(ctx)...)
+		total := len(didl.Containers)
+		end := startIndex + count
+		if end > total {
+			end = total
+		}
+		if startIndex < total {
+			didl.Containers = didl.Containers[startIndex:end]
+		} else {
+			didl.Containers = nil
 		}
-		return didl, 1
+		return didl, total
 
 	case "music":
 		// Music folder - show categories
@@ -240,6 +327,16 @@ func (r *Router) browseDirectChildren(ctx context.Context, objectID string, star
 			{ID: "music/albums", ParentID: "music", Restricted: "1", Title: "Albums", Class: classStorageFolder},
 			{ID: "music/genres", ParentID: "music", Restricted: "1", Title: "Genres", Class: classStorageFolder},
 			{ID: "music/playlists", ParentID: "music", Restricted: "1", Title: "Playlists", Class: classStorageFolder},
+			{ID: "music/folders", ParentID: "music", Restricted: "1", Title: "Folders", Class: classStorageFolder},
+			{ID: "music/recentlyAdded", ParentID: "music", Restricted: "1", Title: "Recently Added", Class: classStorageFolder},
+			{ID: "music/recentlyPlayed", ParentID: "music", Restricted: "1", Title: "Recently Played", Class: classStorageFolder},
+			{ID: "music/starred", ParentID: "music", Restricted: "1", Title: "Starred", Class: classStorageFolder},
+			{ID: "music/randomAlbums", ParentID: "music", Restricted: "1", Title: "Random Albums", Class: classStorageFolder},
+			{ID: "music/randomTracks", ParentID: "music", Restricted: "1", Title: "Random Tracks", Class: classStorageFolder},
+			{ID: "music/composers", ParentID: "music", Restricted: "1", Title: "Composers", Class: classStorageFolder},
+			{ID: "music/years", ParentID: "music", Restricted: "1", Title: "Years", Class: classStorageFolder},
+			{ID: "music/radio", ParentID: "music", Restricted: "1", Title: "Radio", Class: classStorageFolder},
+			{ID: "music/continueListening", ParentID: "music", Restricted: "1", Title: "Continue Listening", Class: classStorageFolder},
 		}
 		// Apply pagination
 		end := startIndex + count
@@ -252,35 +349,107 @@ func (r *Router) browseDirectChildren(ctx context.Context, objectID string, star
 		return didl, len(containers)
 
 	case "music/artists":
+		// Huge libraries can optionally be split into A-Z buckets
+		if conf.Server.DLNA.AlphabetIndex {
+			return r.browseIndex("artistindex/", "music/artists", startIndex, count)
+		}
 		return r.browseArtists(ctx, startIndex, count)
 
 	case "music/albums":
-		return r.browseAlbums(ctx, startIndex, count, "")
+		if conf.Server.DLNA.AlphabetIndex {
+			return r.browseIndex("albumindex/", "music/albums", startIndex, count)
+		}
+		return r.browseAlbums(ctx, startIndex, count, "", sortCriteria)
 
 	case "music/genres":
 		return r.browseGenres(ctx, startIndex, count)
 
 	case "music/playlists":
-		return r.browsePlaylists(ctx, startIndex, count)
+		return r.browsePlaylists(ctx, startIndex, count, sortCriteria)
+
+	case "music/folders":
+		return r.browseFolders(ctx, startIndex, count)
+
+	case "music/recentlyAdded":
+		return r.browseRecentlyAdded(ctx, startIndex, count)
+
+	case "music/recentlyPlayed":
+		return r.browseRecentlyPlayed(ctx, startIndex, count)
+
+	case "music/starred":
+		return r.browseStarred(ctx, startIndex, count)
+
+	case "music/randomAlbums":
+		return r.browseRandomAlbums(ctx, startIndex, count)
+
+	case "music/randomTracks":
+		return r.browseRandomTracks(ctx, startIndex, count)
+
+	case "music/composers":
+		return r.browseComposers(ctx, startIndex, count)
+
+	case "music/years":
+		return r.browseDecades(ctx, startIndex, count)
+
+	case "music/radio":
+		return r.browseRadio(ctx, startIndex, count)
+
+	case "music/continueListening":
+		return r.browseContinueListening(ctx, startIndex, count)
 
 	default:
 		// Check if it's an artist, album, genre, or playlist ID
 		if strings.HasPrefix(objectID, "artist/") {
 			artistID := strings.TrimPrefix(objectID, "artist/")
-			return r.browseAlbums(ctx, startIndex, count, artistID)
+			return r.browseAlbums(ctx, startIndex, count, artistID, sortCriteria)
+		}
+		if strings.HasPrefix(objectID, "artistindex/") {
+			letter := strings.TrimPrefix(objectID, "artistindex/")
+			return r.browseIndexedArtists(ctx, letter, startIndex, count)
+		}
+		if strings.HasPrefix(objectID, "albumindex/") {
+			letter := strings.TrimPrefix(objectID, "albumindex/")
+			return r.browseIndexedAlbums(ctx, letter, startIndex, count)
+		}
+		if strings.HasPrefix(objectID, "artisttracks/") {
+			artistID := strings.TrimPrefix(objectID, "artisttracks/")
+			return r.browseArtistTracks(ctx, artistID, startIndex, count, sortCriteria)
 		}
 		if strings.HasPrefix(objectID, "album/") {
 			albumID := strings.TrimPrefix(objectID, "album/")
-			return r.browseTracks(ctx, albumID, startIndex, count)
+			// Multi-disc albums may be presented as one container per disc
+			if didl, total, ok := r.browseAlbumDiscs(ctx, albumID, startIndex, count); ok {
+				return didl, total
+			}
+			return r.browseTracks(ctx, albumID, startIndex, count, sortCriteria)
+		}
+		if strings.HasPrefix(objectID, "disc/") {
+			discID := strings.TrimPrefix(objectID, "disc/")
+			return r.browseDiscTracks(ctx, discID, startIndex, count, sortCriteria)
 		}
 		if strings.HasPrefix(objectID, "genre/") {
 			genreID := strings.TrimPrefix(objectID, "genre/")
-			return r.browseGenreAlbums(ctx, genreID, startIndex, count)
+			return r.browseGenreAlbums(ctx, genreID, startIndex, count, sortCriteria)
 		}
 		if strings.HasPrefix(objectID, "playlist/") {
 			playlistID := strings.TrimPrefix(objectID, "playlist/")
 			return r.browsePlaylistTracks(ctx, playlistID, startIndex, count)
 		}
+		if strings.HasPrefix(objectID, "folder/") {
+			folderID := strings.TrimPrefix(objectID, "folder/")
+			return r.browseFolderChildren(ctx, folderID, startIndex, count)
+		}
+		if strings.HasPrefix(objectID, "composer/") {
+			artistID := strings.TrimPrefix(objectID, "composer/")
+			return r.browseComposerAlbums(ctx, artistID, startIndex, count, sortCriteria)
+		}
+		if strings.HasPrefix(objectID, "decade/") {
+			decadeID := strings.TrimPrefix(objectID, "decade/")
+			return r.browseDecadeAlbums(ctx, decadeID, startIndex, count, sortCriteria)
+		}
+		if strings.HasPrefix(objectID, "library/") {
+			return r.browseLibrary(ctx, objectID, startIndex, count, sortCriteria)
+		}
 	}
 
 	return didl, 0
@@ -294,36 +463,40 @@ func (r *Router) browseArtists(ctx context.Context, startIndex, count int) (DIDL
 		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
 	}
 
-	// Get artists from database
-	artists, err := r.ds.Artist(ctx).GetAll()
+	// Get artists from database, paginated
+	opts := model.QueryOptions{
+		Sort:   "name",
+		Offset: startIndex,
+		Max:    count,
+	}
+
+	artists, err := r.ds.Artist(ctx).GetAll(opts)
 	if err != nil {
 		log.Error(ctx, "Failed to get artists", err)
 		return didl, 0
 	}
 
-	total := len(artists)
-	end := startIndex + count
-	if end > total {
-		end = total
+	total, err := r.ds.Artist(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count artists", err)
+		total = int64(len(artists))
 	}
 
-	if startIndex < total {
-		for _, artist := range artists[startIndex:end] {
-			didl.Containers = append(didl.Containers, Container{
-				ID:         "artist/" + artist.ID,
-				ParentID:   "music/artists",
-				Restricted: "1",
-				Title:      artist.Name,
-				Class:      classMusicArtist,
-			})
-		}
+	for _, artist := range artists {
+		didl.Containers = append(didl.Containers, Container{
+			ID:         "artist/" + artist.ID,
+			ParentID:   "music/artists",
+			Restricted: "1",
+			Title:      artist.Name,
+			Class:      classMusicArtist,
+		})
 	}
 
-	return didl, total
+	return didl, int(total)
 }
 
 // browseAlbums returns the list of albums (optionally filtered by artist)
-func (r *Router) browseAlbums(ctx context.Context, startIndex, count int, artistID string) (DIDLLite, int) {
+func (r *Router) browseAlbums(ctx context.Context, startIndex, count int, artistID, sortCriteria string) (DIDLLite, int) {
 	didl := DIDLLite{
 		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
 		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
@@ -336,17 +509,36 @@ func (r *Router) browseAlbums(ctx context.Context, startIndex, count int, artist
 		Offset: startIndex,
 		Max:    count,
 	}
+	opts = applySortCriteria(opts, sortCriteria, albumSortColumns)
 
-	// Filter by artist if specified
+	// Filter by artist if specified. The artist's first child is the
+	// "All Tracks" pseudo-container, so album pagination shifts by one
+	extra := 0
 	if artistID != "" {
 		opts.Filters = squirrel.Eq{"album_artist_id": artistID}
+		extra = 1
+		if startIndex == 0 {
+			didl.Containers = append(didl.Containers, Container{
+				ID:         "artisttracks/" + artistID,
+				ParentID:   "artist/" + artistID,
+				Restricted: "1",
+				Title:      "All Tracks",
+				Class:      classStorageFolder,
+			})
+			opts.Max = count - 1
+		} else {
+			opts.Offset = startIndex - 1
+		}
 	}
 
-	// Get albums from database
-	albums, err := r.ds.Album(ctx).GetAll(opts)
-	if err != nil {
-		log.Error(ctx, "Failed to get albums", err)
-		return didl, 0
+	var albums model.Albums
+	var err error
+	if opts.Max > 0 {
+		albums, err = r.ds.Album(ctx).GetAll(opts)
+		if err != nil {
+			log.Error(ctx, "Failed to get albums", err)
+			return didl, 0
+		}
 	}
 
 	// Get total count
@@ -355,6 +547,7 @@ func (r *Router) browseAlbums(ctx context.Context, startIndex, count int, artist
 		log.Error(ctx, "Failed to count albums", err)
 		total = int64(len(albums))
 	}
+	total += int64(extra)
 
 	parentID := "music/albums"
 	if artistID != "" {
@@ -384,36 +577,40 @@ func (r *Router) browseGenres(ctx context.Context, startIndex, count int) (DIDLL
 		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
 	}
 
-	// Get genres from database
-	genres, err := r.ds.Genre(ctx).GetAll()
+	// Get genres from database, paginated
+	opts := model.QueryOptions{
+		Sort:   "name",
+		Offset: startIndex,
+		Max:    count,
+	}
+
+	genres, err := r.ds.Genre(ctx).GetAll(opts)
 	if err != nil {
 		log.Error(ctx, "Failed to get genres", err)
 		return didl, 0
 	}
 
-	total := len(genres)
-	end := startIndex + count
-	if end > total {
-		end = total
+	total, err := r.ds.Genre(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count genres", err)
+		total = int64(len(genres))
 	}
 
-	if startIndex < total {
-		for _, genre := range genres[startIndex:end] {
-			didl.Containers = append(didl.Containers, Container{
-				ID:         "genre/" + genre.ID,
-				ParentID:   "music/genres",
-				Restricted: "1",
-				Title:      genre.Name,
-				Class:      classMusicGenre,
-			})
-		}
+	for _, genre := range genres {
+		didl.Containers = append(didl.Containers, Container{
+			ID:         "genre/" + genre.ID,
+			ParentID:   "music/genres",
+			Restricted: "1",
+			Title:      genre.Name,
+			Class:      classMusicGenre,
+		})
 	}
 
-	return didl, total
+	return didl, int(total)
 }
 
 // browseGenreAlbums returns albums in a genre
-func (r *Router) browseGenreAlbums(ctx context.Context, genreID string, startIndex, count int) (DIDLLite, int) {
+func (r *Router) browseGenreAlbums(ctx context.Context, genreID string, startIndex, count int, sortCriteria string) (DIDLLite, int) {
 	didl := DIDLLite{
 		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
 		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
@@ -425,8 +622,9 @@ func (r *Router) browseGenreAlbums(ctx context.Context, genreID string, startInd
 		Sort:    "name",
 		Offset:  startIndex,
 		Max:     count,
-		Filters: squirrel.Eq{"genre_id": genreID},
+		Filters: genreIDFilter(genreID),
 	}
+	opts = applySortCriteria(opts, sortCriteria, albumSortColumns)
 
 	// Get albums from database
 	albums, err := r.ds.Album(ctx).GetAll(opts)
@@ -457,7 +655,7 @@ func (r *Router) browseGenreAlbums(ctx context.Context, genreID string, startInd
 }
 
 // browsePlaylists returns the list of playlists
-func (r *Router) browsePlaylists(ctx context.Context, startIndex, count int) (DIDLLite, int) {
+func (r *Router) browsePlaylists(ctx context.Context, startIndex, count int, sortCriteria string) (DIDLLite, int) {
 	didl := DIDLLite{
 		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
 		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
@@ -470,6 +668,10 @@ func (r *Router) browsePlaylists(ctx context.Context, startIndex, count int) (DI
 		Offset: startIndex,
 		Max:    count,
 	}
+	if conf.Server.DLNA.PublicPlaylistsOnly {
+		opts.Filters = squirrel.Eq{"public": true}
+	}
+	opts = applySortCriteria(opts, sortCriteria, playlistSortColumns)
 
 	playlists, err := r.ds.Playlist(ctx).GetAll(opts)
 	if err != nil {
@@ -484,13 +686,27 @@ func (r *Router) browsePlaylists(ctx context.Context, startIndex, count int) (DI
 	}
 
 	for _, playlist := range playlists {
+		class := classPlaylistContainer
+		childCount := playlist.SongCount
+		if playlist.IsSmartPlaylist() {
+			class = classSmartPlaylist
+			// Re-evaluate the criteria now - the same path GetWithTracks uses -
+			// so the advertised track count reflects the library as it is today.
+			// The repository's refresh delay keeps this from running on every
+			// page of a TV's scroll
+			if refreshed, err := r.ds.Playlist(ctx).GetWithTracks(playlist.ID, true, false); err == nil {
+				childCount = refreshed.SongCount
+			} else {
+				log.Error(ctx, "Failed to refresh smart playlist", "playlist", playlist.Name, err)
+			}
+		}
 		didl.Containers = append(didl.Containers, Container{
 			ID:         "playlist/" + playlist.ID,
 			ParentID:   "music/playlists",
 			Restricted: "1",
 			Title:      playlist.Name,
-			Class:      classPlaylistContainer,
-			ChildCount: playlist.SongCount,
+			Class:      class,
+			ChildCount: childCount,
 		})
 	}
 
@@ -512,6 +728,11 @@ func (r *Router) browsePlaylistTracks(ctx context.Context, playlistID string, st
 		return didl, 0
 	}
 
+	// Guessing object IDs must not get around the playlist restriction
+	if conf.Server.DLNA.PublicPlaylistsOnly && !playlist.Public {
+		return didl, 0
+	}
+
 	total := len(playlist.Tracks)
 	end := startIndex + count
 	if end > total {
@@ -519,9 +740,49 @@ func (r *Router) browsePlaylistTracks(ctx context.Context, playlistID string, st
 	}
 
 	if startIndex < total {
-		for _, track := range playlist.Tracks[startIndex:end] {
+		for i, track := range playlist.Tracks[startIndex:end] {
 			mf := track.MediaFile
-			item := r.mediaFileToItem(&mf, "playlist/"+playlistID)
+			item := r.mediaFileToItem(ctx, &mf, "playlist/"+playlistID)
+			// Keep the playlist order: the ID is stable per entry (the same
+			// track can appear more than once), and the track number is the
+			// playlist position, so renderers don't re-sort by album order
+			item.ID = "playlist/" + playlistID + "/" + track.ID
+			item.TrackNumber = startIndex + i + 1
+			item.DiscNumber = 0
+			didl.Items = append(didl.Items, item)
+		}
+	}
+
+	return didl, total
+}
+
+// browseContinueListening returns the user's bookmarked tracks, so long-form
+// listening started in another client can be resumed from a renderer
+func (r *Router) browseContinueListening(ctx context.Context, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	bookmarks, err := r.ds.MediaFile(ctx).GetBookmarks()
+	if err != nil {
+		log.Error(ctx, "Failed to get bookmarks", err)
+		return didl, 0
+	}
+
+	total := len(bookmarks)
+	end := startIndex + count
+	if end > total {
+		end = total
+	}
+
+	if startIndex < total {
+		for _, bookmark := range bookmarks[startIndex:end] {
+			mf := bookmark.Item
+			item := r.mediaFileToItem(ctx, &mf, "music/continueListening")
+			// Bookmark positions are stored in milliseconds
+			item.LastPlaybackPosition = formatDuration(float64(bookmark.Position) / 1000)
 			didl.Items = append(didl.Items, item)
 		}
 	}
@@ -530,7 +791,7 @@ func (r *Router) browsePlaylistTracks(ctx context.Context, playlistID string, st
 }
 
 // browseTracks returns tracks in an album
-func (r *Router) browseTracks(ctx context.Context, albumID string, startIndex, count int) (DIDLLite, int) {
+func (r *Router) browseTracks(ctx context.Context, albumID string, startIndex, count int, sortCriteria string) (DIDLLite, int) {
 	didl := DIDLLite{
 		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
 		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
@@ -544,6 +805,7 @@ func (r *Router) browseTracks(ctx context.Context, albumID string, startIndex, c
 		Max:     count,
 		Filters: squirrel.Eq{"album_id": albumID},
 	}
+	opts = applySortCriteria(opts, sortCriteria, trackSortColumns)
 
 	// Get tracks from database
 	tracks, err := r.ds.MediaFile(ctx).GetAll(opts)
@@ -559,15 +821,54 @@ func (r *Router) browseTracks(ctx context.Context, albumID string, startIndex, c
 	}
 
 	for _, track := range tracks {
-		item := r.mediaFileToItem(&track, "album/"+albumID)
+		item := r.mediaFileToItem(ctx, &track, "album/"+albumID)
 		didl.Items = append(didl.Items, item)
 	}
 
 	return didl, int(total)
 }
 
-// mediaFileToItem converts a MediaFile to a DIDL-Lite Item
-func (r *Router) mediaFileToItem(mf *model.MediaFile, parentID string) Item {
+// browseArtistTracks returns every track the artist participates in - not
+// just tracks on their album-artist albums - enabling full-artist shuffle
+func (r *Router) browseArtistTracks(ctx context.Context, artistID string, startIndex, count int, sortCriteria string) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	// Match the artist ID anywhere in the participants document, same as the
+	// repositories' role_total_id filter
+	opts := model.QueryOptions{
+		Sort:    "album",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: squirrel.Like{"participants": fmt.Sprintf(`%%"%s"%%`, artistID)},
+	}
+	opts = applySortCriteria(opts, sortCriteria, trackSortColumns)
+
+	tracks, err := r.ds.MediaFile(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to get artist tracks", "artistID", artistID, err)
+		return didl, 0
+	}
+
+	total, err := r.ds.MediaFile(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count artist tracks", "artistID", artistID, err)
+		total = int64(len(tracks))
+	}
+
+	for _, track := range tracks {
+		didl.Items = append(didl.Items, r.mediaFileToItem(ctx, &track, "artisttracks/"+artistID))
+	}
+
+	return didl, int(total)
+}
+
+// mediaFileToItem converts a MediaFile to a DIDL-Lite Item, applying the
+// renderer profile detected for this request (if any)
+func (r *Router) mediaFileToItem(ctx context.Context, mf *model.MediaFile, parentID string) Item {
 	item := Item{
 		ID:          "track/" + mf.ID,
 		ParentID:    parentID,
@@ -576,15 +877,48 @@ func (r *Router) mediaFileToItem(mf *model.MediaFile, parentID string) Item {
 		Creator:     mf.Artist,
 		Album:       mf.Album,
 		Artist:      mf.Artist,
+		AlbumArtist: mf.AlbumArtist,
 		Class:       classMusicTrack,
 		AlbumArtURI: r.getAlbumArtURL(mf.AlbumID),
 		TrackNumber: mf.TrackNumber,
+		DiscNumber:  mf.DiscNumber,
+	}
+
+	// dc:date takes a full date when the file has one, a bare year otherwise
+	switch {
+	case mf.Date != "":
+		item.Date = mf.Date
+	case mf.Year != 0:
+		item.Date = strconv.Itoa(mf.Year)
+	}
+
+	// All genres, not just the primary one
+	for _, genre := range mf.Genres {
+		item.Genres = append(item.Genres, genre.Name)
+	}
+
+	// Long-form content (audiobooks, podcasts) gets its own class, and the
+	// user's bookmark position, so renderers with bookmarking can resume
+	if isLongForm(mf) {
+		item.Class = classAudioBook
+	}
+	if mf.BookmarkPosition > 0 {
+		// Bookmark positions are stored in milliseconds
+		item.LastPlaybackPosition = formatDuration(float64(mf.BookmarkPosition) / 1000)
 	}
 
-	// Add genre if available
-	if mf.Genre != "" {
-		item.Genre = mf.Genre
+	// Credits beyond the main artists (composers, conductors, remixers, ...)
+	// go out as dc:contributor entries
+	for role, artists := range mf.Participants {
+		if role == model.RoleArtist || role == model.RoleAlbumArtist {
+			continue
+		}
+		for _, artist := range artists {
+			item.Contributors = append(item.Contributors, artist.Name)
+		}
 	}
+	slices.Sort(item.Contributors)
+	item.Contributors = slices.Compact(item.Contributors)
 
 	// Add resource with streaming URL
 	res := Res{
@@ -594,33 +928,112 @@ func (r *Router) mediaFileToItem(mf *model.MediaFile, parentID string) Item {
 		Bitrate:      mf.BitRate * 125, // Convert kbps to bytes/sec
 		SampleFreq:   mf.SampleRate,
 		Channels:     mf.Channels,
-		URL:          r.getStreamURL(mf.ID),
+		URL:          r.getStreamURL(mf.ID, ""),
+	}
+
+	// Apply the renderer profile: a transcoded stream gets the target
+	// format's URL and protocolInfo, and no size (it is unknown upfront)
+	if format := rendererProfileFrom(ctx).targetFormat(mf); format != "" {
+		res.URL = r.getStreamURL(mf.ID, format)
+		res.ProtocolInfo = GetProtocolInfoForMimeType(formatMimeType(format))
+		res.Size = 0
 	}
 	item.Resources = []Res{res}
 
+	// Artwork thumbnails with the standard DLNA image profiles, for renderers
+	// that only show covers exposed as res entries
+	item.Resources = append(item.Resources,
+		Res{ProtocolInfo: protoInfoJPEGTN, Resolution: "160x160", URL: r.getAlbumArtURLSized(mf.AlbumID, 160)},
+		Res{ProtocolInfo: protoInfoJPEGSM, Resolution: "480x480", URL: r.getAlbumArtURLSized(mf.AlbumID, 480)},
+	)
+
 	return item
 }
 
-// getStreamURL returns the streaming URL for a media file
-func (r *Router) getStreamURL(mediaFileID string) string {
+// getStreamURL returns the streaming URL for a media file, transcoded to the
+// given format (empty means the raw file). Streams are served by the DLNA
+// router itself, so no Subsonic credentials are involved
+func (r *Router) getStreamURL(mediaFileID, format string) string {
 	baseURL := conf.Server.BaseURL
 	if baseURL == "" {
 		baseURL = fmt.Sprintf("http://%s:%d", getLocalIP(), r.httpPort)
 	}
-	return fmt.Sprintf("%s/rest/stream?id=%s&f=raw", baseURL, mediaFileID)
+	if format != "" {
+		return fmt.Sprintf("%s/dlna/stream/%s?format=%s", baseURL, mediaFileID, format)
+	}
+	return fmt.Sprintf("%s/dlna/stream/%s", baseURL, mediaFileID)
+}
+
+// longFormGenres mark tracks as long-form (bookmarkable) content
+var longFormGenres = []string{"audiobook", "audio book", "podcast", "spoken", "speech"}
+
+// isLongForm reports whether a track is long-form content, based on its genres
+func isLongForm(mf *model.MediaFile) bool {
+	for _, genre := range mf.Genres {
+		name := strings.ToLower(genre.Name)
+		for _, longForm := range longFormGenres {
+			if strings.Contains(name, longForm) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// errInvalidArgs marks requests with arguments outside the spec, reported to
+// the client as UPnP error 402 instead of a generic action failure
+var errInvalidArgs = errors.New("invalid arguments")
+
+// validatePaging rejects negative paging values and clamps the requested
+// count to DLNA.MaxPageSize, protecting the database from misbehaving
+// control points. A count of zero means "as many as allowed"
+func validatePaging(startIndex, count int) (int, int, error) {
+	if startIndex < 0 || count < 0 {
+		return 0, 0, fmt.Errorf("%w: negative paging values (start %d, count %d)", errInvalidArgs, startIndex, count)
+	}
+	maxPageSize := conf.Server.DLNA.MaxPageSize
+	if maxPageSize <= 0 {
+		maxPageSize = 500
+	}
+	if count == 0 || count > maxPageSize {
+		count = maxPageSize
+	}
+	return startIndex, count, nil
+}
+
+// capabilityList builds a stable, comma-separated list of the properties
+// present in the given mappings, so the advertised capabilities can't drift
+// from what Browse and Search implement
+func capabilityList(maps ...map[string]string) string {
+	set := map[string]struct{}{}
+	for _, m := range maps {
+		for prop := range m {
+			set[prop] = struct{}{}
+		}
+	}
+	props := make([]string, 0, len(set))
+	for prop := range set {
+		props = append(props, prop)
+	}
+	slices.Sort(props)
+	return strings.Join(props, ",")
 }
 
-// handleGetSearchCapabilities returns search capabilities
+// handleGetSearchCapabilities returns search capabilities, derived from the
+// properties the search column mappings actually support. upnp:class is
+// always searchable - it picks the object class
 func (r *Router) handleGetSearchCapabilities(ctx context.Context) (*GetSearchCapabilitiesResponse, error) {
 	return &GetSearchCapabilitiesResponse{
-		SearchCaps: "dc:title,dc:creator,upnp:artist,upnp:album,upnp:genre",
+		SearchCaps: capabilityList(trackSearchColumns, albumSearchColumns, artistSearchColumns,
+			map[string]string{"upnp:class": ""}),
 	}, nil
 }
 
-// handleGetSortCapabilities returns sort capabilities
+// handleGetSortCapabilities returns sort capabilities, derived from the
+// properties the sort column mappings actually support
 func (r *Router) handleGetSortCapabilities(ctx context.Context) (*GetSortCapabilitiesResponse, error) {
 	return &GetSortCapabilitiesResponse{
-		SortCaps: "dc:title,dc:creator,upnp:artist,upnp:album,upnp:originalTrackNumber",
+		SortCaps: capabilityList(albumSortColumns, trackSortColumns, playlistSortColumns),
 	}, nil
 }
 
@@ -631,10 +1044,64 @@ func (r *Router) handleGetSystemUpdateID(ctx context.Context) (*GetSystemUpdateI
 	}, nil
 }
 
-// getUpdateID returns a system update ID (should increment when library changes)
-func (r *Router) getUpdateID() uint32 {
-	// For now, return a constant. In production, this should track library changes.
-	return 1
+// Sortable properties mapped to database columns, per object type. These also
+// drive the capabilities advertised by GetSortCapabilities
+var (
+	albumSortColumns = map[string]string{
+		"dc:title":    "name",
+		"dc:date":     "max_year",
+		"dc:creator":  "album_artist",
+		"upnp:artist": "album_artist",
+	}
+	trackSortColumns = map[string]string{
+		"dc:title":                 "title",
+		"dc:date":                  "date",
+		"dc:creator":               "artist",
+		"upnp:artist":              "artist",
+		"upnp:album":               "album",
+		"upnp:originalTrackNumber": "track_number",
+	}
+	playlistSortColumns = map[string]string{
+		"dc:title": "name",
+		"dc:date":  "updated_at",
+	}
+)
+
+// applySortCriteria maps a client's SortCriteria ("+dc:title,-dc:date") onto
+// the query options. The first property with a known column mapping wins;
+// when none of the requested properties is sortable, the default order is kept
+func applySortCriteria(opts model.QueryOptions, criteria string, columns map[string]string) model.QueryOptions {
+	for _, criterion := range strings.Split(criteria, ",") {
+		criterion = strings.TrimSpace(criterion)
+		if criterion == "" {
+			continue
+		}
+		descending := strings.HasPrefix(criterion, "-")
+		property := strings.TrimPrefix(strings.TrimPrefix(criterion, "-"), "+")
+		column, ok := columns[property]
+		if !ok {
+			continue
+		}
+		opts.Sort = column
+		if descending {
+			opts.Order = "desc"
+		} else {
+			opts.Order = ""
+		}
+		return opts
+	}
+	return opts
+}
+
+// genreIDFilter matches albums or tracks carrying the given genre tag ID.
+// Genres are not columns but entries in the tags JSON, so the filter walks
+// the "genre" branch of the document, same as the repositories' own genre_id
+// filter mapping
+func genreIDFilter(genreID string) squirrel.Sqlizer {
+	return persistence.Exists(`json_tree(tags, "$.genre")`, squirrel.And{
+		squirrel.NotEq{"json_tree.atom": nil},
+		squirrel.Eq{"value": genreID},
+	})
 }
 
 // formatDuration formats a duration in seconds to DLNA format (H:MM:SS.mmm)
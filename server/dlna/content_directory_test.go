@@ -0,0 +1,52 @@
+package dlna
+
+import (
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/conf/configtest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("validatePaging", func() {
+	BeforeEach(func() {
+		DeferCleanup(configtest.SetupConfig())
+	})
+
+	It("passes valid values through unchanged", func() {
+		start, count, err := validatePaging(10, 25)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(start).To(Equal(10))
+		Expect(count).To(Equal(25))
+	})
+
+	It("rejects a negative starting index", func() {
+		_, _, err := validatePaging(-1, 25)
+		Expect(err).To(MatchError(errInvalidArgs))
+	})
+
+	It("rejects a negative count", func() {
+		_, _, err := validatePaging(0, -5)
+		Expect(err).To(MatchError(errInvalidArgs))
+	})
+
+	It("expands a zero count to the configured page size", func() {
+		conf.Server.DLNA.MaxPageSize = 200
+		_, count, err := validatePaging(0, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(200))
+	})
+
+	It("clamps the count to the configured page size", func() {
+		conf.Server.DLNA.MaxPageSize = 200
+		_, count, err := validatePaging(0, 100000)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(200))
+	})
+
+	It("falls back to the default page size when none is configured", func() {
+		conf.Server.DLNA.MaxPageSize = 0
+		_, count, err := validatePaging(0, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(500))
+	})
+})
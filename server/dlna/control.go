@@ -2,10 +2,12 @@ package dlna
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/navidrome/navidrome/log"
 )
@@ -54,7 +56,13 @@ const (
 
 // handleContentDirectoryControl handles SOAP requests for ContentDirectory service
 func (r *Router) handleContentDirectoryControl(w http.ResponseWriter, req *http.Request) {
-	ctx := req.Context()
+	// Detect the renderer, so DIDL responses can apply its device profile
+	ctx := detectRendererProfile(req.Context(), req)
+	trackClient(req, rendererProfileFrom(ctx))
+
+	// Run the request as the configured DLNA user, so annotations, playlists
+	// and library scope are well-defined
+	ctx = r.annotationContext(ctx)
 
 	// Read request body
 	body, err := io.ReadAll(req.Body)
@@ -79,25 +87,35 @@ func (r *Router) handleContentDirectoryControl(w http.ResponseWriter, req *http.
 	log.Debug(ctx, "ContentDirectory request", "action", action)
 
 	// Route to appropriate handler
+	start := time.Now()
 	var response interface{}
 	switch action {
 	case "Browse":
 		response, err = r.handleBrowse(ctx, envelope.Body.Content)
+	case "Search":
+		response, err = r.handleSearch(ctx, envelope.Body.Content)
 	case "GetSearchCapabilities":
 		response, err = r.handleGetSearchCapabilities(ctx)
 	case "GetSortCapabilities":
 		response, err = r.handleGetSortCapabilities(ctx)
 	case "GetSystemUpdateID":
 		response, err = r.handleGetSystemUpdateID(ctx)
+	case "X_GetFeatureList":
+		response, err = r.handleXGetFeatureList(ctx)
 	default:
 		log.Warn(ctx, "Unknown ContentDirectory action", "action", action)
 		r.writeSOAPFault(w, upnpErrorInvalidAction, fmt.Sprintf("Unknown action: %s", action))
 		return
 	}
+	r.metrics.RecordDLNARequest(ctx, action, err == nil, time.Since(start).Milliseconds())
 
 	if err != nil {
 		log.Error(ctx, "ContentDirectory action failed", err, "action", action)
-		r.writeSOAPFault(w, upnpErrorActionFailed, err.Error())
+		if errors.Is(err, errInvalidArgs) {
+			r.writeSOAPFault(w, upnpErrorInvalidArgs, err.Error())
+		} else {
+			r.writeSOAPFault(w, upnpErrorActionFailed, err.Error())
+		}
 		return
 	}
 
@@ -131,6 +149,7 @@ func (r *Router) handleConnectionManagerControl(w http.ResponseWriter, req *http
 	log.Debug(ctx, "ConnectionManager request", "action", action)
 
 	// Route to appropriate handler
+	start := time.Now()
 	var response interface{}
 	switch action {
 	case "GetProtocolInfo":
@@ -144,6 +163,7 @@ func (r *Router) handleConnectionManagerControl(w http.ResponseWriter, req *http
 		r.writeSOAPFault(w, upnpErrorInvalidAction, fmt.Sprintf("Unknown action: %s", action))
 		return
 	}
+	r.metrics.RecordDLNARequest(ctx, action, err == nil, time.Since(start).Milliseconds())
 
 	if err != nil {
 		log.Error(ctx, "ConnectionManager action failed", err, "action", action)
@@ -12,9 +12,9 @@ import (
 
 // DeviceDescription is the root element of device.xml
 type DeviceDescription struct {
-	XMLName     xml.Name `xml:"urn:schemas-upnp-org:device-1-0 root"`
-	SpecVersion SpecVersion
-	Device      Device
+	XMLName     xml.Name    `xml:"urn:schemas-upnp-org:device-1-0 root"`
+	SpecVersion SpecVersion `xml:"specVersion"`
+	Device      Device      `xml:"device"`
 }
 
 // SpecVersion defines the UPnP spec version
@@ -25,19 +25,19 @@ type SpecVersion struct {
 
 // Device describes the DLNA media server
 type Device struct {
-	DeviceType       string    `xml:"deviceType"`
-	FriendlyName     string    `xml:"friendlyName"`
-	Manufacturer     string    `xml:"manufacturer"`
-	ManufacturerURL  string    `xml:"manufacturerURL,omitempty"`
-	ModelDescription string    `xml:"modelDescription,omitempty"`
-	ModelName        string    `xml:"modelName"`
-	ModelNumber      string    `xml:"modelNumber,omitempty"`
-	ModelURL         string    `xml:"modelURL,omitempty"`
-	SerialNumber     string    `xml:"serialNumber,omitempty"`
-	UDN              string    `xml:"UDN"`
-	IconList         *IconList `xml:"iconList,omitempty"`
-	ServiceList      ServiceList
-	PresentationURL  string `xml:"presentationURL,omitempty"`
+	DeviceType       string      `xml:"deviceType"`
+	FriendlyName     string      `xml:"friendlyName"`
+	Manufacturer     string      `xml:"manufacturer"`
+	ManufacturerURL  string      `xml:"manufacturerURL,omitempty"`
+	ModelDescription string      `xml:"modelDescription,omitempty"`
+	ModelName        string      `xml:"modelName"`
+	ModelNumber      string      `xml:"modelNumber,omitempty"`
+	ModelURL         string      `xml:"modelURL,omitempty"`
+	SerialNumber     string      `xml:"serialNumber,omitempty"`
+	UDN              string      `xml:"UDN"`
+	IconList         *IconList   `xml:"iconList,omitempty"`
+	ServiceList      ServiceList `xml:"serviceList"`
+	PresentationURL  string      `xml:"presentationURL,omitempty"`
 }
 
 // IconList contains device icons
@@ -85,10 +85,7 @@ func (r *Router) handleDeviceDescription(w http.ResponseWriter, req *http.Reques
 			ModelURL:         "https://www.navidrome.org",
 			UDN:              r.uuid,
 			IconList: &IconList{
-				Icons: []Icon{
-					{MIMEType: "image/png", Width: 48, Height: 48, Depth: 24, URL: fmt.Sprintf("%s/dlna/icon/48.png", baseURL)},
-					{MIMEType: "image/png", Width: 120, Height: 120, Depth: 24, URL: fmt.Sprintf("%s/dlna/icon/120.png", baseURL)},
-				},
+				Icons: deviceIcons(baseURL),
 			},
 			ServiceList: ServiceList{
 				Services: []Service{
@@ -97,7 +94,7 @@ func (r *Router) handleDeviceDescription(w http.ResponseWriter, req *http.Reques
 						ServiceID:   "urn:upnp-org:serviceId:ContentDirectory",
 						SCPDURL:     fmt.Sprintf("%s/dlna/ContentDirectory.xml", baseURL),
 						ControlURL:  fmt.Sprintf("%s/dlna/ContentDirectory/control", baseURL),
-						EventSubURL: "",
+						EventSubURL: fmt.Sprintf("%s/dlna/ContentDirectory/events", baseURL),
 					},
 					{
 						ServiceType: connectionManagerType,
@@ -106,6 +103,34 @@ func (r *Router) handleDeviceDescription(w http.ResponseWriter, req *http.Reques
 						ControlURL:  fmt.Sprintf("%s/dlna/ConnectionManager/control", baseURL),
 						EventSubURL: "",
 					},
+					{
+						ServiceType: mediaReceiverRegistrarType,
+						ServiceID:   "urn:microsoft.com:serviceId:X_MS_MediaReceiverRegistrar",
+						SCPDURL:     fmt.Sprintf("%s/dlna/MediaReceiverRegistrar.xml", baseURL),
+						ControlURL:  fmt.Sprintf("%s/dlna/MediaReceiverRegistrar/control", baseURL),
+						EventSubURL: "",
+					},
+					{
+						ServiceType: openHomeProductType,
+						ServiceID:   "urn:av-openhome-org:serviceId:Product",
+						SCPDURL:     fmt.Sprintf("%s/dlna/OpenHomeProduct.xml", baseURL),
+						ControlURL:  fmt.Sprintf("%s/dlna/OpenHomeProduct/control", baseURL),
+						EventSubURL: "",
+					},
+					{
+						ServiceType: openHomeInfoType,
+						ServiceID:   "urn:av-openhome-org:serviceId:Info",
+						SCPDURL:     fmt.Sprintf("%s/dlna/OpenHomeInfo.xml", baseURL),
+						ControlURL:  fmt.Sprintf("%s/dlna/OpenHomeInfo/control", baseURL),
+						EventSubURL: "",
+					},
+					{
+						ServiceType: openHomePlaylistType,
+						ServiceID:   "urn:av-openhome-org:serviceId:Playlist",
+						SCPDURL:     fmt.Sprintf("%s/dlna/OpenHomePlaylist.xml", baseURL),
+						ControlURL:  fmt.Sprintf("%s/dlna/OpenHomePlaylist/control", baseURL),
+						EventSubURL: "",
+					},
 				},
 			},
 			PresentationURL: baseURL + "/",
@@ -135,15 +160,6 @@ func (r *Router) handleConnectionManagerDescription(w http.ResponseWriter, req *
 	w.Write([]byte(connectionManagerSCPD))
 }
 
-// handleIcon serves device icons
-func (r *Router) handleIcon(w http.ResponseWriter, req *http.Request) {
-	// Return a simple placeholder response for now
-	// In production, this would serve actual icon images
-	w.Header().Set("Content-Type", "image/png")
-	w.WriteHeader(http.StatusOK)
-	// TODO: Serve actual icon from resources
-}
-
 // getBaseURL returns the base URL for device description URLs
 func (r *Router) getBaseURL(req *http.Request) string {
 	scheme := "http"
@@ -217,6 +233,61 @@ var contentDirectorySCPD = `<?xml version="1.0" encoding="utf-8"?>
         </argument>
       </argumentList>
     </action>
+    <action>
+      <name>Search</name>
+      <argumentList>
+        <argument>
+          <name>ContainerID</name>
+          <direction>in</direction>
+          <relatedStateVariable>A_ARG_TYPE_ObjectID</relatedStateVariable>
+        </argument>
+        <argument>
+          <name>SearchCriteria</name>
+          <direction>in</direction>
+          <relatedStateVariable>A_ARG_TYPE_SearchCriteria</relatedStateVariable>
+        </argument>
+        <argument>
+          <name>Filter</name>
+          <direction>in</direction>
+          <relatedStateVariable>A_ARG_TYPE_Filter</relatedStateVariable>
+        </argument>
+        <argument>
+          <name>StartingIndex</name>
+          <direction>in</direction>
+          <relatedStateVariable>A_ARG_TYPE_Index</relatedStateVariable>
+        </argument>
+        <argument>
+          <name>RequestedCount</name>
+          <direction>in</direction>
+          <relatedStateVariable>A_ARG_TYPE_Count</relatedStateVariable>
+        </argument>
+        <argument>
+          <name>SortCriteria</name>
+          <direction>in</direction>
+          <relatedStateVariable>A_ARG_TYPE_SortCriteria</relatedStateVariable>
+        </argument>
+        <argument>
+          <name>Result</name>
+          <direction>out</direction>
+          <relatedStateVariable>A_ARG_TYPE_Result</relatedStateVariable>
+        </argument>
+        <argument>
+          <name>NumberReturned</name>
+          <direction>out</direction>
+          <relatedStateVariable>A_ARG_TYPE_Count</relatedStateVariable>
+        </argument>
+        <argument>
+          <name>TotalMatches</name>
+          <direction>out</direction>
+          <relatedStateVariable>A_ARG_TYPE_Count</relatedStateVariable>
+        </argument>
+        <argument>
+          <name>UpdateID</name>
+          <direction>out</direction>
+          <relatedStateVariable>A_ARG_TYPE_UpdateID</relatedStateVariable>
+        </argument>
+      </argumentList>
+    </action>
     <action>
       <name>GetSearchCapabilities</name>
       <argumentList>
@@ -247,6 +318,16 @@ var contentDirectorySCPD = `<?xml version="1.0" encoding="utf-8"?>
         </argument>
       </argumentList>
     </action>
+    <action>
+      <name>X_GetFeatureList</name>
+      <argumentList>
+        <argument>
+          <name>FeatureList</name>
+          <direction>out</direction>
+          <relatedStateVariable>A_ARG_TYPE_Featurelist</relatedStateVariable>
+        </argument>
+      </argumentList>
+    </action>
   </actionList>
   <serviceStateTable>
     <stateVariable sendEvents="no">
@@ -273,6 +354,10 @@ var contentDirectorySCPD = `<?xml version="1.0" encoding="utf-8"?>
       <name>A_ARG_TYPE_SortCriteria</name>
       <dataType>string</dataType>
     </stateVariable>
+    <stateVariable sendEvents="no">
+      <name>A_ARG_TYPE_SearchCriteria</name>
+      <dataType>string</dataType>
+    </stateVariable>
     <stateVariable sendEvents="no">
       <name>A_ARG_TYPE_Index</name>
       <dataType>ui4</dataType>
@@ -289,6 +374,10 @@ var contentDirectorySCPD = `<?xml version="1.0" encoding="utf-8"?>
       <name>SystemUpdateID</name>
       <dataType>ui4</dataType>
     </stateVariable>
+    <stateVariable sendEvents="yes">
+      <name>ContainerUpdateIDs</name>
+      <dataType>string</dataType>
+    </stateVariable>
     <stateVariable sendEvents="no">
       <name>SearchCapabilities</name>
       <dataType>string</dataType>
@@ -297,6 +386,10 @@ var contentDirectorySCPD = `<?xml version="1.0" encoding="utf-8"?>
       <name>SortCapabilities</name>
       <dataType>string</dataType>
     </stateVariable>
+    <stateVariable sendEvents="no">
+      <name>A_ARG_TYPE_Featurelist</name>
+      <dataType>string</dataType>
+    </stateVariable>
   </serviceStateTable>
 </scpd>`
 
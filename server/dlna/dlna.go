@@ -4,62 +4,118 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/core"
 	"github.com/navidrome/navidrome/core/artwork"
+	"github.com/navidrome/navidrome/core/ffmpeg"
+	"github.com/navidrome/navidrome/core/metrics"
 	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/utils/cache"
 )
 
 const (
 	// SSDP multicast address and port
 	ssdpAddr = "239.255.255.250:1900"
+	// IPv6 link-local SSDP multicast address
+	ssdpAddrV6 = "[ff02::c]:1900"
 	// UPnP device type for media server
 	deviceType = "urn:schemas-upnp-org:device:MediaServer:1"
 	// UPnP service types
 	contentDirectoryType  = "urn:schemas-upnp-org:service:ContentDirectory:1"
 	connectionManagerType = "urn:schemas-upnp-org:service:ConnectionManager:1"
+	// Microsoft-specific service required by Xbox and Windows Media Player
+	mediaReceiverRegistrarType = "urn:microsoft.com:service:X_MS_MediaReceiverRegistrar:1"
+	// OpenHome services, looked for by Linn/Lumin/Kazoo control points
+	openHomeProductType  = "urn:av-openhome-org:service:Product:1"
+	openHomeInfoType     = "urn:av-openhome-org:service:Info:1"
+	openHomePlaylistType = "urn:av-openhome-org:service:Playlist:1"
 )
 
 // Router handles DLNA/UPnP requests
 type Router struct {
-	ds         model.DataStore
-	artwork    artwork.Artwork
-	serverName string
-	uuid       string
-	httpPort   int
-	interfaces []net.Interface
-	ssdpConn   *net.UDPConn
-	mu         sync.RWMutex
-	running    bool
-	ctx        context.Context
-	cancel     context.CancelFunc
+	ds          model.DataStore
+	artwork     artwork.Artwork
+	streamer    core.MediaStreamer
+	ffmpeg      ffmpeg.FFmpeg
+	metrics     metrics.Metrics
+	serverName  string
+	uuid        string
+	bootID      uint32
+	httpPort    int
+	interfaces  []net.Interface
+	ssdpSockets []*ssdpSocket
+	mu          sync.RWMutex
+	running     bool
+	ctx         context.Context
+	cancel      context.CancelFunc
+
+	// GENA eventing state
+	updateID      atomic.Uint32
+	subMu         sync.Mutex
+	subscriptions map[string]*subscription
+
+	// Per-container update IDs, so clients only re-browse what changed
+	containerMu  sync.Mutex
+	containerIDs map[string]uint32
+	changedIDs   map[string]uint32
+
+	// Short-lived cache of rendered Browse responses
+	browseCache cache.SimpleCache[string, *BrowseResponse]
+
+	// SSDP traffic counters, summarized when DLNA.DebugSSDP is enabled
+	stats ssdpStats
+}
+
+// GENA uses its own HTTP methods, which chi must know about to route them
+func init() {
+	chi.RegisterMethod("SUBSCRIBE")
+	chi.RegisterMethod("UNSUBSCRIBE")
 }
 
 // New creates a new DLNA router
-func New(ds model.DataStore, artwork artwork.Artwork) *Router {
+func New(ds model.DataStore, artwork artwork.Artwork, streamer core.MediaStreamer, ffmpeg ffmpeg.FFmpeg, metrics metrics.Metrics) *Router {
 	serverName := conf.Server.DLNA.ServerName
 	if serverName == "" {
 		serverName = "Navidrome"
 	}
 
-	// Generate a stable UUID based on server config
-	uuid := generateUUID(serverName, conf.Server.Port)
-
-	return &Router{
-		ds:         ds,
-		artwork:    artwork,
-		serverName: serverName,
-		uuid:       uuid,
-		httpPort:   conf.Server.Port,
+	// Load (or generate once) the persistent UUID identifying this server
+	uuid := serverUUID(ds)
+
+	r := &Router{
+		ds:            newLibraryScopedDataStore(ds),
+		artwork:       artwork,
+		streamer:      streamer,
+		ffmpeg:        ffmpeg,
+		metrics:       metrics,
+		serverName:    serverName,
+		uuid:          uuid,
+		bootID:        nextBootID(ds),
+		httpPort:      conf.Server.Port,
+		subscriptions: map[string]*subscription{},
+		containerIDs:  map[string]uint32{},
+		changedIDs:    map[string]uint32{},
+		browseCache:   cache.NewSimpleCache[string, *BrowseResponse](cache.Options{SizeLimit: 1000, DefaultTTL: 5 * time.Minute}),
 	}
+	r.updateID.Store(1)
+	return r
 }
 
 // Routes returns the chi router for DLNA HTTP endpoints
 func (r *Router) Routes() chi.Router {
 	router := chi.NewRouter()
+	router.Use(r.accessControl)
 
 	// Device description
 	router.Get("/device.xml", r.handleDeviceDescription)
@@ -67,13 +123,35 @@ func (r *Router) Routes() chi.Router {
 	// ContentDirectory service
 	router.Get("/ContentDirectory.xml", r.handleContentDirectoryDescription)
 	router.Post("/ContentDirectory/control", r.handleContentDirectoryControl)
+	router.Method("SUBSCRIBE", "/ContentDirectory/events", http.HandlerFunc(r.handleContentDirectoryEvents))
+	router.Method("UNSUBSCRIBE", "/ContentDirectory/events", http.HandlerFunc(r.handleContentDirectoryEvents))
 
 	// ConnectionManager service
 	router.Get("/ConnectionManager.xml", r.handleConnectionManagerDescription)
 	router.Post("/ConnectionManager/control", r.handleConnectionManagerControl)
 
+	// X_MS_MediaReceiverRegistrar service (Xbox/Windows Media Player)
+	router.Get("/MediaReceiverRegistrar.xml", r.handleMediaReceiverRegistrarDescription)
+	router.Post("/MediaReceiverRegistrar/control", r.handleMediaReceiverRegistrarControl)
+
+	// OpenHome services (Linn/Lumin/Kazoo control points)
+	router.Get("/OpenHomeProduct.xml", r.handleOpenHomeProductDescription)
+	router.Post("/OpenHomeProduct/control", r.handleOpenHomeProductControl)
+	router.Get("/OpenHomeInfo.xml", r.handleOpenHomeInfoDescription)
+	router.Post("/OpenHomeInfo/control", r.handleOpenHomeInfoControl)
+	router.Get("/OpenHomePlaylist.xml", r.handleOpenHomePlaylistDescription)
+	router.Post("/OpenHomePlaylist/control", r.handleOpenHomePlaylistControl)
+
 	// Icons
 	router.Get("/icon/{size}.png", r.handleIcon)
+	router.Get("/icon/{size}.jpg", r.handleIcon)
+
+	// Media streaming
+	router.Get("/stream/{id}", r.handleStream)
+	router.Head("/stream/{id}", r.handleStream)
+
+	// Folder images (the scanner-recorded image files of library folders)
+	router.Get("/folderimage/{folderID}/{name}", r.handleFolderImage)
 
 	return router
 }
@@ -104,6 +182,16 @@ func (r *Router) Start(ctx context.Context) error {
 	// Send initial announcements
 	r.announcePresence()
 
+	// Watch the library for changes, driving SystemUpdateID and GENA events
+	go r.watchLibraryChanges()
+
+	// Watch for interface/address changes, rebinding SSDP when they happen
+	go r.watchNetworkChanges()
+
+	if ssdpDebugEnabled() {
+		go r.logSSDPStats()
+	}
+
 	log.Info(r.ctx, "DLNA server started", "name", r.serverName, "uuid", r.uuid)
 
 	return nil
@@ -112,32 +200,65 @@ func (r *Router) Start(ctx context.Context) error {
 // Stop halts SSDP announcements and closes connections
 func (r *Router) Stop() {
 	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	if !r.running {
+		r.mu.Unlock()
 		return
 	}
+	r.running = false
+	cancel := r.cancel
+	r.mu.Unlock()
 
-	// Send byebye notifications
+	// Send byebye notifications while the sockets are still open. This must
+	// happen outside the lock - sendNotify snapshots the socket list under it
 	r.sendByeBye()
 
-	if r.cancel != nil {
-		r.cancel()
+	if cancel != nil {
+		cancel()
 	}
 
-	if r.ssdpConn != nil {
-		r.ssdpConn.Close()
+	r.mu.Lock()
+	for _, s := range r.ssdpSockets {
+		s.conn.Close()
 	}
+	r.ssdpSockets = nil
+	r.mu.Unlock()
 
-	r.running = false
 	log.Info("DLNA server stopped")
 }
 
-// generateUUID creates a stable UUID for this server instance
-func generateUUID(serverName string, port int) string {
-	// Use a combination of server name and port for stability
-	// In production, this should be persisted to maintain the same UUID across restarts
-	return fmt.Sprintf("uuid:navidrome-%s-%d", serverName, port)
+// serverUUID returns the UUID identifying this server on the network. It is
+// generated once and persisted in the property table, so the server keeps its
+// identity across restarts and config changes - renaming the server or moving
+// it to another port must not create a duplicate entry on TVs
+func serverUUID(ds model.DataStore) string {
+	ctx := context.Background()
+	stored, err := ds.Property(ctx).DefaultGet(consts.DLNAServerUUIDKey, "")
+	if err == nil && stored != "" {
+		return stored
+	}
+
+	newUUID := "uuid:" + uuid.NewString()
+	if err := ds.Property(ctx).Put(consts.DLNAServerUUIDKey, newUUID); err != nil {
+		log.Warn(ctx, "Failed to persist DLNA server UUID - a new one will be generated on restart", err)
+	}
+	return newUUID
+}
+
+// nextBootID increments and persists the BOOTID.UPNP.ORG value. UPnP requires
+// it to grow on every restart, so control points notice the device rebooted
+// and refresh any cached state
+func nextBootID(ds model.DataStore) uint32 {
+	ctx := context.Background()
+	stored, err := ds.Property(ctx).DefaultGet(consts.DLNABootIDKey, "0")
+	if err != nil {
+		stored = "0"
+	}
+	last, _ := strconv.Atoi(stored)
+	bootID := uint32(last) + 1
+	if err := ds.Property(ctx).Put(consts.DLNABootIDKey, strconv.Itoa(int(bootID))); err != nil {
+		log.Warn(ctx, "Failed to persist DLNA boot ID", err)
+	}
+	return bootID
 }
 
 // getActiveInterfaces returns network interfaces that are up and have addresses
@@ -148,12 +269,18 @@ func getActiveInterfaces() ([]net.Interface, error) {
 	}
 
 	var active []net.Interface
+	selected := selectedInterfaceNames()
 	for _, iface := range ifaces {
 		// Skip loopback and down interfaces
 		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
 			continue
 		}
 
+		// When the DLNA.Interface option is set, only advertise on those
+		if selected != nil && !selected[iface.Name] {
+			continue
+		}
+
 		// Check if interface has usable addresses
 		addrs, err := iface.Addrs()
 		if err != nil {
@@ -173,6 +300,21 @@ func getActiveInterfaces() ([]net.Interface, error) {
 	return active, nil
 }
 
+// selectedInterfaceNames parses the DLNA.Interface option (comma-separated
+// interface names) into a set. A nil set means no restriction
+func selectedInterfaceNames() map[string]bool {
+	if conf.Server.DLNA.Interface == "" {
+		return nil
+	}
+	selected := map[string]bool{}
+	for _, name := range strings.Split(conf.Server.DLNA.Interface, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			selected[name] = true
+		}
+	}
+	return selected
+}
+
 // getLocalIP returns the first non-loopback IPv4 address
 func getLocalIP() string {
 	ifaces, err := getActiveInterfaces()
@@ -199,9 +341,14 @@ func getLocalIP() string {
 
 // getAlbumArtURL returns the URL for album artwork
 func (r *Router) getAlbumArtURL(albumID string) string {
+	return r.getAlbumArtURLSized(albumID, 300)
+}
+
+// getAlbumArtURLSized returns the URL for album artwork at a specific size
+func (r *Router) getAlbumArtURLSized(albumID string, size int) string {
 	baseURL := conf.Server.BaseURL
 	if baseURL == "" {
 		baseURL = fmt.Sprintf("http://%s:%d", getLocalIP(), r.httpPort)
 	}
-	return fmt.Sprintf("%s/rest/getCoverArt?id=%s&size=300", baseURL, albumID)
+	return fmt.Sprintf("%s/rest/getCoverArt?id=%s&size=%d", baseURL, albumID, size)
 }
@@ -0,0 +1,183 @@
+package dlna_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/core/metrics"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/server/dlna"
+	"github.com/navidrome/navidrome/server/dlna/dlnatest"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// These tests drive the DLNA server the way a renderer would: over HTTP with
+// SOAP requests, parsing the XML that comes back. The datastore is mocked, so
+// they cover routing, XML structure and pagination, not queries
+
+var _ = Describe("DLNA server", func() {
+	var ts *httptest.Server
+	var router *dlna.Router
+	var cp *dlnatest.ControlPoint
+
+	BeforeEach(func() {
+		ds := &tests.MockDataStore{}
+		mfRepo := tests.CreateMockMediaFileRepo()
+		mfRepo.SetData(model.MediaFiles{
+			{ID: "1", Title: "First Track", Artist: "The Artist", Album: "The Album", AlbumID: "al-1", Suffix: "mp3", Duration: 180},
+			{ID: "2", Title: "Second Track", Artist: "The Artist", Album: "The Album", AlbumID: "al-1", Suffix: "mp3", Duration: 240},
+		})
+		ds.MockedMediaFile = mfRepo
+		albumRepo := tests.CreateMockAlbumRepo()
+		albumRepo.SetData(model.Albums{
+			{ID: "al-1", Name: "The Album", AlbumArtist: "The Artist", SongCount: 2},
+		})
+		ds.MockedAlbum = albumRepo
+
+		router = dlna.New(ds, nil, nil, tests.NewMockFFmpeg(""), metrics.NewNoopInstance())
+		r := chi.NewRouter()
+		r.Mount("/dlna", router.Routes())
+		ts = httptest.NewServer(r)
+		cp = dlnatest.NewControlPoint(ts.URL)
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	Describe("device description", func() {
+		It("describes the server and its services", func() {
+			desc, err := cp.DeviceDescription()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(desc.FriendlyName).ToNot(BeEmpty())
+			Expect(desc.UDN).To(HavePrefix("uuid:"))
+
+			var types []string
+			for _, svc := range desc.Services {
+				types = append(types, svc.ServiceType)
+			}
+			Expect(types).To(ContainElements(
+				"urn:schemas-upnp-org:service:ContentDirectory:1",
+				"urn:schemas-upnp-org:service:ConnectionManager:1",
+			))
+		})
+
+		It("serves an SCPD with the implemented actions for every service", func() {
+			desc, err := cp.DeviceDescription()
+			Expect(err).ToNot(HaveOccurred())
+			for _, svc := range desc.Services {
+				scpd, err := cp.ServiceDescription(svc.SCPDURL)
+				Expect(err).ToNot(HaveOccurred(), "SCPD for %s", svc.ServiceType)
+				Expect(scpd.Actions).ToNot(BeEmpty(), "SCPD for %s", svc.ServiceType)
+				if svc.ServiceType == "urn:schemas-upnp-org:service:ContentDirectory:1" {
+					Expect(scpd.Actions).To(ContainElements("Browse", "Search"))
+				}
+			}
+		})
+	})
+
+	Describe("Browse", func() {
+		It("returns the Music container at the root", func() {
+			result, err := cp.Browse("0", "BrowseDirectChildren", 0, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.NumberReturned).To(Equal(1))
+			Expect(result.DIDL.Containers).To(HaveLen(1))
+			Expect(result.DIDL.Containers[0].ID).To(Equal("music"))
+			Expect(result.DIDL.Containers[0].Title).To(Equal("Music"))
+		})
+
+		It("lists the category containers under Music", func() {
+			result, err := cp.Browse("music", "BrowseDirectChildren", 0, 100)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.NumberReturned).To(Equal(result.TotalMatches))
+			var ids []string
+			for _, c := range result.DIDL.Containers {
+				Expect(c.ParentID).To(Equal("music"))
+				ids = append(ids, c.ID)
+			}
+			Expect(ids).To(ContainElements("music/artists", "music/albums", "music/playlists"))
+		})
+
+		It("paginates direct children", func() {
+			all, err := cp.Browse("music", "BrowseDirectChildren", 0, 100)
+			Expect(err).ToNot(HaveOccurred())
+
+			page, err := cp.Browse("music", "BrowseDirectChildren", 2, 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.NumberReturned).To(Equal(3))
+			Expect(page.TotalMatches).To(Equal(all.TotalMatches))
+			Expect(page.DIDL.Containers[0].ID).To(Equal(all.DIDL.Containers[2].ID))
+		})
+
+		It("applies the default page size when RequestedCount is zero", func() {
+			result, err := cp.Browse("music", "BrowseDirectChildren", 0, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.NumberReturned).To(Equal(result.TotalMatches))
+		})
+
+		It("rejects negative paging values with UPnP error 402", func() {
+			_, err := cp.Browse("music", "BrowseDirectChildren", -1, 10)
+			Expect(err).To(MatchError(ContainSubstring("UPnP error 402")))
+		})
+
+		It("lists album tracks as musicTrack items with resources", func() {
+			result, err := cp.Browse("album/al-1", "BrowseDirectChildren", 0, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.DIDL.Items).To(HaveLen(2))
+			for _, item := range result.DIDL.Items {
+				Expect(item.Class).To(Equal("object.item.audioItem.musicTrack"))
+				Expect(item.Resources).ToNot(BeEmpty())
+				Expect(item.Resources[0].URL).To(ContainSubstring("/dlna/stream/"))
+				Expect(item.Resources[0].ProtocolInfo).To(HavePrefix("http-get:"))
+			}
+		})
+	})
+
+	Describe("Search", func() {
+		It("finds tracks by upnp:class", func() {
+			result, err := cp.Search("0", `upnp:class derivedfrom "object.item.audioItem" and dc:title contains "Track"`, 0, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.NumberReturned).To(Equal(2))
+			Expect(result.DIDL.Items[0].Class).To(Equal("object.item.audioItem.musicTrack"))
+		})
+	})
+
+	Describe("GetProtocolInfo", func() {
+		It("advertises the supported source protocols", func() {
+			source, err := cp.GetProtocolInfo()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(source).To(ContainSubstring("audio/mpeg"))
+			Expect(source).To(ContainSubstring("audio/flac"))
+		})
+	})
+
+	Describe("SSDP discovery", func() {
+		It("answers M-SEARCH with the device location", func() {
+			if err := router.Start(context.Background()); err != nil {
+				Skip("SSDP not available in this environment: " + err.Error())
+			}
+			defer router.Stop()
+
+			devices, err := dlnatest.Discover(2 * time.Second)
+			Expect(err).ToNot(HaveOccurred())
+
+			desc, err := cp.DeviceDescription()
+			Expect(err).ToNot(HaveOccurred())
+			found := false
+			for _, d := range devices {
+				if strings.HasPrefix(d.USN, desc.UDN) {
+					found = true
+					Expect(d.Location).To(ContainSubstring("/dlna/device.xml"))
+				}
+			}
+			if !found {
+				Skip("multicast traffic not routed in this environment")
+			}
+		})
+	})
+})
@@ -0,0 +1,333 @@
+// Package dlnatest implements a minimal UPnP control point for exercising the
+// DLNA server in tests: SSDP discovery, device/service descriptions, and the
+// ContentDirectory/ConnectionManager SOAP actions. It parses responses the way
+// a renderer would, so regressions in XML structure are caught end-to-end
+package dlnatest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ControlPoint talks to a DLNA server over HTTP, like a renderer or control
+// point app would. BaseURL is the server root, without the /dlna prefix
+type ControlPoint struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewControlPoint creates a control point for the server at baseURL
+func NewControlPoint(baseURL string) *ControlPoint {
+	return &ControlPoint{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Client:  http.DefaultClient,
+	}
+}
+
+// DeviceDescription is the parsed device description document
+type DeviceDescription struct {
+	FriendlyName string    `xml:"device>friendlyName"`
+	UDN          string    `xml:"device>UDN"`
+	Services     []Service `xml:"device>serviceList>service"`
+}
+
+// Service is one service entry of the device description
+type Service struct {
+	ServiceType string `xml:"serviceType"`
+	ServiceID   string `xml:"serviceId"`
+	SCPDURL     string `xml:"SCPDURL"`
+	ControlURL  string `xml:"controlURL"`
+}
+
+// DeviceDescription fetches and parses /dlna/device.xml
+func (cp *ControlPoint) DeviceDescription() (*DeviceDescription, error) {
+	body, err := cp.get(cp.BaseURL + "/dlna/device.xml")
+	if err != nil {
+		return nil, err
+	}
+	var desc DeviceDescription
+	if err := xml.Unmarshal(body, &desc); err != nil {
+		return nil, fmt.Errorf("failed to parse device description: %w", err)
+	}
+	return &desc, nil
+}
+
+// SCPD is a parsed service description, reduced to its action names
+type SCPD struct {
+	Actions []string
+}
+
+// ServiceDescription fetches an SCPD document and returns its action names.
+// The URL is taken verbatim from the device description
+func (cp *ControlPoint) ServiceDescription(url string) (*SCPD, error) {
+	body, err := cp.get(url)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Actions []string `xml:"actionList>action>name"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse service description: %w", err)
+	}
+	return &SCPD{Actions: doc.Actions}, nil
+}
+
+// DIDL is a parsed DIDL-Lite document
+type DIDL struct {
+	Containers []Container `xml:"container"`
+	Items      []Item      `xml:"item"`
+}
+
+// Container is a parsed DIDL-Lite container
+type Container struct {
+	ID       string `xml:"id,attr"`
+	ParentID string `xml:"parentID,attr"`
+	Title    string `xml:"title"`
+	Class    string `xml:"class"`
+}
+
+// Item is a parsed DIDL-Lite item
+type Item struct {
+	ID        string `xml:"id,attr"`
+	ParentID  string `xml:"parentID,attr"`
+	Title     string `xml:"title"`
+	Class     string `xml:"class"`
+	Resources []Res  `xml:"res"`
+}
+
+// Res is a parsed DIDL-Lite resource
+type Res struct {
+	ProtocolInfo string `xml:"protocolInfo,attr"`
+	URL          string `xml:",chardata"`
+}
+
+// BrowseResult is a parsed Browse or Search response
+type BrowseResult struct {
+	NumberReturned int
+	TotalMatches   int
+	UpdateID       uint32
+	DIDL           DIDL
+}
+
+const contentDirectoryType = "urn:schemas-upnp-org:service:ContentDirectory:1"
+const connectionManagerType = "urn:schemas-upnp-org:service:ConnectionManager:1"
+
+// Browse performs a ContentDirectory Browse and parses the returned DIDL-Lite
+func (cp *ControlPoint) Browse(objectID, browseFlag string, startIndex, count int) (*BrowseResult, error) {
+	body := fmt.Sprintf(`<u:Browse xmlns:u="%s">
+  <ObjectID>%s</ObjectID>
+  <BrowseFlag>%s</BrowseFlag>
+  <Filter>*</Filter>
+  <StartingIndex>%d</StartingIndex>
+  <RequestedCount>%d</RequestedCount>
+  <SortCriteria></SortCriteria>
+</u:Browse>`, contentDirectoryType, objectID, browseFlag, startIndex, count)
+
+	return cp.contentDirectoryAction("Browse", body, "BrowseResponse")
+}
+
+// Search performs a ContentDirectory Search and parses the returned DIDL-Lite
+func (cp *ControlPoint) Search(containerID, criteria string, startIndex, count int) (*BrowseResult, error) {
+	body := fmt.Sprintf(`<u:Search xmlns:u="%s">
+  <ContainerID>%s</ContainerID>
+  <SearchCriteria>%s</SearchCriteria>
+  <Filter>*</Filter>
+  <StartingIndex>%d</StartingIndex>
+  <RequestedCount>%d</RequestedCount>
+  <SortCriteria></SortCriteria>
+</u:Search>`, contentDirectoryType, containerID, escapeXML(criteria), startIndex, count)
+
+	return cp.contentDirectoryAction("Search", body, "SearchResponse")
+}
+
+// GetProtocolInfo performs a ConnectionManager GetProtocolInfo and returns the
+// advertised source protocols
+func (cp *ControlPoint) GetProtocolInfo() (string, error) {
+	body := fmt.Sprintf(`<u:GetProtocolInfo xmlns:u="%s"></u:GetProtocolInfo>`, connectionManagerType)
+	respBody, err := cp.soapRequest(cp.BaseURL+"/dlna/ConnectionManager/control",
+		connectionManagerType+"#GetProtocolInfo", body)
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Source string `xml:"Body>GetProtocolInfoResponse>Source"`
+	}
+	if err := xml.Unmarshal(respBody, &resp); err != nil {
+		return "", fmt.Errorf("failed to parse GetProtocolInfo response: %w", err)
+	}
+	return resp.Source, nil
+}
+
+// contentDirectoryAction sends one ContentDirectory action and parses the
+// Browse/Search style response, including the embedded DIDL-Lite document
+func (cp *ControlPoint) contentDirectoryAction(action, body, responseElement string) (*BrowseResult, error) {
+	respBody, err := cp.soapRequest(cp.BaseURL+"/dlna/ContentDirectory/control",
+		contentDirectoryType+"#"+action, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// The response element name differs per action, so find it by local name
+	var resp struct {
+		Result         string `xml:"Result"`
+		NumberReturned int    `xml:"NumberReturned"`
+		TotalMatches   int    `xml:"TotalMatches"`
+		UpdateID       uint32 `xml:"UpdateID"`
+	}
+	if err := unmarshalBodyElement(respBody, responseElement, &resp); err != nil {
+		return nil, err
+	}
+
+	result := &BrowseResult{
+		NumberReturned: resp.NumberReturned,
+		TotalMatches:   resp.TotalMatches,
+		UpdateID:       resp.UpdateID,
+	}
+	if resp.Result != "" {
+		if err := xml.Unmarshal([]byte(resp.Result), &result.DIDL); err != nil {
+			return nil, fmt.Errorf("failed to parse DIDL-Lite result: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// soapRequest posts a SOAP envelope and returns the raw response body, turning
+// SOAP faults into errors carrying the UPnP error code
+func (cp *ControlPoint) soapRequest(url, soapAction, body string) ([]byte, error) {
+	envelope := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">
+  <s:Body>
+    %s
+  </s:Body>
+</s:Envelope>`, body)
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(envelope))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	req.Header.Set("SOAPAction", `"`+soapAction+`"`)
+
+	resp, err := cp.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var fault struct {
+			Code        int    `xml:"Body>Fault>detail>UPnPError>errorCode"`
+			Description string `xml:"Body>Fault>detail>UPnPError>errorDescription"`
+		}
+		if err := xml.Unmarshal(respBody, &fault); err == nil && fault.Code != 0 {
+			return nil, fmt.Errorf("UPnP error %d: %s", fault.Code, fault.Description)
+		}
+		return nil, fmt.Errorf("SOAP request failed with status %d", resp.StatusCode)
+	}
+	return respBody, nil
+}
+
+// unmarshalBodyElement decodes the named element inside the SOAP Body into v,
+// matching by local name so the per-action namespaces don't get in the way
+func unmarshalBodyElement(envelope []byte, localName string, v interface{}) error {
+	decoder := xml.NewDecoder(bytes.NewReader(envelope))
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("response element %s not found: %w", localName, err)
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == localName {
+			return decoder.DecodeElement(v, &start)
+		}
+	}
+}
+
+// get fetches a URL, failing on non-200 statuses
+func (cp *ControlPoint) get(url string) ([]byte, error) {
+	resp, err := cp.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// DiscoveredDevice is one SSDP search response
+type DiscoveredDevice struct {
+	Location string
+	USN      string
+	ST       string
+}
+
+// Discover sends an SSDP M-SEARCH for ssdp:all and collects the responses
+// arriving within the timeout. It returns whatever answered - callers filter
+// by USN to find the server under test
+func Discover(timeout time.Duration) ([]DiscoveredDevice, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	msearch := "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: 239.255.255.250:1900\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 1\r\n" +
+		"ST: ssdp:all\r\n" +
+		"\r\n"
+	addr, err := net.ResolveUDPAddr("udp4", "239.255.255.250:1900")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.WriteTo([]byte(msearch), addr); err != nil {
+		return nil, err
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	var devices []DiscoveredDevice
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			break // Deadline reached
+		}
+		msg := string(buf[:n])
+		devices = append(devices, DiscoveredDevice{
+			Location: responseHeader(msg, "LOCATION"),
+			USN:      responseHeader(msg, "USN"),
+			ST:       responseHeader(msg, "ST"),
+		})
+	}
+	return devices, nil
+}
+
+// responseHeader extracts a header value from an SSDP response
+func responseHeader(msg, header string) string {
+	for _, line := range strings.Split(msg, "\r\n") {
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(name), header) {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// escapeXML escapes the characters that would break an XML text node
+func escapeXML(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
@@ -0,0 +1,331 @@
+package dlna
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model/id"
+)
+
+const (
+	// How often the library is checked for changes
+	updateIDPollInterval = 30 * time.Second
+	// Default and maximum GENA subscription duration
+	defaultSubscriptionTimeout = 30 * time.Minute
+)
+
+// subscription is one GENA subscriber to ContentDirectory events
+type subscription struct {
+	sid      string
+	callback string
+	expires  time.Time
+	seq      uint32
+}
+
+// getUpdateID returns the current SystemUpdateID. It increments whenever the
+// library changes, so control points know to refresh their cached listings
+func (r *Router) getUpdateID() uint32 {
+	return r.updateID.Load()
+}
+
+// bumpUpdateID increments the SystemUpdateID and notifies all subscribers
+func (r *Router) bumpUpdateID() {
+	r.updateID.Add(1)
+	r.notifySubscribers()
+}
+
+// containerUpdateID returns the update ID for one container. Containers that
+// never changed share the SystemUpdateID
+func (r *Router) containerUpdateID(containerID string) uint32 {
+	r.containerMu.Lock()
+	id, ok := r.containerIDs[containerID]
+	r.containerMu.Unlock()
+	if !ok {
+		return r.getUpdateID()
+	}
+	return id
+}
+
+// bumpContainers increments the update IDs of the given containers and queues
+// them for the next ContainerUpdateIDs event
+func (r *Router) bumpContainers(containerIDs ...string) {
+	r.containerMu.Lock()
+	defer r.containerMu.Unlock()
+	for _, containerID := range containerIDs {
+		r.containerIDs[containerID]++
+		r.changedIDs[containerID] = r.containerIDs[containerID]
+	}
+}
+
+// takeChangedContainers drains the pending change set into the CSV value of
+// the ContainerUpdateIDs state variable ("containerID,updateID,...")
+func (r *Router) takeChangedContainers() string {
+	r.containerMu.Lock()
+	defer r.containerMu.Unlock()
+	if len(r.changedIDs) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(r.changedIDs))
+	for containerID, updateID := range r.changedIDs {
+		pairs = append(pairs, fmt.Sprintf("%s,%d", containerID, updateID))
+	}
+	r.changedIDs = map[string]uint32{}
+	return strings.Join(pairs, ",")
+}
+
+// watchLibraryChanges polls the libraries' last-updated timestamps and bumps
+// the SystemUpdateID when they move - a scan finished or the library changed.
+// The DLNA server has no direct hook into the scanner, so polling it is
+func (r *Router) watchLibraryChanges() {
+	ticker := time.NewTicker(updateIDPollInterval)
+	defer ticker.Stop()
+
+	lastLibChange := r.libraryLastChange()
+	lastPlaylistCheck := time.Now()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			r.expireSubscriptions()
+			changed := false
+
+			if change := r.libraryLastChange(); change.After(lastLibChange) {
+				lastLibChange = change
+				r.bumpContainers("music", "music/artists", "music/albums", "music/genres")
+				changed = true
+			}
+
+			if playlists := r.changedPlaylists(lastPlaylistCheck); len(playlists) > 0 {
+				r.bumpContainers(playlists...)
+				r.bumpContainers("music/playlists")
+				changed = true
+			}
+			lastPlaylistCheck = time.Now()
+
+			if changed {
+				log.Debug(r.ctx, "Library changed - bumping DLNA SystemUpdateID", "updateID", r.getUpdateID()+1)
+				r.bumpUpdateID()
+			}
+		}
+	}
+}
+
+// changedPlaylists returns the container IDs of playlists modified since the
+// given time
+func (r *Router) changedPlaylists(since time.Time) []string {
+	playlists, err := r.ds.Playlist(r.ctx).GetAll()
+	if err != nil {
+		log.Warn(r.ctx, "Failed to check playlists for changes", err)
+		return nil
+	}
+	var changed []string
+	for _, playlist := range playlists {
+		if playlist.UpdatedAt.After(since) {
+			changed = append(changed, "playlist/"+playlist.ID)
+		}
+	}
+	return changed
+}
+
+// libraryLastChange returns the most recent scan/update time across libraries
+func (r *Router) libraryLastChange() time.Time {
+	libs, err := r.ds.Library(r.ctx).GetAll()
+	if err != nil {
+		log.Warn(r.ctx, "Failed to check libraries for changes", err)
+		return time.Time{}
+	}
+	var last time.Time
+	for _, lib := range libs {
+		if lib.LastScanAt.After(last) {
+			last = lib.LastScanAt
+		}
+		if lib.UpdatedAt.After(last) {
+			last = lib.UpdatedAt
+		}
+	}
+	return last
+}
+
+// handleContentDirectoryEvents handles GENA SUBSCRIBE/UNSUBSCRIBE requests on
+// the ContentDirectory eventSubURL
+func (r *Router) handleContentDirectoryEvents(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case "SUBSCRIBE":
+		r.handleSubscribe(w, req)
+	case "UNSUBSCRIBE":
+		r.handleUnsubscribe(w, req)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// handleSubscribe registers a new subscriber, or renews an existing one when
+// a SID header is given. Per GENA, a new subscriber immediately gets an
+// initial NOTIFY with the current state
+func (r *Router) handleSubscribe(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	timeout := parseSubscriptionTimeout(req.Header.Get("TIMEOUT"))
+
+	// Renewal - the subscriber sends its SID instead of a callback
+	if sid := req.Header.Get("SID"); sid != "" {
+		r.subMu.Lock()
+		sub, ok := r.subscriptions[sid]
+		if ok {
+			sub.expires = time.Now().Add(timeout)
+		}
+		r.subMu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		writeSubscribeResponse(w, sid, timeout)
+		return
+	}
+
+	callback := parseCallbackURL(req.Header.Get("CALLBACK"))
+	if callback == "" || req.Header.Get("NT") != "upnp:event" {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	sub := &subscription{
+		sid:      "uuid:" + id.NewRandom(),
+		callback: callback,
+		expires:  time.Now().Add(timeout),
+	}
+	r.subMu.Lock()
+	r.subscriptions[sub.sid] = sub
+	r.subMu.Unlock()
+
+	log.Debug(ctx, "New DLNA event subscription", "sid", sub.sid, "callback", callback)
+	r.stats.subscriptions.Add(1)
+	r.ssdpDebug("SSDP: event subscription", "sid", sub.sid, "callback", callback, "timeout", timeout)
+	writeSubscribeResponse(w, sub.sid, timeout)
+
+	// Initial event with the current state, as GENA requires
+	go r.sendEventNotify(sub, r.getUpdateID(), "")
+}
+
+// handleUnsubscribe removes a subscriber
+func (r *Router) handleUnsubscribe(w http.ResponseWriter, req *http.Request) {
+	sid := req.Header.Get("SID")
+	r.subMu.Lock()
+	_, ok := r.subscriptions[sid]
+	delete(r.subscriptions, sid)
+	r.subMu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	log.Debug(req.Context(), "DLNA event subscription cancelled", "sid", sid)
+	r.stats.subscriptions.Add(1)
+	r.ssdpDebug("SSDP: event subscription cancelled", "sid", sid)
+	w.WriteHeader(http.StatusOK)
+}
+
+// notifySubscribers sends the current SystemUpdateID - plus which containers
+// changed - to every subscriber
+func (r *Router) notifySubscribers() {
+	updateID := r.getUpdateID()
+	containers := r.takeChangedContainers()
+	r.subMu.Lock()
+	subs := make([]*subscription, 0, len(r.subscriptions))
+	for _, sub := range r.subscriptions {
+		subs = append(subs, sub)
+	}
+	r.subMu.Unlock()
+
+	for _, sub := range subs {
+		go r.sendEventNotify(sub, updateID, containers)
+	}
+}
+
+// sendEventNotify delivers one GENA NOTIFY request to a subscriber. Failures are
+// logged only - a dead subscriber is cleaned up when its subscription expires
+func (r *Router) sendEventNotify(sub *subscription, updateID uint32, containers string) {
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<e:propertyset xmlns:e="urn:schemas-upnp-org:event-1-0">
+  <e:property>
+    <SystemUpdateID>%d</SystemUpdateID>
+  </e:property>
+  <e:property>
+    <ContainerUpdateIDs>%s</ContainerUpdateIDs>
+  </e:property>
+</e:propertyset>`, updateID, containers)
+
+	req, err := http.NewRequest("NOTIFY", sub.callback, strings.NewReader(body))
+	if err != nil {
+		log.Warn(r.ctx, "Failed to build NOTIFY request", "callback", sub.callback, err)
+		return
+	}
+
+	r.subMu.Lock()
+	seq := sub.seq
+	sub.seq++
+	r.subMu.Unlock()
+
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	req.Header.Set("NT", "upnp:event")
+	req.Header.Set("NTS", "upnp:propchange")
+	req.Header.Set("SID", sub.sid)
+	req.Header.Set("SEQ", strconv.FormatUint(uint64(seq), 10))
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Debug(r.ctx, "Failed to notify DLNA subscriber", "sid", sub.sid, "callback", sub.callback, err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// expireSubscriptions drops subscriptions past their timeout
+func (r *Router) expireSubscriptions() {
+	now := time.Now()
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+	for sid, sub := range r.subscriptions {
+		if now.After(sub.expires) {
+			delete(r.subscriptions, sid)
+		}
+	}
+}
+
+// writeSubscribeResponse writes the headers of a successful (re)subscription
+func writeSubscribeResponse(w http.ResponseWriter, sid string, timeout time.Duration) {
+	w.Header().Set("SID", sid)
+	w.Header().Set("TIMEOUT", fmt.Sprintf("Second-%d", int(timeout.Seconds())))
+	w.WriteHeader(http.StatusOK)
+}
+
+// parseSubscriptionTimeout parses a GENA TIMEOUT header ("Second-1800" or
+// "infinite"), capped at the server's maximum
+func parseSubscriptionTimeout(header string) time.Duration {
+	if secs, ok := strings.CutPrefix(header, "Second-"); ok {
+		if n, err := strconv.Atoi(secs); err == nil && n > 0 &&
+			time.Duration(n)*time.Second < defaultSubscriptionTimeout {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return defaultSubscriptionTimeout
+}
+
+// parseCallbackURL extracts the first URL from a GENA CALLBACK header, which
+// has the form "<http://host/path>" (possibly several, angle-bracketed)
+func parseCallbackURL(header string) string {
+	start := strings.Index(header, "<")
+	end := strings.Index(header, ">")
+	if start < 0 || end <= start+1 {
+		return ""
+	}
+	url := header[start+1 : end]
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return ""
+	}
+	return url
+}
@@ -0,0 +1,133 @@
+package dlna
+
+import (
+	"strings"
+)
+
+// didlFilter is the parsed Filter argument of Browse/Search: the set of
+// properties the client wants in the DIDL result. A nil filter ("*" or empty)
+// means everything
+type didlFilter map[string]struct{}
+
+// parseDIDLFilter parses a comma-separated property list ("dc:creator,res,
+// res@duration") into a filter. Requesting an attribute implies its element
+func parseDIDLFilter(filter string) didlFilter {
+	filter = strings.TrimSpace(filter)
+	if filter == "" || filter == "*" {
+		return nil
+	}
+	f := didlFilter{}
+	for _, prop := range strings.Split(filter, ",") {
+		prop = strings.TrimSpace(prop)
+		if prop == "" {
+			continue
+		}
+		f[prop] = struct{}{}
+		if elem, _, ok := strings.Cut(prop, "@"); ok && elem != "" {
+			f[elem] = struct{}{}
+		}
+	}
+	return f
+}
+
+// allows reports whether a property was requested. Attributes may be given
+// either qualified ("container@childCount") or bare ("@childCount")
+func (f didlFilter) allows(props ...string) bool {
+	if f == nil {
+		return true
+	}
+	for _, prop := range props {
+		if _, ok := f[prop]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// applyDIDLFilter strips the optional DIDL properties the client did not ask
+// for, so picky renderers get the minimal responses the spec promises them.
+// Required properties (@id, @parentID, @restricted, dc:title, upnp:class and
+// res@protocolInfo) are always kept
+func applyDIDLFilter(didl DIDLLite, filter string) DIDLLite {
+	f := parseDIDLFilter(filter)
+	if f == nil {
+		return didl
+	}
+
+	for i := range didl.Containers {
+		c := &didl.Containers[i]
+		if !f.allows("@childCount", "container@childCount") {
+			c.ChildCount = 0
+		}
+		if !f.allows("@searchable", "container@searchable") {
+			c.Searchable = ""
+		}
+		if !f.allows("upnp:albumArtURI") {
+			c.AlbumArtURI = ""
+		}
+	}
+
+	for i := range didl.Items {
+		item := &didl.Items[i]
+		if !f.allows("dc:creator") {
+			item.Creator = ""
+		}
+		if !f.allows("upnp:album") {
+			item.Album = ""
+		}
+		if !f.allows("upnp:artist") {
+			item.Artist = ""
+		}
+		if !f.allows("upnp:albumArtist") {
+			item.AlbumArtist = ""
+		}
+		if !f.allows("dc:date") {
+			item.Date = ""
+		}
+		if !f.allows("upnp:genre") {
+			item.Genres = nil
+		}
+		if !f.allows("dc:contributor") {
+			item.Contributors = nil
+		}
+		if !f.allows("upnp:albumArtURI") {
+			item.AlbumArtURI = ""
+		}
+		if !f.allows("upnp:lastPlaybackPosition") {
+			item.LastPlaybackPosition = ""
+		}
+		if !f.allows("upnp:originalTrackNumber") {
+			item.TrackNumber = 0
+		}
+		if !f.allows("upnp:originalDiscNumber") {
+			item.DiscNumber = 0
+		}
+		if !f.allows("res") {
+			item.Resources = nil
+			continue
+		}
+		for j := range item.Resources {
+			res := &item.Resources[j]
+			if !f.allows("res@size") {
+				res.Size = 0
+			}
+			if !f.allows("res@duration") {
+				res.Duration = ""
+			}
+			if !f.allows("res@bitrate") {
+				res.Bitrate = 0
+			}
+			if !f.allows("res@sampleFrequency") {
+				res.SampleFreq = 0
+			}
+			if !f.allows("res@nrAudioChannels") {
+				res.Channels = 0
+			}
+			if !f.allows("res@resolution") {
+				res.Resolution = ""
+			}
+		}
+	}
+
+	return didl
+}
@@ -0,0 +1,111 @@
+package dlna
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/disintegration/imaging"
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/resources"
+)
+
+// deviceIconSizes are the icon sizes advertised in the device description
+var deviceIconSizes = []int{48, 120, 256}
+
+// Icons are resized and encoded on first use, then kept in memory - they are
+// tiny and requested by every renderer that discovers the server
+var (
+	iconMu    sync.Mutex
+	iconCache = map[string][]byte{}
+)
+
+// handleIcon serves a device icon, resized from the embedded Navidrome logo.
+// Both PNG and JPEG variants are served - some strict clients only accept JPEG
+func (r *Router) handleIcon(w http.ResponseWriter, req *http.Request) {
+	size, err := strconv.Atoi(chi.URLParam(req, "size"))
+	if err != nil || size <= 0 || size > 512 {
+		http.NotFound(w, req)
+		return
+	}
+
+	format := "png"
+	if strings.HasSuffix(req.URL.Path, ".jpg") {
+		format = "jpeg"
+	}
+
+	data, err := deviceIcon(size, format)
+	if err != nil {
+		log.Error(req.Context(), "Failed to render device icon", "size", size, "format", format, err)
+		http.Error(w, "failed to render icon", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/"+format)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// deviceIcon returns the logo resized to the given square size, encoded as
+// "png" or "jpeg"
+func deviceIcon(size int, format string) ([]byte, error) {
+	key := fmt.Sprintf("%s-%d", format, size)
+
+	iconMu.Lock()
+	defer iconMu.Unlock()
+	if data, ok := iconCache[key]; ok {
+		return data, nil
+	}
+
+	f, err := resources.FS().Open("logo-192x192.png")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedded logo: %w", err)
+	}
+	defer f.Close()
+
+	logo, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode embedded logo: %w", err)
+	}
+
+	resized := imaging.Fit(logo, size, size, imaging.Lanczos)
+
+	buf := &bytes.Buffer{}
+	if format == "jpeg" {
+		// JPEG has no alpha channel - flatten onto a white background
+		bg := imaging.New(size, size, color.White)
+		err = jpeg.Encode(buf, imaging.OverlayCenter(bg, resized, 1), nil)
+	} else {
+		err = png.Encode(buf, resized)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode icon: %w", err)
+	}
+
+	iconCache[key] = buf.Bytes()
+	return iconCache[key], nil
+}
+
+// deviceIcons builds the device description icon list, with PNG and JPEG
+// variants of every advertised size
+func deviceIcons(baseURL string) []Icon {
+	var icons []Icon
+	for _, size := range deviceIconSizes {
+		icons = append(icons,
+			Icon{MIMEType: "image/png", Width: size, Height: size, Depth: 24,
+				URL: fmt.Sprintf("%s/dlna/icon/%d.png", baseURL, size)},
+			Icon{MIMEType: "image/jpeg", Width: size, Height: size, Depth: 24,
+				URL: fmt.Sprintf("%s/dlna/icon/%d.jpg", baseURL, size)},
+		)
+	}
+	return icons
+}
@@ -0,0 +1,267 @@
+package dlna
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// newLibraryScopedDataStore wraps a DataStore so every DLNA query only sees
+// the libraries allowed by DLNA.Libraries. The persistence layer's own library
+// filter is user-based and skipped for admins, so the restriction must be
+// enforced here, at the query level - otherwise guessed object or track IDs,
+// search, the dynamic views and the combined music tree would all get around
+// the top-level container filtering
+func newLibraryScopedDataStore(ds model.DataStore) model.DataStore {
+	return &libraryScopedDataStore{DataStore: ds}
+}
+
+type libraryScopedDataStore struct {
+	model.DataStore
+}
+
+// allowedIDs returns the IDs of the libraries exposed over DLNA. The second
+// return is false when DLNA.Libraries is empty and no scoping is needed.
+// Lookup failures return an empty list, failing closed
+func (s *libraryScopedDataStore) allowedIDs(ctx context.Context) ([]int, bool) {
+	if conf.Server.DLNA.Libraries == "" {
+		return nil, false
+	}
+	libs, err := s.DataStore.Library(ctx).GetAll()
+	if err != nil {
+		log.Warn(ctx, "Failed to load libraries for DLNA scoping", err)
+		return []int{}, true
+	}
+	ids := make([]int, 0, len(libs))
+	for _, lib := range libs {
+		if libraryVisible(lib) {
+			ids = append(ids, lib.ID)
+		}
+	}
+	return ids, true
+}
+
+// scopeOptions adds a library restriction on the given column to the query
+// options, preserving whatever filters the caller set
+func scopeOptions(options []model.QueryOptions, column string, ids []int) []model.QueryOptions {
+	scope := squirrel.Eq{column: ids}
+	var opts model.QueryOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Filters == nil {
+		opts.Filters = scope
+	} else {
+		opts.Filters = squirrel.And{opts.Filters, scope}
+	}
+	return []model.QueryOptions{opts}
+}
+
+func (s *libraryScopedDataStore) MediaFile(ctx context.Context) model.MediaFileRepository {
+	if ids, restricted := s.allowedIDs(ctx); restricted {
+		return &scopedMediaFileRepo{s.DataStore.MediaFile(ctx), ids}
+	}
+	return s.DataStore.MediaFile(ctx)
+}
+
+func (s *libraryScopedDataStore) Album(ctx context.Context) model.AlbumRepository {
+	if ids, restricted := s.allowedIDs(ctx); restricted {
+		return &scopedAlbumRepo{s.DataStore.Album(ctx), ids}
+	}
+	return s.DataStore.Album(ctx)
+}
+
+func (s *libraryScopedDataStore) Artist(ctx context.Context) model.ArtistRepository {
+	if ids, restricted := s.allowedIDs(ctx); restricted {
+		return &scopedArtistRepo{s.DataStore.Artist(ctx), ids}
+	}
+	return s.DataStore.Artist(ctx)
+}
+
+func (s *libraryScopedDataStore) Genre(ctx context.Context) model.GenreRepository {
+	if ids, restricted := s.allowedIDs(ctx); restricted {
+		return &scopedGenreRepo{s.DataStore.Genre(ctx), ids}
+	}
+	return s.DataStore.Genre(ctx)
+}
+
+func (s *libraryScopedDataStore) Folder(ctx context.Context) model.FolderRepository {
+	if ids, restricted := s.allowedIDs(ctx); restricted {
+		return &scopedFolderRepo{s.DataStore.Folder(ctx), ids}
+	}
+	return s.DataStore.Folder(ctx)
+}
+
+func (s *libraryScopedDataStore) Library(ctx context.Context) model.LibraryRepository {
+	if _, restricted := s.allowedIDs(ctx); restricted {
+		return &scopedLibraryRepo{s.DataStore.Library(ctx)}
+	}
+	return s.DataStore.Library(ctx)
+}
+
+func (s *libraryScopedDataStore) Playlist(ctx context.Context) model.PlaylistRepository {
+	if ids, restricted := s.allowedIDs(ctx); restricted {
+		return &scopedPlaylistRepo{s.DataStore.Playlist(ctx), ids}
+	}
+	return s.DataStore.Playlist(ctx)
+}
+
+// scopedMediaFileRepo restricts track queries and direct lookups (the stream
+// route takes raw IDs) to the allowed libraries
+type scopedMediaFileRepo struct {
+	model.MediaFileRepository
+	ids []int
+}
+
+func (r *scopedMediaFileRepo) GetAll(options ...model.QueryOptions) (model.MediaFiles, error) {
+	return r.MediaFileRepository.GetAll(scopeOptions(options, "media_file.library_id", r.ids)...)
+}
+
+func (r *scopedMediaFileRepo) CountAll(options ...model.QueryOptions) (int64, error) {
+	return r.MediaFileRepository.CountAll(scopeOptions(options, "media_file.library_id", r.ids)...)
+}
+
+func (r *scopedMediaFileRepo) Get(id string) (*model.MediaFile, error) {
+	mf, err := r.MediaFileRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(r.ids, mf.LibraryID) {
+		return nil, model.ErrNotFound
+	}
+	return mf, nil
+}
+
+type scopedAlbumRepo struct {
+	model.AlbumRepository
+	ids []int
+}
+
+func (r *scopedAlbumRepo) GetAll(options ...model.QueryOptions) (model.Albums, error) {
+	return r.AlbumRepository.GetAll(scopeOptions(options, "album.library_id", r.ids)...)
+}
+
+func (r *scopedAlbumRepo) CountAll(options ...model.QueryOptions) (int64, error) {
+	return r.AlbumRepository.CountAll(scopeOptions(options, "album.library_id", r.ids)...)
+}
+
+func (r *scopedAlbumRepo) Get(id string) (*model.Album, error) {
+	album, err := r.AlbumRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(r.ids, album.LibraryID) {
+		return nil, model.ErrNotFound
+	}
+	return album, nil
+}
+
+// scopedArtistRepo restricts artist listings through the library_artist join
+// table, the same mapping the repository uses for its own library_id filter
+type scopedArtistRepo struct {
+	model.ArtistRepository
+	ids []int
+}
+
+func (r *scopedArtistRepo) GetAll(options ...model.QueryOptions) (model.Artists, error) {
+	return r.ArtistRepository.GetAll(scopeOptions(options, "library_artist.library_id", r.ids)...)
+}
+
+func (r *scopedArtistRepo) CountAll(options ...model.QueryOptions) (int64, error) {
+	return r.ArtistRepository.CountAll(scopeOptions(options, "library_artist.library_id", r.ids)...)
+}
+
+// scopedGenreRepo restricts genre listings through the library_tag join table
+type scopedGenreRepo struct {
+	model.GenreRepository
+	ids []int
+}
+
+func (r *scopedGenreRepo) GetAll(options ...model.QueryOptions) (model.Genres, error) {
+	return r.GenreRepository.GetAll(scopeOptions(options, "library_tag.library_id", r.ids)...)
+}
+
+func (r *scopedGenreRepo) CountAll(options ...model.QueryOptions) (int64, error) {
+	return r.GenreRepository.CountAll(scopeOptions(options, "library_tag.library_id", r.ids)...)
+}
+
+type scopedFolderRepo struct {
+	model.FolderRepository
+	ids []int
+}
+
+func (r *scopedFolderRepo) GetAll(options ...model.QueryOptions) ([]model.Folder, error) {
+	return r.FolderRepository.GetAll(scopeOptions(options, "folder.library_id", r.ids)...)
+}
+
+func (r *scopedFolderRepo) CountAll(options ...model.QueryOptions) (int64, error) {
+	return r.FolderRepository.CountAll(scopeOptions(options, "folder.library_id", r.ids)...)
+}
+
+func (r *scopedFolderRepo) Get(id string) (*model.Folder, error) {
+	folder, err := r.FolderRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(r.ids, folder.LibraryID) {
+		return nil, model.ErrNotFound
+	}
+	return folder, nil
+}
+
+// scopedLibraryRepo hides restricted libraries from listings and direct gets,
+// so guessed "library/<id>" object IDs come up empty
+type scopedLibraryRepo struct {
+	model.LibraryRepository
+}
+
+func (r *scopedLibraryRepo) GetAll(options ...model.QueryOptions) (model.Libraries, error) {
+	libs, err := r.LibraryRepository.GetAll(options...)
+	if err != nil {
+		return nil, err
+	}
+	visible := make(model.Libraries, 0, len(libs))
+	for _, lib := range libs {
+		if libraryVisible(lib) {
+			visible = append(visible, lib)
+		}
+	}
+	return visible, nil
+}
+
+func (r *scopedLibraryRepo) Get(id int) (*model.Library, error) {
+	lib, err := r.LibraryRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !libraryVisible(*lib) {
+		return nil, model.ErrNotFound
+	}
+	return lib, nil
+}
+
+// scopedPlaylistRepo drops tracks from restricted libraries when a playlist
+// is expanded, so playlists can't leak content the other views hide
+type scopedPlaylistRepo struct {
+	model.PlaylistRepository
+	ids []int
+}
+
+func (r *scopedPlaylistRepo) GetWithTracks(id string, refreshSmartPlaylist, includeMissing bool) (*model.Playlist, error) {
+	pls, err := r.PlaylistRepository.GetWithTracks(id, refreshSmartPlaylist, includeMissing)
+	if err != nil {
+		return nil, err
+	}
+	tracks := make(model.PlaylistTracks, 0, len(pls.Tracks))
+	for _, t := range pls.Tracks {
+		if slices.Contains(r.ids, t.LibraryID) {
+			tracks = append(tracks, t)
+		}
+	}
+	pls.Tracks = tracks
+	return pls, nil
+}
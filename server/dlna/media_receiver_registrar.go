@@ -0,0 +1,153 @@
+package dlna
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/navidrome/navidrome/log"
+)
+
+// X_MS_MediaReceiverRegistrar is a Microsoft-specific service that Xbox
+// consoles and Windows Media Player require before they accept a media
+// server. Everyone is authorized and validated - actual access control is
+// handled by the IP allow/deny lists
+
+// IsAuthorizedResponse for IsAuthorized action
+type IsAuthorizedResponse struct {
+	XMLName xml.Name `xml:"urn:microsoft.com:service:X_MS_MediaReceiverRegistrar:1 IsAuthorizedResponse"`
+	Result  int      `xml:"Result"`
+}
+
+// IsValidatedResponse for IsValidated action
+type IsValidatedResponse struct {
+	XMLName xml.Name `xml:"urn:microsoft.com:service:X_MS_MediaReceiverRegistrar:1 IsValidatedResponse"`
+	Result  int      `xml:"Result"`
+}
+
+// handleIsAuthorized reports that any device is authorized
+func (r *Router) handleIsAuthorized(ctx context.Context) (*IsAuthorizedResponse, error) {
+	return &IsAuthorizedResponse{Result: 1}, nil
+}
+
+// handleIsValidated reports that any device is validated
+func (r *Router) handleIsValidated(ctx context.Context) (*IsValidatedResponse, error) {
+	return &IsValidatedResponse{Result: 1}, nil
+}
+
+// handleMediaReceiverRegistrarControl handles SOAP requests for the
+// X_MS_MediaReceiverRegistrar service
+func (r *Router) handleMediaReceiverRegistrarControl(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	// Read request body
+	if _, err := io.ReadAll(req.Body); err != nil {
+		log.Error(ctx, "Failed to read SOAP request", err)
+		r.writeSOAPFault(w, upnpErrorActionFailed, "Failed to read request")
+		return
+	}
+
+	// Determine action from SOAPAction header
+	soapAction := strings.Trim(req.Header.Get("SOAPAction"), `"`)
+	action := extractActionName(soapAction)
+
+	log.Debug(ctx, "MediaReceiverRegistrar request", "action", action)
+
+	// Route to appropriate handler
+	var response interface{}
+	var err error
+	switch action {
+	case "IsAuthorized":
+		response, err = r.handleIsAuthorized(ctx)
+	case "IsValidated":
+		response, err = r.handleIsValidated(ctx)
+	default:
+		log.Warn(ctx, "Unknown MediaReceiverRegistrar action", "action", action)
+		r.writeSOAPFault(w, upnpErrorInvalidAction, fmt.Sprintf("Unknown action: %s", action))
+		return
+	}
+
+	if err != nil {
+		log.Error(ctx, "MediaReceiverRegistrar action failed", err, "action", action)
+		r.writeSOAPFault(w, upnpErrorActionFailed, err.Error())
+		return
+	}
+
+	r.writeSOAPResponse(w, response)
+}
+
+// handleMediaReceiverRegistrarDescription returns the service description
+func (r *Router) handleMediaReceiverRegistrarDescription(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.Write([]byte(mediaReceiverRegistrarSCPD))
+}
+
+// X_MS_MediaReceiverRegistrar Service Control Protocol Description
+var mediaReceiverRegistrarSCPD = `<?xml version="1.0" encoding="utf-8"?>
+<scpd xmlns="urn:schemas-upnp-org:service-1-0">
+  <specVersion>
+    <major>1</major>
+    <minor>0</minor>
+  </specVersion>
+  <actionList>
+    <action>
+      <name>IsAuthorized</name>
+      <argumentList>
+        <argument>
+          <name>DeviceID</name>
+          <direction>in</direction>
+          <relatedStateVariable>A_ARG_TYPE_DeviceID</relatedStateVariable>
+        </argument>
+        <argument>
+          <name>Result</name>
+          <direction>out</direction>
+          <relatedStateVariable>A_ARG_TYPE_Result</relatedStateVariable>
+        </argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>IsValidated</name>
+      <argumentList>
+        <argument>
+          <name>DeviceID</name>
+          <direction>in</direction>
+          <relatedStateVariable>A_ARG_TYPE_DeviceID</relatedStateVariable>
+        </argument>
+        <argument>
+          <name>Result</name>
+          <direction>out</direction>
+          <relatedStateVariable>A_ARG_TYPE_Result</relatedStateVariable>
+        </argument>
+      </argumentList>
+    </action>
+  </actionList>
+  <serviceStateTable>
+    <stateVariable sendEvents="no">
+      <name>A_ARG_TYPE_DeviceID</name>
+      <dataType>string</dataType>
+    </stateVariable>
+    <stateVariable sendEvents="no">
+      <name>A_ARG_TYPE_Result</name>
+      <dataType>int</dataType>
+    </stateVariable>
+    <stateVariable sendEvents="yes">
+      <name>AuthorizationGrantedUpdateID</name>
+      <dataType>ui4</dataType>
+    </stateVariable>
+    <stateVariable sendEvents="yes">
+      <name>AuthorizationDeniedUpdateID</name>
+      <dataType>ui4</dataType>
+    </stateVariable>
+    <stateVariable sendEvents="yes">
+      <name>ValidationSucceededUpdateID</name>
+      <dataType>ui4</dataType>
+    </stateVariable>
+    <stateVariable sendEvents="yes">
+      <name>ValidationRevokedUpdateID</name>
+      <dataType>ui4</dataType>
+    </stateVariable>
+  </serviceStateTable>
+</scpd>`
@@ -0,0 +1,475 @@
+package dlna
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/log"
+)
+
+// OpenHome control points (Linn Kazoo, Lumin) only treat a device as an
+// OpenHome node when it exposes the av-openhome-org Product service. We
+// advertise a minimal, read-only implementation of Product, Info and Playlist
+// alongside the standard UPnP services: Product describes the server with a
+// single Playlist source, while Info and Playlist report an idle player, so
+// these control points accept the device and browse it natively through the
+// ContentDirectory service
+
+// OpenHome Product responses
+
+// ProductManufacturerResponse for the Product Manufacturer action
+type ProductManufacturerResponse struct {
+	XMLName  xml.Name `xml:"urn:av-openhome-org:service:Product:1 ManufacturerResponse"`
+	Name     string   `xml:"Name"`
+	Info     string   `xml:"Info"`
+	Url      string   `xml:"Url"`
+	ImageUri string   `xml:"ImageUri"`
+}
+
+// ProductModelResponse for the Product Model action
+type ProductModelResponse struct {
+	XMLName  xml.Name `xml:"urn:av-openhome-org:service:Product:1 ModelResponse"`
+	Name     string   `xml:"Name"`
+	Info     string   `xml:"Info"`
+	Url      string   `xml:"Url"`
+	ImageUri string   `xml:"ImageUri"`
+}
+
+// ProductProductResponse for the Product Product action
+type ProductProductResponse struct {
+	XMLName  xml.Name `xml:"urn:av-openhome-org:service:Product:1 ProductResponse"`
+	Room     string   `xml:"Room"`
+	Name     string   `xml:"Name"`
+	Info     string   `xml:"Info"`
+	Url      string   `xml:"Url"`
+	ImageUri string   `xml:"ImageUri"`
+}
+
+// ProductStandbyResponse for the Product Standby action
+type ProductStandbyResponse struct {
+	XMLName xml.Name `xml:"urn:av-openhome-org:service:Product:1 StandbyResponse"`
+	Value   bool     `xml:"Value"`
+}
+
+// ProductSourceCountResponse for the Product SourceCount action
+type ProductSourceCountResponse struct {
+	XMLName xml.Name `xml:"urn:av-openhome-org:service:Product:1 SourceCountResponse"`
+	Value   int      `xml:"Value"`
+}
+
+// ProductSourceIndexResponse for the Product SourceIndex action
+type ProductSourceIndexResponse struct {
+	XMLName xml.Name `xml:"urn:av-openhome-org:service:Product:1 SourceIndexResponse"`
+	Value   int      `xml:"Value"`
+}
+
+// ProductSourceXmlResponse for the Product SourceXml action
+type ProductSourceXmlResponse struct {
+	XMLName xml.Name `xml:"urn:av-openhome-org:service:Product:1 SourceXmlResponse"`
+	Value   string   `xml:"Value"`
+}
+
+// ProductAttributesResponse for the Product Attributes action
+type ProductAttributesResponse struct {
+	XMLName xml.Name `xml:"urn:av-openhome-org:service:Product:1 AttributesResponse"`
+	Value   string   `xml:"Value"`
+}
+
+// The single source we expose: the server library, browsed as a playlist
+const openHomeSourceXml = `<SourceList><Source><Name>Library</Name><Type>Playlist</Type><Visible>true</Visible></Source></SourceList>`
+
+// handleOpenHomeProductControl handles SOAP requests for the OpenHome Product
+// service. All answers are static - the server is always on, with one source
+func (r *Router) handleOpenHomeProductControl(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	if _, err := io.ReadAll(req.Body); err != nil {
+		log.Error(ctx, "Failed to read SOAP request", err)
+		r.writeSOAPFault(w, upnpErrorActionFailed, "Failed to read request")
+		return
+	}
+
+	soapAction := strings.Trim(req.Header.Get("SOAPAction"), `"`)
+	action := extractActionName(soapAction)
+
+	log.Debug(ctx, "OpenHome Product request", "action", action)
+
+	var response interface{}
+	switch action {
+	case "Manufacturer":
+		response = &ProductManufacturerResponse{Name: "Navidrome", Info: "Navidrome Music Server", Url: "https://www.navidrome.org"}
+	case "Model":
+		response = &ProductModelResponse{Name: "Navidrome", Info: consts.Version, Url: "https://www.navidrome.org"}
+	case "Product":
+		response = &ProductProductResponse{Room: r.serverName, Name: r.serverName, Info: "Navidrome Music Server", Url: "https://www.navidrome.org"}
+	case "Standby":
+		response = &ProductStandbyResponse{Value: false}
+	case "SourceCount":
+		response = &ProductSourceCountResponse{Value: 1}
+	case "SourceIndex":
+		response = &ProductSourceIndexResponse{Value: 0}
+	case "SourceXml":
+		response = &ProductSourceXmlResponse{Value: openHomeSourceXml}
+	case "Attributes":
+		response = &ProductAttributesResponse{Value: "Info"}
+	default:
+		log.Warn(ctx, "Unknown OpenHome Product action", "action", action)
+		r.writeSOAPFault(w, upnpErrorInvalidAction, fmt.Sprintf("Unknown action: %s", action))
+		return
+	}
+
+	r.writeSOAPResponse(w, response)
+}
+
+// OpenHome Info responses
+
+// InfoTrackResponse for the Info Track action
+type InfoTrackResponse struct {
+	XMLName  xml.Name `xml:"urn:av-openhome-org:service:Info:1 TrackResponse"`
+	Uri      string   `xml:"Uri"`
+	Metadata string   `xml:"Metadata"`
+}
+
+// InfoDetailsResponse for the Info Details action
+type InfoDetailsResponse struct {
+	XMLName    xml.Name `xml:"urn:av-openhome-org:service:Info:1 DetailsResponse"`
+	Duration   int      `xml:"Duration"`
+	BitRate    int      `xml:"BitRate"`
+	BitDepth   int      `xml:"BitDepth"`
+	SampleRate int      `xml:"SampleRate"`
+	Lossless   bool     `xml:"Lossless"`
+	CodecName  string   `xml:"CodecName"`
+}
+
+// InfoMetatextResponse for the Info Metatext action
+type InfoMetatextResponse struct {
+	XMLName xml.Name `xml:"urn:av-openhome-org:service:Info:1 MetatextResponse"`
+	Value   string   `xml:"Value"`
+}
+
+// handleOpenHomeInfoControl handles SOAP requests for the OpenHome Info
+// service. The server never plays anything itself, so it reports no track
+func (r *Router) handleOpenHomeInfoControl(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	if _, err := io.ReadAll(req.Body); err != nil {
+		log.Error(ctx, "Failed to read SOAP request", err)
+		r.writeSOAPFault(w, upnpErrorActionFailed, "Failed to read request")
+		return
+	}
+
+	soapAction := strings.Trim(req.Header.Get("SOAPAction"), `"`)
+	action := extractActionName(soapAction)
+
+	log.Debug(ctx, "OpenHome Info request", "action", action)
+
+	var response interface{}
+	switch action {
+	case "Track":
+		response = &InfoTrackResponse{}
+	case "Details":
+		response = &InfoDetailsResponse{}
+	case "Metatext":
+		response = &InfoMetatextResponse{}
+	default:
+		log.Warn(ctx, "Unknown OpenHome Info action", "action", action)
+		r.writeSOAPFault(w, upnpErrorInvalidAction, fmt.Sprintf("Unknown action: %s", action))
+		return
+	}
+
+	r.writeSOAPResponse(w, response)
+}
+
+// OpenHome Playlist responses
+
+// PlaylistTransportStateResponse for the Playlist TransportState action
+type PlaylistTransportStateResponse struct {
+	XMLName xml.Name `xml:"urn:av-openhome-org:service:Playlist:1 TransportStateResponse"`
+	Value   string   `xml:"Value"`
+}
+
+// PlaylistRepeatResponse for the Playlist Repeat action
+type PlaylistRepeatResponse struct {
+	XMLName xml.Name `xml:"urn:av-openhome-org:service:Playlist:1 RepeatResponse"`
+	Value   bool     `xml:"Value"`
+}
+
+// PlaylistShuffleResponse for the Playlist Shuffle action
+type PlaylistShuffleResponse struct {
+	XMLName xml.Name `xml:"urn:av-openhome-org:service:Playlist:1 ShuffleResponse"`
+	Value   bool     `xml:"Value"`
+}
+
+// PlaylistIdResponse for the Playlist Id action
+type PlaylistIdResponse struct {
+	XMLName xml.Name `xml:"urn:av-openhome-org:service:Playlist:1 IdResponse"`
+	Value   int      `xml:"Value"`
+}
+
+// PlaylistIdArrayResponse for the Playlist IdArray action
+type PlaylistIdArrayResponse struct {
+	XMLName xml.Name `xml:"urn:av-openhome-org:service:Playlist:1 IdArrayResponse"`
+	Token   int      `xml:"Token"`
+	Array   string   `xml:"Array"`
+}
+
+// PlaylistProtocolInfoResponse for the Playlist ProtocolInfo action
+type PlaylistProtocolInfoResponse struct {
+	XMLName xml.Name `xml:"urn:av-openhome-org:service:Playlist:1 ProtocolInfoResponse"`
+	Value   string   `xml:"Value"`
+}
+
+// handleOpenHomePlaylistControl handles SOAP requests for the OpenHome
+// Playlist service. The playlist is always empty and stopped - the control
+// point builds its own queue from tracks browsed via ContentDirectory
+func (r *Router) handleOpenHomePlaylistControl(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	if _, err := io.ReadAll(req.Body); err != nil {
+		log.Error(ctx, "Failed to read SOAP request", err)
+		r.writeSOAPFault(w, upnpErrorActionFailed, "Failed to read request")
+		return
+	}
+
+	soapAction := strings.Trim(req.Header.Get("SOAPAction"), `"`)
+	action := extractActionName(soapAction)
+
+	log.Debug(ctx, "OpenHome Playlist request", "action", action)
+
+	var response interface{}
+	switch action {
+	case "TransportState":
+		response = &PlaylistTransportStateResponse{Value: "Stopped"}
+	case "Repeat":
+		response = &PlaylistRepeatResponse{}
+	case "Shuffle":
+		response = &PlaylistShuffleResponse{}
+	case "Id":
+		response = &PlaylistIdResponse{}
+	case "IdArray":
+		response = &PlaylistIdArrayResponse{}
+	case "ProtocolInfo":
+		info, _ := r.handleGetProtocolInfo(ctx)
+		response = &PlaylistProtocolInfoResponse{Value: info.Source}
+	default:
+		log.Warn(ctx, "Unknown OpenHome Playlist action", "action", action)
+		r.writeSOAPFault(w, upnpErrorInvalidAction, fmt.Sprintf("Unknown action: %s", action))
+		return
+	}
+
+	r.writeSOAPResponse(w, response)
+}
+
+// handleOpenHomeProductDescription returns the Product service description
+func (r *Router) handleOpenHomeProductDescription(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.Write([]byte(openHomeProductSCPD))
+}
+
+// handleOpenHomeInfoDescription returns the Info service description
+func (r *Router) handleOpenHomeInfoDescription(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.Write([]byte(openHomeInfoSCPD))
+}
+
+// handleOpenHomePlaylistDescription returns the Playlist service description
+func (r *Router) handleOpenHomePlaylistDescription(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.Write([]byte(openHomePlaylistSCPD))
+}
+
+// OpenHome Product Service Control Protocol Description
+var openHomeProductSCPD = `<?xml version="1.0" encoding="utf-8"?>
+<scpd xmlns="urn:schemas-upnp-org:service-1-0">
+  <specVersion>
+    <major>1</major>
+    <minor>0</minor>
+  </specVersion>
+  <actionList>
+    <action>
+      <name>Manufacturer</name>
+      <argumentList>
+        <argument><name>Name</name><direction>out</direction><relatedStateVariable>ManufacturerName</relatedStateVariable></argument>
+        <argument><name>Info</name><direction>out</direction><relatedStateVariable>ManufacturerInfo</relatedStateVariable></argument>
+        <argument><name>Url</name><direction>out</direction><relatedStateVariable>ManufacturerUrl</relatedStateVariable></argument>
+        <argument><name>ImageUri</name><direction>out</direction><relatedStateVariable>ManufacturerImageUri</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>Model</name>
+      <argumentList>
+        <argument><name>Name</name><direction>out</direction><relatedStateVariable>ModelName</relatedStateVariable></argument>
+        <argument><name>Info</name><direction>out</direction><relatedStateVariable>ModelInfo</relatedStateVariable></argument>
+        <argument><name>Url</name><direction>out</direction><relatedStateVariable>ModelUrl</relatedStateVariable></argument>
+        <argument><name>ImageUri</name><direction>out</direction><relatedStateVariable>ModelImageUri</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>Product</name>
+      <argumentList>
+        <argument><name>Room</name><direction>out</direction><relatedStateVariable>ProductRoom</relatedStateVariable></argument>
+        <argument><name>Name</name><direction>out</direction><relatedStateVariable>ProductName</relatedStateVariable></argument>
+        <argument><name>Info</name><direction>out</direction><relatedStateVariable>ProductInfo</relatedStateVariable></argument>
+        <argument><name>Url</name><direction>out</direction><relatedStateVariable>ProductUrl</relatedStateVariable></argument>
+        <argument><name>ImageUri</name><direction>out</direction><relatedStateVariable>ProductImageUri</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>Standby</name>
+      <argumentList>
+        <argument><name>Value</name><direction>out</direction><relatedStateVariable>Standby</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>SourceCount</name>
+      <argumentList>
+        <argument><name>Value</name><direction>out</direction><relatedStateVariable>SourceCount</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>SourceIndex</name>
+      <argumentList>
+        <argument><name>Value</name><direction>out</direction><relatedStateVariable>SourceIndex</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>SourceXml</name>
+      <argumentList>
+        <argument><name>Value</name><direction>out</direction><relatedStateVariable>SourceXml</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>Attributes</name>
+      <argumentList>
+        <argument><name>Value</name><direction>out</direction><relatedStateVariable>Attributes</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+  </actionList>
+  <serviceStateTable>
+    <stateVariable sendEvents="no"><name>ManufacturerName</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>ManufacturerInfo</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>ManufacturerUrl</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>ManufacturerImageUri</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>ModelName</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>ModelInfo</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>ModelUrl</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>ModelImageUri</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>ProductRoom</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>ProductName</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>ProductInfo</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>ProductUrl</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>ProductImageUri</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>Standby</name><dataType>boolean</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>SourceCount</name><dataType>ui4</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>SourceIndex</name><dataType>ui4</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>SourceXml</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>Attributes</name><dataType>string</dataType></stateVariable>
+  </serviceStateTable>
+</scpd>`
+
+// OpenHome Info Service Control Protocol Description
+var openHomeInfoSCPD = `<?xml version="1.0" encoding="utf-8"?>
+<scpd xmlns="urn:schemas-upnp-org:service-1-0">
+  <specVersion>
+    <major>1</major>
+    <minor>0</minor>
+  </specVersion>
+  <actionList>
+    <action>
+      <name>Track</name>
+      <argumentList>
+        <argument><name>Uri</name><direction>out</direction><relatedStateVariable>Uri</relatedStateVariable></argument>
+        <argument><name>Metadata</name><direction>out</direction><relatedStateVariable>Metadata</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>Details</name>
+      <argumentList>
+        <argument><name>Duration</name><direction>out</direction><relatedStateVariable>Duration</relatedStateVariable></argument>
+        <argument><name>BitRate</name><direction>out</direction><relatedStateVariable>BitRate</relatedStateVariable></argument>
+        <argument><name>BitDepth</name><direction>out</direction><relatedStateVariable>BitDepth</relatedStateVariable></argument>
+        <argument><name>SampleRate</name><direction>out</direction><relatedStateVariable>SampleRate</relatedStateVariable></argument>
+        <argument><name>Lossless</name><direction>out</direction><relatedStateVariable>Lossless</relatedStateVariable></argument>
+        <argument><name>CodecName</name><direction>out</direction><relatedStateVariable>CodecName</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>Metatext</name>
+      <argumentList>
+        <argument><name>Value</name><direction>out</direction><relatedStateVariable>Metatext</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+  </actionList>
+  <serviceStateTable>
+    <stateVariable sendEvents="yes"><name>Uri</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>Metadata</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>Duration</name><dataType>ui4</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>BitRate</name><dataType>ui4</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>BitDepth</name><dataType>ui4</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>SampleRate</name><dataType>ui4</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>Lossless</name><dataType>boolean</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>CodecName</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>Metatext</name><dataType>string</dataType></stateVariable>
+  </serviceStateTable>
+</scpd>`
+
+// OpenHome Playlist Service Control Protocol Description
+var openHomePlaylistSCPD = `<?xml version="1.0" encoding="utf-8"?>
+<scpd xmlns="urn:schemas-upnp-org:service-1-0">
+  <specVersion>
+    <major>1</major>
+    <minor>0</minor>
+  </specVersion>
+  <actionList>
+    <action>
+      <name>TransportState</name>
+      <argumentList>
+        <argument><name>Value</name><direction>out</direction><relatedStateVariable>TransportState</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>Repeat</name>
+      <argumentList>
+        <argument><name>Value</name><direction>out</direction><relatedStateVariable>Repeat</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>Shuffle</name>
+      <argumentList>
+        <argument><name>Value</name><direction>out</direction><relatedStateVariable>Shuffle</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>Id</name>
+      <argumentList>
+        <argument><name>Value</name><direction>out</direction><relatedStateVariable>Id</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>IdArray</name>
+      <argumentList>
+        <argument><name>Token</name><direction>out</direction><relatedStateVariable>IdArrayToken</relatedStateVariable></argument>
+        <argument><name>Array</name><direction>out</direction><relatedStateVariable>IdArrayData</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+    <action>
+      <name>ProtocolInfo</name>
+      <argumentList>
+        <argument><name>Value</name><direction>out</direction><relatedStateVariable>ProtocolInfo</relatedStateVariable></argument>
+      </argumentList>
+    </action>
+  </actionList>
+  <serviceStateTable>
+    <stateVariable sendEvents="yes"><name>TransportState</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>Repeat</name><dataType>boolean</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>Shuffle</name><dataType>boolean</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>Id</name><dataType>ui4</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>IdArrayToken</name><dataType>ui4</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>IdArrayData</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>ProtocolInfo</name><dataType>string</dataType></stateVariable>
+  </serviceStateTable>
+</scpd>`
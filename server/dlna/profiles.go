@@ -0,0 +1,151 @@
+package dlna
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// rendererProfile describes the quirks of a known renderer family and how to
+// work around them: which source formats must be transcoded, and the highest
+// sample rate the device accepts
+type rendererProfile struct {
+	Name          string
+	Matches       []string          // substrings matched against User-Agent and X-AV-Client-Info
+	Transcode     map[string]string // source format -> transcode target
+	MaxSampleRate int               // tracks above this rate are transcoded (0 = no cap)
+	StreamFormat  string            // transcode target for sample-rate capped tracks
+}
+
+// rendererProfiles lists the known renderer families, in match order. The
+// profiles mirror the workarounds MinimServer and Jellyfin ship for the same
+// devices
+var rendererProfiles = []*rendererProfile{
+	{
+		Name:    "Samsung TV",
+		Matches: []string{"samsung"},
+		// Samsung TVs stutter on FLAC and don't decode Vorbis/Opus - feed
+		// them LPCM instead
+		Transcode: map[string]string{"flac": "wav", "ogg": "wav", "opus": "wav"},
+	},
+	{
+		Name:    "Sonos",
+		Matches: []string{"sonos"},
+		// Sonos refuses hi-res streams above 48kHz
+		MaxSampleRate: 48000,
+		StreamFormat:  "flac",
+	},
+	{
+		Name:      "LG TV",
+		Matches:   []string{"lge_dlna_sdk", "lg smart tv"},
+		Transcode: map[string]string{"ogg": "mp3", "opus": "mp3"},
+	},
+	{
+		Name:      "Xbox",
+		Matches:   []string{"xbox"},
+		Transcode: map[string]string{"flac": "mp3", "ogg": "mp3", "opus": "mp3"},
+	},
+}
+
+type rendererProfileKey struct{}
+
+// detectRendererProfile matches the request headers against the known
+// renderer profiles and stores the result in the context, where the DIDL
+// builders pick it up. Unknown renderers get no profile
+func detectRendererProfile(ctx context.Context, req *http.Request) context.Context {
+	info := strings.ToLower(req.Header.Get("User-Agent") + " " + req.Header.Get("X-AV-Client-Info"))
+	for _, profile := range rendererProfiles {
+		for _, match := range profile.Matches {
+			if strings.Contains(info, match) {
+				log.Debug(ctx, "Detected DLNA renderer", "profile", profile.Name,
+					"userAgent", req.Header.Get("User-Agent"))
+				return context.WithValue(ctx, rendererProfileKey{}, profile)
+			}
+		}
+	}
+	return ctx
+}
+
+// rendererProfileFrom returns the renderer profile detected for this request,
+// or nil when the renderer is unknown
+func rendererProfileFrom(ctx context.Context) *rendererProfile {
+	profile, _ := ctx.Value(rendererProfileKey{}).(*rendererProfile)
+	return profile
+}
+
+// targetFormat returns the transcode target for a track, or "" to serve the
+// file as-is
+func (p *rendererProfile) targetFormat(mf *model.MediaFile) string {
+	if p == nil {
+		return ""
+	}
+	if target, ok := p.Transcode[strings.ToLower(mf.Suffix)]; ok {
+		return target
+	}
+	if p.MaxSampleRate > 0 && mf.SampleRate > p.MaxSampleRate {
+		if p.StreamFormat != "" {
+			return p.StreamFormat
+		}
+		return "flac"
+	}
+	return ""
+}
+
+// Hi-res handling modes (DLNA.HiResMode / DLNA.HiResProfileModes)
+const (
+	hiResPassthrough = "passthrough"
+	hiResDownsample  = "downsample"
+	hiResLPCM        = "lpcm"
+)
+
+// hiResMode returns how hi-res content should be served to this renderer:
+// the per-profile override when one is configured, the global default
+// otherwise. Unknown renderers get the global default too
+func (p *rendererProfile) hiResMode() string {
+	for _, entry := range strings.Split(conf.Server.DLNA.HiResProfileModes, ",") {
+		name, mode, ok := strings.Cut(entry, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), p.name()) {
+			return strings.TrimSpace(mode)
+		}
+	}
+	if mode := conf.Server.DLNA.HiResMode; mode != "" {
+		return mode
+	}
+	return hiResPassthrough
+}
+
+// applyReplayGain reports whether transcodes for this renderer should have
+// the track's ReplayGain applied, per the DLNA.ReplayGainProfiles option.
+// Meant for devices with no volume leveling of their own, like most TVs
+func (p *rendererProfile) applyReplayGain() bool {
+	if p == nil {
+		return false
+	}
+	for _, name := range strings.Split(conf.Server.DLNA.ReplayGainProfiles, ",") {
+		if strings.EqualFold(strings.TrimSpace(name), p.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// name returns the profile name, or "" for the nil (generic) profile
+func (p *rendererProfile) name() string {
+	if p == nil {
+		return ""
+	}
+	return p.Name
+}
+
+// formatMimeType returns the MIME type of a transcode target format
+func formatMimeType(format string) string {
+	if mimeType := mime.TypeByExtension("." + format); mimeType != "" {
+		return mimeType
+	}
+	return "audio/" + format
+}
@@ -0,0 +1,36 @@
+package dlna
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// Samsung TVs probe the ContentDirectory with the vendor action
+// X_GetFeatureList before showing the server under "Sources". The response
+// tells the TV which container holds each media type; without it, many
+// models refuse to browse at all
+
+// XGetFeatureListResponse for the Samsung X_GetFeatureList vendor action
+type XGetFeatureListResponse struct {
+	XMLName     xml.Name `xml:"urn:schemas-upnp-org:service:ContentDirectory:1 X_GetFeatureListResponse"`
+	FeatureList string   `xml:"FeatureList"`
+}
+
+// samsungFeatureList advertises the audio container under the ID Samsung
+// expects ("A_A"). Video and image are listed as empty ("-1"), so the TV
+// doesn't offer sections we can't fill
+const samsungFeatureList = `<?xml version="1.0" encoding="utf-8"?>
+<Features xmlns="urn:schemas-upnp-org:av:avs" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="urn:schemas-upnp-org:av:avs http://www.upnp.org/schemas/av/avs.xsd">
+<Feature name="samsung.com_BASICVIEW" version="1">
+<container id="A_A" type="object.item.audioItem"/>
+<container id="-1" type="object.item.videoItem"/>
+<container id="-1" type="object.item.imageItem"/>
+</Feature>
+</Features>`
+
+// handleXGetFeatureList answers the Samsung X_GetFeatureList vendor action
+func (r *Router) handleXGetFeatureList(ctx context.Context) (*XGetFeatureListResponse, error) {
+	return &XGetFeatureListResponse{
+		FeatureList: samsungFeatureList,
+	}, nil
+}
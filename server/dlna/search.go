@@ -0,0 +1,537 @@
+package dlna
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/persistence"
+)
+
+// Search request/response structures
+
+// SearchRequest represents a ContentDirectory Search request
+type SearchRequest struct {
+	XMLName        xml.Name `xml:"Search"`
+	ContainerID    string   `xml:"ContainerID"`
+	SearchCriteria string   `xml:"SearchCriteria"`
+	Filter         string   `xml:"Filter"`
+	StartingIndex  int      `xml:"StartingIndex"`
+	RequestedCount int      `xml:"RequestedCount"`
+	SortCriteria   string   `xml:"SortCriteria"`
+}
+
+// SearchResponse represents a ContentDirectory Search response
+type SearchResponse struct {
+	XMLName        xml.Name `xml:"urn:schemas-upnp-org:service:ContentDirectory:1 SearchResponse"`
+	Result         string   `xml:"Result"`
+	NumberReturned int      `xml:"NumberReturned"`
+	TotalMatches   int      `xml:"TotalMatches"`
+	UpdateID       uint32   `xml:"UpdateID"`
+}
+
+// handleSearch handles the ContentDirectory Search action. The search criteria
+// string is parsed and translated into a database query; the upnp:class terms
+// decide whether tracks, albums or artists are searched (control points like
+// BubbleUPnP issue one Search per class)
+func (r *Router) handleSearch(ctx context.Context, body []byte) (*SearchResponse, error) {
+	// Parse Search request
+	var req SearchRequest
+	if err := xml.Unmarshal(body, &req); err != nil {
+		// Try to extract from nested structure
+		type SearchWrapper struct {
+			Search SearchRequest `xml:"Search"`
+		}
+		var wrapper SearchWrapper
+		if err := xml.Unmarshal(body, &wrapper); err != nil {
+			return nil, fmt.Errorf("failed to parse Search request: %w", err)
+		}
+		req = wrapper.Search
+	}
+
+	log.Debug(ctx, "Search request",
+		"containerID", req.ContainerID,
+		"criteria", req.SearchCriteria,
+		"startIndex", req.StartingIndex,
+		"count", req.RequestedCount)
+
+	// Clamp and validate paging before anything reaches the database
+	var err error
+	req.StartingIndex, req.RequestedCount, err = validatePaging(req.StartingIndex, req.RequestedCount)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := parseSearchCriteria(req.SearchCriteria)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search criteria %q: %w", req.SearchCriteria, err)
+	}
+
+	var didl DIDLLite
+	var total int
+
+	switch searchClass(expr) {
+	case classMusicAlbum:
+		didl, total = r.searchAlbums(ctx, expr, req.StartingIndex, req.RequestedCount)
+	case classMusicArtist:
+		didl, total = r.searchArtists(ctx, expr, req.StartingIndex, req.RequestedCount)
+	default:
+		didl, total = r.searchTracks(ctx, expr, req.StartingIndex, req.RequestedCount)
+	}
+
+	// Strip properties the client did not ask for, then marshal to XML
+	didl = applyDIDLFilter(didl, req.Filter)
+	didlXML, err := xml.Marshal(didl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal DIDL-Lite: %w", err)
+	}
+
+	return &SearchResponse{
+		Result:         string(didlXML),
+		NumberReturned: len(didl.Containers) + len(didl.Items),
+		TotalMatches:   total,
+		UpdateID:       r.getUpdateID(),
+	}, nil
+}
+
+// searchTracks searches media files matching the criteria
+func (r *Router) searchTracks(ctx context.Context, expr searchExpr, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	opts := model.QueryOptions{
+		Sort:    "title",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: searchFilters(expr, trackSearchColumns),
+	}
+
+	tracks, err := r.ds.MediaFile(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to search tracks", err)
+		return didl, 0
+	}
+
+	total, err := r.ds.MediaFile(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count track search results", err)
+		total = int64(len(tracks))
+	}
+
+	for _, track := range tracks {
+		item := r.mediaFileToItem(ctx, &track, "album/"+track.AlbumID)
+		didl.Items = append(didl.Items, item)
+	}
+
+	return didl, int(total)
+}
+
+// searchAlbums searches albums matching the criteria
+func (r *Router) searchAlbums(ctx context.Context, expr searchExpr, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	opts := model.QueryOptions{
+		Sort:    "name",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: searchFilters(expr, albumSearchColumns),
+	}
+
+	albums, err := r.ds.Album(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to search albums", err)
+		return didl, 0
+	}
+
+	total, err := r.ds.Album(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count album search results", err)
+		total = int64(len(albums))
+	}
+
+	for _, album := range albums {
+		artURL := r.getAlbumArtURL(album.ID)
+		didl.Containers = append(didl.Containers, Container{
+			ID:          "album/" + album.ID,
+			ParentID:    "music/albums",
+			Restricted:  "1",
+			Title:       album.Name,
+			Class:       classMusicAlbum,
+			AlbumArtURI: artURL,
+		})
+	}
+
+	return didl, int(total)
+}
+
+// searchArtists searches artists matching the criteria
+func (r *Router) searchArtists(ctx context.Context, expr searchExpr, startIndex, count int) (DIDLLite, int) {
+	didl := DIDLLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+
+	opts := model.QueryOptions{
+		Sort:    "name",
+		Offset:  startIndex,
+		Max:     count,
+		Filters: searchFilters(expr, artistSearchColumns),
+	}
+
+	artists, err := r.ds.Artist(ctx).GetAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to search artists", err)
+		return didl, 0
+	}
+
+	total, err := r.ds.Artist(ctx).CountAll(opts)
+	if err != nil {
+		log.Error(ctx, "Failed to count artist search results", err)
+		total = int64(len(artists))
+	}
+
+	for _, artist := range artists {
+		didl.Containers = append(didl.Containers, Container{
+			ID:         "artist/" + artist.ID,
+			ParentID:   "music/artists",
+			Restricted: "1",
+			Title:      artist.Name,
+			Class:      classMusicArtist,
+		})
+	}
+
+	return didl, int(total)
+}
+
+// Searchable properties mapped to database columns, per object class. These
+// also drive the capabilities advertised by GetSearchCapabilities
+var (
+	trackSearchColumns = map[string]string{
+		"dc:title":    "title",
+		"dc:creator":  "artist",
+		"upnp:artist": "artist",
+		"upnp:album":  "album",
+		"upnp:genre":  "genre",
+	}
+	albumSearchColumns = map[string]string{
+		"dc:title":    "name",
+		"dc:creator":  "album_artist",
+		"upnp:artist": "album_artist",
+		"upnp:genre":  "genre",
+	}
+	artistSearchColumns = map[string]string{
+		"dc:title":    "name",
+		"dc:creator":  "name",
+		"upnp:artist": "name",
+	}
+)
+
+// UPnP search criteria expression tree. A nil searchExpr means "match
+// everything" (the `*` criteria)
+
+type searchExpr interface{}
+
+// searchLogicalExpr is an `and`/`or` of two sub-expressions
+type searchLogicalExpr struct {
+	op          string // "and" or "or"
+	left, right searchExpr
+}
+
+// searchRelExpr is a single `property op value` comparison. For `exists`,
+// value is "true" or "false"
+type searchRelExpr struct {
+	property string
+	op       string
+	value    string
+}
+
+// searchClass inspects the upnp:class terms of the criteria and decides which
+// object class is being searched. Defaults to tracks - audio items are what
+// every control point asks for first
+func searchClass(expr searchExpr) string {
+	for _, rel := range collectRelExprs(expr) {
+		if rel.property != "upnp:class" {
+			continue
+		}
+		if rel.op != "=" && rel.op != "derivedfrom" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(rel.value, "object.container.album"):
+			return classMusicAlbum
+		case strings.HasPrefix(rel.value, "object.container.person"):
+			return classMusicArtist
+		case strings.HasPrefix(rel.value, "object.item.audioItem"), rel.value == "object.item":
+			return classMusicTrack
+		}
+	}
+	return classMusicTrack
+}
+
+// collectRelExprs flattens the expression tree into its comparisons
+func collectRelExprs(expr searchExpr) []*searchRelExpr {
+	switch e := expr.(type) {
+	case *searchRelExpr:
+		return []*searchRelExpr{e}
+	case *searchLogicalExpr:
+		return append(collectRelExprs(e.left), collectRelExprs(e.right)...)
+	}
+	return nil
+}
+
+// searchFilters translates the criteria into squirrel filters using the given
+// property-to-column map. Properties without a mapping (upnp:class, @refID)
+// don't restrict the result - the class already picked the table
+func searchFilters(expr searchExpr, columns map[string]string) squirrel.Sqlizer {
+	switch e := expr.(type) {
+	case *searchLogicalExpr:
+		left := searchFilters(e.left, columns)
+		right := searchFilters(e.right, columns)
+		if left == nil {
+			return right
+		}
+		if right == nil {
+			return left
+		}
+		if e.op == "or" {
+			return squirrel.Or{left, right}
+		}
+		return squirrel.And{left, right}
+	case *searchRelExpr:
+		col, ok := columns[e.property]
+		if !ok {
+			return nil
+		}
+		// Genres are tag entries, not a column - they need their own filter
+		if col == "genre" {
+			return genreSearchFilter(e.op, e.value)
+		}
+		switch e.op {
+		case "contains":
+			return squirrel.Like{col: "%" + e.value + "%"}
+		case "doesNotContain":
+			return squirrel.NotLike{col: "%" + e.value + "%"}
+		case "=":
+			return squirrel.Eq{col: e.value}
+		case "!=":
+			return squirrel.NotEq{col: e.value}
+		case "<":
+			return squirrel.Lt{col: e.value}
+		case "<=":
+			return squirrel.LtOrEq{col: e.value}
+		case ">":
+			return squirrel.Gt{col: e.value}
+		case ">=":
+			return squirrel.GtOrEq{col: e.value}
+		case "exists":
+			if e.value == "false" {
+				return squirrel.Eq{col: ""}
+			}
+			return squirrel.NotEq{col: ""}
+		}
+	}
+	return nil
+}
+
+// genreSearchFilter matches genre tag entries in the tags JSON document by
+// name. Comparisons that make no sense for genres (<, > and friends) don't
+// restrict the result
+func genreSearchFilter(op, value string) squirrel.Sqlizer {
+	genreTree := `json_tree(tags, "$.genre")`
+	match := func(cond squirrel.Sqlizer) squirrel.And {
+		return squirrel.And{squirrel.NotEq{"atom": nil}, cond}
+	}
+	switch op {
+	case "contains":
+		return persistence.Exists(genreTree, match(squirrel.Like{"value": "%" + value + "%"}))
+	case "doesNotContain":
+		return persistence.NotExists(genreTree, match(squirrel.Like{"value": "%" + value + "%"}))
+	case "=":
+		// Like without wildcards, so the match is case-insensitive
+		return persistence.Exists(genreTree, match(squirrel.Like{"value": value}))
+	case "!=":
+		return persistence.NotExists(genreTree, match(squirrel.Like{"value": value}))
+	case "exists":
+		if value == "false" {
+			return persistence.NotExists(genreTree, squirrel.NotEq{"atom": nil})
+		}
+		return persistence.Exists(genreTree, squirrel.NotEq{"atom": nil})
+	}
+	return nil
+}
+
+// parseSearchCriteria parses the UPnP ContentDirectory search criteria
+// grammar: relation expressions (`property op "value"`, `property exists
+// bool`) combined with `and`/`or` and parentheses. `*` (and an empty string)
+// matches everything and yields a nil expression
+func parseSearchCriteria(criteria string) (searchExpr, error) {
+	criteria = strings.TrimSpace(criteria)
+	if criteria == "" || criteria == "*" {
+		return nil, nil
+	}
+
+	p := &searchParser{tokens: tokenizeSearchCriteria(criteria)}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q", p.tokens[p.pos].value)
+	}
+	return expr, nil
+}
+
+// searchToken is one token of the criteria string. Quoted tokens are always
+// values, never keywords or operators
+type searchToken struct {
+	value  string
+	quoted bool
+}
+
+// tokenizeSearchCriteria splits the criteria into tokens: quoted strings
+// (with backslash escapes), parentheses, comparison operators and bare words
+func tokenizeSearchCriteria(criteria string) []searchToken {
+	var tokens []searchToken
+	runes := []rune(criteria)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, searchToken{value: string(c)})
+			i++
+		case c == '"':
+			var sb strings.Builder
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			i++ // closing quote
+			tokens = append(tokens, searchToken{value: sb.String(), quoted: true})
+		case c == '<' || c == '>' || c == '=' || c == '!':
+			op := string(c)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+				i++
+			}
+			tokens = append(tokens, searchToken{value: op})
+			i++
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) &&
+				!strings.ContainsRune(`()"<>=!`, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, searchToken{value: string(runes[start:i])})
+		}
+	}
+	return tokens
+}
+
+// searchParser is a recursive descent parser over the criteria tokens.
+// `and` binds tighter than `or`, per the ContentDirectory spec
+type searchParser struct {
+	tokens []searchToken
+	pos    int
+}
+
+func (p *searchParser) parseOr() (searchExpr, error) {
+	expr, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.matchKeyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		expr = &searchLogicalExpr{op: "or", left: expr, right: right}
+	}
+	return expr, nil
+}
+
+func (p *searchParser) parseAnd() (searchExpr, error) {
+	expr, err := p.parseRel()
+	if err != nil {
+		return nil, err
+	}
+	for p.matchKeyword("and") {
+		right, err := p.parseRel()
+		if err != nil {
+			return nil, err
+		}
+		expr = &searchLogicalExpr{op: "and", left: expr, right: right}
+	}
+	return expr, nil
+}
+
+func (p *searchParser) parseRel() (searchExpr, error) {
+	tok, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of criteria")
+	}
+
+	if !tok.quoted && tok.value == "(" {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.next(); !ok || closing.quoted || closing.value != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return expr, nil
+	}
+
+	property := tok.value
+	op, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("missing operator after %q", property)
+	}
+	switch op.value {
+	case "contains", "doesNotContain", "derivedfrom", "=", "!=", "<", "<=", ">", ">=", "exists":
+	default:
+		return nil, fmt.Errorf("unknown operator %q", op.value)
+	}
+
+	value, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("missing value for %q %s", property, op.value)
+	}
+	return &searchRelExpr{property: property, op: op.value, value: value.value}, nil
+}
+
+// matchKeyword consumes the next token if it is the given (unquoted) keyword
+func (p *searchParser) matchKeyword(keyword string) bool {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && p.tokens[p.pos].value == keyword {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *searchParser) next() (searchToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return searchToken{}, false
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok, true
+}
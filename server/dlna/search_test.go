@@ -0,0 +1,170 @@
+package dlna
+
+import (
+	"github.com/Masterminds/squirrel"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("parseSearchCriteria", func() {
+	It("returns a nil expression for the match-everything criteria", func() {
+		expr, err := parseSearchCriteria("*")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(expr).To(BeNil())
+
+		expr, err = parseSearchCriteria("  ")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(expr).To(BeNil())
+	})
+
+	It("parses a single relation expression", func() {
+		expr, err := parseSearchCriteria(`dc:title contains "love"`)
+		Expect(err).ToNot(HaveOccurred())
+		rel, ok := expr.(*searchRelExpr)
+		Expect(ok).To(BeTrue())
+		Expect(rel.property).To(Equal("dc:title"))
+		Expect(rel.op).To(Equal("contains"))
+		Expect(rel.value).To(Equal("love"))
+	})
+
+	It("parses the criteria BubbleUPnP sends", func() {
+		expr, err := parseSearchCriteria(
+			`upnp:class derivedfrom "object.item.audioItem" and dc:title contains "love"`)
+		Expect(err).ToNot(HaveOccurred())
+		logical, ok := expr.(*searchLogicalExpr)
+		Expect(ok).To(BeTrue())
+		Expect(logical.op).To(Equal("and"))
+		Expect(logical.left.(*searchRelExpr).property).To(Equal("upnp:class"))
+		Expect(logical.right.(*searchRelExpr).value).To(Equal("love"))
+	})
+
+	It("unescapes backslash escapes inside quoted values", func() {
+		expr, err := parseSearchCriteria(`dc:title = "he said \"hi\""`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(expr.(*searchRelExpr).value).To(Equal(`he said "hi"`))
+	})
+
+	It("binds and tighter than or", func() {
+		expr, err := parseSearchCriteria(
+			`dc:title contains "a" or dc:title contains "b" and upnp:genre = "Rock"`)
+		Expect(err).ToNot(HaveOccurred())
+		logical := expr.(*searchLogicalExpr)
+		Expect(logical.op).To(Equal("or"))
+		right := logical.right.(*searchLogicalExpr)
+		Expect(right.op).To(Equal("and"))
+	})
+
+	It("honors parentheses over precedence", func() {
+		expr, err := parseSearchCriteria(
+			`(dc:title contains "a" or dc:title contains "b") and upnp:genre = "Rock"`)
+		Expect(err).ToNot(HaveOccurred())
+		logical := expr.(*searchLogicalExpr)
+		Expect(logical.op).To(Equal("and"))
+		left := logical.left.(*searchLogicalExpr)
+		Expect(left.op).To(Equal("or"))
+	})
+
+	It("parses exists expressions", func() {
+		expr, err := parseSearchCriteria(`upnp:albumArtURI exists true`)
+		Expect(err).ToNot(HaveOccurred())
+		rel := expr.(*searchRelExpr)
+		Expect(rel.op).To(Equal("exists"))
+		Expect(rel.value).To(Equal("true"))
+	})
+
+	It("rejects unknown operators", func() {
+		_, err := parseSearchCriteria(`dc:title startsWith "love"`)
+		Expect(err).To(MatchError(ContainSubstring("unknown operator")))
+	})
+
+	It("rejects unbalanced parentheses", func() {
+		_, err := parseSearchCriteria(`(dc:title contains "love"`)
+		Expect(err).To(MatchError(ContainSubstring("missing closing parenthesis")))
+	})
+
+	It("rejects trailing tokens", func() {
+		_, err := parseSearchCriteria(`dc:title contains "love" extra`)
+		Expect(err).To(MatchError(ContainSubstring("unexpected token")))
+	})
+
+	It("rejects a relation without a value", func() {
+		_, err := parseSearchCriteria(`dc:title contains`)
+		Expect(err).To(MatchError(ContainSubstring("missing value")))
+	})
+})
+
+var _ = Describe("searchClass", func() {
+	parse := func(criteria string) searchExpr {
+		expr, err := parseSearchCriteria(criteria)
+		Expect(err).ToNot(HaveOccurred())
+		return expr
+	}
+
+	It("picks albums for album container classes", func() {
+		expr := parse(`upnp:class = "object.container.album.musicAlbum"`)
+		Expect(searchClass(expr)).To(Equal(classMusicAlbum))
+	})
+
+	It("picks artists for person container classes", func() {
+		expr := parse(`upnp:class derivedfrom "object.container.person.musicArtist"`)
+		Expect(searchClass(expr)).To(Equal(classMusicArtist))
+	})
+
+	It("picks tracks for audio item classes", func() {
+		expr := parse(`upnp:class derivedfrom "object.item.audioItem" and dc:title contains "a"`)
+		Expect(searchClass(expr)).To(Equal(classMusicTrack))
+	})
+
+	It("defaults to tracks when no class term is present", func() {
+		Expect(searchClass(parse(`dc:title contains "a"`))).To(Equal(classMusicTrack))
+		Expect(searchClass(nil)).To(Equal(classMusicTrack))
+	})
+})
+
+var _ = Describe("searchFilters", func() {
+	parse := func(criteria string) searchExpr {
+		expr, err := parseSearchCriteria(criteria)
+		Expect(err).ToNot(HaveOccurred())
+		return expr
+	}
+
+	It("translates contains into a Like filter", func() {
+		filter := searchFilters(parse(`dc:title contains "love"`), trackSearchColumns)
+		Expect(filter).To(Equal(squirrel.Like{"title": "%love%"}))
+	})
+
+	It("translates = into an Eq filter", func() {
+		filter := searchFilters(parse(`upnp:album = "Abbey Road"`), trackSearchColumns)
+		Expect(filter).To(Equal(squirrel.Eq{"album": "Abbey Road"}))
+	})
+
+	It("combines and/or expressions", func() {
+		filter := searchFilters(
+			parse(`dc:title contains "a" or upnp:album contains "b"`), trackSearchColumns)
+		Expect(filter).To(Equal(squirrel.Or{
+			squirrel.Like{"title": "%a%"},
+			squirrel.Like{"album": "%b%"},
+		}))
+	})
+
+	It("ignores properties without a column mapping", func() {
+		filter := searchFilters(
+			parse(`upnp:class derivedfrom "object.item.audioItem" and dc:title contains "a"`),
+			trackSearchColumns)
+		Expect(filter).To(Equal(squirrel.Like{"title": "%a%"}))
+	})
+
+	It("translates exists false into an empty-value check", func() {
+		filter := searchFilters(parse(`dc:creator exists false`), trackSearchColumns)
+		Expect(filter).To(Equal(squirrel.Eq{"artist": ""}))
+	})
+
+	It("builds a tag-tree filter for genres", func() {
+		filter := searchFilters(parse(`upnp:genre = "Rock"`), trackSearchColumns)
+		Expect(filter).ToNot(BeNil())
+		sql, args, err := filter.ToSql()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sql).To(ContainSubstring("json_tree"))
+		Expect(args).To(ContainElement("Rock"))
+	})
+})
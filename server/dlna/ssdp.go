@@ -1,12 +1,17 @@
 package dlna
 
 import (
+	"errors"
 	"fmt"
 	"net"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/utils/random"
 )
 
 const (
@@ -14,26 +19,78 @@ const (
 	ssdpAlive  = "ssdp:alive"
 	ssdpByeBye = "ssdp:byebye"
 	ssdpAll    = "ssdp:all"
+)
 
-	// Cache control max-age in seconds
-	cacheMaxAge = 1800
+// cacheMaxAge is the advertised max-age in seconds, from configuration
+func cacheMaxAge() int {
+	return int(conf.Server.DLNA.CacheMaxAge.Seconds())
+}
 
-	// Announcement interval
-	announceInterval = 30 * time.Minute
-)
+// announceInterval is how often alive notifications are sent, from configuration
+func announceInterval() time.Duration {
+	return conf.Server.DLNA.AnnounceInterval
+}
+
+// ssdpSocket is a multicast listener joined on one interface. It remembers
+// the interface's unicast addresses, so responses leave through the network
+// the request came in on and LOCATION URLs point to an address the client
+// can reach
+type ssdpSocket struct {
+	conn    *net.UDPConn
+	iface   net.Interface
+	network string // "udp4" or "udp6"
+	localIP net.IP // source address for outgoing messages on this socket
+	hostIP  net.IP // IPv4 address used in LOCATION URLs
+}
 
-// startSSDP initializes the SSDP listener for M-SEARCH requests
+// startSSDP joins the SSDP multicast group on every active interface,
+// listening for M-SEARCH requests. IPv6 is joined best-effort, so dual-stack
+// renderers discover us too
 func (r *Router) startSSDP() error {
-	// Parse multicast address
-	addr, err := net.ResolveUDPAddr("udp4", ssdpAddr)
+	r.mu.Lock()
+	for _, iface := range r.interfaces {
+		r.joinSSDPGroup(iface, "udp4")
+		// IPv6 is best-effort - silently skipped on IPv4-only systems
+		r.joinSSDPGroup(iface, "udp6")
+	}
+	sockets := slices.Clone(r.ssdpSockets)
+	r.mu.Unlock()
+	if len(sockets) == 0 {
+		return fmt.Errorf("no usable interfaces for SSDP")
+	}
+
+	// Start listening for M-SEARCH requests
+	for _, s := range sockets {
+		go r.listenSSDP(s)
+	}
+
+	// Start periodic announcements
+	go r.periodicAnnounce()
+
+	return nil
+}
+
+// joinSSDPGroup joins the SSDP multicast group of one address family on one
+// interface. Failures are logged but not fatal - startSSDP only errors when
+// no group could be joined at all
+func (r *Router) joinSSDPGroup(iface net.Interface, network string) {
+	localIP := interfaceIP(iface, network)
+	if localIP == nil {
+		return
+	}
+
+	addr, err := net.ResolveUDPAddr(network, ssdpGroupAddr(network))
 	if err != nil {
-		return fmt.Errorf("failed to resolve SSDP address: %w", err)
+		log.Error(r.ctx, "Failed to resolve SSDP address", "network", network, err)
+		return
 	}
 
-	// Listen on all interfaces for multicast
-	conn, err := net.ListenMulticastUDP("udp4", nil, addr)
+	conn, err := net.ListenMulticastUDP(network, &iface, addr)
 	if err != nil {
-		return fmt.Errorf("failed to listen on multicast: %w", err)
+		if network == "udp4" {
+			log.Warn(r.ctx, "Failed to join SSDP multicast group", "interface", iface.Name, err)
+		}
+		return
 	}
 
 	// Set read buffer size
@@ -41,19 +98,45 @@ func (r *Router) startSSDP() error {
 		log.Warn(r.ctx, "Failed to set SSDP read buffer", err)
 	}
 
-	r.ssdpConn = conn
-
-	// Start listening for M-SEARCH requests
-	go r.listenSSDP()
+	r.ssdpSockets = append(r.ssdpSockets, &ssdpSocket{
+		conn:    conn,
+		iface:   iface,
+		network: network,
+		localIP: localIP,
+		hostIP:  interfaceIP(iface, "udp4"),
+	})
+	log.Debug(r.ctx, "Joined SSDP multicast group", "interface", iface.Name, "network", network, "addr", localIP)
+}
 
-	// Start periodic announcements
-	go r.periodicAnnounce()
+// ssdpGroupAddr returns the SSDP multicast group of an address family
+func ssdpGroupAddr(network string) string {
+	if network == "udp6" {
+		return ssdpAddrV6
+	}
+	return ssdpAddr
+}
 
+// interfaceIP returns the interface's first usable address of the given
+// family, used to bind outgoing SSDP traffic to that interface
+func interfaceIP(iface net.Interface, network string) net.IP {
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() {
+			continue
+		}
+		if (network == "udp4") == (ipnet.IP.To4() != nil) {
+			return ipnet.IP
+		}
+	}
 	return nil
 }
 
-// listenSSDP handles incoming SSDP M-SEARCH requests
-func (r *Router) listenSSDP() {
+// listenSSDP handles incoming SSDP M-SEARCH requests on one socket
+func (r *Router) listenSSDP(s *ssdpSocket) {
 	buf := make([]byte, 2048)
 
 	for {
@@ -64,34 +147,46 @@ func (r *Router) listenSSDP() {
 		}
 
 		// Set read deadline to allow checking context
-		if err := r.ssdpConn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		if err := s.conn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
 			continue
 		}
 
-		n, remoteAddr, err := r.ssdpConn.ReadFromUDP(buf)
+		n, remoteAddr, err := s.conn.ReadFromUDP(buf)
 		if err != nil {
 			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 				continue
 			}
-			log.Error(r.ctx, "Error reading SSDP packet", err)
+			// The socket is closed when rebinding after a network change
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Error(r.ctx, "Error reading SSDP packet", "interface", s.iface.Name, err)
 			continue
 		}
 
 		msg := string(buf[:n])
 		if strings.HasPrefix(msg, "M-SEARCH") {
-			r.handleMSearch(msg, remoteAddr)
+			r.handleMSearch(msg, remoteAddr, s)
 		}
 	}
 }
 
 // handleMSearch responds to SSDP M-SEARCH discovery requests
-func (r *Router) handleMSearch(msg string, remoteAddr *net.UDPAddr) {
+func (r *Router) handleMSearch(msg string, remoteAddr *net.UDPAddr, s *ssdpSocket) {
+	// Don't advertise ourselves to restricted IPs
+	if !ipAllowed(remoteAddr.IP.String()) {
+		return
+	}
+
 	// Parse the search target
 	st := extractHeader(msg, "ST")
 	if st == "" {
 		return
 	}
 
+	r.stats.msearches.Add(1)
+	r.ssdpDebug("SSDP: M-SEARCH received", "st", st, "from", remoteAddr.String(), "interface", s.iface.Name)
+
 	// Check if we should respond to this search
 	shouldRespond := false
 	var respondTargets []string
@@ -112,6 +207,12 @@ func (r *Router) handleMSearch(msg string, remoteAddr *net.UDPAddr) {
 	case connectionManagerType:
 		shouldRespond = true
 		respondTargets = []string{connectionManagerType}
+	case mediaReceiverRegistrarType:
+		shouldRespond = true
+		respondTargets = []string{mediaReceiverRegistrarType}
+	case openHomeProductType, openHomeInfoType, openHomePlaylistType:
+		shouldRespond = true
+		respondTargets = []string{st}
 	default:
 		// Check if it's our UUID
 		if st == r.uuid {
@@ -125,16 +226,31 @@ func (r *Router) handleMSearch(msg string, remoteAddr *net.UDPAddr) {
 	}
 
 	log.Debug(r.ctx, "Responding to M-SEARCH", "st", st, "from", remoteAddr.String())
+	r.metrics.RecordDLNASearchResponse(r.ctx, st)
 
-	// Send responses for each target
-	for _, target := range respondTargets {
-		r.sendSearchResponse(target, remoteAddr)
+	// UPnP requires responses to be spread over a random delay within MX
+	// seconds, so replies from many devices on the network don't collide
+	mx := 1
+	if v, err := strconv.Atoi(extractHeader(msg, "MX")); err == nil && v > 0 {
+		mx = min(v, 5)
 	}
+	go func() {
+		delay := time.Duration(random.Int64N(mx*1000)) * time.Millisecond
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		for _, target := range respondTargets {
+			r.sendSearchResponse(target, remoteAddr, s)
+		}
+	}()
 }
 
-// sendSearchResponse sends an M-SEARCH response to the requester
-func (r *Router) sendSearchResponse(st string, remoteAddr *net.UDPAddr) {
-	location := r.getDeviceURL()
+// sendSearchResponse sends an M-SEARCH response to the requester, through the
+// socket the request arrived on
+func (r *Router) sendSearchResponse(st string, remoteAddr *net.UDPAddr, s *ssdpSocket) {
+	location := r.getDeviceURLFor(s.hostIP)
 	usn := r.getUSN(st)
 
 	response := fmt.Sprintf("HTTP/1.1 200 OK\r\n"+
@@ -145,27 +261,27 @@ func (r *Router) sendSearchResponse(st string, remoteAddr *net.UDPAddr) {
 		"SERVER: %s\r\n"+
 		"ST: %s\r\n"+
 		"USN: %s\r\n"+
-		"BOOTID.UPNP.ORG: 1\r\n"+
+		"BOOTID.UPNP.ORG: %d\r\n"+
 		"CONFIGID.UPNP.ORG: 1\r\n"+
+		"SEARCHPORT.UPNP.ORG: 1900\r\n"+
 		"\r\n",
-		cacheMaxAge,
+		cacheMaxAge(),
 		time.Now().UTC().Format(time.RFC1123),
 		location,
 		r.getServerString(),
 		st,
 		usn,
+		r.bootID,
 	)
 
-	conn, err := net.DialUDP("udp4", nil, remoteAddr)
-	if err != nil {
-		log.Error(r.ctx, "Failed to dial for M-SEARCH response", err)
-		return
-	}
-	defer conn.Close()
-
-	if _, err := conn.Write([]byte(response)); err != nil {
+	// Reply on the listening socket, so the response leaves through the
+	// interface the search arrived on - required for link-local IPv6
+	if _, err := s.conn.WriteToUDP([]byte(response), remoteAddr); err != nil {
 		log.Error(r.ctx, "Failed to send M-SEARCH response", err)
+		return
 	}
+	r.stats.responses.Add(1)
+	r.ssdpDebug("SSDP: M-SEARCH response sent", "st", st, "to", remoteAddr.String(), "location", location)
 }
 
 // announcePresence sends SSDP NOTIFY alive messages for all services
@@ -182,9 +298,81 @@ func (r *Router) sendByeBye() {
 	}
 }
 
+// networkCheckInterval is how often interface addresses are re-checked
+const networkCheckInterval = 30 * time.Second
+
+// networkFingerprint summarizes the addresses of the given interfaces, to
+// detect configuration changes between checks
+func networkFingerprint(ifaces []net.Interface) string {
+	var parts []string
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			parts = append(parts, iface.Name+"/"+addr.String())
+		}
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, ",")
+}
+
+// watchNetworkChanges re-checks the interface addresses periodically. When
+// they change (DHCP renew, VPN up/down, cable replug), the presence advertised
+// with the old addresses is withdrawn and the SSDP sockets are rebound, so
+// LOCATION URLs never go stale
+func (r *Router) watchNetworkChanges() {
+	ticker := time.NewTicker(networkCheckInterval)
+	defer ticker.Stop()
+
+	current := networkFingerprint(r.interfaces)
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ifaces, err := getActiveInterfaces()
+		if err != nil {
+			log.Warn(r.ctx, "Failed to check network interfaces", err)
+			continue
+		}
+		fingerprint := networkFingerprint(ifaces)
+		if fingerprint == current {
+			continue
+		}
+
+		log.Info(r.ctx, "Network configuration changed, rebinding DLNA advertisements")
+
+		// Withdraw the presence advertised with the old addresses, then
+		// rebind on the new ones and re-announce
+		r.sendByeBye()
+		r.mu.Lock()
+		for _, s := range r.ssdpSockets {
+			s.conn.Close()
+		}
+		r.ssdpSockets = nil
+		r.interfaces = ifaces
+		for _, iface := range r.interfaces {
+			r.joinSSDPGroup(iface, "udp4")
+			r.joinSSDPGroup(iface, "udp6")
+		}
+		sockets := r.ssdpSockets
+		r.mu.Unlock()
+
+		for _, s := range sockets {
+			go r.listenSSDP(s)
+		}
+		r.announcePresence()
+		current = fingerprint
+	}
+}
+
 // periodicAnnounce sends announcements at regular intervals
 func (r *Router) periodicAnnounce() {
-	ticker := time.NewTicker(announceInterval)
+	ticker := time.NewTicker(announceInterval())
 	defer ticker.Stop()
 
 	for {
@@ -197,10 +385,28 @@ func (r *Router) periodicAnnounce() {
 	}
 }
 
-// sendNotify sends an SSDP NOTIFY message
+// snapshotSockets copies the socket list under the lock. The network watcher
+// closes and rebuilds the list concurrently, so readers must never iterate
+// r.ssdpSockets directly
+func (r *Router) snapshotSockets() []*ssdpSocket {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.ssdpSockets)
+}
+
+// sendNotify sends an SSDP NOTIFY message on every joined socket
 func (r *Router) sendNotify(nt, nts string) {
-	location := r.getDeviceURL()
+	for _, s := range r.snapshotSockets() {
+		r.sendNotifyOn(s, nt, nts)
+	}
+}
+
+// sendNotifyOn sends an SSDP NOTIFY message through one socket, with the
+// matching interface address as source and LOCATION
+func (r *Router) sendNotifyOn(s *ssdpSocket, nt, nts string) {
+	location := r.getDeviceURLFor(s.hostIP)
 	usn := r.getUSN(nt)
+	group := ssdpGroupAddr(s.network)
 
 	var msg string
 	if nts == ssdpByeBye {
@@ -209,13 +415,14 @@ func (r *Router) sendNotify(nt, nts string) {
 			"NT: %s\r\n"+
 			"NTS: %s\r\n"+
 			"USN: %s\r\n"+
-			"BOOTID.UPNP.ORG: 1\r\n"+
+			"BOOTID.UPNP.ORG: %d\r\n"+
 			"CONFIGID.UPNP.ORG: 1\r\n"+
 			"\r\n",
-			ssdpAddr,
+			group,
 			nt,
 			nts,
 			usn,
+			r.bootID,
 		)
 	} else {
 		msg = fmt.Sprintf("NOTIFY * HTTP/1.1\r\n"+
@@ -226,29 +433,36 @@ func (r *Router) sendNotify(nt, nts string) {
 			"NTS: %s\r\n"+
 			"SERVER: %s\r\n"+
 			"USN: %s\r\n"+
-			"BOOTID.UPNP.ORG: 1\r\n"+
+			"BOOTID.UPNP.ORG: %d\r\n"+
 			"CONFIGID.UPNP.ORG: 1\r\n"+
+			"SEARCHPORT.UPNP.ORG: 1900\r\n"+
 			"\r\n",
-			ssdpAddr,
-			cacheMaxAge,
+			group,
+			cacheMaxAge(),
 			location,
 			nt,
 			nts,
 			r.getServerString(),
 			usn,
+			r.bootID,
 		)
 	}
 
-	// Send to multicast address
-	addr, err := net.ResolveUDPAddr("udp4", ssdpAddr)
+	// Send to the multicast group, sourced from this interface's address
+	addr, err := net.ResolveUDPAddr(s.network, group)
 	if err != nil {
 		log.Error(r.ctx, "Failed to resolve SSDP address for notify", err)
 		return
 	}
 
-	conn, err := net.DialUDP("udp4", nil, addr)
+	laddr := &net.UDPAddr{IP: s.localIP}
+	if s.network == "udp6" {
+		// Link-local addresses need the interface as scope zone
+		laddr.Zone = s.iface.Name
+	}
+	conn, err := net.DialUDP(s.network, laddr, addr)
 	if err != nil {
-		log.Error(r.ctx, "Failed to dial for NOTIFY", err)
+		log.Error(r.ctx, "Failed to dial for NOTIFY", "interface", s.iface.Name, err)
 		return
 	}
 	defer conn.Close()
@@ -260,6 +474,8 @@ func (r *Router) sendNotify(nt, nts string) {
 		}
 		time.Sleep(100 * time.Millisecond)
 	}
+	r.stats.notifies.Add(1)
+	r.ssdpDebug("SSDP: NOTIFY sent", "nt", nt, "nts", nts, "interface", s.iface.Name)
 }
 
 // getAllServiceTypes returns all service types to advertise
@@ -270,6 +486,10 @@ func (r *Router) getAllServiceTypes() []string {
 		deviceType,
 		contentDirectoryType,
 		connectionManagerType,
+		mediaReceiverRegistrarType,
+		openHomeProductType,
+		openHomeInfoType,
+		openHomePlaylistType,
 	}
 }
 
@@ -281,11 +501,14 @@ func (r *Router) getUSN(st string) string {
 	return fmt.Sprintf("%s::%s", r.uuid, st)
 }
 
-// getDeviceURL returns the URL to the device description
-func (r *Router) getDeviceURL() string {
-	localIP := getLocalIP()
-	baseURL := fmt.Sprintf("http://%s:%d", localIP, r.httpPort)
-	return fmt.Sprintf("%s/dlna/device.xml", baseURL)
+// getDeviceURLFor returns the URL to the device description, using the given
+// interface address as host so clients on that network can reach it
+func (r *Router) getDeviceURLFor(ip net.IP) string {
+	host := getLocalIP()
+	if ip != nil {
+		host = ip.String()
+	}
+	return fmt.Sprintf("http://%s:%d/dlna/device.xml", host, r.httpPort)
 }
 
 // getServerString returns the SERVER header value
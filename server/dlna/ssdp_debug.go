@@ -0,0 +1,61 @@
+package dlna
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/log"
+)
+
+// Discovery problems ("my TV can't find the server") are hard to diagnose
+// from normal logs. When DLNA.DebugSSDP is enabled, every M-SEARCH received,
+// every response and NOTIFY sent, and all subscription activity is logged at
+// Info level, plus a traffic summary once a minute, without having to raise
+// the global log level
+
+// ssdpStats counts SSDP and eventing traffic for the periodic summaries
+type ssdpStats struct {
+	msearches     atomic.Uint64
+	responses     atomic.Uint64
+	notifies      atomic.Uint64
+	subscriptions atomic.Uint64
+}
+
+// ssdpDebugEnabled reports whether SSDP debug logging is on
+func ssdpDebugEnabled() bool {
+	return conf.Server.DLNA.DebugSSDP
+}
+
+// ssdpDebug logs an SSDP traffic event when DLNA.DebugSSDP is enabled
+func (r *Router) ssdpDebug(msg string, keyValuePairs ...interface{}) {
+	if !ssdpDebugEnabled() {
+		return
+	}
+	args := append([]interface{}{r.ctx, msg}, keyValuePairs...)
+	log.Info(args...)
+}
+
+// How often SSDP traffic summaries are logged in debug mode
+const ssdpStatsInterval = time.Minute
+
+// logSSDPStats periodically logs how much SSDP and eventing traffic was seen
+// since the last summary
+func (r *Router) logSSDPStats() {
+	ticker := time.NewTicker(ssdpStatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			log.Info(r.ctx, "SSDP traffic summary",
+				"interval", ssdpStatsInterval,
+				"msearchesReceived", r.stats.msearches.Swap(0),
+				"responsesSent", r.stats.responses.Swap(0),
+				"notifiesSent", r.stats.notifies.Swap(0),
+				"subscriptionRequests", r.stats.subscriptions.Swap(0))
+		}
+	}
+}
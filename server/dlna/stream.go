@@ -0,0 +1,199 @@
+package dlna
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// handleStream serves a media file to renderers, without going through the
+// Subsonic API and its authentication. Raw files are served straight from
+// disk, which gives renderers Range support and a proper Content-Length;
+// transcodes go through the media streamer
+func (r *Router) handleStream(w http.ResponseWriter, req *http.Request) {
+	ctx := detectRendererProfile(req.Context(), req)
+	trackClient(req, rendererProfileFrom(ctx))
+	id := chi.URLParam(req, "id")
+
+	// Run the request as the configured DLNA user, same as the control
+	// handler, so the lookup below is scoped like every browse - a raw track
+	// ID must not stream anything browsing wouldn't show
+	ctx = r.annotationContext(ctx)
+
+	mf, err := r.ds.MediaFile(ctx).Get(id)
+	if err != nil {
+		log.Error(ctx, "Failed to get media file for streaming", "id", id, err)
+		http.NotFound(w, req)
+		return
+	}
+
+	// Count the bytes actually sent, whichever path serves them
+	cw := &countingWriter{ResponseWriter: w}
+	w = cw
+	defer func() { r.metrics.RecordDLNAStreamedBytes(ctx, cw.bytes) }()
+
+	format := req.URL.Query().Get("format")
+	mimeType := mf.ContentType()
+	if format != "" {
+		mimeType = formatMimeType(format)
+	}
+
+	// DLNA-specific headers renderers use to decide seeking and buffering
+	w.Header().Set("transferMode.dlna.org", "Streaming")
+	w.Header().Set("contentFeatures.dlna.org", dlnaContentFeatures(mimeType))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	// Hi-res content may need to be downsampled or converted to LPCM for
+	// renderers that can't play it, per DLNA.HiResMode
+	if isHiRes(mf) {
+		if r.serveHiResStream(ctx, w, mf) {
+			return
+		}
+	}
+
+	if format == "" {
+		w.Header().Set("Content-Type", mimeType)
+		http.ServeFile(w, req, mf.AbsolutePath())
+		return
+	}
+
+	// Renderers flagged for ReplayGain get the track gain baked into the
+	// transcode, for devices with no volume leveling of their own
+	if rendererProfileFrom(ctx).applyReplayGain() {
+		if r.serveReplayGainStream(ctx, w, mf, format) {
+			return
+		}
+	}
+
+	stream, err := r.streamer.DoStream(ctx, mf, format, 0, 0)
+	if err != nil {
+		log.Error(ctx, "Failed to open transcoded stream", "id", id, "format", format, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer func() {
+		if err := stream.Close(); err != nil {
+			log.Error(ctx, "Error closing stream", "id", id, "file", stream.Name(), err)
+		}
+	}()
+
+	if stream.Seekable() {
+		http.ServeContent(w, req, stream.Name(), stream.ModTime(), stream)
+		return
+	}
+
+	// Transcoded on the fly - no ranges or content length
+	w.Header().Set("Accept-Ranges", "none")
+	w.Header().Set("Content-Type", stream.ContentType())
+	if _, err := io.Copy(w, stream); err != nil {
+		log.Error(ctx, "Error sending transcoded file", "id", id, err)
+	}
+}
+
+// serveReplayGainStream transcodes the file with its ReplayGain track gain
+// applied as an ffmpeg volume filter. Returns false when the track has no
+// gain tag or the transcode cannot start, so the caller falls back to the
+// normal transcoding path
+func (r *Router) serveReplayGainStream(ctx context.Context, w http.ResponseWriter, mf *model.MediaFile, format string) bool {
+	if mf.RGTrackGain == nil {
+		return false
+	}
+	gain := *mf.RGTrackGain
+	// Cap the gain so the loudest sample never clips
+	if mf.RGTrackPeak != nil && *mf.RGTrackPeak > 0 {
+		if headroom := -20 * math.Log10(*mf.RGTrackPeak); gain > headroom {
+			gain = headroom
+		}
+	}
+
+	command := fmt.Sprintf("ffmpeg -i %%s -map 0:a:0 -v 0 -af volume=%.2fdB -f %s -", gain, format)
+	stream, err := r.ffmpeg.Transcode(ctx, command, mf.AbsolutePath(), 0, 0)
+	if err != nil {
+		log.Error(ctx, "Failed to start ReplayGain transcode", "id", mf.ID, "format", format, err)
+		return false
+	}
+	defer func() {
+		if err := stream.Close(); err != nil {
+			log.Error(ctx, "Error closing ReplayGain transcode", "id", mf.ID, err)
+		}
+	}()
+
+	// Transcoded on the fly - no ranges or content length
+	w.Header().Set("Accept-Ranges", "none")
+	w.Header().Set("Content-Type", formatMimeType(format))
+	if _, err := io.Copy(w, stream); err != nil {
+		log.Error(ctx, "Error sending ReplayGain-adjusted file", "id", mf.ID, err)
+	}
+	return true
+}
+
+// isHiRes reports whether a track exceeds CD-quality limits
+func isHiRes(mf *model.MediaFile) bool {
+	return mf.SampleRate > 48000 || mf.BitDepth > 16
+}
+
+// serveHiResStream serves a hi-res track according to the renderer's hi-res
+// mode: capped at 48kHz in its own codec (downsample) or converted to
+// 16-bit LPCM (lpcm). Returns false for passthrough or when the transcode
+// cannot start, so the caller continues with the normal path
+func (r *Router) serveHiResStream(ctx context.Context, w http.ResponseWriter, mf *model.MediaFile) bool {
+	var command, target string
+	switch rendererProfileFrom(ctx).hiResMode() {
+	case hiResDownsample:
+		// FLAC is the safe lossless target for every hi-res source codec
+		target = "flac"
+		command = "ffmpeg -i %s -map 0:a:0 -v 0 -ar 48000 -sample_fmt s16 -f flac -"
+	case hiResLPCM:
+		target = "wav"
+		command = "ffmpeg -i %s -map 0:a:0 -v 0 -ar 48000 -sample_fmt s16 -f wav -"
+	default:
+		return false
+	}
+
+	stream, err := r.ffmpeg.Transcode(ctx, command, mf.AbsolutePath(), 0, 0)
+	if err != nil {
+		log.Error(ctx, "Failed to start hi-res transcode", "id", mf.ID, "target", target, err)
+		return false
+	}
+	defer func() {
+		if err := stream.Close(); err != nil {
+			log.Error(ctx, "Error closing hi-res transcode", "id", mf.ID, err)
+		}
+	}()
+
+	// Transcoded on the fly - no ranges or content length
+	w.Header().Set("Accept-Ranges", "none")
+	w.Header().Set("Content-Type", formatMimeType(target))
+	if _, err := io.Copy(w, stream); err != nil {
+		log.Error(ctx, "Error sending hi-res transcode", "id", mf.ID, err)
+	}
+	return true
+}
+
+// countingWriter wraps a ResponseWriter, counting the bytes written through
+// it, for the streamed-bytes metric
+type countingWriter struct {
+	http.ResponseWriter
+	bytes int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.ResponseWriter.Write(p)
+	c.bytes += int64(n)
+	return n, err
+}
+
+// dlnaContentFeatures returns the value of the contentFeatures.dlna.org
+// header: the additional-info part of the protocolInfo advertised for the
+// MIME type
+func dlnaContentFeatures(mimeType string) string {
+	info := GetProtocolInfoForMimeType(mimeType)
+	return info[strings.LastIndex(info, ":")+1:]
+}
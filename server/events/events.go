@@ -63,11 +63,35 @@ type RefreshResource struct {
 	resources map[string][]string
 }
 
+// IdentifyProgress reports the progress of a batch fingerprint identification
+// job (see core/fingerprint), so the UI can show a live counter and offer
+// cancellation while a long run is in flight
+type IdentifyProgress struct {
+	baseEvent
+	JobID     string `json:"jobId"`
+	Kind      string `json:"kind"`
+	TargetID  string `json:"targetId"`
+	Running   bool   `json:"running"`
+	Total     int    `json:"total"`
+	Processed int    `json:"processed"`
+	Matched   int    `json:"matched"`
+	Failed    int    `json:"failed"`
+	Error     string `json:"error,omitempty"`
+}
+
 type NowPlayingCount struct {
 	baseEvent
 	Count int `json:"count"`
 }
 
+// SonosDevicesChanged signals that the list of discovered Sonos devices
+// changed, so clients can re-fetch it. Scanning is true while a discovery
+// scan is still collecting answers
+type SonosDevicesChanged struct {
+	baseEvent
+	Scanning bool `json:"scanning"`
+}
+
 func (rr *RefreshResource) With(resource string, ids ...string) *RefreshResource {
 	if rr.resources == nil {
 		rr.resources = make(map[string][]string)
@@ -0,0 +1,240 @@
+package nativeapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core/fingerprint"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// addIdentifyRoute exposes on-demand track identification: the file is
+// fingerprinted and looked up in AcoustID, and the ranked matches (with their
+// release candidates) are returned, backing an "Identify this track" action
+// in the UI for files with mystery metadata
+func (api *Router) addIdentifyRoute(r chi.Router) {
+	r.Post("/track/{id}/identify", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := chi.URLParam(r, "id")
+
+		mf, err := api.ds.MediaFile(ctx).Get(id)
+		if errors.Is(err, model.ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error(ctx, "Error retrieving track for identification", "id", id, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		svc := fingerprint.NewService(api.ds)
+		if !svc.IsEnabled() {
+			http.Error(w, "fingerprinting is not enabled", http.StatusServiceUnavailable)
+			return
+		}
+
+		matches, err := svc.Identify(ctx, mf)
+		if err != nil && !errors.Is(err, fingerprint.ErrNoMatch) {
+			log.Error(ctx, "Error identifying track", "id", id, "path", mf.Path, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if matches == nil {
+			matches = []fingerprint.MatchResult{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(matches); err != nil {
+			log.Error(ctx, "Error encoding identification matches", err)
+		}
+	})
+}
+
+// addAcceptMatchRoute applies a match the user accepted: the corrected tags
+// are written back to the file, the pending match is cleared, and a targeted
+// rescan of the file's folder is triggered so the library reflects the fix
+func (api *Router) addAcceptMatchRoute(r chi.Router) {
+	r.Post("/track/{id}/identify/accept", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := chi.URLParam(r, "id")
+
+		var match fingerprint.MatchResult
+		if err := json.NewDecoder(r.Body).Decode(&match); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mf, err := api.ds.MediaFile(ctx).Get(id)
+		if errors.Is(err, model.ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error(ctx, "Error retrieving track for match acceptance", "id", id, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		// In "db" mode the match is applied to the database only, for
+		// libraries on read-only shares where tags cannot be written
+		if conf.Server.Fingerprint.ApplyMode == fingerprint.ApplyModeDB {
+			if err := fingerprint.ApplyToDB(ctx, api.ds, mf, match); err != nil {
+				log.Error(ctx, "Error applying accepted match to database", "id", id, err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			fingerprint.GetMatchStore().Delete(mf.ID)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if err := fingerprint.WriteBack(ctx, mf, match); err != nil {
+			log.Error(ctx, "Error writing accepted match to file", "id", id, "path", mf.Path, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fingerprint.GetMatchStore().Delete(mf.ID)
+
+		// Rescan just the file's folder, following the same pattern as the
+		// Subsonic startScan endpoint
+		target := model.ScanTarget{LibraryID: mf.LibraryID, FolderPath: path.Dir(mf.Path)}
+		if target.FolderPath == "." {
+			target.FolderPath = ""
+		}
+		go func() {
+			if _, err := api.scanner.ScanFolders(ctx, false, []model.ScanTarget{target}); err != nil {
+				log.Error(ctx, "Error rescanning after match acceptance", "target", target.String(), err)
+			}
+		}()
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// addPendingMatchRoutes exposes the review queue of pending matches: matches
+// found by automatic identification wait here until a user accepts (see
+// addAcceptMatchRoute), rejects or re-runs them
+func (api *Router) addPendingMatchRoutes(r chi.Router) {
+	r.Route("/identify/pending", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			pending := fingerprint.GetMatchStore().All()
+			slices.SortFunc(pending, func(a, b *fingerprint.PendingMatch) int {
+				if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+					return c
+				}
+				return strings.Compare(a.MediaFileID, b.MediaFileID)
+			})
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(pending); err != nil {
+				log.Error(r.Context(), "Error encoding pending matches", err)
+			}
+		})
+		r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			id := chi.URLParam(r, "id")
+			fingerprint.GetMatchStore().Delete(id)
+			// Also drop the persisted match, or the file would never be
+			// offered for identification again
+			if err := api.ds.Fingerprint(ctx).DeleteMatch(id); err != nil {
+				log.Error(ctx, "Error deleting persisted match", "id", id, err)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+		r.Post("/{id}/rerun", func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			id := chi.URLParam(r, "id")
+
+			mf, err := api.ds.MediaFile(ctx).Get(id)
+			if errors.Is(err, model.ErrNotFound) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				log.Error(ctx, "Error retrieving track for re-identification", "id", id, err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			svc := fingerprint.NewService(api.ds)
+			if !svc.IsEnabled() {
+				http.Error(w, "fingerprinting is not enabled", http.StatusServiceUnavailable)
+				return
+			}
+
+			matches, err := svc.Identify(ctx, mf)
+			if err != nil && !errors.Is(err, fingerprint.ErrNoMatch) {
+				log.Error(ctx, "Error re-identifying track", "id", id, "path", mf.Path, err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			if len(matches) == 0 {
+				fingerprint.GetMatchStore().Delete(mf.ID)
+				matches = []fingerprint.MatchResult{}
+			} else {
+				fingerprint.GetMatchStore().Put(mf.ID, mf.Path, matches)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(matches); err != nil {
+				log.Error(ctx, "Error encoding identification matches", err)
+			}
+		})
+	})
+}
+
+// addIdentifyJobRoutes manages batch identification jobs, which fingerprint a
+// whole album, folder or library in the background. Progress is pushed to
+// clients through the events channel, so these endpoints only start, inspect
+// and cancel jobs
+func (api *Router) addIdentifyJobRoutes(r chi.Router) {
+	r.Route("/identify/job", func(r chi.Router) {
+		r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			var payload struct {
+				Kind     string `json:"kind"`
+				TargetID string `json:"targetId"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			jobID, err := fingerprint.GetJobRunner().Start(ctx, api.ds, payload.Kind, payload.TargetID)
+			switch {
+			case errors.Is(err, fingerprint.ErrDisabled):
+				http.Error(w, "fingerprinting is not enabled", http.StatusServiceUnavailable)
+				return
+			case errors.Is(err, fingerprint.ErrJobRunning):
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			case errors.Is(err, model.ErrNotFound):
+				http.Error(w, "no files found for target", http.StatusNotFound)
+				return
+			case err != nil:
+				log.Error(ctx, "Error starting identification job", "kind", payload.Kind,
+					"targetId", payload.TargetID, err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"` + jobID + `"}`))
+		})
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(fingerprint.GetJobRunner().Status()); err != nil {
+				log.Error(r.Context(), "Error encoding identification job status", err)
+			}
+		})
+		r.Delete("/", func(w http.ResponseWriter, r *http.Request) {
+			fingerprint.GetJobRunner().Cancel()
+			w.WriteHeader(http.StatusNoContent)
+		})
+	})
+}
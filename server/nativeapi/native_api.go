@@ -18,6 +18,7 @@ import (
 	"github.com/navidrome/navidrome/model"
 	"github.com/navidrome/navidrome/model/request"
 	"github.com/navidrome/navidrome/server"
+	"github.com/navidrome/navidrome/server/dlna"
 )
 
 type Router struct {
@@ -28,10 +29,11 @@ type Router struct {
 	insights    metrics.Insights
 	libs        core.Library
 	maintenance core.Maintenance
+	scanner     model.Scanner
 }
 
-func New(ds model.DataStore, share core.Share, playlists core.Playlists, insights metrics.Insights, libraryService core.Library, maintenance core.Maintenance) *Router {
-	r := &Router{ds: ds, share: share, playlists: playlists, insights: insights, libs: libraryService, maintenance: maintenance}
+func New(ds model.DataStore, share core.Share, playlists core.Playlists, insights metrics.Insights, libraryService core.Library, maintenance core.Maintenance, scanner model.Scanner) *Router {
+	r := &Router{ds: ds, share: share, playlists: playlists, insights: insights, libs: libraryService, maintenance: maintenance, scanner: scanner}
 	r.Handler = r.routes()
 	return r
 }
@@ -56,6 +58,7 @@ func (api *Router) routes() http.Handler {
 		api.R(r, "/transcoding", model.Transcoding{}, conf.Server.EnableTranscodingConfig)
 		api.R(r, "/radio", model.Radio{}, true)
 		api.R(r, "/tag", model.Tag{}, true)
+		api.R(r, "/castAudit", model.CastAuditEntry{}, false)
 		if conf.Server.EnableSharing {
 			api.RX(r, "/share", api.share.NewRepository, true)
 		}
@@ -65,13 +68,20 @@ func (api *Router) routes() http.Handler {
 		api.addSongPlaylistsRoute(r)
 		api.addQueueRoute(r)
 		api.addMissingFilesRoute(r)
+		api.addIdentifyRoute(r)
 		api.addKeepAliveRoute(r)
 		api.addInsightsRoute(r)
 
 		r.With(adminOnlyMiddleware).Group(func(r chi.Router) {
+			// Reviewing matches applies or discards metadata changes, so the
+			// whole queue is admin-only, like accepting a match
+			api.addAcceptMatchRoute(r)
+			api.addPendingMatchRoutes(r)
 			api.addInspectRoute(r)
 			api.addConfigRoute(r)
 			api.addUserLibraryRoute(r)
+			api.addDLNAClientsRoute(r)
+			api.addIdentifyJobRoutes(r)
 			api.RX(r, "/library", api.libs.NewRepository, true)
 		})
 	})
@@ -217,6 +227,15 @@ func (api *Router) addConfigRoute(r chi.Router) {
 	}
 }
 
+func (api *Router) addDLNAClientsRoute(r chi.Router) {
+	r.Get("/dlna/clients", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(dlna.ActiveClients()); err != nil {
+			log.Error(r.Context(), "Error encoding DLNA clients", err)
+		}
+	})
+}
+
 func (api *Router) addKeepAliveRoute(r chi.Router) {
 	r.Get("/keepalive/*", func(w http.ResponseWriter, r *http.Request) {
 		_, _ = w.Write([]byte(`{"response":"ok", "id":"keepalive"}`))
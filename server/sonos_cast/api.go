@@ -2,18 +2,25 @@ package sonos_cast
 
 import (
 	"context"
-	"crypto/md5"
-	"crypto/rand"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/core"
+	"github.com/navidrome/navidrome/core/artwork"
+	"github.com/navidrome/navidrome/core/scrobbler"
 	"github.com/navidrome/navidrome/log"
 	"github.com/navidrome/navidrome/model"
 	"github.com/navidrome/navidrome/model/request"
@@ -24,13 +31,21 @@ import (
 type API struct {
 	sonosCast *SonosCast
 	ds        model.DataStore
+	streamer  core.MediaStreamer
+	artwork   artwork.Artwork
+	share     core.Share
+	tracker   *PlaybackTracker
 }
 
 // NewAPI creates a new Sonos Cast API handler
-func NewAPI(sonosCast *SonosCast, ds model.DataStore) *API {
+func NewAPI(sonosCast *SonosCast, ds model.DataStore, streamer core.MediaStreamer, artwork artwork.Artwork, share core.Share, plays scrobbler.PlayTracker) *API {
 	return &API{
 		sonosCast: sonosCast,
 		ds:        ds,
+		streamer:  streamer,
+		artwork:   artwork,
+		share:     share,
+		tracker:   NewPlaybackTracker(sonosCast, plays),
 	}
 }
 
@@ -38,70 +53,847 @@ func NewAPI(sonosCast *SonosCast, ds model.DataStore) *API {
 func (a *API) Router() http.Handler {
 	r := chi.NewRouter()
 
-	// Apply authentication middleware - user must be logged in
+	// Stream and art endpoints - speakers can't authenticate, so access is
+	// granted by the signed expiring token embedded in the URL instead
+	r.Get("/stream/{token}", a.handleStream)
+	r.Get("/art/{token}", a.handleArt)
+
+	// All remaining endpoints require a logged-in user
 	log.Info("Setting up Sonos Cast router with authentication middleware")
-	r.Use(server.Authenticator(a.ds))
-	r.Use(server.JWTRefresher)
-
-	// Device endpoints
-	r.Get("/devices", a.getDevices)
-	r.Post("/devices/refresh", a.refreshDevices)
-	r.Get("/devices/{id}", a.getDevice)
-	r.Get("/devices/{id}/state", a.getDeviceState)
-
-	// Playback control
-	r.Post("/devices/{id}/play", a.play)
-	r.Post("/devices/{id}/pause", a.pause)
-	r.Post("/devices/{id}/stop", a.stop)
-	r.Post("/devices/{id}/seek", a.seek)
-	r.Post("/devices/{id}/next", a.next)
-	r.Post("/devices/{id}/previous", a.previous)
-
-	// Volume control
-	r.Get("/devices/{id}/volume", a.getVolume)
-	r.Post("/devices/{id}/volume", a.setVolume)
-	r.Post("/devices/{id}/mute", a.setMute)
-
-	// Cast media
-	r.Post("/devices/{id}/cast", a.castMedia)
+	r.Group(func(r chi.Router) {
+		r.Use(server.Authenticator(a.ds))
+		r.Use(server.JWTRefresher)
+
+		// Device endpoints
+		r.Get("/devices", a.getDevices)
+		r.Post("/devices/refresh", a.refreshDevices)
+		r.Get("/devices/health", a.getDevicesHealth)
+		r.Get("/groups", a.getGroups)
+
+		// Per-user default device and browser-to-speaker handoff
+		r.Get("/default-device", a.getDefaultDevice)
+		r.Put("/default-device", a.setDefaultDevice)
+		r.Post("/handoff", a.handoffPlayback)
+
+		// Per-device endpoints, gated by the device permission model
+		r.Route("/devices/{id}", func(r chi.Router) {
+			r.Use(a.requireDeviceAccess)
+
+			r.Get("/", a.getDevice)
+			r.Get("/state", a.getDeviceState)
+			r.Get("/settings", a.getDeviceSettings)
+			r.Put("/settings", a.setDeviceSettings)
+			r.Get("/permissions", a.getDevicePermissions)
+			r.Put("/permissions", a.setDevicePermissions)
+
+			// Playback control
+			r.Post("/play", a.play)
+			r.Post("/pause", a.pause)
+			r.Post("/stop", a.stop)
+			r.Post("/seek", a.seek)
+			r.Post("/next", a.next)
+			r.Post("/previous", a.previous)
+
+			// Queue inspection and editing
+			r.Get("/queue", a.getQueue)
+			r.Delete("/queue", a.clearQueue)
+			r.Delete("/queue/{index}", a.removeFromQueue)
+			r.Post("/queue/reorder", a.reorderQueue)
+			r.Post("/queue/save", a.saveQueue)
+
+			// Play mode control
+			r.Get("/playmode", a.getPlayMode)
+			r.Post("/playmode", a.setPlayMode)
+
+			// Transfer playback to another device
+			r.Post("/transfer", a.transferPlayback)
+			r.Post("/announce", a.announce)
+
+			r.Get("/party", a.getParty)
+			r.Post("/party", a.startParty)
+			r.Delete("/party", a.stopParty)
+
+			// Playback snapshot/restore - the primitive behind announce,
+			// transfer and "undo that cast"
+			r.Get("/snapshot", a.getSnapshot)
+			r.Post("/restore", a.restoreSnapshot)
+
+			// Sleep timer
+			r.Post("/sleep", a.setSleepTimer)
+
+			// Crossfade control
+			r.Get("/crossfade", a.getCrossfade)
+			r.Post("/crossfade", a.setCrossfade)
+			r.Get("/eq", a.getEQ)
+			r.Post("/eq", a.setEQ)
+
+			// Volume control
+			r.Get("/volume", a.getVolume)
+			r.Post("/volume", a.setVolume)
+			r.Post("/mute", a.setMute)
+
+			// Sonos Favorites ("My Sonos")
+			r.Post("/favorites", a.addFavorite)
+
+			// Cast media
+			r.Post("/cast", a.castMedia)
+		})
+	})
 
 	return r
 }
 
-// getDevices returns all discovered Sonos devices
-func (a *API) getDevices(w http.ResponseWriter, r *http.Request) {
-	devices := a.sonosCast.GetDevices()
-	a.sendJSON(w, http.StatusOK, devices)
+// getDevices returns all discovered Sonos devices the user may control
+func (a *API) getDevices(w http.ResponseWriter, r *http.Request) {
+	devices := a.filterAllowedDevices(r.Context(), a.sonosCast.GetDevices())
+	a.sendJSON(w, http.StatusOK, devices)
+}
+
+// refreshDevices kicks off a new SSDP discovery. A scan takes the full SSDP
+// window, so it runs in the background - the UI gets a sonosDevicesChanged
+// event (and re-fetches the device list) as each device answers
+func (a *API) refreshDevices(w http.ResponseWriter, r *http.Request) {
+	// Detach from the request context - the scan outlives the response
+	ctx := context.WithoutCancel(r.Context())
+	go func() {
+		if err := a.sonosCast.RefreshDevices(ctx); err != nil {
+			log.Error(ctx, "Sonos device refresh failed", err)
+		}
+	}()
+	a.sendJSON(w, http.StatusAccepted, map[string]string{"status": "scanning"})
+}
+
+// deviceHealthEntry combines discovery info and SOAP call history for one
+// device in the health report
+type deviceHealthEntry struct {
+	UUID      string    `json:"uuid"`
+	RoomName  string    `json:"roomName"`
+	Reachable bool      `json:"reachable"`
+	LastSeen  time.Time `json:"lastSeen"` // last SSDP sighting
+	DeviceHealthStats
+}
+
+// getDevicesHealth summarizes per-device reachability, last SSDP sighting,
+// last successful SOAP call, consecutive failures and average call latency,
+// for debugging "speaker not responding" reports (admin only)
+func (a *API) getDevicesHealth(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	user, ok := request.UserFrom(ctx)
+	if !ok || !user.IsAdmin {
+		a.sendError(w, http.StatusForbidden, "only admins can view device health")
+		return
+	}
+
+	devices := a.sonosCast.GetDevices()
+	entries := make([]deviceHealthEntry, 0, len(devices))
+	for _, device := range devices {
+		entries = append(entries, deviceHealthEntry{
+			UUID:              device.UUID,
+			RoomName:          device.RoomName,
+			Reachable:         device.Reachable,
+			LastSeen:          device.LastSeen,
+			DeviceHealthStats: a.sonosCast.DeviceHealth(device.UUID),
+		})
+	}
+	a.sendJSON(w, http.StatusOK, entries)
+}
+
+// filterAllowedDevices drops devices the requesting user may not control
+func (a *API) filterAllowedDevices(ctx context.Context, devices []*SonosDevice) []*SonosDevice {
+	allowed := make([]*SonosDevice, 0, len(devices))
+	for _, device := range devices {
+		if a.canControlDevice(ctx, device.UUID) {
+			allowed = append(allowed, device)
+		}
+	}
+	return allowed
+}
+
+// getDevice returns a specific device by UUID
+func (a *API) getDevice(w http.ResponseWriter, r *http.Request) {
+	deviceID := chi.URLParam(r, "id")
+	device, ok := a.sonosCast.GetDevice(deviceID)
+	if !ok {
+		a.sendError(w, http.StatusNotFound, "device not found")
+		return
+	}
+	a.sendJSON(w, http.StatusOK, device)
+}
+
+// getDeviceState returns the current playback state of a device
+func (a *API) getDeviceState(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	state, err := a.sonosCast.GetPlaybackState(ctx, deviceID)
+	if err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	// Enrich track with quality info from database. Only Navidrome streams
+	// have a library entry to look up - anything else is another source
+	if state.Source == SourceNavidrome && state.CurrentTrack != nil {
+		a.enrichTrackQuality(ctx, state.CurrentTrack)
+	}
+
+	a.sendJSON(w, http.StatusOK, state)
+}
+
+// getDeviceSettings returns the persisted per-device settings
+func (a *API) getDeviceSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	if _, ok := a.sonosCast.GetDevice(deviceID); !ok {
+		a.sendError(w, http.StatusNotFound, "device not found")
+		return
+	}
+	a.sendJSON(w, http.StatusOK, loadDeviceSettings(ctx, a.ds, deviceID))
+}
+
+// setDeviceSettings updates the persisted per-device settings (admin only)
+func (a *API) setDeviceSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	user, ok := request.UserFrom(ctx)
+	if !ok || !user.IsAdmin {
+		a.sendError(w, http.StatusForbidden, "only admins can change device settings")
+		return
+	}
+
+	if _, ok := a.sonosCast.GetDevice(deviceID); !ok {
+		a.sendError(w, http.StatusNotFound, "device not found")
+		return
+	}
+
+	var settings DeviceSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if settings.MaxVolume < 0 || settings.MaxVolume > 100 ||
+		settings.DefaultVolume < 0 || settings.DefaultVolume > 100 {
+		a.sendError(w, http.StatusBadRequest, "volumes must be between 0 and 100")
+		return
+	}
+	if settings.FadeSeconds < 0 || settings.FadeSeconds > 30 {
+		a.sendError(w, http.StatusBadRequest, "fadeSeconds must be between 0 and 30")
+		return
+	}
+
+	if err := saveDeviceSettings(ctx, a.ds, deviceID, settings); err != nil {
+		a.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.sendJSON(w, http.StatusOK, settings)
+}
+
+// getDevicePermissions returns the list of users allowed to control a device (admin only)
+func (a *API) getDevicePermissions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	user, ok := request.UserFrom(ctx)
+	if !ok || !user.IsAdmin {
+		a.sendError(w, http.StatusForbidden, "only admins can view device permissions")
+		return
+	}
+
+	if _, ok := a.sonosCast.GetDevice(deviceID); !ok {
+		a.sendError(w, http.StatusNotFound, "device not found")
+		return
+	}
+	a.sendJSON(w, http.StatusOK, loadDevicePermissions(ctx, a.ds, deviceID))
+}
+
+// setDevicePermissions updates the list of users allowed to control a device (admin only)
+func (a *API) setDevicePermissions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	user, ok := request.UserFrom(ctx)
+	if !ok || !user.IsAdmin {
+		a.sendError(w, http.StatusForbidden, "only admins can change device permissions")
+		return
+	}
+
+	if _, ok := a.sonosCast.GetDevice(deviceID); !ok {
+		a.sendError(w, http.StatusNotFound, "device not found")
+		return
+	}
+
+	var perms DevicePermissions
+	if err := json.NewDecoder(r.Body).Decode(&perms); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := saveDevicePermissions(ctx, a.ds, deviceID, perms); err != nil {
+		a.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.sendJSON(w, http.StatusOK, perms)
+}
+
+// enrichTrackQuality looks up track in database and adds quality info
+func (a *API) enrichTrackQuality(ctx context.Context, track *Track) {
+	// Extract track ID from stream URI
+	// URI format: http://host:port/rest/stream?id=TRACKID&u=...
+	trackID := extractTrackIDFromURI(track.URI)
+	if trackID == "" {
+		return
+	}
+
+	// Look up track in database
+	mfRepo := a.ds.MediaFile(ctx)
+	mf, err := mfRepo.Get(trackID)
+	if err != nil {
+		log.Debug(ctx, "Could not look up track for quality info", "trackID", trackID, "error", err)
+		return
+	}
+
+	// Populate quality fields
+	track.Format = strings.ToUpper(mf.Suffix)
+	track.BitRate = mf.BitRate
+	track.SampleRate = mf.SampleRate
+	track.BitDepth = mf.BitDepth
+
+	// Check if transcoding is likely happening
+	// Sonos can't handle >48kHz, so hi-res audio gets transcoded
+	track.Transcoding = mf.SampleRate > 48000
+
+	log.Debug(ctx, "Enriched track with quality info",
+		"trackID", trackID,
+		"format", track.Format,
+		"bitRate", track.BitRate,
+		"sampleRate", track.SampleRate,
+		"bitDepth", track.BitDepth,
+		"transcoding", track.Transcoding)
+}
+
+// extractTrackIDFromURI extracts the track ID from one of our stream URLs
+func extractTrackIDFromURI(uri string) string {
+	// Parse the URL
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+
+	// Signed stream URLs carry the track ID inside the token path segment
+	if idx := strings.LastIndex(parsed.Path, "/stream/"); idx != -1 {
+		if info, err := decodeStreamToken(parsed.Path[idx+len("/stream/"):]); err == nil {
+			return info.id
+		}
+	}
+
+	// Fall back to the "id" query parameter used by older Subsonic-style URLs
+	return parsed.Query().Get("id")
+}
+
+// play starts playback on a device
+func (a *API) play(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	if err := a.sonosCast.Play(ctx, deviceID); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.recordAudit(ctx, deviceID, auditActionPlay, "", "")
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "playing"})
+}
+
+// pause pauses playback on a device
+func (a *API) pause(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	// With fading configured, ramp down first and put the volume back once
+	// the transport is paused, so resuming doesn't start silent
+	prevVolume := a.fadeOutBeforeStop(ctx, deviceID)
+	err := a.sonosCast.Pause(ctx, deviceID)
+	a.restoreVolumeAfterStop(ctx, deviceID, prevVolume)
+	if err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.recordAudit(ctx, deviceID, auditActionPause, "", "")
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "paused"})
+}
+
+// stop stops playback on a device
+func (a *API) stop(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	prevVolume := a.fadeOutBeforeStop(ctx, deviceID)
+	err := a.sonosCast.Stop(ctx, deviceID)
+	a.restoreVolumeAfterStop(ctx, deviceID, prevVolume)
+	if err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.tracker.StopTracking(deviceID)
+	a.stopQueuePlayer(deviceID)
+	a.stopPartySession(deviceID)
+	deviceQueues.Delete(deviceID)
+	a.recordAudit(ctx, deviceID, auditActionStop, "", "")
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "stopped"})
+}
+
+// seekRequest is the request body for seek
+type seekRequest struct {
+	Position int `json:"position"` // seconds
+}
+
+// seek seeks to a position on a device
+func (a *API) seek(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	var req seekRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	position := time.Duration(req.Position) * time.Second
+	if err := a.sonosCast.Seek(ctx, deviceID, position); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "seeked"})
+}
+
+// next skips to the next track on a device
+func (a *API) next(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	if err := a.sonosCast.Next(ctx, deviceID); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.recordAudit(ctx, deviceID, auditActionNext, "", "")
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "next"})
+}
+
+// previous goes to the previous track on a device
+func (a *API) previous(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	if err := a.sonosCast.Previous(ctx, deviceID); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.recordAudit(ctx, deviceID, auditActionPrevious, "", "")
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "previous"})
+}
+
+// getQueue returns the device's playback queue, with entries mapped back to
+// Navidrome tracks where possible
+func (a *API) getQueue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("_start"))
+	count, _ := strconv.Atoi(r.URL.Query().Get("_end"))
+	if count <= 0 {
+		count = 100
+	} else {
+		count -= start
+	}
+
+	items, total, err := a.sonosCast.GetQueue(ctx, deviceID, start, count)
+	if err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	// Resolve Navidrome tracks from the stream URIs, preferring our own
+	// metadata over whatever the speaker reports
+	mfRepo := a.ds.MediaFile(ctx)
+	for i := range items {
+		trackID := extractTrackIDFromURI(items[i].URI)
+		if trackID == "" {
+			continue
+		}
+		items[i].TrackID = trackID
+		if mf, err := mfRepo.Get(trackID); err == nil {
+			items[i].Title = mf.Title
+			items[i].Artist = mf.Artist
+			items[i].Album = mf.Album
+			items[i].Duration = int(mf.Duration)
+		}
+	}
+
+	a.sendJSON(w, http.StatusOK, map[string]interface{}{"items": items, "total": total})
+}
+
+// clearQueue removes all tracks from the device's queue
+func (a *API) clearQueue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	if err := a.sonosCast.ClearQueue(ctx, deviceID); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "cleared"})
+}
+
+// removeFromQueue removes a single track (1-based index) from the device's queue
+func (a *API) removeFromQueue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	index, err := strconv.Atoi(chi.URLParam(r, "index"))
+	if err != nil || index < 1 {
+		a.sendError(w, http.StatusBadRequest, "index must be a positive number")
+		return
+	}
+
+	if err := a.sonosCast.RemoveFromQueue(ctx, deviceID, index); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "removed"})
+}
+
+// reorderQueueRequest is the request body for reordering queue tracks
+type reorderQueueRequest struct {
+	Start        int `json:"start"` // 1-based
+	Count        int `json:"count"`
+	InsertBefore int `json:"insertBefore"` // 1-based
+}
+
+// reorderQueue moves a block of tracks within the device's queue
+func (a *API) reorderQueue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	var req reorderQueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+	if req.Start < 1 || req.InsertBefore < 1 {
+		a.sendError(w, http.StatusBadRequest, "start and insertBefore must be positive numbers")
+		return
+	}
+
+	if err := a.sonosCast.ReorderQueue(ctx, deviceID, req.Start, req.Count, req.InsertBefore); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "reordered"})
+}
+
+// saveQueueRequest is the request body for saving the queue as a playlist
+type saveQueueRequest struct {
+	Name string `json:"name"`
+}
+
+// saveQueue reads the device's queue and saves the resolvable Navidrome tracks
+// as a new playlist owned by the requesting user
+func (a *API) saveQueue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	var req saveQueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		a.sendError(w, http.StatusBadRequest, "invalid request body - name is required")
+		return
+	}
+
+	user, ok := request.UserFrom(ctx)
+	if !ok {
+		a.sendError(w, http.StatusUnauthorized, "no user in context")
+		return
+	}
+
+	// Read the full queue, page by page
+	var trackIds []string
+	start := 0
+	for {
+		items, total, err := a.sonosCast.GetQueue(ctx, deviceID, start, 200)
+		if err != nil {
+			if err == ErrDeviceNotFound {
+				a.sendError(w, http.StatusNotFound, "device not found")
+			} else {
+				a.sendError(w, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		for _, item := range items {
+			if id := extractTrackIDFromURI(item.URI); id != "" {
+				trackIds = append(trackIds, id)
+			}
+		}
+		start += len(items)
+		if len(items) == 0 || start >= total {
+			break
+		}
+	}
+
+	if len(trackIds) == 0 {
+		a.sendError(w, http.StatusBadRequest, "queue contains no Navidrome tracks")
+		return
+	}
+
+	pls := &model.Playlist{Name: req.Name, OwnerID: user.ID}
+	pls.AddMediaFilesByID(trackIds)
+	err := a.ds.WithTxImmediate(func(tx model.DataStore) error {
+		return tx.Playlist(ctx).Put(pls)
+	})
+	if err != nil {
+		log.Error(ctx, "Failed to save Sonos queue as playlist", err, "deviceID", deviceID, "name", req.Name)
+		a.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Info(ctx, "Saved Sonos queue as playlist", "deviceID", deviceID, "playlist", req.Name, "tracks", len(trackIds))
+	a.sendJSON(w, http.StatusCreated, map[string]interface{}{"id": pls.ID, "name": pls.Name, "songCount": len(trackIds)})
+}
+
+// addFavoriteRequest is the request body for pinning a Sonos favorite
+type addFavoriteRequest struct {
+	Type string `json:"type"` // "track", "album" or "playlist"
+	ID   string `json:"id"`
+}
+
+// addFavorite pins a track, album or playlist into the Sonos app's
+// "My Sonos" favorites list. A Sonos favorite references a single URI
+// (full-container favorites would need a SMAPI music service), so album and
+// playlist favorites point at their first track - selecting them in the
+// Sonos app starts playback from there. The stream token embedded in a
+// favorite never expires, unlike regular cast URLs, since favorites must
+// stay playable indefinitely
+func (a *API) addFavorite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	var req addFavoriteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+		a.sendError(w, http.StatusBadRequest, "invalid request body - id is required")
+		return
+	}
+
+	title, track, err := a.favoriteTarget(ctx, req.Type, req.ID)
+	if err != nil {
+		log.Error(ctx, "Failed to resolve favorite target", err, "type", req.Type, "id", req.ID)
+		a.sendError(w, http.StatusNotFound, "item not found")
+		return
+	}
+
+	// Same library check as casting - don't pin tracks the user can't play
+	user, ok := request.UserFrom(ctx)
+	if !ok || !user.HasLibraryAccess(track.LibraryID) {
+		a.sendError(w, http.StatusForbidden, "item not in user's libraries")
+		return
+	}
+
+	baseURL := a.sonosCast.GetStreamBaseURLFor(deviceID)
+	opts := castStreamOptions(ctx, a.ds, track)
+	token := encodePersistentStreamToken(track.ID, opts)
+	streamURL := fmt.Sprintf("%s%s/stream/%s", baseURL, consts.URLPathSonosCast, token)
+
+	artURL := ""
+	if track.HasCoverArt {
+		artURL = buildPersistentCoverArtURL(baseURL, track.AlbumID)
+	}
+	mimeType := track.ContentType()
+	if opts.format != "" {
+		mimeType = mime.TypeByExtension("." + opts.format)
+	}
+
+	didl := didlTrack(track, opts, streamURL, mimeType, artURL)
+	didl.Title = title
+	resMD := BuildDIDLMetadata(didl)
+
+	objectID, err := a.sonosCast.AddFavorite(ctx, deviceID, title, "Navidrome", streamURL, resMD)
+	if err != nil {
+		log.Error(ctx, "Failed to add Sonos favorite", err, "deviceID", deviceID, "title", title)
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	a.sendJSON(w, http.StatusCreated, map[string]string{"status": "added", "objectId": objectID, "title": title})
+}
+
+// favoriteTarget resolves the favorite's display title and the track its
+// stream URI will point at
+func (a *API) favoriteTarget(ctx context.Context, itemType, id string) (string, *model.MediaFile, error) {
+	switch itemType {
+	case "", "track":
+		track, err := a.ds.MediaFile(ctx).Get(id)
+		if err != nil {
+			return "", nil, err
+		}
+		return track.Title, track, nil
+	case "album":
+		album, err := a.ds.Album(ctx).Get(id)
+		if err != nil {
+			return "", nil, err
+		}
+		tracks, err := a.ds.MediaFile(ctx).GetAll(model.QueryOptions{
+			Sort:    "disc_number, track_number",
+			Max:     1,
+			Filters: squirrel.Eq{"album_id": id},
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		if len(tracks) == 0 {
+			return "", nil, model.ErrNotFound
+		}
+		return album.Name, &tracks[0], nil
+	case "playlist":
+		pls, err := a.ds.Playlist(ctx).GetWithTracks(id, true, false)
+		if err != nil {
+			return "", nil, err
+		}
+		tracks := pls.MediaFiles()
+		if len(tracks) == 0 {
+			return "", nil, model.ErrNotFound
+		}
+		return pls.Name, &tracks[0], nil
+	default:
+		return "", nil, fmt.Errorf("unsupported favorite type: %s", itemType)
+	}
+}
+
+// playModeRequest is the request body for play mode changes
+type playModeRequest struct {
+	Shuffle bool   `json:"shuffle"`
+	Repeat  string `json:"repeat"` // none, one, all
+}
+
+// getPlayMode returns the current shuffle/repeat mode of a device
+func (a *API) getPlayMode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	shuffle, repeat, err := a.sonosCast.GetPlayMode(ctx, deviceID)
+	if err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, map[string]interface{}{"shuffle": shuffle, "repeat": repeat})
+}
+
+// setPlayMode sets the shuffle/repeat mode of a device
+func (a *API) setPlayMode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	var req playModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	switch req.Repeat {
+	case "", RepeatNone, RepeatOne, RepeatAll:
+	default:
+		a.sendError(w, http.StatusBadRequest, "repeat must be one of: none, one, all")
+		return
+	}
+
+	if err := a.sonosCast.SetPlayMode(ctx, deviceID, req.Shuffle, req.Repeat); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, map[string]interface{}{"shuffle": req.Shuffle, "repeat": req.Repeat})
+}
+
+// transferRequest is the request body for transferring playback
+type transferRequest struct {
+	TargetID string `json:"targetId"`
 }
 
-// refreshDevices forces a new SSDP discovery
-func (a *API) refreshDevices(w http.ResponseWriter, r *http.Request) {
+// transferPlayback moves the current playback from one device to another
+func (a *API) transferPlayback(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	if err := a.sonosCast.RefreshDevices(ctx); err != nil {
-		a.sendError(w, http.StatusInternalServerError, err.Error())
+	deviceID := chi.URLParam(r, "id")
+
+	var req transferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TargetID == "" {
+		a.sendError(w, http.StatusBadRequest, "invalid request body - targetId is required")
 		return
 	}
-	devices := a.sonosCast.GetDevices()
-	a.sendJSON(w, http.StatusOK, devices)
-}
 
-// getDevice returns a specific device by UUID
-func (a *API) getDevice(w http.ResponseWriter, r *http.Request) {
-	deviceID := chi.URLParam(r, "id")
-	device, ok := a.sonosCast.GetDevice(deviceID)
-	if !ok {
-		a.sendError(w, http.StatusNotFound, "device not found")
+	// The middleware only checks the source device - the target needs its own check
+	if !a.canControlDevice(ctx, req.TargetID) {
+		a.sendError(w, http.StatusForbidden, "you are not allowed to control the target device")
 		return
 	}
-	a.sendJSON(w, http.StatusOK, device)
-}
-
-// getDeviceState returns the current playback state of a device
-func (a *API) getDeviceState(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	deviceID := chi.URLParam(r, "id")
 
-	state, err := a.sonosCast.GetPlaybackState(ctx, deviceID)
+	snapshot, err := a.sonosCast.TransferPlayback(ctx, deviceID, req.TargetID)
 	if err != nil {
 		if err == ErrDeviceNotFound {
 			a.sendError(w, http.StatusNotFound, "device not found")
@@ -111,68 +903,117 @@ func (a *API) getDeviceState(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Enrich track with quality info from database
-	if state.CurrentTrack != nil && state.CurrentTrack.URI != "" {
-		a.enrichTrackQuality(ctx, state.CurrentTrack)
+	// Move any pending queue to the target device
+	if val, ok := deviceQueues.Load(deviceID); ok {
+		deviceQueues.Delete(deviceID)
+		deviceQueues.Store(req.TargetID, val)
 	}
 
-	a.sendJSON(w, http.StatusOK, state)
+	// Move playback tracking and the playlist player to the target device.
+	// A party session stays behind - it is tied to the source device
+	a.tracker.StopTracking(deviceID)
+	a.stopQueuePlayer(deviceID)
+	a.stopPartySession(deviceID)
+	if trackID := extractTrackIDFromURI(snapshot.URI); trackID != "" && snapshot.State == StatePlaying {
+		if mf, err := a.ds.MediaFile(ctx).Get(trackID); err == nil {
+			a.tracker.Track(ctx, req.TargetID, trackID, int(mf.Duration))
+		}
+		// The next-URI hint doesn't survive the transfer - re-queue it on the
+		// target and let the player keep the queue going from there
+		if nextTrackID := a.preQueueNext(ctx, req.TargetID); nextTrackID != "" {
+			a.startQueuePlayer(ctx, req.TargetID, trackID, nextTrackID)
+		}
+	}
+
+	a.recordAudit(ctx, req.TargetID, auditActionTransfer, "device", deviceID)
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "transferred", "targetId": req.TargetID})
 }
 
-// enrichTrackQuality looks up track in database and adds quality info
-func (a *API) enrichTrackQuality(ctx context.Context, track *Track) {
-	// Extract track ID from stream URI
-	// URI format: http://host:port/rest/stream?id=TRACKID&u=...
-	trackID := extractTrackIDFromURI(track.URI)
-	if trackID == "" {
+// announceRequest is the request body for playing a notification clip.
+// Either a library track ID or a raw URI reachable by the speaker is required
+type announceRequest struct {
+	TrackID string `json:"trackId"`
+	URI     string `json:"uri"`
+	Volume  int    `json:"volume"` // optional, 0 keeps the current volume
+}
+
+// announce plays a short notification on a device, then restores playback
+func (a *API) announce(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	var req announceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
-
-	// Look up track in database
-	mfRepo := a.ds.MediaFile(ctx)
-	mf, err := mfRepo.Get(trackID)
-	if err != nil {
-		log.Debug(ctx, "Could not look up track for quality info", "trackID", trackID, "error", err)
+	if req.TrackID == "" && req.URI == "" {
+		a.sendError(w, http.StatusBadRequest, "trackId or uri is required")
+		return
+	}
+	if req.Volume < 0 || req.Volume > 100 {
+		a.sendError(w, http.StatusBadRequest, "volume must be between 0 and 100")
 		return
 	}
 
-	// Populate quality fields
-	track.Format = strings.ToUpper(mf.Suffix)
-	track.BitRate = mf.BitRate
-	track.SampleRate = mf.SampleRate
-	track.BitDepth = mf.BitDepth
-
-	// Check if transcoding is likely happening
-	// Sonos can't handle >48kHz, so hi-res audio gets transcoded
-	track.Transcoding = mf.SampleRate > 48000
+	uri := req.URI
+	metadata := ""
+	if req.TrackID != "" {
+		media, err := a.buildTrackMedia(ctx, deviceID, req.TrackID)
+		if err != nil {
+			if err == ErrNotAuthorized {
+				a.sendError(w, http.StatusForbidden, err.Error())
+			} else {
+				a.sendError(w, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		uri = media.streamURL
+		metadata = media.metadata
+	}
 
-	log.Debug(ctx, "Enriched track with quality info",
-		"trackID", trackID,
-		"format", track.Format,
-		"bitRate", track.BitRate,
-		"sampleRate", track.SampleRate,
-		"bitDepth", track.BitDepth,
-		"transcoding", track.Transcoding)
+	if err := a.sonosCast.Announce(ctx, deviceID, uri, metadata, req.Volume); err != nil {
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "announced"})
 }
 
-// extractTrackIDFromURI extracts the track ID from a Subsonic stream URL
-func extractTrackIDFromURI(uri string) string {
-	// Parse the URL
-	parsed, err := url.Parse(uri)
+// getSnapshot captures the device's full playback state (transport URI,
+// queue, position, volume and mute). Clients can hold on to the returned
+// snapshot and POST it back to /restore later, e.g. to undo a cast
+func (a *API) getSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	snapshot, err := a.sonosCast.SnapshotPlayback(ctx, deviceID)
 	if err != nil {
-		return ""
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
 	}
-
-	// Get the "id" query parameter
-	return parsed.Query().Get("id")
+	a.sendJSON(w, http.StatusOK, snapshot)
 }
 
-// play starts playback on a device
-func (a *API) play(w http.ResponseWriter, r *http.Request) {
+// restoreSnapshot applies a snapshot previously returned by getSnapshot
+func (a *API) restoreSnapshot(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	deviceID := chi.URLParam(r, "id")
 
-	if err := a.sonosCast.Play(ctx, deviceID); err != nil {
+	var snapshot PlaybackSnapshot
+	if err := json.NewDecoder(r.Body).Decode(&snapshot); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := a.sonosCast.RestorePlayback(ctx, deviceID, &snapshot); err != nil {
 		if err == ErrDeviceNotFound {
 			a.sendError(w, http.StatusNotFound, "device not found")
 		} else {
@@ -180,15 +1021,32 @@ func (a *API) play(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	a.sendJSON(w, http.StatusOK, map[string]string{"status": "playing"})
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "restored"})
 }
 
-// pause pauses playback on a device
-func (a *API) pause(w http.ResponseWriter, r *http.Request) {
+// sleepTimerRequest is the request body for the sleep timer
+type sleepTimerRequest struct {
+	Duration int `json:"duration"` // seconds, 0 cancels the timer
+}
+
+// setSleepTimer sets or cancels the sleep timer on a device
+func (a *API) setSleepTimer(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	deviceID := chi.URLParam(r, "id")
 
-	if err := a.sonosCast.Pause(ctx, deviceID); err != nil {
+	var req sleepTimerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.Duration < 0 {
+		a.sendError(w, http.StatusBadRequest, "duration must not be negative")
+		return
+	}
+
+	duration := time.Duration(req.Duration) * time.Second
+	if err := a.sonosCast.SetSleepTimer(ctx, deviceID, duration); err != nil {
 		if err == ErrDeviceNotFound {
 			a.sendError(w, http.StatusNotFound, "device not found")
 		} else {
@@ -196,15 +1054,21 @@ func (a *API) pause(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	a.sendJSON(w, http.StatusOK, map[string]string{"status": "paused"})
+	a.sendJSON(w, http.StatusOK, map[string]int{"sleepTimer": req.Duration})
 }
 
-// stop stops playback on a device
-func (a *API) stop(w http.ResponseWriter, r *http.Request) {
+// crossfadeRequest is the request body for crossfade changes
+type crossfadeRequest struct {
+	Crossfade bool `json:"crossfade"`
+}
+
+// getCrossfade returns the current crossfade setting of a device
+func (a *API) getCrossfade(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	deviceID := chi.URLParam(r, "id")
 
-	if err := a.sonosCast.Stop(ctx, deviceID); err != nil {
+	crossfade, err := a.sonosCast.GetCrossfade(ctx, deviceID)
+	if err != nil {
 		if err == ErrDeviceNotFound {
 			a.sendError(w, http.StatusNotFound, "device not found")
 		} else {
@@ -212,27 +1076,21 @@ func (a *API) stop(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	a.sendJSON(w, http.StatusOK, map[string]string{"status": "stopped"})
-}
-
-// seekRequest is the request body for seek
-type seekRequest struct {
-	Position int `json:"position"` // seconds
+	a.sendJSON(w, http.StatusOK, map[string]bool{"crossfade": crossfade})
 }
 
-// seek seeks to a position on a device
-func (a *API) seek(w http.ResponseWriter, r *http.Request) {
+// setCrossfade enables or disables crossfade on a device
+func (a *API) setCrossfade(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	deviceID := chi.URLParam(r, "id")
 
-	var req seekRequest
+	var req crossfadeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		a.sendError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	position := time.Duration(req.Position) * time.Second
-	if err := a.sonosCast.Seek(ctx, deviceID, position); err != nil {
+	if err := a.sonosCast.SetCrossfade(ctx, deviceID, req.Crossfade); err != nil {
 		if err == ErrDeviceNotFound {
 			a.sendError(w, http.StatusNotFound, "device not found")
 		} else {
@@ -240,15 +1098,24 @@ func (a *API) seek(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	a.sendJSON(w, http.StatusOK, map[string]string{"status": "seeked"})
+	a.sendJSON(w, http.StatusOK, map[string]bool{"crossfade": req.Crossfade})
 }
 
-// next skips to the next track on a device
-func (a *API) next(w http.ResponseWriter, r *http.Request) {
+// eqRequest is the request body for EQ changes. Fields are pointers so
+// callers can adjust a single control without resetting the others
+type eqRequest struct {
+	Bass     *int  `json:"bass"`   // -10 to 10
+	Treble   *int  `json:"treble"` // -10 to 10
+	Loudness *bool `json:"loudness"`
+}
+
+// getEQ returns the current tone controls of a device
+func (a *API) getEQ(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	deviceID := chi.URLParam(r, "id")
 
-	if err := a.sonosCast.Next(ctx, deviceID); err != nil {
+	eq, err := a.sonosCast.GetEQ(ctx, deviceID)
+	if err != nil {
 		if err == ErrDeviceNotFound {
 			a.sendError(w, http.StatusNotFound, "device not found")
 		} else {
@@ -256,15 +1123,40 @@ func (a *API) next(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	a.sendJSON(w, http.StatusOK, map[string]string{"status": "next"})
+	a.sendJSON(w, http.StatusOK, eq)
 }
 
-// previous goes to the previous track on a device
-func (a *API) previous(w http.ResponseWriter, r *http.Request) {
+// setEQ updates one or more tone controls on a device
+func (a *API) setEQ(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	deviceID := chi.URLParam(r, "id")
 
-	if err := a.sonosCast.Previous(ctx, deviceID); err != nil {
+	var req eqRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Bass == nil && req.Treble == nil && req.Loudness == nil {
+		a.sendError(w, http.StatusBadRequest, "at least one of bass, treble or loudness is required")
+		return
+	}
+	if (req.Bass != nil && (*req.Bass < -10 || *req.Bass > 10)) ||
+		(req.Treble != nil && (*req.Treble < -10 || *req.Treble > 10)) {
+		a.sendError(w, http.StatusBadRequest, "bass and treble must be between -10 and 10")
+		return
+	}
+
+	var err error
+	if req.Bass != nil {
+		err = a.sonosCast.SetBass(ctx, deviceID, *req.Bass)
+	}
+	if err == nil && req.Treble != nil {
+		err = a.sonosCast.SetTreble(ctx, deviceID, *req.Treble)
+	}
+	if err == nil && req.Loudness != nil {
+		err = a.sonosCast.SetLoudness(ctx, deviceID, *req.Loudness)
+	}
+	if err != nil {
 		if err == ErrDeviceNotFound {
 			a.sendError(w, http.StatusNotFound, "device not found")
 		} else {
@@ -272,7 +1164,13 @@ func (a *API) previous(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	a.sendJSON(w, http.StatusOK, map[string]string{"status": "previous"})
+
+	eq, err := a.sonosCast.GetEQ(ctx, deviceID)
+	if err != nil {
+		a.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.sendJSON(w, http.StatusOK, eq)
 }
 
 // getVolume returns the current volume of a device
@@ -308,6 +1206,10 @@ func (a *API) setVolume(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Respect the configured per-device volume limit
+	settings := loadDeviceSettings(ctx, a.ds, deviceID)
+	req.Volume = settings.clampVolume(req.Volume)
+
 	if err := a.sonosCast.SetVolume(ctx, deviceID, req.Volume); err != nil {
 		if err == ErrDeviceNotFound {
 			a.sendError(w, http.StatusNotFound, "device not found")
@@ -316,6 +1218,7 @@ func (a *API) setVolume(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	a.recordAudit(ctx, deviceID, auditActionVolume, "", "")
 	a.sendJSON(w, http.StatusOK, map[string]int{"volume": req.Volume})
 }
 
@@ -351,6 +1254,7 @@ type castRequest struct {
 	// New format from UI
 	TrackIds []string `json:"trackIds"` // array of track IDs
 	Resource string   `json:"resource"` // album, playlist, song
+	Mode     string   `json:"mode"`     // replace (default), next, append
 
 	// Legacy format
 	Type       string `json:"type"`       // track, album, playlist
@@ -384,15 +1288,56 @@ func (a *API) castMedia(w http.ResponseWriter, r *http.Request) {
 		log.Debug(ctx, "User for cast", "username", user.UserName)
 	}
 
+	if req.Mode == "" {
+		req.Mode = "replace"
+	}
+	if req.Mode != "replace" && req.Mode != "next" && req.Mode != "append" {
+		a.sendError(w, http.StatusBadRequest, "mode must be one of: replace, next, append")
+		return
+	}
+
 	// Handle new format from UI (trackIds + resource)
 	if len(req.TrackIds) > 0 {
-		log.Info(ctx, "Casting tracks to Sonos", "count", len(req.TrackIds), "resource", req.Resource, "deviceID", deviceID)
+		log.Info(ctx, "Casting tracks to Sonos", "count", len(req.TrackIds), "resource", req.Resource, "deviceID", deviceID, "mode", req.Mode)
+
+		// The "next" and "append" modes only touch the queue - playback is
+		// left alone. For "next" the tracks are enqueued in reverse so they
+		// end up in their original order right after the current track.
+		if req.Mode != "replace" {
+			asNext := req.Mode == "next"
+			trackIds := req.TrackIds
+			if asNext {
+				trackIds = slices.Clone(trackIds)
+				slices.Reverse(trackIds)
+			}
+			for _, trackID := range trackIds {
+				if err := a.enqueueTrack(ctx, deviceID, trackID, asNext); err != nil {
+					log.Error(ctx, "Failed to enqueue track", err, "trackID", trackID, "deviceID", deviceID)
+					if err == ErrDeviceNotFound {
+						a.sendError(w, http.StatusNotFound, "device not found")
+					} else if err == ErrNotAuthorized {
+						a.sendError(w, http.StatusForbidden, err.Error())
+					} else {
+						a.sendError(w, http.StatusInternalServerError, err.Error())
+					}
+					return
+				}
+			}
+			a.recordAudit(ctx, deviceID, auditActionEnqueue, "track", req.TrackIds[0])
+			a.sendJSON(w, http.StatusOK, map[string]string{"status": "queued", "queueSize": fmt.Sprintf("%d", len(req.TrackIds))})
+			return
+		}
 
-		// Cast the first track and start playback
-		if err := a.castTrack(ctx, deviceID, req.TrackIds[0], user); err != nil {
+		// Cast the first track and start playback, replacing any playlist
+		// player or party session still driving the device
+		a.stopQueuePlayer(deviceID)
+		a.stopPartySession(deviceID)
+		if err := a.castTrack(ctx, deviceID, req.TrackIds[0]); err != nil {
 			log.Error(ctx, "Failed to cast track", err, "trackID", req.TrackIds[0], "deviceID", deviceID)
 			if err == ErrDeviceNotFound {
 				a.sendError(w, http.StatusNotFound, "device not found")
+			} else if err == ErrNotAuthorized {
+				a.sendError(w, http.StatusForbidden, err.Error())
 			} else {
 				a.sendError(w, http.StatusInternalServerError, err.Error())
 			}
@@ -401,7 +1346,7 @@ func (a *API) castMedia(w http.ResponseWriter, r *http.Request) {
 
 		// If there are more tracks, set the second one as "next" for gapless playback
 		if len(req.TrackIds) > 1 {
-			if err := a.setNextTrack(ctx, deviceID, req.TrackIds[1], user); err != nil {
+			if err := a.setNextTrack(ctx, deviceID, req.TrackIds[1]); err != nil {
 				// Non-fatal - first track is already playing
 				log.Warn(ctx, "Failed to set next track", err, "trackID", req.TrackIds[1])
 			}
@@ -410,8 +1355,13 @@ func (a *API) castMedia(w http.ResponseWriter, r *http.Request) {
 			if len(req.TrackIds) > 2 {
 				a.storeQueue(deviceID, req.TrackIds[2:], user)
 			}
+
+			// Watch the transport and keep feeding tracks - S1 speakers and
+			// generic renderers never advance to the next URI by themselves
+			a.startQueuePlayer(ctx, deviceID, req.TrackIds[0], req.TrackIds[1])
 		}
 
+		a.recordAudit(ctx, deviceID, auditActionCast, "track", req.TrackIds[0])
 		a.sendJSON(w, http.StatusOK, map[string]string{"status": "casting", "queueSize": fmt.Sprintf("%d", len(req.TrackIds))})
 		return
 	}
@@ -420,15 +1370,37 @@ func (a *API) castMedia(w http.ResponseWriter, r *http.Request) {
 	switch req.Type {
 	case "track":
 		log.Info(ctx, "Casting single track (legacy)", "trackID", req.ID, "deviceID", deviceID)
-		if err := a.castTrack(ctx, deviceID, req.ID, user); err != nil {
+		a.stopQueuePlayer(deviceID)
+		a.stopPartySession(deviceID)
+		if err := a.castTrack(ctx, deviceID, req.ID); err != nil {
 			log.Error(ctx, "Failed to cast track", err, "trackID", req.ID, "deviceID", deviceID)
 			if err == ErrDeviceNotFound {
 				a.sendError(w, http.StatusNotFound, "device not found")
+			} else if err == ErrNotAuthorized {
+				a.sendError(w, http.StatusForbidden, err.Error())
 			} else {
 				a.sendError(w, http.StatusInternalServerError, err.Error())
 			}
 			return
 		}
+		a.recordAudit(ctx, deviceID, auditActionCast, "track", req.ID)
+	case "share":
+		log.Info(ctx, "Casting share", "shareID", req.ID, "deviceID", deviceID)
+		if err := a.castShare(ctx, deviceID, req.ID); err != nil {
+			log.Error(ctx, "Failed to cast share", err, "shareID", req.ID, "deviceID", deviceID)
+			switch {
+			case errors.Is(err, model.ErrExpired):
+				a.sendError(w, http.StatusGone, "share has expired")
+			case errors.Is(err, model.ErrNotFound):
+				a.sendError(w, http.StatusNotFound, "share not found")
+			case errors.Is(err, ErrDeviceNotFound):
+				a.sendError(w, http.StatusNotFound, "device not found")
+			default:
+				a.sendError(w, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		a.recordAudit(ctx, deviceID, auditActionCast, "share", req.ID)
 	case "album":
 		a.sendError(w, http.StatusNotImplemented, "album casting not yet implemented")
 		return
@@ -444,8 +1416,17 @@ func (a *API) castMedia(w http.ResponseWriter, r *http.Request) {
 	a.sendJSON(w, http.StatusOK, map[string]string{"status": "casting"})
 }
 
-// castTrack casts a single track to a device
-func (a *API) castTrack(ctx context.Context, deviceID, trackID string, user model.User) error {
+// trackMedia holds everything needed to point a Sonos device at a track
+type trackMedia struct {
+	track     *model.MediaFile
+	streamURL string
+	metadata  string
+}
+
+// buildTrackMedia looks up a track and builds its stream URL and DIDL
+// metadata. The stream URL is built for the target device, so the right LAN
+// address is used when the server listens on multiple networks
+func (a *API) buildTrackMedia(ctx context.Context, deviceID, trackID string) (*trackMedia, error) {
 	log.Debug(ctx, "Looking up track for cast", "trackID", trackID)
 
 	// Get track from database
@@ -453,123 +1434,248 @@ func (a *API) castTrack(ctx context.Context, deviceID, trackID string, user mode
 	track, err := mfRepo.Get(trackID)
 	if err != nil {
 		log.Error(ctx, "Failed to get track from database", err, "trackID", trackID)
-		return fmt.Errorf("track not found: %w", err)
+		return nil, fmt.Errorf("track not found: %w", err)
 	}
 
 	log.Debug(ctx, "Found track", "title", track.Title, "artist", track.Artist, "album", track.Album,
 		"format", track.Suffix, "sampleRate", track.SampleRate, "bitDepth", track.BitDepth)
 
-	// Get full user with password for Subsonic auth
-	userRepo := a.ds.User(ctx)
-	fullUser, err := userRepo.FindByUsernameWithPassword(user.UserName)
-	if err != nil {
-		log.Error(ctx, "Failed to get user for Subsonic auth", err, "username", user.UserName)
-		return fmt.Errorf("user not found: %w", err)
+	// Make sure the requesting user's libraries include this track before
+	// handing out a stream URL for it
+	user, ok := request.UserFrom(ctx)
+	if !ok || !user.HasLibraryAccess(track.LibraryID) {
+		log.Warn(ctx, "Cast denied - track not in user's libraries", "username", user.UserName,
+			"trackID", trackID, "libraryID", track.LibraryID)
+		return nil, ErrNotAuthorized
+	}
+
+	// Decide whether (and how) to transcode, combining the configured policy
+	// with the requesting player's transcoding profile
+	opts := castStreamOptions(ctx, a.ds, track)
+
+	// If the device is known to refuse this format, go straight to the
+	// transcode target that worked last time
+	if opts.format == "" {
+		formats := loadDeviceFormats(ctx, a.ds, deviceID)
+		if formats.Fallback != "" && formats.rejects(track.Suffix) {
+			log.Debug(ctx, "Device rejects source format - transcoding", "deviceID", deviceID,
+				"format", track.Suffix, "fallback", formats.Fallback)
+			opts.format = formats.Fallback
+		}
 	}
 
+	return a.buildTrackMediaWithOptions(deviceID, track, opts), nil
+}
+
+// buildTrackMediaWithOptions builds the stream URL and DIDL metadata for an
+// already-loaded track, with an explicit transcoding decision
+func (a *API) buildTrackMediaWithOptions(deviceID string, track *model.MediaFile, opts streamOptions) *trackMedia {
 	// Get the base URL for streaming - Sonos needs an absolute URL it can reach
 	// We use the internal IP since Sonos is on the same network
-	baseURL := a.sonosCast.GetStreamBaseURL()
-	log.Debug(ctx, "Using stream base URL", "baseURL", baseURL)
-
-	// Check for hi-res audio that Sonos doesn't support
-	// Sonos FLAC limit: 48kHz sample rate, 24-bit depth
-	needsTranscode := false
-	if track.SampleRate > 48000 {
-		log.Warn(ctx, "Hi-res audio detected - will transcode for Sonos compatibility",
-			"track", track.Title, "sampleRate", track.SampleRate, "limit", 48000)
-		needsTranscode = true
-	}
+	baseURL := a.sonosCast.GetStreamBaseURLFor(deviceID)
 
-	// Build stream URL with Subsonic token auth
-	streamURL := buildStreamURL(baseURL, trackID, fullUser, needsTranscode)
-	log.Debug(ctx, "Built stream URL", "streamURL", streamURL, "transcoding", needsTranscode)
+	// Build a signed, expiring stream URL
+	streamURL := buildStreamURL(baseURL, track.ID, opts)
 
 	// Build album art URL
 	artURL := ""
 	if track.HasCoverArt {
-		artURL = buildCoverArtURL(baseURL, track.AlbumID, fullUser)
-		log.Debug(ctx, "Built cover art URL", "artURL", artURL)
+		artURL = buildCoverArtURL(baseURL, track.AlbumID)
 	}
 
-	// Get MIME type for the stream
+	// Get MIME type for the stream - the target format's, when transcoding
 	mimeType := track.ContentType()
+	if opts.format != "" {
+		mimeType = mime.TypeByExtension("." + opts.format)
+	}
 	if mimeType == "" {
 		mimeType = "audio/flac" // Default fallback
 	}
 
 	// Build DIDL metadata with stream URL and MIME type
 	// The <res> element with protocolInfo is REQUIRED by Sonos
-	// Include duration so Sonos can display track length correctly
-	metadata := a.sonosCast.BuildTrackMetadata(
-		track.ID,
-		track.Title,
-		track.Artist,
-		track.Album,
-		artURL,
-		streamURL,
-		mimeType,
-		track.Duration,
-	)
-	log.Debug(ctx, "Built DIDL metadata", "metadataLen", len(metadata), "mimeType", mimeType, "duration", track.Duration)
+	metadata := a.sonosCast.BuildTrackMetadata(didlTrack(track, opts, streamURL, mimeType, artURL))
 
-	// Cast to device
-	log.Info(ctx, "Sending PlayURI to Sonos", "deviceID", deviceID, "track", track.Title)
-	err = a.sonosCast.PlayURI(ctx, deviceID, streamURL, metadata)
+	return &trackMedia{track: track, streamURL: streamURL, metadata: metadata}
+}
+
+// castTrack casts a single track to a device
+func (a *API) castTrack(ctx context.Context, deviceID, trackID string) error {
+	media, err := a.buildTrackMedia(ctx, deviceID, trackID)
 	if err != nil {
-		log.Error(ctx, "PlayURI failed", err, "deviceID", deviceID, "streamURL", streamURL)
 		return err
 	}
 
-	log.Info(ctx, "Successfully sent cast command", "deviceID", deviceID, "track", track.Title)
+	// Apply the configured default cast volume (clamped to the device limit)
+	// before starting playback, so nothing blasts at the speaker's last
+	// volume. With fade-in configured, playback starts silent and ramps up
+	settings := loadDeviceSettings(ctx, a.ds, deviceID)
+	fadeTarget := a.prepareCastVolume(ctx, deviceID, settings)
+
+	// Cast to device
+	log.Info(ctx, "Sending PlayURI to Sonos", "deviceID", deviceID, "track", media.track.Title)
+	err = a.sonosCast.PlayURI(ctx, deviceID, media.streamURL, media.metadata)
+	if err != nil {
+		// Some devices refuse certain formats with an UPnP fault - retry with a
+		// transcoded stream before giving up
+		media, err = a.retryCastTranscoded(ctx, deviceID, media, err)
+		if err != nil {
+			log.Error(ctx, "PlayURI failed", err, "deviceID", deviceID, "streamURL", media.streamURL)
+			a.restoreVolumeAfterStop(ctx, deviceID, fadeTarget)
+			return err
+		}
+	}
+	a.finishCastVolume(ctx, deviceID, settings, fadeTarget)
+
+	log.Info(ctx, "Successfully sent cast command", "deviceID", deviceID, "track", media.track.Title)
+
+	// Follow playback so plays are counted and scrobbled
+	a.tracker.Track(ctx, deviceID, media.track.ID, int(media.track.Duration))
 	return nil
 }
 
-// generateSubsonicToken generates a Subsonic API token (MD5 of password+salt)
-func generateSubsonicToken(password string) (token, salt string) {
-	// Generate random salt
-	saltBytes := make([]byte, 8)
-	rand.Read(saltBytes)
-	salt = hex.EncodeToString(saltBytes)
+// castRetryFormats are the transcode targets tried, in order, when a device
+// refuses a stream. FLAC keeps quality; MP3 is the lowest common denominator
+var castRetryFormats = []string{"flac", "mp3"}
+
+// retryCastTranscoded handles a PlayURI failure. When the fault is UPnP 714
+// (illegal MIME type) or 701 (transition not available - how some devices
+// report an unplayable stream), it retries with a transcoded stream and
+// corrected protocolInfo, and on success records the working format for
+// future casts to this device. Any other error is returned as-is
+func (a *API) retryCastTranscoded(ctx context.Context, deviceID string, media *trackMedia, playErr error) (*trackMedia, error) {
+	var upnpErr *UPnPError
+	if !errors.As(playErr, &upnpErr) {
+		return media, playErr
+	}
+	if upnpErr.Code != UPnPErrorIllegalMIMEType && upnpErr.Code != UPnPErrorTransitionNotAvailable {
+		return media, playErr
+	}
+
+	source := strings.ToLower(media.track.Suffix)
+	for _, format := range castRetryFormats {
+		if format == source {
+			continue
+		}
+		log.Info(ctx, "Device refused stream - retrying transcoded", "deviceID", deviceID,
+			"track", media.track.Title, "sourceFormat", source, "format", format, "upnpError", upnpErr.Code)
+		retry := a.buildTrackMediaWithOptions(deviceID, media.track, streamOptions{format: format})
+		if err := a.sonosCast.PlayURI(ctx, deviceID, retry.streamURL, retry.metadata); err != nil {
+			log.Warn(ctx, "Transcoded retry failed", "deviceID", deviceID, "format", format, err)
+			continue
+		}
+		// Remember the rejection so future casts transcode straight away
+		markFormatRejected(ctx, a.ds, deviceID, source, format)
+		return retry, nil
+	}
+	return media, playErr
+}
 
-	// Token is MD5(password + salt)
-	hash := md5.Sum([]byte(password + salt))
-	token = hex.EncodeToString(hash[:])
+// enqueueTrack adds a single track to the device's queue, optionally right
+// after the current track
+func (a *API) enqueueTrack(ctx context.Context, deviceID, trackID string, asNext bool) error {
+	media, err := a.buildTrackMedia(ctx, deviceID, trackID)
+	if err != nil {
+		return err
+	}
+	return a.sonosCast.AddToQueue(ctx, deviceID, media.streamURL, media.metadata, asNext)
+}
 
-	return token, salt
+// streamOptions is the transcoding decision for a single stream URL. The
+// zero value means "serve the file as-is"
+type streamOptions struct {
+	format  string
+	bitRate int
 }
 
-// buildStreamURL builds a Subsonic stream URL for a track with token auth
-// If needsTranscode is true, it will request FLAC transcoding at 48kHz for hi-res compatibility
-func buildStreamURL(baseURL, trackID string, user *model.User, needsTranscode bool) string {
-	// Generate Subsonic token auth
-	token, salt := generateSubsonicToken(user.Password)
+// castStreamOptions applies the configured transcoding policy to a track.
+// A transcoding profile assigned to the requesting player takes precedence;
+// otherwise tracks above MaxSampleRate (or all tracks, with ForceTranscoding
+// set) are transcoded to StreamFormat at MaxBitRate
+func castStreamOptions(ctx context.Context, ds model.DataStore, track *model.MediaFile) streamOptions {
+	cfg := conf.Server.SonosCast
+
+	if player, ok := request.PlayerFrom(ctx); ok && player.TranscodingId != "" {
+		t, err := ds.Transcoding(ctx).Get(player.TranscodingId)
+		if err != nil {
+			log.Warn(ctx, "Failed to load player transcoding profile", "transcodingId", player.TranscodingId, err)
+		} else {
+			bitRate := player.MaxBitRate
+			if bitRate == 0 {
+				bitRate = t.DefaultBitRate
+			}
+			return streamOptions{format: t.TargetFormat, bitRate: bitRate}
+		}
+	}
+
+	if cfg.ForceTranscoding {
+		return streamOptions{format: cfg.StreamFormat, bitRate: cfg.MaxBitRate}
+	}
+
+	// Sonos tops out at 48kHz/24-bit FLAC, so hi-res tracks must always be
+	// transcoded. Note: Seeking may be limited with transcoded streams
+	if cfg.MaxSampleRate > 0 && track.SampleRate > cfg.MaxSampleRate {
+		log.Warn(ctx, "Hi-res audio detected - will transcode for Sonos compatibility",
+			"track", track.Title, "sampleRate", track.SampleRate, "limit", cfg.MaxSampleRate)
+		return streamOptions{format: cfg.StreamFormat, bitRate: cfg.MaxBitRate}
+	}
+
+	return streamOptions{}
+}
 
-	if needsTranscode {
-		// Hi-res audio needs transcoding to 48kHz FLAC for Sonos compatibility
-		// We use FLAC to maintain quality, and estimateContentLength for seeking
-		// Note: Seeking may be limited with transcoded streams
-		return fmt.Sprintf("%s/rest/stream?id=%s&u=%s&t=%s&s=%s&c=SonosCast&v=1.16.1&format=flac&maxBitRate=0&estimateContentLength=true",
-			baseURL, trackID, user.UserName, token, salt)
+// didlTrack maps a media file (and the transcoding decision applied to its
+// stream) to the DIDL metadata fields Sonos can display
+func didlTrack(track *model.MediaFile, opts streamOptions, streamURL, mimeType, artURL string) DIDLTrack {
+	t := DIDLTrack{
+		ID:          track.ID,
+		Title:       track.Title,
+		Artist:      track.Artist,
+		Album:       track.Album,
+		AlbumArtURL: artURL,
+		StreamURI:   streamURL,
+		MimeType:    mimeType,
+		Duration:    track.Duration,
+		TrackNumber: track.TrackNumber,
+		Genre:       track.Genre,
+		Date:        track.Date,
 	}
+	if opts.format == "" {
+		// Quality attributes describe the stream, not the library entry - a
+		// transcoded stream's sample rate, channels and size no longer match
+		// the source file, so only advertise them for raw streams
+		t.BitRate = track.BitRate
+		t.SampleRate = track.SampleRate
+		t.Channels = track.Channels
+		t.Size = track.Size
+	} else {
+		t.BitRate = opts.bitRate
+	}
+	return t
+}
 
-	// Build HTTP URL with Subsonic token authentication
-	// Use format=raw to serve original file without transcoding - this ensures:
-	//   1. Proper Content-Length header (required by Sonos for seeking)
-	//   2. Range request support (206 Partial Content responses)
-	//   3. Seek/scrub functionality works correctly
-	// Note: Transcoded streams set Accept-Ranges: none which breaks seeking
-	return fmt.Sprintf("%s/rest/stream?id=%s&u=%s&t=%s&s=%s&c=SonosCast&v=1.16.1&format=raw",
-		baseURL, trackID, user.UserName, token, salt)
+// buildStreamURL builds a signed, expiring stream URL for a track, encoding
+// the transcoding decision into the token
+func buildStreamURL(baseURL, trackID string, opts streamOptions) string {
+	// The signed token replaces the old Subsonic MD5 token auth - it grants
+	// access to this one track and expires, so a URL logged by the speaker
+	// can't be replayed indefinitely or used to reach the rest of the API
+	token := encodeStreamToken(trackID, opts)
+	return fmt.Sprintf("%s%s/stream/%s", baseURL, consts.URLPathSonosCast, token)
 }
 
-// buildCoverArtURL builds a Subsonic cover art URL with token auth
-func buildCoverArtURL(baseURL, albumID string, user *model.User) string {
-	// Generate Subsonic token auth
-	token, salt := generateSubsonicToken(user.Password)
+// buildCoverArtURL builds a cover art URL for DIDL metadata, pointing at the
+// cast art proxy with a signed, expiring token - so the URL the speaker and
+// the Sonos app cache carries no credentials and stops working after a while
+func buildCoverArtURL(baseURL, albumID string) string {
+	token := encodeArtToken("al-" + albumID)
+	return fmt.Sprintf("%s%s/art/%s", baseURL, consts.URLPathSonosCast, token)
+}
 
-	return fmt.Sprintf("%s/rest/getCoverArt?id=%s&u=%s&t=%s&s=%s&c=SonosCast&v=1.16.1",
-		baseURL, albumID, user.UserName, token, salt)
+// buildPersistentCoverArtURL is the non-expiring variant, used in Sonos
+// favorites - their artwork must stay resolvable across sessions
+func buildPersistentCoverArtURL(baseURL, albumID string) string {
+	token := encodePersistentArtToken("al-" + albumID)
+	return fmt.Sprintf("%s%s/art/%s", baseURL, consts.URLPathSonosCast, token)
 }
 
 // sendJSON sends a JSON response
@@ -629,56 +1735,14 @@ func (a *API) getNextFromQueue(deviceID string) (string, *model.User, bool) {
 }
 
 // setNextTrack sets the next track for gapless playback
-func (a *API) setNextTrack(ctx context.Context, deviceID, trackID string, user model.User) error {
+func (a *API) setNextTrack(ctx context.Context, deviceID, trackID string) error {
 	log.Debug(ctx, "Setting next track", "trackID", trackID, "deviceID", deviceID)
 
-	// Get track from database
-	mfRepo := a.ds.MediaFile(ctx)
-	track, err := mfRepo.Get(trackID)
-	if err != nil {
-		return fmt.Errorf("track not found: %w", err)
-	}
-
-	// Get full user with password for Subsonic auth
-	userRepo := a.ds.User(ctx)
-	fullUser, err := userRepo.FindByUsernameWithPassword(user.UserName)
+	media, err := a.buildTrackMedia(ctx, deviceID, trackID)
 	if err != nil {
-		return fmt.Errorf("user not found: %w", err)
-	}
-
-	// Get the base URL for streaming
-	baseURL := a.sonosCast.GetStreamBaseURL()
-
-	// Check for hi-res audio
-	needsTranscode := track.SampleRate > 48000
-
-	// Build stream URL
-	streamURL := buildStreamURL(baseURL, trackID, fullUser, needsTranscode)
-
-	// Build album art URL
-	artURL := ""
-	if track.HasCoverArt {
-		artURL = buildCoverArtURL(baseURL, track.AlbumID, fullUser)
-	}
-
-	// Get MIME type
-	mimeType := track.ContentType()
-	if mimeType == "" {
-		mimeType = "audio/flac"
+		return err
 	}
 
-	// Build DIDL metadata
-	metadata := a.sonosCast.BuildTrackMetadata(
-		track.ID,
-		track.Title,
-		track.Artist,
-		track.Album,
-		artURL,
-		streamURL,
-		mimeType,
-		track.Duration,
-	)
-
 	// Get device and coordinator
 	device, ok := a.sonosCast.GetDevice(deviceID)
 	if !ok {
@@ -687,11 +1751,11 @@ func (a *API) setNextTrack(ctx context.Context, deviceID, trackID string, user m
 
 	// Use the device directly - the transport layer handles coordinator logic
 	// Set next URI for gapless playback
-	err = a.sonosCast.transport.SetNextAVTransportURI(ctx, device, streamURL, metadata)
+	err = a.sonosCast.transport.SetNextAVTransportURI(ctx, device, media.streamURL, media.metadata)
 	if err != nil {
 		return err
 	}
 
-	log.Info(ctx, "Set next track for gapless playback", "deviceID", deviceID, "track", track.Title)
+	log.Info(ctx, "Set next track for gapless playback", "deviceID", deviceID, "track", media.track.Title)
 	return nil
 }
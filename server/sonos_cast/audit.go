@@ -0,0 +1,47 @@
+package sonos_cast
+
+import (
+	"context"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// Audit log action names. One entry is recorded per successful cast or
+// control request, so households can see who took over which room
+const (
+	auditActionCast     = "cast"
+	auditActionEnqueue  = "enqueue"
+	auditActionPlay     = "play"
+	auditActionPause    = "pause"
+	auditActionStop     = "stop"
+	auditActionNext     = "next"
+	auditActionPrevious = "previous"
+	auditActionVolume   = "volume"
+	auditActionTransfer = "transfer"
+	auditActionHandoff  = "handoff"
+	auditActionParty    = "party"
+)
+
+// recordAudit writes an entry to the cast audit log. The user is taken from
+// the request context by the repository. Failures are logged and otherwise
+// ignored - auditing must never get in the way of playback
+func (a *API) recordAudit(ctx context.Context, deviceID, action, itemType, itemID string) {
+	entry := &model.CastAuditEntry{
+		DeviceID: deviceID,
+		Action:   action,
+		ItemType: itemType,
+		ItemID:   itemID,
+	}
+	if device, ok := a.sonosCast.GetDevice(deviceID); ok {
+		entry.RoomName = device.RoomName
+	}
+	if itemType == "track" {
+		if mf, err := a.ds.MediaFile(ctx).Get(itemID); err == nil {
+			entry.ItemName = mf.Title
+		}
+	}
+	if err := a.ds.CastAudit(ctx).Record(entry); err != nil {
+		log.Warn(ctx, "Failed to record cast audit entry", "device", deviceID, "action", action, err)
+	}
+}
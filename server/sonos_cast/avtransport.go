@@ -17,15 +17,19 @@ import (
 
 // AVTransport provides playback control for Sonos devices
 type AVTransport struct {
-	client *http.Client
+	client  *http.Client
+	health  *healthTracker
+	limiter *soapLimiter
 }
 
 // NewAVTransport creates a new AVTransport controller
-func NewAVTransport() *AVTransport {
+func NewAVTransport(health *healthTracker, limiter *soapLimiter) *AVTransport {
 	return &AVTransport{
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: soapTimeout(),
 		},
+		health:  health,
+		limiter: limiter,
 	}
 }
 
@@ -65,6 +69,28 @@ func (a *AVTransport) SetNextAVTransportURI(ctx context.Context, device *SonosDe
 	return nil
 }
 
+// AddURIToQueue appends a track to the queue, or inserts it right after the
+// currently playing track when asNext is true
+func (a *AVTransport) AddURIToQueue(ctx context.Context, device *SonosDevice, uri string, metadata string, asNext bool) error {
+	action := AddURIToQueueAction{
+		XmlnsU:              AVTransportURN,
+		InstanceID:          0,
+		EnqueuedURI:         uri,
+		EnqueuedURIMetaData: metadata,
+	}
+	if asNext {
+		action.EnqueueAsNext = 1
+	}
+
+	_, err := a.sendAction(ctx, device, "AddURIToQueue", action)
+	if err != nil {
+		return fmt.Errorf("AddURIToQueue failed: %w", err)
+	}
+
+	log.Debug(ctx, "Added URI to queue", "device", device.RoomName, "uri", uri, "asNext", asNext)
+	return nil
+}
+
 // Play starts or resumes playback
 func (a *AVTransport) Play(ctx context.Context, device *SonosDevice) error {
 	action := PlayAction{
@@ -138,6 +164,24 @@ func (a *AVTransport) Seek(ctx context.Context, device *SonosDevice, position ti
 	return nil
 }
 
+// SeekTrack jumps to the given track in the queue (1-based)
+func (a *AVTransport) SeekTrack(ctx context.Context, device *SonosDevice, trackNum int) error {
+	action := SeekAction{
+		XmlnsU:     AVTransportURN,
+		InstanceID: 0,
+		Unit:       "TRACK_NR",
+		Target:     strconv.Itoa(trackNum),
+	}
+
+	_, err := a.sendAction(ctx, device, "Seek", action)
+	if err != nil {
+		return fmt.Errorf("Seek failed: %w", err)
+	}
+
+	log.Debug(ctx, "Seeked to track", "device", device.RoomName, "track", trackNum)
+	return nil
+}
+
 // Next skips to the next track in the queue
 func (a *AVTransport) Next(ctx context.Context, device *SonosDevice) error {
 	action := NextAction{
@@ -203,6 +247,26 @@ func (a *AVTransport) GetPositionInfo(ctx context.Context, device *SonosDevice)
 	return track, nil
 }
 
+// GetMediaInfo gets the current transport URI and its metadata
+func (a *AVTransport) GetMediaInfo(ctx context.Context, device *SonosDevice) (*GetMediaInfoResponse, error) {
+	action := GetMediaInfoAction{
+		XmlnsU:     AVTransportURN,
+		InstanceID: 0,
+	}
+
+	respBody, err := a.sendAction(ctx, device, "GetMediaInfo", action)
+	if err != nil {
+		return nil, fmt.Errorf("GetMediaInfo failed: %w", err)
+	}
+
+	var resp GetMediaInfoResponse
+	if err := extractSOAPResponse(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse GetMediaInfo response: %w", err)
+	}
+
+	return &resp, nil
+}
+
 // GetTransportInfo gets the current transport state
 func (a *AVTransport) GetTransportInfo(ctx context.Context, device *SonosDevice) (string, error) {
 	action := GetTransportInfoAction{
@@ -224,6 +288,230 @@ func (a *AVTransport) GetTransportInfo(ctx context.Context, device *SonosDevice)
 	return resp.CurrentTransportState, nil
 }
 
+// RemoveTrackFromQueue removes a single track from the queue (1-based index)
+func (a *AVTransport) RemoveTrackFromQueue(ctx context.Context, device *SonosDevice, index int) error {
+	action := RemoveTrackFromQueueAction{
+		XmlnsU:     AVTransportURN,
+		InstanceID: 0,
+		ObjectID:   fmt.Sprintf("Q:0/%d", index),
+	}
+
+	_, err := a.sendAction(ctx, device, "RemoveTrackFromQueue", action)
+	if err != nil {
+		return fmt.Errorf("RemoveTrackFromQueue failed: %w", err)
+	}
+
+	log.Debug(ctx, "Removed track from queue", "device", device.RoomName, "index", index)
+	return nil
+}
+
+// ReorderTracksInQueue moves a block of tracks within the queue.
+// All indexes are 1-based; insertBefore is the position the block is moved in front of
+func (a *AVTransport) ReorderTracksInQueue(ctx context.Context, device *SonosDevice, start, count, insertBefore int) error {
+	action := ReorderTracksInQueueAction{
+		XmlnsU:         AVTransportURN,
+		InstanceID:     0,
+		StartingIndex:  start,
+		NumberOfTracks: count,
+		InsertBefore:   insertBefore,
+	}
+
+	_, err := a.sendAction(ctx, device, "ReorderTracksInQueue", action)
+	if err != nil {
+		return fmt.Errorf("ReorderTracksInQueue failed: %w", err)
+	}
+
+	log.Debug(ctx, "Reordered tracks in queue", "device", device.RoomName,
+		"start", start, "count", count, "insertBefore", insertBefore)
+	return nil
+}
+
+// RemoveAllTracksFromQueue clears the device's queue
+func (a *AVTransport) RemoveAllTracksFromQueue(ctx context.Context, device *SonosDevice) error {
+	action := RemoveAllTracksFromQueueAction{
+		XmlnsU:     AVTransportURN,
+		InstanceID: 0,
+	}
+
+	_, err := a.sendAction(ctx, device, "RemoveAllTracksFromQueue", action)
+	if err != nil {
+		return fmt.Errorf("RemoveAllTracksFromQueue failed: %w", err)
+	}
+
+	log.Debug(ctx, "Cleared queue", "device", device.RoomName)
+	return nil
+}
+
+// SetPlayMode sets the queue play mode (shuffle/repeat combination)
+func (a *AVTransport) SetPlayMode(ctx context.Context, device *SonosDevice, mode string) error {
+	action := SetPlayModeAction{
+		XmlnsU:      AVTransportURN,
+		InstanceID:  0,
+		NewPlayMode: mode,
+	}
+
+	_, err := a.sendAction(ctx, device, "SetPlayMode", action)
+	if err != nil {
+		return fmt.Errorf("SetPlayMode failed: %w", err)
+	}
+
+	log.Debug(ctx, "Set play mode", "device", device.RoomName, "mode", mode)
+	return nil
+}
+
+// GetPlayMode gets the current queue play mode
+func (a *AVTransport) GetPlayMode(ctx context.Context, device *SonosDevice) (string, error) {
+	action := GetTransportSettingsAction{
+		XmlnsU:     AVTransportURN,
+		InstanceID: 0,
+	}
+
+	respBody, err := a.sendAction(ctx, device, "GetTransportSettings", action)
+	if err != nil {
+		return "", fmt.Errorf("GetTransportSettings failed: %w", err)
+	}
+
+	var resp GetTransportSettingsResponse
+	if err := extractSOAPResponse(respBody, &resp); err != nil {
+		return "", fmt.Errorf("failed to parse GetTransportSettings response: %w", err)
+	}
+
+	return resp.PlayMode, nil
+}
+
+// SetCrossfade enables or disables crossfade between queue tracks
+func (a *AVTransport) SetCrossfade(ctx context.Context, device *SonosDevice, crossfade bool) error {
+	mode := 0
+	if crossfade {
+		mode = 1
+	}
+
+	action := SetCrossfadeModeAction{
+		XmlnsU:        AVTransportURN,
+		InstanceID:    0,
+		CrossfadeMode: mode,
+	}
+
+	_, err := a.sendAction(ctx, device, "SetCrossfadeMode", action)
+	if err != nil {
+		return fmt.Errorf("SetCrossfadeMode failed: %w", err)
+	}
+
+	log.Debug(ctx, "Set crossfade mode", "device", device.RoomName, "crossfade", crossfade)
+	return nil
+}
+
+// GetCrossfade gets the current crossfade setting
+func (a *AVTransport) GetCrossfade(ctx context.Context, device *SonosDevice) (bool, error) {
+	action := GetCrossfadeModeAction{
+		XmlnsU:     AVTransportURN,
+		InstanceID: 0,
+	}
+
+	respBody, err := a.sendAction(ctx, device, "GetCrossfadeMode", action)
+	if err != nil {
+		return false, fmt.Errorf("GetCrossfadeMode failed: %w", err)
+	}
+
+	var resp GetCrossfadeModeResponse
+	if err := extractSOAPResponse(respBody, &resp); err != nil {
+		return false, fmt.Errorf("failed to parse GetCrossfadeMode response: %w", err)
+	}
+
+	return resp.CrossfadeMode == 1, nil
+}
+
+// ConfigureSleepTimer sets a sleep timer on the device.
+// A non-positive duration cancels any active timer
+func (a *AVTransport) ConfigureSleepTimer(ctx context.Context, device *SonosDevice, duration time.Duration) error {
+	target := ""
+	if duration > 0 {
+		hours := int(duration.Hours())
+		minutes := int(duration.Minutes()) % 60
+		seconds := int(duration.Seconds()) % 60
+		target = fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+	}
+
+	action := ConfigureSleepTimerAction{
+		XmlnsU:                AVTransportURN,
+		InstanceID:            0,
+		NewSleepTimerDuration: target,
+	}
+
+	_, err := a.sendAction(ctx, device, "ConfigureSleepTimer", action)
+	if err != nil {
+		return fmt.Errorf("ConfigureSleepTimer failed: %w", err)
+	}
+
+	log.Debug(ctx, "Configured sleep timer", "device", device.RoomName, "duration", target)
+	return nil
+}
+
+// GetRemainingSleepTimer returns the remaining sleep timer duration in seconds.
+// Returns 0 when no sleep timer is active
+func (a *AVTransport) GetRemainingSleepTimer(ctx context.Context, device *SonosDevice) (int, error) {
+	action := GetRemainingSleepTimerDurationAction{
+		XmlnsU:     AVTransportURN,
+		InstanceID: 0,
+	}
+
+	respBody, err := a.sendAction(ctx, device, "GetRemainingSleepTimerDuration", action)
+	if err != nil {
+		return 0, fmt.Errorf("GetRemainingSleepTimerDuration failed: %w", err)
+	}
+
+	var resp GetRemainingSleepTimerDurationResponse
+	if err := extractSOAPResponse(respBody, &resp); err != nil {
+		return 0, fmt.Errorf("failed to parse GetRemainingSleepTimerDuration response: %w", err)
+	}
+
+	// An empty duration means no timer is set
+	if resp.RemainingSleepTimerDuration == "" {
+		return 0, nil
+	}
+	return parseDuration(resp.RemainingSleepTimerDuration), nil
+}
+
+// playModeFor maps shuffle and repeat flags to a Sonos play mode string
+func playModeFor(shuffle bool, repeat string) string {
+	if shuffle {
+		switch repeat {
+		case RepeatOne:
+			return PlayModeShuffleRepOne
+		case RepeatAll:
+			return PlayModeShuffle
+		default:
+			return PlayModeShuffleNoRepeat
+		}
+	}
+	switch repeat {
+	case RepeatOne:
+		return PlayModeRepeatOne
+	case RepeatAll:
+		return PlayModeRepeatAll
+	default:
+		return PlayModeNormal
+	}
+}
+
+// parsePlayMode maps a Sonos play mode string back to shuffle and repeat flags
+func parsePlayMode(mode string) (shuffle bool, repeat string) {
+	switch mode {
+	case PlayModeShuffle:
+		return true, RepeatAll
+	case PlayModeShuffleRepOne:
+		return true, RepeatOne
+	case PlayModeShuffleNoRepeat:
+		return true, RepeatNone
+	case PlayModeRepeatAll:
+		return false, RepeatAll
+	case PlayModeRepeatOne:
+		return false, RepeatOne
+	default:
+		return false, RepeatNone
+	}
+}
+
 // PlayURI sets the URI and starts playback in one call
 func (a *AVTransport) PlayURI(ctx context.Context, device *SonosDevice, uri string, metadata string) error {
 	if err := a.SetAVTransportURI(ctx, device, uri, metadata); err != nil {
@@ -234,6 +522,18 @@ func (a *AVTransport) PlayURI(ctx context.Context, device *SonosDevice, uri stri
 
 // sendAction sends a SOAP action to the device
 func (a *AVTransport) sendAction(ctx context.Context, device *SonosDevice, actionName string, action interface{}) ([]byte, error) {
+	// Skip devices in failure backoff instead of waiting for a timeout
+	if !a.health.available(device.UUID) {
+		return nil, ErrDeviceUnreachable
+	}
+
+	// Queue behind other in-flight calls to this device - bursts of UI
+	// actions can overwhelm older ZonePlayers
+	if err := a.limiter.acquire(ctx, device.UUID); err != nil {
+		return nil, err
+	}
+	defer a.limiter.release(device.UUID)
+
 	// Build SOAP envelope
 	envelope := SOAPEnvelope{
 		XmlnsS:        "http://schemas.xmlsoap.org/soap/envelope/",
@@ -264,11 +564,14 @@ func (a *AVTransport) sendAction(ctx context.Context, device *SonosDevice, actio
 	// DEBUG: Log the full SOAP request
 	log.Debug(ctx, "SOAP Request", "url", url, "action", actionName, "body", string(body))
 
-	// Send request
+	// Send request, timing it for the health stats
+	start := time.Now()
 	resp, err := a.client.Do(req)
 	if err != nil {
-		return nil, err
+		a.health.reportFailure(device.UUID)
+		return nil, fmt.Errorf("%w: %v", ErrDeviceUnreachable, err)
 	}
+	a.health.reportSuccess(device.UUID, time.Since(start))
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
@@ -420,28 +723,63 @@ func extractSOAPResponse(body []byte, v interface{}) error {
 	return xml.Unmarshal([]byte(content), v)
 }
 
+// DIDLTrack holds everything that gets rendered into a DIDL-Lite item.
+// StreamURI and MimeType are REQUIRED for Sonos to understand the content;
+// all other fields are optional and omitted from the metadata when zero
+type DIDLTrack struct {
+	ID          string
+	Title       string
+	Artist      string
+	Album       string
+	AlbumArtURL string
+	StreamURI   string
+	MimeType    string
+	Duration    float32 // seconds
+	TrackNumber int
+	Genre       string
+	Date        string // release date (YYYY-MM-DD or just a year)
+	BitRate     int    // kbps
+	SampleRate  int    // Hz
+	Channels    int
+	Size        int64 // bytes
+}
+
 // BuildDIDLMetadata creates DIDL-Lite metadata for a track
 // Uses musicTrack format for discrete file playback
-// The streamURI and mimeType are REQUIRED for Sonos to understand the content
-// durationSecs is the track duration in seconds (0 to omit)
-func BuildDIDLMetadata(id, title, artist, album, albumArtURL, streamURI, mimeType string, durationSecs float32) string {
+func BuildDIDLMetadata(track DIDLTrack) string {
 	// Build metadata with proper artist/album info for discrete tracks
 	var albumArtElement string
-	if albumArtURL != "" {
-		albumArtElement = fmt.Sprintf("<upnp:albumArtURI>%s</upnp:albumArtURI>\n", html.EscapeString(albumArtURL))
+	if track.AlbumArtURL != "" {
+		albumArtElement = fmt.Sprintf("<upnp:albumArtURI>%s</upnp:albumArtURI>\n", html.EscapeString(track.AlbumArtURL))
 	}
 
 	var creatorElement string
-	if artist != "" {
-		creatorElement = fmt.Sprintf("<dc:creator>%s</dc:creator>\n", html.EscapeString(artist))
+	if track.Artist != "" {
+		creatorElement = fmt.Sprintf("<dc:creator>%s</dc:creator>\n", html.EscapeString(track.Artist))
 	}
 
 	var albumElement string
-	if album != "" {
-		albumElement = fmt.Sprintf("<upnp:album>%s</upnp:album>\n", html.EscapeString(album))
+	if track.Album != "" {
+		albumElement = fmt.Sprintf("<upnp:album>%s</upnp:album>\n", html.EscapeString(track.Album))
+	}
+
+	var trackNumberElement string
+	if track.TrackNumber > 0 {
+		trackNumberElement = fmt.Sprintf("<upnp:originalTrackNumber>%d</upnp:originalTrackNumber>\n", track.TrackNumber)
+	}
+
+	var genreElement string
+	if track.Genre != "" {
+		genreElement = fmt.Sprintf("<upnp:genre>%s</upnp:genre>\n", html.EscapeString(track.Genre))
+	}
+
+	var dateElement string
+	if track.Date != "" {
+		dateElement = fmt.Sprintf("<dc:date>%s</dc:date>\n", html.EscapeString(track.Date))
 	}
 
 	// Default MIME type if not specified
+	mimeType := track.MimeType
 	if mimeType == "" {
 		mimeType = "audio/flac"
 	}
@@ -450,25 +788,41 @@ func BuildDIDLMetadata(id, title, artist, album, albumArtURL, streamURI, mimeTyp
 	// Without it, Sonos returns error 714 (Illegal MIME-Type)
 	// Include duration attribute if provided (format: H:MM:SS or H:MM:SS.mmm)
 	protocolInfo := fmt.Sprintf("http-get:*:%s:*", mimeType)
-	var durationAttr string
-	if durationSecs > 0 {
-		hours := int(durationSecs) / 3600
-		minutes := (int(durationSecs) % 3600) / 60
-		seconds := int(durationSecs) % 60
-		durationAttr = fmt.Sprintf(" duration=\"%d:%02d:%02d\"", hours, minutes, seconds)
+	var resAttrs strings.Builder
+	if track.Duration > 0 {
+		hours := int(track.Duration) / 3600
+		minutes := (int(track.Duration) % 3600) / 60
+		seconds := int(track.Duration) % 60
+		fmt.Fprintf(&resAttrs, " duration=\"%d:%02d:%02d\"", hours, minutes, seconds)
+	}
+	// The DIDL bitrate attribute is in bytes per second, not kbps
+	if track.BitRate > 0 {
+		fmt.Fprintf(&resAttrs, " bitrate=\"%d\"", track.BitRate*1000/8)
+	}
+	if track.SampleRate > 0 {
+		fmt.Fprintf(&resAttrs, " sampleFrequency=\"%d\"", track.SampleRate)
+	}
+	if track.Channels > 0 {
+		fmt.Fprintf(&resAttrs, " nrAudioChannels=\"%d\"", track.Channels)
+	}
+	if track.Size > 0 {
+		fmt.Fprintf(&resAttrs, " size=\"%d\"", track.Size)
 	}
-	resElement := fmt.Sprintf("<res protocolInfo=\"%s\"%s>%s</res>\n", protocolInfo, durationAttr, html.EscapeString(streamURI))
+	resElement := fmt.Sprintf("<res protocolInfo=\"%s\"%s>%s</res>\n", protocolInfo, resAttrs.String(), html.EscapeString(track.StreamURI))
 
 	return fmt.Sprintf(`<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/">
 <item id="%s" parentID="0" restricted="true">
 <dc:title>%s</dc:title>
-%s%s%s%s<upnp:class>object.item.audioItem.musicTrack</upnp:class>
+%s%s%s%s%s%s%s<upnp:class>object.item.audioItem.musicTrack</upnp:class>
 </item>
 </DIDL-Lite>`,
-		html.EscapeString(id),
-		html.EscapeString(title),
+		html.EscapeString(track.ID),
+		html.EscapeString(track.Title),
 		creatorElement,
 		albumElement,
+		trackNumberElement,
+		genreElement,
+		dateElement,
 		albumArtElement,
 		resElement)
 }
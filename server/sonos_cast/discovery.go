@@ -9,16 +9,20 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/log"
 )
 
 const (
-	ssdpMulticastAddr = "239.255.255.250:1900"
-	sonosSearchTarget = "urn:schemas-upnp-org:device:ZonePlayer:1"
-	ssdpSearchTimeout = 3 * time.Second
-	deviceFetchTimeout = 5 * time.Second
+	ssdpMulticastAddr         = "239.255.255.250:1900"
+	sonosSearchTarget         = "urn:schemas-upnp-org:device:ZonePlayer:1"
+	defaultSSDPMX             = 2
+	defaultSSDPTimeout        = 3 * time.Second
+	defaultSOAPTimeout        = 10 * time.Second
+	defaultDeviceFetchTimeout = 5 * time.Second
 )
 
 // Discovery handles Sonos device discovery via SSDP
@@ -32,24 +36,26 @@ func NewDiscovery() *Discovery {
 	return &Discovery{
 		cache: NewDeviceCache(),
 		client: &http.Client{
-			Timeout: deviceFetchTimeout,
+			Timeout: deviceFetchTimeout(),
 		},
 	}
 }
 
-// Scan performs SSDP discovery for Sonos devices
-func (d *Discovery) Scan(ctx context.Context) ([]*SonosDevice, error) {
+// Scan performs SSDP discovery for Sonos devices. Each device is cached (and
+// reported through onDevice, when given) as soon as its description has been
+// fetched, so callers can surface results while the scan is still running
+func (d *Discovery) Scan(ctx context.Context, onDevice func(*SonosDevice)) ([]*SonosDevice, error) {
 	log.Debug(ctx, "Starting Sonos SSDP discovery scan")
 
-	// Create UDP connection for multicast
-	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero, Port: 0})
+	// Create UDP connection for multicast, bound to the configured interface (if any)
+	conn, err := net.ListenUDP("udp4", localUDPAddr(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create UDP listener: %w", err)
 	}
 	defer conn.Close()
 
 	// Build M-SEARCH request
-	searchRequest := buildMSearchRequest(sonosSearchTarget)
+	searchRequest := buildMSearchRequest(sonosSearchTarget, ssdpMX())
 
 	// Resolve multicast address
 	multicastAddr, err := net.ResolveUDPAddr("udp4", ssdpMulticastAddr)
@@ -65,9 +71,15 @@ func (d *Discovery) Scan(ctx context.Context) ([]*SonosDevice, error) {
 
 	log.Debug(ctx, "Sent SSDP M-SEARCH for Sonos devices")
 
-	// Collect responses
+	// Collect responses, fetching each device description as soon as its
+	// announcement arrives instead of waiting out the full SSDP window
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		devices []*SonosDevice
+	)
 	locations := make(map[string]bool)
-	deadline := time.Now().Add(ssdpSearchTimeout)
+	deadline := time.Now().Add(ssdpTimeout())
 	conn.SetReadDeadline(deadline)
 
 	buf := make([]byte, 2048)
@@ -83,31 +95,46 @@ func (d *Discovery) Scan(ctx context.Context) ([]*SonosDevice, error) {
 
 		// Parse response to extract LOCATION header
 		location := parseLocationFromResponse(string(buf[:n]))
-		if location != "" && !locations[location] {
-			locations[location] = true
-			log.Debug(ctx, "Found Sonos device", "location", location)
-		}
-	}
-
-	// Fetch device descriptions
-	var devices []*SonosDevice
-	for location := range locations {
-		device, err := d.fetchDeviceDescription(ctx, location)
-		if err != nil {
-			log.Warn(ctx, "Failed to fetch device description", "location", location, err)
+		if location == "" || locations[location] {
 			continue
 		}
-		devices = append(devices, device)
-		d.cache.Set(device)
+		locations[location] = true
+		log.Debug(ctx, "Found Sonos device", "location", location)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			device, err := d.fetchDeviceDescription(ctx, location)
+			if err != nil {
+				log.Warn(ctx, "Failed to fetch device description", "location", location, err)
+				return
+			}
+			d.cache.Set(device)
+			mu.Lock()
+			devices = append(devices, device)
+			mu.Unlock()
+			if onDevice != nil {
+				onDevice(device)
+			}
+		}()
 	}
+	wg.Wait()
 
 	log.Info(ctx, "Sonos discovery complete", "devicesFound", len(devices))
 	return devices, nil
 }
 
-// GetDevices returns all cached devices
+// GetDevices returns all cached devices that represent controllable rooms.
+// Hidden stereo pair members and bonded satellites are filtered out
 func (d *Discovery) GetDevices() []*SonosDevice {
-	return d.cache.GetAll()
+	all := d.cache.GetAll()
+	devices := make([]*SonosDevice, 0, len(all))
+	for _, device := range all {
+		if !device.Invisible {
+			devices = append(devices, device)
+		}
+	}
+	return devices
 }
 
 // GetDevice returns a specific device by UUID
@@ -115,17 +142,72 @@ func (d *Discovery) GetDevice(uuid string) (*SonosDevice, bool) {
 	return d.cache.Get(uuid)
 }
 
+// localUDPAddr returns the local address to bind the SSDP socket to.
+// SonosCast.Interface may be an IP address or an interface name; when empty
+// (or unresolvable) the socket binds to all interfaces
+func localUDPAddr(ctx context.Context) *net.UDPAddr {
+	name := conf.Server.SonosCast.Interface
+	if name == "" {
+		return &net.UDPAddr{IP: net.IPv4zero, Port: 0}
+	}
+	if ip := net.ParseIP(name); ip != nil {
+		return &net.UDPAddr{IP: ip, Port: 0}
+	}
+	if iface, err := net.InterfaceByName(name); err == nil {
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+				return &net.UDPAddr{IP: ipNet.IP, Port: 0}
+			}
+		}
+	}
+	log.Warn(ctx, "Could not resolve SonosCast.Interface - binding to all interfaces", "interface", name)
+	return &net.UDPAddr{IP: net.IPv4zero, Port: 0}
+}
+
+// ssdpMX returns the configured MX value for M-SEARCH requests
+func ssdpMX() int {
+	if mx := conf.Server.SonosCast.SSDPMX; mx > 0 {
+		return mx
+	}
+	return defaultSSDPMX
+}
+
+// ssdpTimeout returns how long to wait for M-SEARCH responses
+func ssdpTimeout() time.Duration {
+	if t := conf.Server.SonosCast.SSDPTimeout; t > 0 {
+		return t
+	}
+	return defaultSSDPTimeout
+}
+
+// soapTimeout returns the per-request timeout for SOAP control calls
+func soapTimeout() time.Duration {
+	if t := conf.Server.SonosCast.SOAPTimeout; t > 0 {
+		return t
+	}
+	return defaultSOAPTimeout
+}
+
+// deviceFetchTimeout returns the timeout for fetching device descriptions
+func deviceFetchTimeout() time.Duration {
+	if t := conf.Server.SonosCast.DeviceFetchTimeout; t > 0 {
+		return t
+	}
+	return defaultDeviceFetchTimeout
+}
+
 // buildMSearchRequest creates an SSDP M-SEARCH request
-func buildMSearchRequest(searchTarget string) string {
+func buildMSearchRequest(searchTarget string, mx int) string {
 	return fmt.Sprintf(
 		"M-SEARCH * HTTP/1.1\r\n"+
 			"HOST: %s\r\n"+
 			"MAN: \"ssdp:discover\"\r\n"+
-			"MX: 2\r\n"+
+			"MX: %d\r\n"+
 			"ST: %s\r\n"+
 			"USER-AGENT: Navidrome/1.0 UPnP/1.0\r\n"+
 			"\r\n",
-		ssdpMulticastAddr, searchTarget)
+		ssdpMulticastAddr, mx, searchTarget)
 }
 
 // parseLocationFromResponse extracts the LOCATION header from SSDP response
@@ -190,9 +272,70 @@ func (d *Discovery) fetchDeviceDescription(ctx context.Context, location string)
 		LastSeen:    time.Now(),
 	}
 
+	// Portable models run on battery - fetch their charge state so the UI
+	// can warn before a speaker dies mid-playback
+	if isPortableModel(device.ModelName) {
+		battery, err := d.fetchBatteryStatus(ctx, device)
+		if err != nil {
+			log.Warn(ctx, "Failed to fetch battery status", "roomName", device.RoomName, "model", device.ModelName, err)
+		} else {
+			device.Battery = battery
+		}
+	}
+
 	return device, nil
 }
 
+// isPortableModel reports whether a Sonos model has a battery
+func isPortableModel(modelName string) bool {
+	return strings.Contains(modelName, "Roam") || strings.Contains(modelName, "Move")
+}
+
+// fetchBatteryStatus queries the device's battery status endpoint
+func (d *Discovery) fetchBatteryStatus(ctx context.Context, device *SonosDevice) (*BatteryStatus, error) {
+	url := fmt.Sprintf("http://%s:%d/status/batterystatus", device.IP, device.Port)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var info ZPSupportInfo
+	if err := xml.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse battery status: %w", err)
+	}
+	if len(info.Data) == 0 {
+		return nil, fmt.Errorf("no battery info in response")
+	}
+
+	battery := &BatteryStatus{}
+	for _, entry := range info.Data {
+		switch entry.Name {
+		case "Level":
+			fmt.Sscanf(strings.TrimSpace(entry.Value), "%d", &battery.Level)
+		case "PowerSource":
+			// Anything other than BATTERY means the speaker is docked or plugged in
+			battery.Charging = strings.TrimSpace(entry.Value) != "BATTERY"
+		}
+	}
+
+	return battery, nil
+}
+
 // parseIPPort extracts IP and port from a URL like http://192.168.1.10:1400/xml/device_description.xml
 func parseIPPort(location string) (string, int) {
 	// Remove protocol
@@ -286,9 +429,13 @@ func (d *Discovery) FetchZoneGroupTopology(ctx context.Context, device *SonosDev
 	updatedCount := 0
 	notFoundCount := 0
 	for _, group := range zgs.ZoneGroups {
+		// Only visible members count as controllable rooms - hidden stereo
+		// pair members and bonded Subs/Surrounds are driven by their parent
 		var memberUUIDs []string
 		for _, member := range group.Members {
-			memberUUIDs = append(memberUUIDs, member.UUID)
+			if member.Invisible != "1" {
+				memberUUIDs = append(memberUUIDs, member.UUID)
+			}
 		}
 
 		for _, member := range group.Members {
@@ -296,6 +443,7 @@ func (d *Discovery) FetchZoneGroupTopology(ctx context.Context, device *SonosDev
 				cached.GroupID = group.ID
 				cached.IsCoordinator = (member.UUID == group.Coordinator)
 				cached.GroupMembers = memberUUIDs
+				cached.Invisible = member.Invisible == "1"
 				if cached.RoomName == "" {
 					cached.RoomName = member.ZoneName
 				}
@@ -305,11 +453,22 @@ func (d *Discovery) FetchZoneGroupTopology(ctx context.Context, device *SonosDev
 					"roomName", cached.RoomName,
 					"uuid", member.UUID,
 					"isCoordinator", cached.IsCoordinator,
+					"invisible", cached.Invisible,
 					"groupId", group.ID)
 			} else {
 				notFoundCount++
 				log.Debug(ctx, "Device from zone topology not in cache", "uuid", member.UUID, "zoneName", member.ZoneName)
 			}
+
+			// Home-theater satellites are listed under their parent member
+			// and must never show up as standalone devices
+			for _, sat := range member.Satellites {
+				if cached, ok := d.cache.Get(sat.UUID); ok {
+					cached.Invisible = true
+					d.cache.Set(cached)
+					log.Debug(ctx, "Marked satellite as invisible", "uuid", sat.UUID, "zoneName", sat.ZoneName)
+				}
+			}
 		}
 	}
 
@@ -20,6 +20,14 @@ var (
 
 	// ErrPlaybackFailed is returned when playback control fails
 	ErrPlaybackFailed = errors.New("playback control failed")
+
+	// ErrNotAuthorized is returned when the user's libraries don't include
+	// the requested content
+	ErrNotAuthorized = errors.New("user does not have access to this content")
+
+	// ErrDeviceUnreachable is returned when a device is in failure backoff
+	// or a call to it failed at the network level
+	ErrDeviceUnreachable = errors.New("sonos device is unreachable")
 )
 
 // UPnP error codes from Sonos/AVTransport specification
@@ -0,0 +1,113 @@
+package sonos_cast
+
+import (
+	"context"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+)
+
+// fadeSteps is the maximum number of SetVolume calls used for one ramp. The
+// per-device SOAP limiter paces them, so more steps just means a smoother but
+// chattier fade
+const fadeSteps = 8
+
+// prepareCastVolume sets the speaker volume for a new cast. Without fade-in
+// configured it just applies the default cast volume, like before. With
+// fade-in it drops the volume to zero so playback starts silent, and returns
+// the level finishCastVolume should ramp back up to (0 means no ramp)
+func (a *API) prepareCastVolume(ctx context.Context, deviceID string, settings DeviceSettings) int {
+	target := settings.clampVolume(settings.DefaultVolume)
+	if settings.FadeSeconds <= 0 {
+		if target > 0 {
+			if err := a.sonosCast.SetVolume(ctx, deviceID, target); err != nil {
+				log.Warn(ctx, "Failed to apply default cast volume", "deviceID", deviceID, err)
+			}
+		}
+		return 0
+	}
+
+	// Without a default volume the fade ends at the speaker's current level
+	if target == 0 {
+		current, err := a.sonosCast.GetVolume(ctx, deviceID)
+		if err != nil || current <= 0 {
+			return 0
+		}
+		target = settings.clampVolume(current)
+	}
+
+	if err := a.sonosCast.SetVolume(ctx, deviceID, 0); err != nil {
+		log.Warn(ctx, "Failed to silence device for fade-in", "deviceID", deviceID, err)
+		return 0
+	}
+	return target
+}
+
+// finishCastVolume starts the fade-in ramp after playback has started. It
+// runs in the background so the cast request doesn't block for the whole fade
+func (a *API) finishCastVolume(ctx context.Context, deviceID string, settings DeviceSettings, target int) {
+	if target <= 0 {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	go a.rampVolume(ctx, deviceID, 0, target, time.Duration(settings.FadeSeconds)*time.Second)
+}
+
+// fadeOutBeforeStop ramps the volume down to zero before a pause or stop, if
+// the device has fading configured. It returns the volume to restore
+// afterwards, or -1 when no fade happened. Unlike fade-in this runs
+// synchronously - the transport command should land at the bottom of the ramp
+func (a *API) fadeOutBeforeStop(ctx context.Context, deviceID string) int {
+	settings := loadDeviceSettings(ctx, a.ds, deviceID)
+	if settings.FadeSeconds <= 0 {
+		return -1
+	}
+	current, err := a.sonosCast.GetVolume(ctx, deviceID)
+	if err != nil || current <= 0 {
+		return -1
+	}
+	a.rampVolume(ctx, deviceID, current, 0, time.Duration(settings.FadeSeconds)*time.Second)
+	return current
+}
+
+// restoreVolumeAfterStop puts the volume back after a fade-out, so the next
+// play doesn't start silent. The speaker is paused or stopped by now, so
+// nothing audible happens
+func (a *API) restoreVolumeAfterStop(ctx context.Context, deviceID string, volume int) {
+	if volume <= 0 {
+		return
+	}
+	if err := a.sonosCast.SetVolume(ctx, deviceID, volume); err != nil {
+		log.Warn(ctx, "Failed to restore volume after fade-out", "deviceID", deviceID, "volume", volume, err)
+	}
+}
+
+// rampVolume steps the volume from one level to another over the given
+// duration. The ramp is cut short if a step fails - the device is probably
+// gone, and retrying would just stretch the failure out
+func (a *API) rampVolume(ctx context.Context, deviceID string, from, to int, duration time.Duration) {
+	steps := fadeSteps
+	diff := to - from
+	if diff < 0 {
+		if -diff < steps {
+			steps = -diff
+		}
+	} else if diff < steps {
+		steps = diff
+	}
+	if steps == 0 {
+		return
+	}
+
+	interval := duration / time.Duration(steps)
+	for i := 1; i <= steps; i++ {
+		volume := from + diff*i/steps
+		if err := a.sonosCast.SetVolume(ctx, deviceID, volume); err != nil {
+			log.Warn(ctx, "Volume ramp aborted", "deviceID", deviceID, "volume", volume, err)
+			return
+		}
+		if i < steps {
+			time.Sleep(interval)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package sonos_cast
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+const deviceFormatsKeyPrefix = "SonosCastDeviceFormats-"
+
+// deviceFormats remembers the outcome of MIME-type remediation for a device,
+// so source formats a speaker already refused aren't tried again. Persisted
+// in the properties table like the other per-device settings
+type deviceFormats struct {
+	Rejected []string `json:"rejected,omitempty"` // source formats the device refused to play
+	Fallback string   `json:"fallback,omitempty"` // transcode target that worked instead
+}
+
+func deviceFormatsKey(uuid string) string {
+	return deviceFormatsKeyPrefix + uuid
+}
+
+// rejects reports whether the device is known to refuse the given format
+func (f deviceFormats) rejects(format string) bool {
+	return slices.Contains(f.Rejected, strings.ToLower(format))
+}
+
+// loadDeviceFormats reads the recorded format acceptance for a device.
+// Missing or invalid data yields the zero value (nothing known yet)
+func loadDeviceFormats(ctx context.Context, ds model.DataStore, uuid string) deviceFormats {
+	var formats deviceFormats
+	value, err := ds.Property(ctx).DefaultGet(deviceFormatsKey(uuid), "")
+	if err != nil || value == "" {
+		return formats
+	}
+	if err := json.Unmarshal([]byte(value), &formats); err != nil {
+		log.Warn(ctx, "Invalid Sonos device format record - ignoring", "uuid", uuid, err)
+	}
+	return formats
+}
+
+// markFormatRejected records that a device refused a source format and which
+// transcode target worked instead, so future casts skip the failing attempt
+func markFormatRejected(ctx context.Context, ds model.DataStore, uuid, format, fallback string) {
+	format = strings.ToLower(format)
+	formats := loadDeviceFormats(ctx, ds, uuid)
+	if !formats.rejects(format) {
+		formats.Rejected = append(formats.Rejected, format)
+	}
+	formats.Fallback = fallback
+
+	value, err := json.Marshal(formats)
+	if err == nil {
+		err = ds.Property(ctx).Put(deviceFormatsKey(uuid), string(value))
+	}
+	if err != nil {
+		log.Warn(ctx, "Failed to record rejected format for device", "uuid", uuid, "format", format, err)
+	}
+}
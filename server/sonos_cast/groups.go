@@ -0,0 +1,108 @@
+package sonos_cast
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/navidrome/navidrome/log"
+)
+
+// DeviceGroup is one zone group as presented to the UI - a coordinator plus
+// its member rooms, like the Sonos app shows them
+type DeviceGroup struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"` // the coordinator's room name
+	Coordinator *SonosDevice   `json:"coordinator,omitempty"`
+	Members     []*SonosDevice `json:"members"`
+}
+
+// GetGroups returns the current zone groups, with hidden members filtered
+// out. The topology is refreshed from a reachable device first, so grouping
+// changes made in the Sonos app show up right away
+func (s *SonosCast) GetGroups(ctx context.Context) []*DeviceGroup {
+	devices := s.GetDevices()
+	for _, device := range devices {
+		if !device.Reachable {
+			continue
+		}
+		if err := s.discovery.FetchZoneGroupTopology(ctx, device); err != nil {
+			log.Warn(ctx, "Failed to refresh zone topology", "roomName", device.RoomName, err)
+		}
+		break
+	}
+
+	// Re-read the cache - the topology fetch may have updated group info
+	devices = s.GetDevices()
+	groups := map[string]*DeviceGroup{}
+	for _, device := range devices {
+		if device.Invisible {
+			continue
+		}
+		groupID := device.GroupID
+		if groupID == "" {
+			// Never grouped (or topology unavailable) - a group of one
+			groupID = device.UUID
+		}
+		group, ok := groups[groupID]
+		if !ok {
+			group = &DeviceGroup{ID: groupID}
+			groups[groupID] = group
+		}
+		if device.IsCoordinator {
+			group.Coordinator = device
+			group.Name = device.RoomName
+		}
+		group.Members = append(group.Members, device)
+	}
+
+	result := make([]*DeviceGroup, 0, len(groups))
+	for _, group := range groups {
+		// A standalone device is its own coordinator, even when the topology
+		// never said so
+		if group.Coordinator == nil && len(group.Members) == 1 {
+			group.Coordinator = group.Members[0]
+		}
+		if group.Name == "" && group.Coordinator != nil {
+			group.Name = group.Coordinator.RoomName
+		}
+		// Coordinator first, the rest by room name
+		slices.SortFunc(group.Members, func(a, b *SonosDevice) int {
+			if a.IsCoordinator != b.IsCoordinator {
+				if a.IsCoordinator {
+					return -1
+				}
+				return 1
+			}
+			return strings.Compare(a.RoomName, b.RoomName)
+		})
+		result = append(result, group)
+	}
+	slices.SortFunc(result, func(a, b *DeviceGroup) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
+// getGroups returns the zone groups the user may control, so the UI can
+// present rooms grouped like the Sonos app
+func (a *API) getGroups(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	groups := a.sonosCast.GetGroups(ctx)
+
+	// Apply device permissions per group - a group is listed if the user may
+	// control its coordinator
+	allowed := make([]*DeviceGroup, 0, len(groups))
+	for _, group := range groups {
+		if group.Coordinator != nil && !a.canControlDevice(ctx, group.Coordinator.UUID) {
+			continue
+		}
+		group.Members = a.filterAllowedDevices(ctx, group.Members)
+		if len(group.Members) == 0 {
+			continue
+		}
+		allowed = append(allowed, group)
+	}
+	a.sendJSON(w, http.StatusOK, allowed)
+}
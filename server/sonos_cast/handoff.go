@@ -0,0 +1,202 @@
+package sonos_cast
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+)
+
+// defaultDeviceKey is the UserProps key holding a user's preferred cast device
+const defaultDeviceKey = "SonosCastDefaultDevice"
+
+// defaultDeviceResponse is the response body for the default device endpoints
+type defaultDeviceResponse struct {
+	DeviceID string `json:"deviceId"`
+	RoomName string `json:"roomName,omitempty"`
+}
+
+// getDefaultDevice returns the requesting user's preferred cast device. The
+// room name is only filled in while the device is actually discovered
+func (a *API) getDefaultDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := request.UserFrom(ctx)
+	if !ok {
+		a.sendError(w, http.StatusForbidden, "no user in context")
+		return
+	}
+
+	deviceID, err := a.ds.UserProps(ctx).DefaultGet(user.ID, defaultDeviceKey, "")
+	if err != nil {
+		a.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := defaultDeviceResponse{DeviceID: deviceID}
+	if device, ok := a.sonosCast.GetDevice(deviceID); ok {
+		resp.RoomName = device.RoomName
+	}
+	a.sendJSON(w, http.StatusOK, resp)
+}
+
+// defaultDeviceRequest is the request body for setting the default device
+type defaultDeviceRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// setDefaultDevice stores the requesting user's preferred cast device. An
+// empty deviceId clears the preference
+func (a *API) setDefaultDevice(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := request.UserFrom(ctx)
+	if !ok {
+		a.sendError(w, http.StatusForbidden, "no user in context")
+		return
+	}
+
+	var req defaultDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.DeviceID == "" {
+		if err := a.ds.UserProps(ctx).Delete(user.ID, defaultDeviceKey); err != nil {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		a.sendJSON(w, http.StatusOK, defaultDeviceResponse{})
+		return
+	}
+
+	device, ok := a.sonosCast.GetDevice(req.DeviceID)
+	if !ok {
+		a.sendError(w, http.StatusNotFound, "device not found")
+		return
+	}
+	if !a.canControlDevice(ctx, req.DeviceID) {
+		a.sendError(w, http.StatusForbidden, "you are not allowed to control this device")
+		return
+	}
+
+	if err := a.ds.UserProps(ctx).Put(user.ID, defaultDeviceKey, req.DeviceID); err != nil {
+		a.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.sendJSON(w, http.StatusOK, defaultDeviceResponse{DeviceID: device.UUID, RoomName: device.RoomName})
+}
+
+// handoffRequest is the request body for the handoff endpoint. DeviceID is
+// optional and overrides the user's default device
+type handoffRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// handoffPlayback casts the user's saved web-player queue to their default
+// device (or the one given in the body) in one call: the remaining tracks are
+// cast starting at the current one, and playback is seeked to the saved
+// position - a seamless browser-to-speaker handoff
+func (a *API) handoffPlayback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := request.UserFrom(ctx)
+	if !ok {
+		a.sendError(w, http.StatusForbidden, "no user in context")
+		return
+	}
+
+	var req handoffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		a.sendError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	deviceID := req.DeviceID
+	if deviceID == "" {
+		deviceID, _ = a.ds.UserProps(ctx).DefaultGet(user.ID, defaultDeviceKey, "")
+	}
+	if deviceID == "" {
+		a.sendError(w, http.StatusBadRequest, "no default cast device configured")
+		return
+	}
+	if _, ok := a.sonosCast.GetDevice(deviceID); !ok {
+		a.sendError(w, http.StatusNotFound, "device not found")
+		return
+	}
+	if !a.canControlDevice(ctx, deviceID) {
+		a.sendError(w, http.StatusForbidden, "you are not allowed to control this device")
+		return
+	}
+
+	pq, err := a.ds.PlayQueue(ctx).Retrieve(user.ID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			a.sendError(w, http.StatusNotFound, "no saved play queue to hand off")
+			return
+		}
+		a.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(pq.Items) == 0 {
+		a.sendError(w, http.StatusNotFound, "no saved play queue to hand off")
+		return
+	}
+
+	// Start from the queue's current track; anything before it already played
+	current := pq.Current
+	if current < 0 || current >= len(pq.Items) {
+		current = 0
+	}
+	trackIds := make([]string, 0, len(pq.Items)-current)
+	for _, item := range pq.Items[current:] {
+		trackIds = append(trackIds, item.ID)
+	}
+
+	log.Info(ctx, "Handing off play queue to Sonos", "deviceID", deviceID, "tracks", len(trackIds),
+		"position", pq.Position, "username", user.UserName)
+
+	a.stopQueuePlayer(deviceID)
+	a.stopPartySession(deviceID)
+	if err := a.castTrack(ctx, deviceID, trackIds[0]); err != nil {
+		log.Error(ctx, "Failed to hand off play queue", err, "trackID", trackIds[0], "deviceID", deviceID)
+		if err == ErrDeviceNotFound {
+			a.sendError(w, http.StatusNotFound, "device not found")
+		} else if err == ErrNotAuthorized {
+			a.sendError(w, http.StatusForbidden, err.Error())
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	// Pick up where the web player left off. Seeking is best effort - on a
+	// transcoded stream the speaker may refuse it
+	if pq.Position > 0 {
+		if err := a.sonosCast.Seek(ctx, deviceID, time.Duration(pq.Position)*time.Millisecond); err != nil {
+			log.Warn(ctx, "Failed to seek to saved position after handoff", "position", pq.Position, err)
+		}
+	}
+
+	if len(trackIds) > 1 {
+		if err := a.setNextTrack(ctx, deviceID, trackIds[1]); err != nil {
+			// Non-fatal - first track is already playing
+			log.Warn(ctx, "Failed to set next track", err, "trackID", trackIds[1])
+		}
+		if len(trackIds) > 2 {
+			a.storeQueue(deviceID, trackIds[2:], user)
+		}
+		a.startQueuePlayer(ctx, deviceID, trackIds[0], trackIds[1])
+	}
+
+	a.recordAudit(ctx, deviceID, auditActionHandoff, "track", trackIds[0])
+	a.sendJSON(w, http.StatusOK, map[string]string{
+		"status":    "casting",
+		"deviceId":  deviceID,
+		"queueSize": fmt.Sprintf("%d", len(trackIds)),
+	})
+}
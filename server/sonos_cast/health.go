@@ -0,0 +1,117 @@
+package sonos_cast
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// healthBackoffBase is the wait before retrying a device after its first failure
+	healthBackoffBase = 5 * time.Second
+	// healthBackoffMax caps the exponential backoff between retries
+	healthBackoffMax = 5 * time.Minute
+)
+
+// healthTracker records per-device reachability. After a failed SOAP call the
+// device is skipped for an exponentially growing period, so a powered-off
+// speaker doesn't cause a full HTTP timeout on every control request
+type healthTracker struct {
+	mu      sync.Mutex
+	entries map[string]*deviceHealth
+}
+
+type deviceHealth struct {
+	failures     int
+	retryAt      time.Time // calls before this time are skipped
+	lastSuccess  time.Time
+	latencySum   time.Duration
+	latencyCount int
+}
+
+// DeviceHealthStats is a point-in-time summary of a device's SOAP call
+// history, exposed through the health endpoint for debugging
+type DeviceHealthStats struct {
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	InBackoff           bool       `json:"inBackoff"`
+	LastSuccess         *time.Time `json:"lastSuccess,omitempty"`
+	AvgLatencyMs        int64      `json:"avgLatencyMs,omitempty"`
+}
+
+func newHealthTracker() *healthTracker {
+	return &healthTracker{
+		entries: make(map[string]*deviceHealth),
+	}
+}
+
+// available reports whether a device should be contacted. A device in backoff
+// is only retried after its retry time has passed
+func (h *healthTracker) available(uuid string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entry, ok := h.entries[uuid]
+	if !ok {
+		return true
+	}
+	return !time.Now().Before(entry.retryAt)
+}
+
+// reportSuccess clears any backoff for a device and records the call latency
+func (h *healthTracker) reportSuccess(uuid string, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entry, ok := h.entries[uuid]
+	if !ok {
+		entry = &deviceHealth{}
+		h.entries[uuid] = entry
+	}
+	entry.failures = 0
+	entry.retryAt = time.Time{}
+	entry.lastSuccess = time.Now()
+	entry.latencySum += latency
+	entry.latencyCount++
+}
+
+// reportFailure records a failed call and pushes the next retry further out
+// with each consecutive failure
+func (h *healthTracker) reportFailure(uuid string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entry, ok := h.entries[uuid]
+	if !ok {
+		entry = &deviceHealth{}
+		h.entries[uuid] = entry
+	}
+	entry.failures++
+
+	backoff := healthBackoffBase
+	for i := 1; i < entry.failures && backoff < healthBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > healthBackoffMax {
+		backoff = healthBackoffMax
+	}
+	entry.retryAt = time.Now().Add(backoff)
+}
+
+// stats returns a snapshot of a device's call history. The zero value is
+// returned for devices that have never been contacted
+func (h *healthTracker) stats(uuid string) DeviceHealthStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entry, ok := h.entries[uuid]
+	if !ok {
+		return DeviceHealthStats{}
+	}
+	stats := DeviceHealthStats{
+		ConsecutiveFailures: entry.failures,
+		InBackoff:           time.Now().Before(entry.retryAt),
+	}
+	if !entry.lastSuccess.IsZero() {
+		lastSuccess := entry.lastSuccess
+		stats.LastSuccess = &lastSuccess
+	}
+	if entry.latencyCount > 0 {
+		stats.AvgLatencyMs = (entry.latencySum / time.Duration(entry.latencyCount)).Milliseconds()
+	}
+	return stats
+}
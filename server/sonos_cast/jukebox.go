@@ -0,0 +1,319 @@
+package sonos_cast
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"mime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/navidrome/navidrome/conf"
+	"github.com/navidrome/navidrome/core/playback"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+	"github.com/navidrome/navidrome/utils/singleton"
+)
+
+// JukeboxDevice adapts a Sonos room to the Subsonic jukeboxControl command
+// set, so clients that only speak the Subsonic API can target a speaker.
+// The playlist lives on the server side (like the MPV jukebox); the device
+// only ever sees the current track and its successor
+type JukeboxDevice struct {
+	sonos *SonosCast
+	ds    model.DataStore
+
+	mu    sync.Mutex
+	queue model.MediaFiles
+	index int
+	gain  float32
+}
+
+// GetJukeboxDevice returns the Sonos jukebox adapter singleton
+func GetJukeboxDevice(ds model.DataStore) *JukeboxDevice {
+	return singleton.GetInstance(func() *JukeboxDevice {
+		return &JukeboxDevice{
+			sonos: GetInstance(),
+			ds:    ds,
+			gain:  playback.DefaultGain,
+		}
+	})
+}
+
+// deviceID resolves the configured jukebox room to a device UUID.
+// SonosCast.JukeboxRoom may be a room name or a UUID
+func (j *JukeboxDevice) deviceID() (string, error) {
+	room := conf.Server.SonosCast.JukeboxRoom
+	for _, device := range j.sonos.GetDevices() {
+		if device.UUID == room || strings.EqualFold(device.RoomName, room) {
+			return device.UUID, nil
+		}
+	}
+	return "", fmt.Errorf("%w: jukebox room %q", ErrDeviceNotFound, room)
+}
+
+// status builds a Subsonic jukebox status from the device's playback state
+func (j *JukeboxDevice) status(ctx context.Context) playback.DeviceStatus {
+	status := playback.DeviceStatus{
+		CurrentIndex: j.index,
+		Gain:         j.gain,
+	}
+
+	uuid, err := j.deviceID()
+	if err != nil {
+		return status
+	}
+	state, err := j.sonos.GetPlaybackState(ctx, uuid)
+	if err != nil {
+		log.Warn(ctx, "Failed to get Sonos state for jukebox status", err)
+		return status
+	}
+	status.Playing = state.State == StatePlaying
+	if state.CurrentTrack != nil {
+		status.Position = state.CurrentTrack.Position
+	}
+	return status
+}
+
+// Get returns the server-side playlist and the current status
+func (j *JukeboxDevice) Get(ctx context.Context) (model.MediaFiles, playback.DeviceStatus, error) {
+	j.mu.Lock()
+	queue := make(model.MediaFiles, len(j.queue))
+	copy(queue, j.queue)
+	j.mu.Unlock()
+	return queue, j.status(ctx), nil
+}
+
+// Status returns the current jukebox status
+func (j *JukeboxDevice) Status(ctx context.Context) (playback.DeviceStatus, error) {
+	return j.status(ctx), nil
+}
+
+// Set replaces the playlist with the given track IDs
+func (j *JukeboxDevice) Set(ctx context.Context, ids []string) (playback.DeviceStatus, error) {
+	tracks, err := j.lookupTracks(ctx, ids)
+	if err != nil {
+		return playback.DeviceStatus{}, err
+	}
+	j.mu.Lock()
+	j.queue = tracks
+	j.index = 0
+	j.mu.Unlock()
+	return j.status(ctx), nil
+}
+
+// Add appends track IDs to the playlist
+func (j *JukeboxDevice) Add(ctx context.Context, ids []string) (playback.DeviceStatus, error) {
+	tracks, err := j.lookupTracks(ctx, ids)
+	if err != nil {
+		return playback.DeviceStatus{}, err
+	}
+	j.mu.Lock()
+	j.queue = append(j.queue, tracks...)
+	j.mu.Unlock()
+	return j.status(ctx), nil
+}
+
+// Start plays the current playlist entry on the Sonos room
+func (j *JukeboxDevice) Start(ctx context.Context) (playback.DeviceStatus, error) {
+	j.mu.Lock()
+	empty := len(j.queue) == 0
+	index := j.index
+	j.mu.Unlock()
+	if empty {
+		return playback.DeviceStatus{}, fmt.Errorf("jukebox playlist is empty")
+	}
+
+	// Resuming a paused track beats restarting it, but only the device
+	// knows whether anything is loaded - try Play first
+	uuid, err := j.deviceID()
+	if err != nil {
+		return playback.DeviceStatus{}, err
+	}
+	state, stateErr := j.sonos.GetPlaybackState(ctx, uuid)
+	if stateErr == nil && state.State == StatePaused {
+		if err := j.sonos.Play(ctx, uuid); err == nil {
+			return j.status(ctx), nil
+		}
+	}
+
+	if err := j.playIndex(ctx, index); err != nil {
+		return playback.DeviceStatus{}, err
+	}
+	return j.status(ctx), nil
+}
+
+// Stop pauses playback on the Sonos room
+func (j *JukeboxDevice) Stop(ctx context.Context) (playback.DeviceStatus, error) {
+	uuid, err := j.deviceID()
+	if err != nil {
+		return playback.DeviceStatus{}, err
+	}
+	if err := j.sonos.Pause(ctx, uuid); err != nil {
+		return playback.DeviceStatus{}, err
+	}
+	return j.status(ctx), nil
+}
+
+// Skip jumps to the given playlist index, optionally seeking into the track
+func (j *JukeboxDevice) Skip(ctx context.Context, index int, offset int) (playback.DeviceStatus, error) {
+	j.mu.Lock()
+	if index < 0 || index >= len(j.queue) {
+		j.mu.Unlock()
+		return playback.DeviceStatus{}, fmt.Errorf("index %d out of range", index)
+	}
+	j.index = index
+	j.mu.Unlock()
+
+	if err := j.playIndex(ctx, index); err != nil {
+		return playback.DeviceStatus{}, err
+	}
+	if offset > 0 {
+		uuid, err := j.deviceID()
+		if err == nil {
+			if err := j.sonos.Seek(ctx, uuid, time.Duration(offset)*time.Second); err != nil {
+				log.Warn(ctx, "Jukebox seek failed", "offset", offset, err)
+			}
+		}
+	}
+	return j.status(ctx), nil
+}
+
+// Clear empties the playlist and stops playback
+func (j *JukeboxDevice) Clear(ctx context.Context) (playback.DeviceStatus, error) {
+	j.mu.Lock()
+	j.queue = nil
+	j.index = 0
+	j.mu.Unlock()
+
+	if uuid, err := j.deviceID(); err == nil {
+		if err := j.sonos.Stop(ctx, uuid); err != nil {
+			log.Warn(ctx, "Failed to stop Sonos after jukebox clear", err)
+		}
+	}
+	return j.status(ctx), nil
+}
+
+// Remove deletes one playlist entry
+func (j *JukeboxDevice) Remove(ctx context.Context, index int) (playback.DeviceStatus, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if index < 0 || index >= len(j.queue) {
+		return playback.DeviceStatus{}, fmt.Errorf("index %d out of range", index)
+	}
+	j.queue = append(j.queue[:index], j.queue[index+1:]...)
+	if j.index > index {
+		j.index--
+	}
+	return playback.DeviceStatus{CurrentIndex: j.index, Gain: j.gain}, nil
+}
+
+// Shuffle randomizes the playlist order
+func (j *JukeboxDevice) Shuffle(ctx context.Context) (playback.DeviceStatus, error) {
+	j.mu.Lock()
+	rand.Shuffle(len(j.queue), func(a, b int) {
+		j.queue[a], j.queue[b] = j.queue[b], j.queue[a]
+	})
+	j.index = 0
+	j.mu.Unlock()
+	return j.status(ctx), nil
+}
+
+// SetGain maps the Subsonic jukebox gain (0.0-1.0) to the room volume
+func (j *JukeboxDevice) SetGain(ctx context.Context, gain float32) (playback.DeviceStatus, error) {
+	uuid, err := j.deviceID()
+	if err != nil {
+		return playback.DeviceStatus{}, err
+	}
+	volume := int(gain * 100)
+	if err := j.sonos.SetVolume(ctx, uuid, volume); err != nil {
+		return playback.DeviceStatus{}, err
+	}
+	j.mu.Lock()
+	j.gain = gain
+	j.mu.Unlock()
+	return j.status(ctx), nil
+}
+
+// lookupTracks resolves track IDs, dropping anything outside the requesting
+// user's libraries
+func (j *JukeboxDevice) lookupTracks(ctx context.Context, ids []string) (model.MediaFiles, error) {
+	user, _ := request.UserFrom(ctx)
+	tracks := make(model.MediaFiles, 0, len(ids))
+	for _, id := range ids {
+		track, err := j.ds.MediaFile(ctx).Get(id)
+		if err != nil {
+			return nil, fmt.Errorf("track not found: %w", err)
+		}
+		if !user.HasLibraryAccess(track.LibraryID) {
+			log.Warn(ctx, "Jukebox request denied - track not in user's libraries",
+				"username", user.UserName, "trackID", id, "libraryID", track.LibraryID)
+			continue
+		}
+		tracks = append(tracks, *track)
+	}
+	return tracks, nil
+}
+
+// playIndex sends the given playlist entry to the speaker, pre-queuing the
+// following entry for gapless transition
+func (j *JukeboxDevice) playIndex(ctx context.Context, index int) error {
+	uuid, err := j.deviceID()
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	if index >= len(j.queue) {
+		j.mu.Unlock()
+		return fmt.Errorf("index %d out of range", index)
+	}
+	track := j.queue[index]
+	var next *model.MediaFile
+	if index+1 < len(j.queue) {
+		next = &j.queue[index+1]
+	}
+	j.mu.Unlock()
+
+	uri, metadata := j.trackMedia(ctx, &track)
+	if err := j.sonos.PlayURI(ctx, uuid, uri, metadata); err != nil {
+		return err
+	}
+
+	if next != nil {
+		device, err := j.sonos.getCoordinator(ctx, uuid)
+		if err == nil {
+			nextURI, nextMetadata := j.trackMedia(ctx, next)
+			if err := j.sonos.transport.SetNextAVTransportURI(ctx, device, nextURI, nextMetadata); err != nil {
+				log.Debug(ctx, "Failed to pre-queue next jukebox track", "track", next.Title, err)
+			}
+		}
+	}
+	return nil
+}
+
+// trackMedia builds the signed stream URL and DIDL metadata for a track
+func (j *JukeboxDevice) trackMedia(ctx context.Context, track *model.MediaFile) (string, string) {
+	uuid, _ := j.deviceID()
+	baseURL := j.sonos.GetStreamBaseURLFor(uuid)
+	opts := castStreamOptions(ctx, j.ds, track)
+	streamURL := buildStreamURL(baseURL, track.ID, opts)
+
+	artURL := ""
+	if track.HasCoverArt {
+		artURL = buildCoverArtURL(baseURL, track.AlbumID)
+	}
+
+	mimeType := track.ContentType()
+	if opts.format != "" {
+		mimeType = mime.TypeByExtension("." + opts.format)
+	}
+	if mimeType == "" {
+		mimeType = "audio/flac"
+	}
+
+	metadata := j.sonos.BuildTrackMetadata(didlTrack(track, opts, streamURL, mimeType, artURL))
+	return streamURL, metadata
+}
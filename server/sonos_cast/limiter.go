@@ -0,0 +1,91 @@
+package sonos_cast
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// soapMaxConcurrent is how many SOAP calls may be in flight to one device.
+	// Older ZonePlayers serialize requests internally and start dropping
+	// connections when hit with bursts, so keep this small
+	soapMaxConcurrent = 2
+
+	// soapMinInterval is the minimum spacing between calls to one device
+	soapMinInterval = 50 * time.Millisecond
+)
+
+// soapLimiter queues and paces SOAP traffic per device. Like the health
+// tracker it is shared by all control services, so a burst of mixed transport
+// and volume actions still respects the device's limits as a whole
+type soapLimiter struct {
+	mu      sync.Mutex
+	entries map[string]*deviceLimiter
+}
+
+type deviceLimiter struct {
+	slots    chan struct{} // one entry per in-flight call
+	mu       sync.Mutex
+	nextCall time.Time
+}
+
+func newSOAPLimiter() *soapLimiter {
+	return &soapLimiter{
+		entries: make(map[string]*deviceLimiter),
+	}
+}
+
+func (l *soapLimiter) forDevice(uuid string) *deviceLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.entries[uuid]
+	if !ok {
+		entry = &deviceLimiter{slots: make(chan struct{}, soapMaxConcurrent)}
+		l.entries[uuid] = entry
+	}
+	return entry
+}
+
+// acquire blocks until the device has a free concurrency slot and the pacing
+// interval has elapsed, or the context is canceled. Every successful acquire
+// must be paired with a release
+func (l *soapLimiter) acquire(ctx context.Context, uuid string) error {
+	entry := l.forDevice(uuid)
+	select {
+	case entry.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// Claim the next send time, then wait for it - concurrent callers each
+	// get their own slot in the schedule
+	entry.mu.Lock()
+	at := entry.nextCall
+	if now := time.Now(); at.Before(now) {
+		at = now
+	}
+	entry.nextCall = at.Add(soapMinInterval)
+	entry.mu.Unlock()
+
+	if wait := time.Until(at); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			<-entry.slots
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// release frees the concurrency slot taken by acquire
+func (l *soapLimiter) release(uuid string) {
+	entry := l.forDevice(uuid)
+	select {
+	case <-entry.slots:
+	default:
+	}
+}
@@ -0,0 +1,299 @@
+package sonos_cast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+)
+
+// partyPollInterval is how often a party session checks the saved play queue
+// for changes. Edits land on the speakers within one interval
+const partyPollInterval = 2 * time.Second
+
+// partySessions tracks the active party session per device UUID
+var partySessions = &sync.Map{}
+
+// partySession mirrors one user's web player queue to a Sonos device (or
+// group, via its coordinator). The session owns the device's native queue
+// while it runs - queue edits, skips and reorders in the web UI are replayed
+// onto the speaker
+type partySession struct {
+	deviceID string
+	userID   string
+	cancel   context.CancelFunc
+
+	mu        sync.Mutex
+	trackIds  []string
+	current   int
+	updatedAt time.Time
+}
+
+func (s *partySession) snapshot() (trackIds []string, current int, updatedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.trackIds, s.current, s.updatedAt
+}
+
+func (s *partySession) update(trackIds []string, current int, updatedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if trackIds != nil {
+		s.trackIds = trackIds
+	}
+	s.current = current
+	if !updatedAt.IsZero() {
+		s.updatedAt = updatedAt
+	}
+}
+
+// startParty begins mirroring the requesting user's play queue to a device
+func (a *API) startParty(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	deviceID := chi.URLParam(r, "id")
+
+	user, ok := request.UserFrom(ctx)
+	if !ok {
+		a.sendError(w, http.StatusForbidden, "no user in context")
+		return
+	}
+	if _, ok := a.sonosCast.GetDevice(deviceID); !ok {
+		a.sendError(w, http.StatusNotFound, "device not found")
+		return
+	}
+
+	pq, err := a.ds.PlayQueue(ctx).RetrieveWithMediaFiles(user.ID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			a.sendError(w, http.StatusNotFound, "no play queue to mirror")
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	if len(pq.Items) == 0 {
+		a.sendError(w, http.StatusNotFound, "no play queue to mirror")
+		return
+	}
+
+	// The party session replaces any other server-driven playback on the device
+	a.stopPartySession(deviceID)
+	a.stopQueuePlayer(deviceID)
+	deviceQueues.Delete(deviceID)
+
+	trackIds := make([]string, len(pq.Items))
+	for i := range pq.Items {
+		trackIds[i] = pq.Items[i].ID
+	}
+
+	if err := a.rebuildPartyQueue(ctx, deviceID, trackIds); err != nil {
+		if errors.Is(err, ErrNotAuthorized) {
+			a.sendError(w, http.StatusForbidden, err.Error())
+		} else {
+			a.sendError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	current := pq.Current
+	if current < 0 || current >= len(pq.Items) {
+		current = 0
+	}
+	if err := a.sonosCast.PlayFromQueue(ctx, deviceID, current+1); err != nil {
+		a.sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if pq.Position > 0 {
+		if err := a.sonosCast.Seek(ctx, deviceID, time.Duration(pq.Position)*time.Millisecond); err != nil {
+			log.Warn(ctx, "Failed to seek to saved position for party mode", "position", pq.Position, err)
+		}
+	}
+	mf := &pq.Items[current]
+	a.tracker.Track(ctx, deviceID, mf.ID, int(mf.Duration))
+
+	session := &partySession{
+		deviceID:  deviceID,
+		userID:    user.ID,
+		trackIds:  trackIds,
+		current:   current,
+		updatedAt: pq.UpdatedAt,
+	}
+	// Detach from the request so the session keeps the user (for queue reads
+	// and per-track access checks) but outlives the HTTP call
+	sessionCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	session.cancel = cancel
+	partySessions.Store(deviceID, session)
+	go a.runPartySession(sessionCtx, session)
+
+	log.Info(ctx, "Started party mode", "deviceID", deviceID, "username", user.UserName, "queueSize", len(trackIds))
+	a.recordAudit(ctx, deviceID, auditActionParty, "", "")
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "party", "queueSize": fmt.Sprintf("%d", len(trackIds))})
+}
+
+// stopParty ends the device's party session, leaving whatever is playing alone
+func (a *API) stopParty(w http.ResponseWriter, r *http.Request) {
+	deviceID := chi.URLParam(r, "id")
+	a.stopPartySession(deviceID)
+	a.sendJSON(w, http.StatusOK, map[string]string{"status": "stopped"})
+}
+
+// getParty reports whether a party session is running on the device
+func (a *API) getParty(w http.ResponseWriter, r *http.Request) {
+	deviceID := chi.URLParam(r, "id")
+	val, ok := partySessions.Load(deviceID)
+	if !ok {
+		a.sendJSON(w, http.StatusOK, map[string]any{"active": false})
+		return
+	}
+	session := val.(*partySession)
+	trackIds, current, _ := session.snapshot()
+	a.sendJSON(w, http.StatusOK, map[string]any{
+		"active":    true,
+		"queueSize": len(trackIds),
+		"current":   current,
+	})
+}
+
+// stopPartySession cancels the party session for a device, if one is running
+func (a *API) stopPartySession(deviceID string) {
+	if val, ok := partySessions.LoadAndDelete(deviceID); ok {
+		val.(*partySession).cancel()
+		log.Debug("Stopped party session", "deviceID", deviceID)
+	}
+}
+
+// rebuildPartyQueue replaces the device's native queue with the given tracks.
+// The first failure is fatal - starting a party with a broken queue would
+// just skip around unpredictably
+func (a *API) rebuildPartyQueue(ctx context.Context, deviceID string, trackIds []string) error {
+	if err := a.sonosCast.ClearQueue(ctx, deviceID); err != nil {
+		return err
+	}
+	for _, trackID := range trackIds {
+		if err := a.enqueueTrack(ctx, deviceID, trackID, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runPartySession watches the user's saved play queue and replays changes
+// onto the device until the session is cancelled or the queue goes away
+func (a *API) runPartySession(ctx context.Context, s *partySession) {
+	ticker := time.NewTicker(partyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, ok := a.sonosCast.GetDevice(s.deviceID); !ok {
+			log.Info(ctx, "Party device disappeared - ending session", "deviceID", s.deviceID)
+			a.stopPartySession(s.deviceID)
+			return
+		}
+
+		a.followSpeakerProgress(ctx, s)
+
+		pq, err := a.ds.PlayQueue(ctx).Retrieve(s.userID)
+		if err != nil {
+			if errors.Is(err, model.ErrNotFound) {
+				// The user cleared their queue - party's over
+				log.Info(ctx, "Play queue cleared - ending party session", "deviceID", s.deviceID)
+				if err := a.sonosCast.ClearQueue(ctx, s.deviceID); err != nil {
+					log.Warn(ctx, "Failed to clear device queue after party", "deviceID", s.deviceID, err)
+				}
+				a.stopPartySession(s.deviceID)
+				return
+			}
+			log.Debug(ctx, "Failed to read play queue for party sync", "deviceID", s.deviceID, err)
+			continue
+		}
+
+		_, _, lastUpdate := s.snapshot()
+		if !pq.UpdatedAt.After(lastUpdate) {
+			continue
+		}
+		a.applyQueueChange(ctx, s, pq)
+	}
+}
+
+// followSpeakerProgress keeps the session's current index aligned with the
+// speaker's own queue position, so a natural advance to the next track isn't
+// mistaken for a skip in the web UI and yanked back
+func (a *API) followSpeakerProgress(ctx context.Context, s *partySession) {
+	state, err := a.sonosCast.GetPlaybackState(ctx, s.deviceID)
+	if err != nil || state.Source != SourceQueue || state.CurrentTrack == nil {
+		return
+	}
+	trackIds, current, _ := s.snapshot()
+	idx := state.CurrentTrack.TrackNum - 1
+	if idx < 0 || idx >= len(trackIds) || idx == current {
+		return
+	}
+	s.update(nil, idx, time.Time{})
+	// Follow playback so plays are counted and scrobbled
+	if mf, err := a.ds.MediaFile(ctx).Get(trackIds[idx]); err == nil {
+		a.tracker.Track(ctx, s.deviceID, mf.ID, int(mf.Duration))
+	}
+}
+
+// applyQueueChange mirrors one saved-queue update onto the device. Appends
+// and skips are applied incrementally; anything else rebuilds the queue
+func (a *API) applyQueueChange(ctx context.Context, s *partySession, pq *model.PlayQueue) {
+	newIds := make([]string, len(pq.Items))
+	for i := range pq.Items {
+		newIds[i] = pq.Items[i].ID
+	}
+
+	trackIds, current, _ := s.snapshot()
+	switch {
+	case slices.Equal(newIds, trackIds):
+		// Same tracks - only the playhead may have moved
+		if pq.Current != current && pq.Current >= 0 && pq.Current < len(newIds) {
+			if err := a.sonosCast.SeekToQueueEntry(ctx, s.deviceID, pq.Current+1); err != nil {
+				log.Warn(ctx, "Failed to mirror skip to device", "deviceID", s.deviceID, err)
+				return
+			}
+			current = pq.Current
+			if mf, err := a.ds.MediaFile(ctx).Get(newIds[current]); err == nil {
+				a.tracker.Track(ctx, s.deviceID, mf.ID, int(mf.Duration))
+			}
+		}
+	case len(newIds) > len(trackIds) && slices.Equal(newIds[:len(trackIds)], trackIds):
+		// Tracks were appended - add just those, playback is untouched
+		for _, trackID := range newIds[len(trackIds):] {
+			if err := a.enqueueTrack(ctx, s.deviceID, trackID, false); err != nil {
+				log.Warn(ctx, "Failed to mirror appended track", "trackID", trackID, "deviceID", s.deviceID, err)
+				return
+			}
+		}
+	default:
+		// Removal or reorder - rebuild the queue and point the transport back
+		// at the current entry
+		if err := a.rebuildPartyQueue(ctx, s.deviceID, newIds); err != nil {
+			log.Warn(ctx, "Failed to rebuild party queue", "deviceID", s.deviceID, err)
+			return
+		}
+		current = pq.Current
+		if current < 0 || current >= len(newIds) {
+			current = 0
+		}
+		if err := a.sonosCast.PlayFromQueue(ctx, s.deviceID, current+1); err != nil {
+			log.Warn(ctx, "Failed to resume playback after queue rebuild", "deviceID", s.deviceID, err)
+			return
+		}
+	}
+	s.update(newIds, current, pq.UpdatedAt)
+}
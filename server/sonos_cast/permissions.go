@@ -0,0 +1,86 @@
+package sonos_cast
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/model/request"
+)
+
+// DevicePermissions holds the admin-managed list of users allowed to control
+// a device. An empty list means everyone may control it (the default). Admins
+// are always allowed. Like DeviceSettings, they are persisted in the
+// properties table, keyed by the device UUID
+type DevicePermissions struct {
+	UserIDs []string `json:"userIds"`
+}
+
+const devicePermissionsKeyPrefix = "SonosCastDevicePermissions-"
+
+func devicePermissionsKey(uuid string) string {
+	return devicePermissionsKeyPrefix + uuid
+}
+
+// loadDevicePermissions reads the persisted permissions for a device. Missing
+// or invalid permissions yield the zero value (everyone allowed)
+func loadDevicePermissions(ctx context.Context, ds model.DataStore, uuid string) DevicePermissions {
+	var perms DevicePermissions
+	value, err := ds.Property(ctx).DefaultGet(devicePermissionsKey(uuid), "")
+	if err != nil || value == "" {
+		return perms
+	}
+	if err := json.Unmarshal([]byte(value), &perms); err != nil {
+		log.Warn(ctx, "Invalid Sonos device permissions - ignoring", "uuid", uuid, err)
+	}
+	return perms
+}
+
+// saveDevicePermissions persists the permissions for a device
+func saveDevicePermissions(ctx context.Context, ds model.DataStore, uuid string, perms DevicePermissions) error {
+	value, err := json.Marshal(perms)
+	if err != nil {
+		return err
+	}
+	return ds.Property(ctx).Put(devicePermissionsKey(uuid), string(value))
+}
+
+// allows reports whether the given user may control the device
+func (p DevicePermissions) allows(user model.User) bool {
+	if user.IsAdmin {
+		return true
+	}
+	if len(p.UserIDs) == 0 {
+		return true
+	}
+	return slices.Contains(p.UserIDs, user.ID)
+}
+
+// canControlDevice checks the persisted permissions for a device against the
+// user in the request context
+func (a *API) canControlDevice(ctx context.Context, deviceID string) bool {
+	user, ok := request.UserFrom(ctx)
+	if !ok {
+		return false
+	}
+	return loadDevicePermissions(ctx, a.ds, deviceID).allows(user)
+}
+
+// requireDeviceAccess is a middleware that rejects requests from users who
+// are not allowed to control the device addressed by the route
+func (a *API) requireDeviceAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		deviceID := chi.URLParam(r, "id")
+		if !a.canControlDevice(ctx, deviceID) {
+			log.Warn(ctx, "Sonos device access denied", "deviceID", deviceID)
+			a.sendError(w, http.StatusForbidden, "you are not allowed to control this device")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
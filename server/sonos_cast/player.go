@@ -0,0 +1,157 @@
+package sonos_cast
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+)
+
+const (
+	// How often the queue player checks the transport state. Shorter than the
+	// tracker interval, so a speaker that stopped at the end of a track doesn't
+	// sit silent for long before the next one is pushed
+	queuePollInterval = 3 * time.Second
+
+	// How long a freshly cast track may report STOPPED before the player gives
+	// up on it. Speakers buffer for a bit before the transport starts moving
+	queueStartTimeout = 30 * time.Second
+)
+
+// queuePlayers holds the cancel function of the player goroutine per device
+// (thread-safe access via sync.Map)
+var queuePlayers = &sync.Map{}
+
+// startQueuePlayer starts (or replaces) the server-side playlist player for a
+// device. Sonos S2 honors SetNextAVTransportURI and advances on its own, but
+// S1 speakers and generic renderers just stop at the end of the track - the
+// player watches the transport and pushes the next queued track in either
+// case, so a multi-track cast doesn't silently end after the first track
+func (a *API) startQueuePlayer(ctx context.Context, deviceID, currentTrackID, nextTrackID string) {
+	a.stopQueuePlayer(deviceID)
+
+	// Detach from the request context, but keep its values (user, player, etc.)
+	playerCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	queuePlayers.Store(deviceID, cancel)
+
+	log.Debug(ctx, "Started Sonos queue player", "deviceID", deviceID, "trackID", currentTrackID)
+	go a.runQueuePlayer(playerCtx, deviceID, currentTrackID, nextTrackID)
+}
+
+// stopQueuePlayer cancels the playlist player for a device, if any
+func (a *API) stopQueuePlayer(deviceID string) {
+	if val, ok := queuePlayers.LoadAndDelete(deviceID); ok {
+		val.(context.CancelFunc)()
+	}
+}
+
+// runQueuePlayer is the player loop. It follows the transport until the stored
+// queue is exhausted, the user switches the device to another source, or the
+// player is replaced by a newer cast
+func (a *API) runQueuePlayer(ctx context.Context, deviceID, currentTrackID, nextTrackID string) {
+	ticker := time.NewTicker(queuePollInterval)
+	defer ticker.Stop()
+
+	playing := false
+	lastCast := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		device, ok := a.sonosCast.GetDevice(deviceID)
+		if !ok {
+			log.Debug(ctx, "Device disappeared - stopping queue player", "deviceID", deviceID)
+			return
+		}
+
+		state, err := a.sonosCast.transport.GetTransportInfo(ctx, device)
+		if err != nil {
+			log.Debug(ctx, "Failed to get transport state for queue player", "device", device.RoomName, err)
+			continue
+		}
+
+		if state == StateStopped {
+			// A freshly cast track can report STOPPED while the speaker is
+			// still buffering - don't mistake that for the end of the track
+			if !playing {
+				if time.Since(lastCast) > queueStartTimeout {
+					log.Debug(ctx, "Track never started - stopping queue player",
+						"device", device.RoomName, "trackID", currentTrackID)
+					return
+				}
+				continue
+			}
+			if nextTrackID == "" {
+				log.Debug(ctx, "Queue finished - stopping queue player", "device", device.RoomName)
+				return
+			}
+
+			// The renderer reached the end of the track without advancing on
+			// its own - the S1/generic case. Push the next track directly
+			log.Debug(ctx, "Device stopped without advancing - casting next queued track",
+				"device", device.RoomName, "trackID", nextTrackID)
+			if err := a.castTrack(ctx, deviceID, nextTrackID); err != nil {
+				log.Warn(ctx, "Queue player failed to cast next track",
+					"device", device.RoomName, "trackID", nextTrackID, err)
+				return
+			}
+			currentTrackID = nextTrackID
+			nextTrackID = a.preQueueNext(ctx, deviceID)
+			playing = false
+			lastCast = time.Now()
+			continue
+		}
+
+		track, err := a.sonosCast.transport.GetPositionInfo(ctx, device)
+		if err != nil {
+			log.Debug(ctx, "Failed to get position info for queue player", "device", device.RoomName, err)
+			continue
+		}
+		playingID := extractTrackIDFromURI(track.URI)
+
+		if playingID == currentTrackID {
+			if state == StatePlaying {
+				playing = true
+			}
+			continue
+		}
+
+		if nextTrackID != "" && playingID == nextTrackID {
+			// The device honored SetNextAVTransportURI and advanced by itself.
+			// Hand the new track to the tracker and pre-queue the one after it
+			currentTrackID = nextTrackID
+			nextTrackID = a.preQueueNext(ctx, deviceID)
+			playing = true
+			if mf, err := a.ds.MediaFile(ctx).Get(currentTrackID); err == nil {
+				a.tracker.Track(ctx, deviceID, currentTrackID, int(mf.Duration))
+			}
+			continue
+		}
+
+		// The user switched the device to another source - abandon the queue
+		log.Debug(ctx, "Device is playing something else - stopping queue player",
+			"device", device.RoomName, "uri", track.URI)
+		deviceQueues.Delete(deviceID)
+		return
+	}
+}
+
+// preQueueNext pops the next pending track from the device queue and hands it
+// to the speaker as the next URI. Returns the popped track ID, or "" when the
+// queue is exhausted
+func (a *API) preQueueNext(ctx context.Context, deviceID string) string {
+	trackID, _, ok := a.getNextFromQueue(deviceID)
+	if !ok {
+		return ""
+	}
+	if err := a.setNextTrack(ctx, deviceID, trackID); err != nil {
+		// Non-fatal - the player will cast the track itself when the device stops
+		log.Debug(ctx, "Failed to pre-queue next track", "deviceID", deviceID, "trackID", trackID, err)
+	}
+	return trackID
+}
@@ -0,0 +1,210 @@
+package sonos_cast
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+)
+
+// ContentDirectory provides access to the device's ContentDirectory service,
+// which exposes the playback queue as container "Q:0"
+type ContentDirectory struct {
+	client  *http.Client
+	health  *healthTracker
+	limiter *soapLimiter
+}
+
+// NewContentDirectory creates a new ContentDirectory controller
+func NewContentDirectory(health *healthTracker, limiter *soapLimiter) *ContentDirectory {
+	return &ContentDirectory{
+		client: &http.Client{
+			Timeout: soapTimeout(),
+		},
+		health:  health,
+		limiter: limiter,
+	}
+}
+
+// QueueItem represents one entry in a device's playback queue
+type QueueItem struct {
+	Index    int    `json:"index"` // 1-based position in the queue
+	URI      string `json:"uri"`
+	Title    string `json:"title"`
+	Artist   string `json:"artist,omitempty"`
+	Album    string `json:"album,omitempty"`
+	TrackID  string `json:"trackId,omitempty"` // Navidrome ID, when the URI points back at us
+	Duration int    `json:"duration,omitempty"`
+}
+
+// didlLite is the subset of a DIDL-Lite document needed to read queue entries
+type didlLite struct {
+	XMLName xml.Name   `xml:"DIDL-Lite"`
+	Items   []didlItem `xml:"item"`
+}
+
+type didlItem struct {
+	ID      string `xml:"id,attr"`
+	Title   string `xml:"title"`
+	Creator string `xml:"creator"`
+	Album   string `xml:"album"`
+	Res     string `xml:"res"`
+}
+
+// BrowseQueue reads a page of the device's queue (container Q:0)
+func (c *ContentDirectory) BrowseQueue(ctx context.Context, device *SonosDevice, start, count int) ([]QueueItem, int, error) {
+	action := BrowseAction{
+		XmlnsU:         ContentDirectoryURN,
+		ObjectID:       "Q:0",
+		BrowseFlag:     "BrowseDirectChildren",
+		Filter:         "*",
+		StartingIndex:  start,
+		RequestedCount: count,
+	}
+
+	respBody, err := c.sendAction(ctx, device, "Browse", action)
+	if err != nil {
+		return nil, 0, fmt.Errorf("queue Browse failed: %w", err)
+	}
+
+	var resp BrowseResponse
+	if err := extractSOAPResponse(respBody, &resp); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse queue Browse response: %w", err)
+	}
+
+	// The Result element contains an XML-escaped DIDL-Lite document
+	var didl didlLite
+	if err := xml.Unmarshal([]byte(html.UnescapeString(resp.Result)), &didl); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse queue DIDL: %w", err)
+	}
+
+	items := make([]QueueItem, 0, len(didl.Items))
+	for i, item := range didl.Items {
+		items = append(items, QueueItem{
+			Index:  start + i + 1,
+			URI:    item.Res,
+			Title:  item.Title,
+			Artist: item.Creator,
+			Album:  item.Album,
+		})
+	}
+
+	return items, resp.TotalMatches, nil
+}
+
+// AddFavorite pins an item into the Sonos Favorites list (container "FV:2")
+// via the ContentDirectory CreateObject action. Favorites are shared across
+// the whole household, so any reachable device will do. The returned object
+// ID identifies the new favorite
+func (c *ContentDirectory) AddFavorite(ctx context.Context, device *SonosDevice, title, description, uri, resMD string) (string, error) {
+	// A favorite is a DIDL item of class sonos-favorite. The r:resMD element
+	// carries the (escaped) DIDL metadata of the favorited item itself, which
+	// the Sonos app uses to render and later play the entry
+	elements := fmt.Sprintf(`<DIDL-Lite xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/" xmlns:r="urn:schemas-rinconnetworks-com:metadata-1-0/" xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/">
+<item id="" parentID="%s" restricted="false">
+<dc:title>%s</dc:title>
+<upnp:class>object.itemobject.item.sonos-favorite</upnp:class>
+<r:description>%s</r:description>
+<r:resMD>%s</r:resMD>
+<res protocolInfo="http-get:*:*:*">%s</res>
+</item>
+</DIDL-Lite>`,
+		FavoritesContainerID,
+		html.EscapeString(title),
+		html.EscapeString(description),
+		html.EscapeString(resMD),
+		html.EscapeString(uri))
+
+	action := CreateObjectAction{
+		XmlnsU:      ContentDirectoryURN,
+		ContainerID: FavoritesContainerID,
+		Elements:    elements,
+	}
+
+	respBody, err := c.sendAction(ctx, device, "CreateObject", action)
+	if err != nil {
+		return "", fmt.Errorf("favorites CreateObject failed: %w", err)
+	}
+
+	var resp CreateObjectResponse
+	if err := extractSOAPResponse(respBody, &resp); err != nil {
+		return "", fmt.Errorf("failed to parse CreateObject response: %w", err)
+	}
+
+	log.Info(ctx, "Added Sonos favorite", "title", title, "objectID", resp.ObjectID)
+	return resp.ObjectID, nil
+}
+
+// sendAction sends a SOAP action to the device's ContentDirectory service
+func (c *ContentDirectory) sendAction(ctx context.Context, device *SonosDevice, actionName string, action interface{}) ([]byte, error) {
+	// Skip devices in failure backoff instead of waiting for a timeout
+	if !c.health.available(device.UUID) {
+		return nil, ErrDeviceUnreachable
+	}
+
+	// Queue behind other in-flight calls to this device - bursts of UI
+	// actions can overwhelm older ZonePlayers
+	if err := c.limiter.acquire(ctx, device.UUID); err != nil {
+		return nil, err
+	}
+	defer c.limiter.release(device.UUID)
+
+	// Build SOAP envelope
+	envelope := SOAPEnvelope{
+		XmlnsS:        "http://schemas.xmlsoap.org/soap/envelope/",
+		EncodingStyle: "http://schemas.xmlsoap.org/soap/encoding/",
+		Body: SOAPBody{
+			Content: action,
+		},
+	}
+
+	body, err := xml.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SOAP envelope: %w", err)
+	}
+
+	// Add XML declaration
+	body = append([]byte(xml.Header), body...)
+
+	// Build request
+	url := fmt.Sprintf("http://%s:%d%s", device.IP, device.Port, ContentDirectoryControlURL)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	req.Header.Set("SOAPACTION", fmt.Sprintf("\"%s#%s\"", ContentDirectoryURN, actionName))
+
+	// Send request, timing it for the health stats
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.health.reportFailure(device.UUID)
+		return nil, fmt.Errorf("%w: %v", ErrDeviceUnreachable, err)
+	}
+	c.health.reportSuccess(device.UUID, time.Since(start))
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if upnpErr := parseSOAPFault(respBody); upnpErr != nil {
+			log.Error(ctx, "SOAP fault received", "action", actionName,
+				"code", upnpErr.Code, "description", upnpErr.Description)
+			return nil, upnpErr
+		}
+		return nil, fmt.Errorf("SOAP request failed: %d - %s", resp.StatusCode, string(respBody))
+	}
+
+	return respBody, nil
+}
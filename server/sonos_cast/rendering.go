@@ -14,15 +14,19 @@ import (
 
 // RenderingControl provides volume and mute control for Sonos devices
 type RenderingControl struct {
-	client *http.Client
+	client  *http.Client
+	health  *healthTracker
+	limiter *soapLimiter
 }
 
 // NewRenderingControl creates a new RenderingControl controller
-func NewRenderingControl() *RenderingControl {
+func NewRenderingControl(health *healthTracker, limiter *soapLimiter) *RenderingControl {
 	return &RenderingControl{
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: soapTimeout(),
 		},
+		health:  health,
+		limiter: limiter,
 	}
 }
 
@@ -117,6 +121,135 @@ func (r *RenderingControl) SetMute(ctx context.Context, device *SonosDevice, mut
 	return nil
 }
 
+// GetBass gets the current bass level (-10 to 10)
+func (r *RenderingControl) GetBass(ctx context.Context, device *SonosDevice) (int, error) {
+	action := GetBassAction{
+		XmlnsU:     RenderingControlURN,
+		InstanceID: 0,
+	}
+
+	respBody, err := r.sendAction(ctx, device, "GetBass", action)
+	if err != nil {
+		return 0, fmt.Errorf("GetBass failed: %w", err)
+	}
+
+	var resp GetBassResponse
+	if err := extractSOAPResponse(respBody, &resp); err != nil {
+		return 0, fmt.Errorf("failed to parse GetBass response: %w", err)
+	}
+
+	return resp.CurrentBass, nil
+}
+
+// SetBass sets the bass level (-10 to 10)
+func (r *RenderingControl) SetBass(ctx context.Context, device *SonosDevice, bass int) error {
+	action := SetBassAction{
+		XmlnsU:      RenderingControlURN,
+		InstanceID:  0,
+		DesiredBass: clampEQ(bass),
+	}
+
+	_, err := r.sendAction(ctx, device, "SetBass", action)
+	if err != nil {
+		return fmt.Errorf("SetBass failed: %w", err)
+	}
+
+	log.Debug(ctx, "Set bass", "device", device.RoomName, "bass", bass)
+	return nil
+}
+
+// GetTreble gets the current treble level (-10 to 10)
+func (r *RenderingControl) GetTreble(ctx context.Context, device *SonosDevice) (int, error) {
+	action := GetTrebleAction{
+		XmlnsU:     RenderingControlURN,
+		InstanceID: 0,
+	}
+
+	respBody, err := r.sendAction(ctx, device, "GetTreble", action)
+	if err != nil {
+		return 0, fmt.Errorf("GetTreble failed: %w", err)
+	}
+
+	var resp GetTrebleResponse
+	if err := extractSOAPResponse(respBody, &resp); err != nil {
+		return 0, fmt.Errorf("failed to parse GetTreble response: %w", err)
+	}
+
+	return resp.CurrentTreble, nil
+}
+
+// SetTreble sets the treble level (-10 to 10)
+func (r *RenderingControl) SetTreble(ctx context.Context, device *SonosDevice, treble int) error {
+	action := SetTrebleAction{
+		XmlnsU:        RenderingControlURN,
+		InstanceID:    0,
+		DesiredTreble: clampEQ(treble),
+	}
+
+	_, err := r.sendAction(ctx, device, "SetTreble", action)
+	if err != nil {
+		return fmt.Errorf("SetTreble failed: %w", err)
+	}
+
+	log.Debug(ctx, "Set treble", "device", device.RoomName, "treble", treble)
+	return nil
+}
+
+// GetLoudness gets the current loudness state
+func (r *RenderingControl) GetLoudness(ctx context.Context, device *SonosDevice) (bool, error) {
+	action := GetLoudnessAction{
+		XmlnsU:     RenderingControlURN,
+		InstanceID: 0,
+		Channel:    "Master",
+	}
+
+	respBody, err := r.sendAction(ctx, device, "GetLoudness", action)
+	if err != nil {
+		return false, fmt.Errorf("GetLoudness failed: %w", err)
+	}
+
+	var resp GetLoudnessResponse
+	if err := extractSOAPResponse(respBody, &resp); err != nil {
+		return false, fmt.Errorf("failed to parse GetLoudness response: %w", err)
+	}
+
+	return resp.CurrentLoudness == 1, nil
+}
+
+// SetLoudness sets the loudness state
+func (r *RenderingControl) SetLoudness(ctx context.Context, device *SonosDevice, loudness bool) error {
+	loudnessVal := 0
+	if loudness {
+		loudnessVal = 1
+	}
+
+	action := SetLoudnessAction{
+		XmlnsU:          RenderingControlURN,
+		InstanceID:      0,
+		Channel:         "Master",
+		DesiredLoudness: loudnessVal,
+	}
+
+	_, err := r.sendAction(ctx, device, "SetLoudness", action)
+	if err != nil {
+		return fmt.Errorf("SetLoudness failed: %w", err)
+	}
+
+	log.Debug(ctx, "Set loudness", "device", device.RoomName, "loudness", loudness)
+	return nil
+}
+
+// clampEQ clamps a bass/treble level to the Sonos range of -10 to 10
+func clampEQ(level int) int {
+	if level < -10 {
+		return -10
+	}
+	if level > 10 {
+		return 10
+	}
+	return level
+}
+
 // ToggleMute toggles the mute state and returns the new state
 func (r *RenderingControl) ToggleMute(ctx context.Context, device *SonosDevice) (bool, error) {
 	currentMute, err := r.GetMute(ctx, device)
@@ -156,6 +289,18 @@ func (r *RenderingControl) AdjustVolume(ctx context.Context, device *SonosDevice
 
 // sendAction sends a SOAP action to the device's RenderingControl service
 func (r *RenderingControl) sendAction(ctx context.Context, device *SonosDevice, actionName string, action interface{}) ([]byte, error) {
+	// Skip devices in failure backoff instead of waiting for a timeout
+	if !r.health.available(device.UUID) {
+		return nil, ErrDeviceUnreachable
+	}
+
+	// Queue behind other in-flight calls to this device - bursts of UI
+	// actions can overwhelm older ZonePlayers
+	if err := r.limiter.acquire(ctx, device.UUID); err != nil {
+		return nil, err
+	}
+	defer r.limiter.release(device.UUID)
+
 	// Build SOAP envelope
 	envelope := SOAPEnvelope{
 		XmlnsS:        "http://schemas.xmlsoap.org/soap/envelope/",
@@ -183,11 +328,14 @@ func (r *RenderingControl) sendAction(ctx context.Context, device *SonosDevice,
 	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
 	req.Header.Set("SOAPACTION", fmt.Sprintf("\"%s#%s\"", RenderingControlURN, actionName))
 
-	// Send request
+	// Send request, timing it for the health stats
+	start := time.Now()
 	resp, err := r.client.Do(req)
 	if err != nil {
-		return nil, err
+		r.health.reportFailure(device.UUID)
+		return nil, fmt.Errorf("%w: %v", ErrDeviceUnreachable, err)
 	}
+	r.health.reportSuccess(device.UUID, time.Since(start))
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
@@ -0,0 +1,55 @@
+package sonos_cast
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// DeviceSettings holds admin-configured, per-device settings. They are
+// persisted in the properties table, keyed by the device UUID, so they survive
+// restarts and rediscoveries
+type DeviceSettings struct {
+	MaxVolume     int `json:"maxVolume"`     // 0 means no limit
+	DefaultVolume int `json:"defaultVolume"` // applied when casting starts, 0 keeps the current volume
+	FadeSeconds   int `json:"fadeSeconds"`   // fade-in/out duration, 0 disables fading
+}
+
+const deviceSettingsKeyPrefix = "SonosCastDeviceSettings-"
+
+func deviceSettingsKey(uuid string) string {
+	return deviceSettingsKeyPrefix + uuid
+}
+
+// loadDeviceSettings reads the persisted settings for a device. Missing or
+// invalid settings yield the zero value (no limit, no default volume)
+func loadDeviceSettings(ctx context.Context, ds model.DataStore, uuid string) DeviceSettings {
+	var settings DeviceSettings
+	value, err := ds.Property(ctx).DefaultGet(deviceSettingsKey(uuid), "")
+	if err != nil || value == "" {
+		return settings
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		log.Warn(ctx, "Invalid Sonos device settings - ignoring", "uuid", uuid, err)
+	}
+	return settings
+}
+
+// saveDeviceSettings persists the settings for a device
+func saveDeviceSettings(ctx context.Context, ds model.DataStore, uuid string, settings DeviceSettings) error {
+	value, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return ds.Property(ctx).Put(deviceSettingsKey(uuid), string(value))
+}
+
+// clampVolume limits a requested volume to the device's configured maximum
+func (s DeviceSettings) clampVolume(volume int) int {
+	if s.MaxVolume > 0 && volume > s.MaxVolume {
+		return s.MaxVolume
+	}
+	return volume
+}
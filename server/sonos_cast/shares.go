@@ -0,0 +1,95 @@
+package sonos_cast
+
+import (
+	"context"
+	"fmt"
+	"mime"
+
+	"github.com/navidrome/navidrome/consts"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// shareStreamOptions maps a share's transcoding settings to stream options -
+// shares carry their own format and bitrate, chosen when they were created
+func shareStreamOptions(share *model.Share) streamOptions {
+	return streamOptions{format: share.Format, bitRate: share.MaxBitRate}
+}
+
+// castShare resolves a public share and plays its tracks on a device. The
+// tracks are loaded into the device's own Sonos queue, so the speaker
+// advances through them natively. Stream URLs are share-scoped: access is
+// granted by the share itself, honoring its expiry and transcoding settings
+// rather than the caster's library permissions
+func (a *API) castShare(ctx context.Context, deviceID, shareID string) error {
+	share, err := a.share.Load(ctx, shareID)
+	if err != nil {
+		return err
+	}
+	if len(share.Tracks) == 0 {
+		return fmt.Errorf("%w: share %q has no tracks", model.ErrNotFound, shareID)
+	}
+
+	baseURL := a.sonosCast.GetStreamBaseURLFor(deviceID)
+	opts := shareStreamOptions(share)
+
+	// Stop anything the server-side playlist player or a party session may
+	// still be feeding, and rebuild the Sonos queue with the share's tracks
+	a.stopQueuePlayer(deviceID)
+	a.stopPartySession(deviceID)
+	deviceQueues.Delete(deviceID)
+	if err := a.sonosCast.ClearQueue(ctx, deviceID); err != nil {
+		return err
+	}
+	for i := range share.Tracks {
+		media := a.buildShareTrackMedia(share, &share.Tracks[i], baseURL, opts)
+		if err := a.sonosCast.AddToQueue(ctx, deviceID, media.streamURL, media.metadata, false); err != nil {
+			if i == 0 {
+				return err
+			}
+			// Partial queue - keep what made it and play that
+			log.Warn(ctx, "Failed to enqueue share track", "shareID", shareID, "track", media.track.Title, err)
+		}
+	}
+
+	// Apply the configured default cast volume (clamped to the device limit)
+	// before starting playback, so nothing blasts at the speaker's last
+	// volume. With fade-in configured, playback starts silent and ramps up
+	settings := loadDeviceSettings(ctx, a.ds, deviceID)
+	fadeTarget := a.prepareCastVolume(ctx, deviceID, settings)
+
+	if err := a.sonosCast.PlayFromQueue(ctx, deviceID, 1); err != nil {
+		a.restoreVolumeAfterStop(ctx, deviceID, fadeTarget)
+		return err
+	}
+	a.finishCastVolume(ctx, deviceID, settings, fadeTarget)
+	log.Info(ctx, "Casting share to Sonos", "shareID", shareID, "deviceID", deviceID, "tracks", len(share.Tracks))
+
+	// Follow playback so plays are counted and scrobbled
+	first := &share.Tracks[0]
+	a.tracker.Track(ctx, deviceID, first.ID, int(first.Duration))
+	return nil
+}
+
+// buildShareTrackMedia builds the share-scoped stream URL and DIDL metadata
+// for one track of a share
+func (a *API) buildShareTrackMedia(share *model.Share, track *model.MediaFile, baseURL string, opts streamOptions) *trackMedia {
+	token := encodeShareStreamToken(share, track.ID, opts)
+	streamURL := fmt.Sprintf("%s%s/stream/%s", baseURL, consts.URLPathSonosCast, token)
+
+	artURL := ""
+	if track.HasCoverArt {
+		artURL = buildCoverArtURL(baseURL, track.AlbumID)
+	}
+
+	mimeType := track.ContentType()
+	if opts.format != "" {
+		mimeType = mime.TypeByExtension("." + opts.format)
+	}
+	if mimeType == "" {
+		mimeType = "audio/flac"
+	}
+
+	metadata := a.sonosCast.BuildTrackMetadata(didlTrack(track, opts, streamURL, mimeType, artURL))
+	return &trackMedia{track: track, streamURL: streamURL, metadata: metadata}
+}
@@ -3,30 +3,56 @@ package sonos_cast
 import (
 	"context"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/server/events"
+	"github.com/navidrome/navidrome/utils/singleton"
 )
 
 // SonosCast is the main service for Sonos speaker control
 type SonosCast struct {
-	discovery  *Discovery
-	transport  *AVTransport
-	rendering  *RenderingControl
-	running    bool
-	stopCh     chan struct{}
-	wg         sync.WaitGroup
-	mu         sync.RWMutex
+	discovery *Discovery
+	transport *AVTransport
+	rendering *RenderingControl
+	content   *ContentDirectory
+	state     *stateCache
+	health    *healthTracker
+	broker    events.Broker
+	running   bool
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+	mu        sync.RWMutex
+}
+
+// GetInstance returns the SonosCast service singleton, shared by the REST
+// API and the Subsonic jukebox adapter
+func GetInstance() *SonosCast {
+	return singleton.GetInstance(func() *SonosCast {
+		return NewSonosCast()
+	})
 }
 
 // NewSonosCast creates a new SonosCast service
 func NewSonosCast() *SonosCast {
+	// The health tracker and SOAP limiter are shared by all controllers, so a
+	// failure reported by one service puts the whole device in backoff, and
+	// concurrency and pacing limits apply to the device as a whole
+	health := newHealthTracker()
+	limiter := newSOAPLimiter()
 	return &SonosCast{
 		discovery: NewDiscovery(),
-		transport: NewAVTransport(),
-		rendering: NewRenderingControl(),
+		transport: NewAVTransport(health, limiter),
+		rendering: NewRenderingControl(health, limiter),
+		content:   NewContentDirectory(health, limiter),
+		state:     newStateCache(),
+		health:    health,
+		broker:    events.GetBroker(),
 		stopCh:    make(chan struct{}),
 	}
 }
@@ -46,10 +72,12 @@ func (s *SonosCast) Start(ctx context.Context) error {
 	// Initial discovery
 	s.runDiscovery(ctx)
 
-	// Start periodic discovery
+	// Start periodic discovery. A non-positive interval disables it entirely,
+	// leaving only the initial scan and manual refreshes
 	interval := conf.Server.SonosCast.DiscoveryInterval
-	if interval == 0 {
-		interval = 5 * time.Minute
+	if interval <= 0 {
+		log.Info(ctx, "Sonos Cast periodic discovery is DISABLED")
+		return nil
 	}
 
 	s.wg.Add(1)
@@ -90,7 +118,14 @@ func (s *SonosCast) Shutdown() {
 
 // runDiscovery performs SSDP discovery and fetches zone topology
 func (s *SonosCast) runDiscovery(ctx context.Context) {
-	devices, err := s.discovery.Scan(ctx)
+	// Notify clients about each device as it answers, so the cast picker can
+	// fill in progressively instead of waiting out the full SSDP window
+	devices, err := s.discovery.Scan(ctx, func(*SonosDevice) {
+		s.broker.SendBroadcastMessage(ctx, &events.SonosDevicesChanged{Scanning: true})
+	})
+	// Always signal the end of the scan, even a failed one - clients use it to
+	// clear their "scanning" indicator
+	defer s.broker.SendBroadcastMessage(ctx, &events.SonosDevicesChanged{})
 	if err != nil {
 		log.Error(ctx, "Sonos discovery failed", err)
 		return
@@ -122,14 +157,27 @@ func (s *SonosCast) RefreshDevices(ctx context.Context) error {
 	return nil
 }
 
-// GetDevices returns all discovered Sonos devices
+// GetDevices returns all discovered Sonos devices, with their current reachability
 func (s *SonosCast) GetDevices() []*SonosDevice {
-	return s.discovery.GetDevices()
+	devices := s.discovery.GetDevices()
+	for _, device := range devices {
+		device.Reachable = s.health.available(device.UUID)
+	}
+	return devices
 }
 
 // GetDevice returns a specific device by UUID
 func (s *SonosCast) GetDevice(uuid string) (*SonosDevice, bool) {
-	return s.discovery.GetDevice(uuid)
+	device, ok := s.discovery.GetDevice(uuid)
+	if ok {
+		device.Reachable = s.health.available(uuid)
+	}
+	return device, ok
+}
+
+// DeviceHealth returns the SOAP call history summary for a device
+func (s *SonosCast) DeviceHealth(uuid string) DeviceHealthStats {
+	return s.health.stats(uuid)
 }
 
 // getCoordinator returns the group coordinator for a device
@@ -183,7 +231,11 @@ func (s *SonosCast) Play(ctx context.Context, uuid string) error {
 	if err != nil {
 		return err
 	}
-	return s.transport.Play(ctx, device)
+	if err := s.transport.Play(ctx, device); err != nil {
+		return err
+	}
+	s.state.invalidate(uuid)
+	return nil
 }
 
 // PlayURI sets a URI and starts playback
@@ -192,7 +244,48 @@ func (s *SonosCast) PlayURI(ctx context.Context, uuid string, uri string, metada
 	if err != nil {
 		return err
 	}
-	return s.transport.PlayURI(ctx, device, uri, metadata)
+	if err := s.transport.PlayURI(ctx, device, uri, metadata); err != nil {
+		return err
+	}
+	s.state.invalidate(uuid)
+	return nil
+}
+
+// PlayFromQueue switches a device's transport to its own Sonos queue,
+// starting at the given 1-based entry, and starts playback
+func (s *SonosCast) PlayFromQueue(ctx context.Context, uuid string, trackNum int) error {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	uri := "x-rincon-queue:" + device.UUID + "#0"
+	if err := s.transport.SetAVTransportURI(ctx, device, uri, ""); err != nil {
+		return err
+	}
+	if trackNum > 1 {
+		if err := s.transport.SeekTrack(ctx, device, trackNum); err != nil {
+			log.Warn(ctx, "Failed to seek to queue entry", "track", trackNum, err)
+		}
+	}
+	if err := s.transport.Play(ctx, device); err != nil {
+		return err
+	}
+	s.state.invalidate(uuid)
+	return nil
+}
+
+// SeekToQueueEntry jumps the transport to the given 1-based entry of the
+// queue it is already playing from
+func (s *SonosCast) SeekToQueueEntry(ctx context.Context, uuid string, trackNum int) error {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if err := s.transport.SeekTrack(ctx, device, trackNum); err != nil {
+		return err
+	}
+	s.state.invalidate(uuid)
+	return nil
 }
 
 // Pause pauses playback on a device
@@ -201,7 +294,11 @@ func (s *SonosCast) Pause(ctx context.Context, uuid string) error {
 	if err != nil {
 		return err
 	}
-	return s.transport.Pause(ctx, device)
+	if err := s.transport.Pause(ctx, device); err != nil {
+		return err
+	}
+	s.state.invalidate(uuid)
+	return nil
 }
 
 // Stop stops playback on a device
@@ -210,7 +307,11 @@ func (s *SonosCast) Stop(ctx context.Context, uuid string) error {
 	if err != nil {
 		return err
 	}
-	return s.transport.Stop(ctx, device)
+	if err := s.transport.Stop(ctx, device); err != nil {
+		return err
+	}
+	s.state.invalidate(uuid)
+	return nil
 }
 
 // Seek seeks to a position on a device
@@ -219,7 +320,11 @@ func (s *SonosCast) Seek(ctx context.Context, uuid string, position time.Duratio
 	if err != nil {
 		return err
 	}
-	return s.transport.Seek(ctx, device, position)
+	if err := s.transport.Seek(ctx, device, position); err != nil {
+		return err
+	}
+	s.state.invalidate(uuid)
+	return nil
 }
 
 // Next skips to the next track on a device
@@ -228,7 +333,11 @@ func (s *SonosCast) Next(ctx context.Context, uuid string) error {
 	if err != nil {
 		return err
 	}
-	return s.transport.Next(ctx, device)
+	if err := s.transport.Next(ctx, device); err != nil {
+		return err
+	}
+	s.state.invalidate(uuid)
+	return nil
 }
 
 // Previous goes to the previous track on a device
@@ -237,11 +346,416 @@ func (s *SonosCast) Previous(ctx context.Context, uuid string) error {
 	if err != nil {
 		return err
 	}
-	return s.transport.Previous(ctx, device)
+	if err := s.transport.Previous(ctx, device); err != nil {
+		return err
+	}
+	s.state.invalidate(uuid)
+	return nil
+}
+
+// SetPlayMode sets shuffle and repeat modes on a device's queue
+func (s *SonosCast) SetPlayMode(ctx context.Context, uuid string, shuffle bool, repeat string) error {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if err := s.transport.SetPlayMode(ctx, device, playModeFor(shuffle, repeat)); err != nil {
+		return err
+	}
+	s.state.invalidate(uuid)
+	return nil
+}
+
+// GetPlayMode gets the current shuffle and repeat modes of a device's queue
+func (s *SonosCast) GetPlayMode(ctx context.Context, uuid string) (shuffle bool, repeat string, err error) {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return false, "", err
+	}
+	mode, err := s.transport.GetPlayMode(ctx, device)
+	if err != nil {
+		return false, "", err
+	}
+	shuffle, repeat = parsePlayMode(mode)
+	return shuffle, repeat, nil
+}
+
+// SetCrossfade enables or disables crossfade on a device's queue
+func (s *SonosCast) SetCrossfade(ctx context.Context, uuid string, crossfade bool) error {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if err := s.transport.SetCrossfade(ctx, device, crossfade); err != nil {
+		return err
+	}
+	s.state.invalidate(uuid)
+	return nil
 }
 
-// GetPlaybackState gets the current playback state of a device
+// GetCrossfade gets the current crossfade setting of a device's queue
+func (s *SonosCast) GetCrossfade(ctx context.Context, uuid string) (bool, error) {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return false, err
+	}
+	return s.transport.GetCrossfade(ctx, device)
+}
+
+// SetSleepTimer sets a sleep timer on a device. A non-positive duration cancels it
+func (s *SonosCast) SetSleepTimer(ctx context.Context, uuid string, duration time.Duration) error {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	return s.transport.ConfigureSleepTimer(ctx, device, duration)
+}
+
+// GetSleepTimer returns the remaining sleep timer duration in seconds (0 if none)
+func (s *SonosCast) GetSleepTimer(ctx context.Context, uuid string) (int, error) {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return 0, err
+	}
+	return s.transport.GetRemainingSleepTimer(ctx, device)
+}
+
+// PlaybackSnapshot captures what a device is playing, so it can be restored
+// later or on another device. Announcements, transfer-playback and "undo that
+// cast" all build on this one mechanism
+type PlaybackSnapshot struct {
+	URI      string      `json:"uri"`
+	Metadata string      `json:"metadata"`
+	Track    int         `json:"track"`    // 1-based queue position
+	Position int         `json:"position"` // seconds
+	State    string      `json:"state"`
+	Volume   int         `json:"volume"` // -1 when the volume could not be read
+	Muted    bool        `json:"muted"`
+	Queue    []QueueItem `json:"queue,omitempty"` // captured when playing from the device queue
+}
+
+// snapshotQueueMax caps how many queue entries a snapshot captures, to keep
+// snapshots of huge queues manageable
+const snapshotQueueMax = 500
+
+// isQueueURI reports whether a transport URI points at the device's own queue
+func isQueueURI(uri string) bool {
+	return strings.HasPrefix(uri, "x-rincon-queue:")
+}
+
+// SnapshotPlayback captures the current transport URI, queue, position,
+// volume, mute and transport state of a device
+func (s *SonosCast) SnapshotPlayback(ctx context.Context, uuid string) (*PlaybackSnapshot, error) {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := s.transport.GetMediaInfo(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.transport.GetTransportInfo(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &PlaybackSnapshot{
+		URI:      media.CurrentURI,
+		Metadata: media.CurrentURIMetaData,
+		State:    state,
+		Volume:   -1,
+	}
+
+	if track, err := s.transport.GetPositionInfo(ctx, device); err == nil {
+		snapshot.Track = track.TrackNum
+		snapshot.Position = track.Position
+	}
+
+	// Volume and mute are best-effort - a snapshot without them still restores
+	// the transport state
+	if volume, err := s.rendering.GetVolume(ctx, device); err == nil {
+		snapshot.Volume = volume
+	} else {
+		log.Warn(ctx, "Failed to read volume for snapshot", "device", device.RoomName, err)
+	}
+	if muted, err := s.rendering.GetMute(ctx, device); err == nil {
+		snapshot.Muted = muted
+	}
+
+	// Capture the queue contents when the device is playing from its queue,
+	// so a restore can rebuild it after it was replaced by a cast
+	if isQueueURI(media.CurrentURI) {
+		for start := 0; start < snapshotQueueMax; {
+			items, total, err := s.content.BrowseQueue(ctx, device, start, 200)
+			if err != nil {
+				log.Warn(ctx, "Failed to read queue for snapshot", "device", device.RoomName, err)
+				break
+			}
+			snapshot.Queue = append(snapshot.Queue, items...)
+			start += len(items)
+			if len(items) == 0 || start >= total {
+				break
+			}
+		}
+	}
+
+	return snapshot, nil
+}
+
+// RestorePlayback restores a previously captured snapshot on a device
+func (s *SonosCast) RestorePlayback(ctx context.Context, uuid string, snapshot *PlaybackSnapshot) error {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	if snapshot.URI == "" {
+		return nil
+	}
+
+	// Rebuild the queue first, so a queue-backed transport URI has something
+	// to play. Failing to re-enqueue a single entry is not fatal
+	if len(snapshot.Queue) > 0 && isQueueURI(snapshot.URI) {
+		if err := s.transport.RemoveAllTracksFromQueue(ctx, device); err != nil {
+			log.Warn(ctx, "Failed to clear queue before restore", "device", device.RoomName, err)
+		} else {
+			for _, item := range snapshot.Queue {
+				if err := s.transport.AddURIToQueue(ctx, device, item.URI, "", false); err != nil {
+					log.Warn(ctx, "Failed to re-enqueue track during restore", "device", device.RoomName, "uri", item.URI, err)
+				}
+			}
+		}
+	}
+
+	if err := s.transport.SetAVTransportURI(ctx, device, snapshot.URI, snapshot.Metadata); err != nil {
+		return err
+	}
+
+	if snapshot.Track > 1 {
+		if err := s.transport.SeekTrack(ctx, device, snapshot.Track); err != nil {
+			log.Warn(ctx, "Failed to restore queue position", "device", device.RoomName, err)
+		}
+	}
+	if snapshot.Position > 0 {
+		if err := s.transport.Seek(ctx, device, time.Duration(snapshot.Position)*time.Second); err != nil {
+			// Non-fatal - not all content supports seeking
+			log.Warn(ctx, "Failed to restore playback position", "device", device.RoomName, err)
+		}
+	}
+
+	if snapshot.Volume >= 0 {
+		if err := s.rendering.SetVolume(ctx, device, snapshot.Volume); err != nil {
+			log.Warn(ctx, "Failed to restore volume", "device", device.RoomName, err)
+		}
+		if err := s.rendering.SetMute(ctx, device, snapshot.Muted); err != nil {
+			log.Warn(ctx, "Failed to restore mute", "device", device.RoomName, err)
+		}
+	}
+
+	if snapshot.State == StatePlaying {
+		return s.transport.Play(ctx, device)
+	}
+	return nil
+}
+
+// announceMaxWait caps how long Announce waits for a clip to finish before
+// restoring playback anyway
+const announceMaxWait = 60 * time.Second
+
+// Announce interrupts a device to play a short notification clip, then
+// restores whatever was playing before. When volume is positive, the clip is
+// played at that volume and the previous volume is restored afterwards
+func (s *SonosCast) Announce(ctx context.Context, uuid string, uri string, metadata string, volume int) error {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	// Capture what is playing now (including volume and mute), so it can all
+	// be put back afterwards
+	snapshot, err := s.SnapshotPlayback(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if volume > 0 {
+		if err := s.rendering.SetVolume(ctx, device, volume); err != nil {
+			log.Warn(ctx, "Failed to set announcement volume", "device", device.RoomName, err)
+		}
+	}
+
+	// Play the clip and wait for it to finish
+	if err := s.transport.SetAVTransportURI(ctx, device, uri, metadata); err != nil {
+		return err
+	}
+	if err := s.transport.Play(ctx, device); err != nil {
+		return err
+	}
+	s.waitForStop(ctx, device)
+
+	// Put everything back the way it was - the snapshot restore covers the
+	// previous volume as well
+	if err := s.RestorePlayback(ctx, uuid, snapshot); err != nil {
+		return fmt.Errorf("failed to restore playback after announcement: %w", err)
+	}
+
+	log.Info(ctx, "Played announcement", "device", device.RoomName, "uri", uri)
+	return nil
+}
+
+// waitForStop polls the device until the transport stops or announceMaxWait passes
+func (s *SonosCast) waitForStop(ctx context.Context, device *SonosDevice) {
+	deadline := time.Now().Add(announceMaxWait)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(500 * time.Millisecond):
+		}
+		state, err := s.transport.GetTransportInfo(ctx, device)
+		if err != nil || state == StateStopped {
+			return
+		}
+	}
+}
+
+// TransferPlayback moves the current track, position and playing state from
+// one device to another, stopping playback on the source
+func (s *SonosCast) TransferPlayback(ctx context.Context, fromUUID, toUUID string) (*PlaybackSnapshot, error) {
+	if _, ok := s.GetDevice(toUUID); !ok {
+		return nil, ErrDeviceNotFound
+	}
+
+	snapshot, err := s.SnapshotPlayback(ctx, fromUUID)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.URI == "" {
+		return nil, fmt.Errorf("%w: nothing is playing on the source device", ErrPlaybackFailed)
+	}
+
+	if err := s.RestorePlayback(ctx, toUUID, snapshot); err != nil {
+		return nil, err
+	}
+
+	// Stop the source only after the target is playing, so a failed transfer
+	// leaves the source untouched
+	if err := s.Stop(ctx, fromUUID); err != nil {
+		log.Warn(ctx, "Failed to stop source device after transfer", "uuid", fromUUID, err)
+	}
+
+	log.Info(ctx, "Transferred playback between Sonos devices", "from", fromUUID, "to", toUUID)
+	return snapshot, nil
+}
+
+// GetQueue reads a page of a device's playback queue
+func (s *SonosCast) GetQueue(ctx context.Context, uuid string, start, count int) ([]QueueItem, int, error) {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.content.BrowseQueue(ctx, device, start, count)
+}
+
+// AddFavorite pins an entry into the household's Sonos Favorites list
+// ("My Sonos"), using the given device to issue the request. Favorites are
+// household-wide, so they show up for every room
+func (s *SonosCast) AddFavorite(ctx context.Context, uuid, title, description, uri, resMD string) (string, error) {
+	device, ok := s.GetDevice(uuid)
+	if !ok {
+		return "", ErrDeviceNotFound
+	}
+	return s.content.AddFavorite(ctx, device, title, description, uri, resMD)
+}
+
+// RemoveFromQueue removes a single track from a device's queue (1-based index)
+func (s *SonosCast) RemoveFromQueue(ctx context.Context, uuid string, index int) error {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	return s.transport.RemoveTrackFromQueue(ctx, device, index)
+}
+
+// ReorderQueue moves a block of tracks within a device's queue (1-based indexes)
+func (s *SonosCast) ReorderQueue(ctx context.Context, uuid string, start, count, insertBefore int) error {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	return s.transport.ReorderTracksInQueue(ctx, device, start, count, insertBefore)
+}
+
+// AddToQueue adds a track to a device's queue, either at the end or right
+// after the currently playing track
+func (s *SonosCast) AddToQueue(ctx context.Context, uuid string, uri string, metadata string, asNext bool) error {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	return s.transport.AddURIToQueue(ctx, device, uri, metadata, asNext)
+}
+
+// ClearQueue removes all tracks from a device's queue
+func (s *SonosCast) ClearQueue(ctx context.Context, uuid string) error {
+	device, err := s.getCoordinator(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	return s.transport.RemoveAllTracksFromQueue(ctx, device)
+}
+
+// GetPlaybackState gets the current playback state of a device. Results are
+// cached for a short time and concurrent requests are coalesced, so frequent
+// UI polling doesn't multiply SOAP traffic to the speaker
 func (s *SonosCast) GetPlaybackState(ctx context.Context, uuid string) (*PlaybackState, error) {
+	if _, ok := s.GetDevice(uuid); !ok {
+		return nil, ErrDeviceNotFound
+	}
+	return s.state.get(uuid, func() (*PlaybackState, error) {
+		return s.fetchPlaybackState(ctx, uuid)
+	})
+}
+
+// classifySource maps a transport URI to one of the Source constants. Sonos
+// encodes the input in the URI scheme: line-in and TV have dedicated schemes,
+// radio and music services use the x-sonosapi family, and plain http(s) is
+// either one of our signed stream URLs or some other direct stream
+func classifySource(uri string) string {
+	switch {
+	case uri == "":
+		return SourceNone
+	case strings.HasPrefix(uri, "x-rincon-stream:"):
+		return SourceLineIn
+	case strings.HasPrefix(uri, "x-sonos-htastream:"):
+		return SourceTV
+	case isQueueURI(uri):
+		return SourceQueue
+	case strings.HasPrefix(uri, "x-rincon:"):
+		return SourceGroup
+	case strings.HasPrefix(uri, "x-rincon-mp3radio:"),
+		strings.HasPrefix(uri, "x-sonosapi-stream:"),
+		strings.HasPrefix(uri, "x-sonosapi-radio:"),
+		strings.HasPrefix(uri, "x-sonosapi-hls:"),
+		strings.HasPrefix(uri, "hls-radio:"),
+		strings.HasPrefix(uri, "aac:"):
+		return SourceRadio
+	case strings.HasPrefix(uri, "x-sonos-spotify:"),
+		strings.HasPrefix(uri, "x-sonos-vli:"):
+		return SourceService
+	case strings.HasPrefix(uri, "http://"), strings.HasPrefix(uri, "https://"):
+		if extractTrackIDFromURI(uri) != "" {
+			return SourceNavidrome
+		}
+		return SourceStream
+	default:
+		return SourceStream
+	}
+}
+
+// fetchPlaybackState reads the full playback state from the speaker
+func (s *SonosCast) fetchPlaybackState(ctx context.Context, uuid string) (*PlaybackState, error) {
 	device, ok := s.GetDevice(uuid)
 	if !ok {
 		return nil, ErrDeviceNotFound
@@ -274,21 +788,115 @@ func (s *SonosCast) GetPlaybackState(ctx context.Context, uuid string) (*Playbac
 		log.Warn(ctx, "Failed to get mute state", err)
 	}
 
+	// Get play mode (shuffle/repeat)
+	shuffle := false
+	repeat := RepeatNone
+	if mode, err := s.transport.GetPlayMode(ctx, device); err != nil {
+		// Non-fatal, continue without play mode
+		log.Warn(ctx, "Failed to get play mode", err)
+	} else {
+		shuffle, repeat = parsePlayMode(mode)
+	}
+
+	// Get crossfade setting
+	crossfade, err := s.transport.GetCrossfade(ctx, device)
+	if err != nil {
+		// Non-fatal, continue without crossfade state
+		log.Warn(ctx, "Failed to get crossfade mode", err)
+	}
+
+	// Get remaining sleep timer
+	sleepTimer, err := s.transport.GetRemainingSleepTimer(ctx, device)
+	if err != nil {
+		// Non-fatal, continue without sleep timer info
+		log.Warn(ctx, "Failed to get sleep timer", err)
+	}
+
+	// Get EQ settings
+	eq, err := s.getEQ(ctx, device)
+	if err != nil {
+		// Non-fatal, continue without EQ info
+		log.Warn(ctx, "Failed to get EQ settings", err)
+	}
+
 	return &PlaybackState{
 		State:        transportState,
+		Source:       classifySource(track.URI),
 		CurrentTrack: track,
 		Volume:       volume,
 		Muted:        muted,
+		Shuffle:      shuffle,
+		Repeat:       repeat,
+		Crossfade:    crossfade,
+		SleepTimer:   sleepTimer,
+		EQ:           eq,
 	}, nil
 }
 
+// getEQ reads all tone controls from a device
+func (s *SonosCast) getEQ(ctx context.Context, device *SonosDevice) (*EQSettings, error) {
+	bass, err := s.rendering.GetBass(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	treble, err := s.rendering.GetTreble(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	loudness, err := s.rendering.GetLoudness(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	return &EQSettings{Bass: bass, Treble: treble, Loudness: loudness}, nil
+}
+
+// GetEQ gets the tone controls from a device
+func (s *SonosCast) GetEQ(ctx context.Context, uuid string) (*EQSettings, error) {
+	device, ok := s.GetDevice(uuid)
+	if !ok {
+		return nil, ErrDeviceNotFound
+	}
+	return s.getEQ(ctx, device)
+}
+
+// SetBass sets the bass level on a device (-10 to 10)
+func (s *SonosCast) SetBass(ctx context.Context, uuid string, bass int) error {
+	device, ok := s.GetDevice(uuid)
+	if !ok {
+		return ErrDeviceNotFound
+	}
+	return s.rendering.SetBass(ctx, device, bass)
+}
+
+// SetTreble sets the treble level on a device (-10 to 10)
+func (s *SonosCast) SetTreble(ctx context.Context, uuid string, treble int) error {
+	device, ok := s.GetDevice(uuid)
+	if !ok {
+		return ErrDeviceNotFound
+	}
+	return s.rendering.SetTreble(ctx, device, treble)
+}
+
+// SetLoudness sets the loudness state on a device
+func (s *SonosCast) SetLoudness(ctx context.Context, uuid string, loudness bool) error {
+	device, ok := s.GetDevice(uuid)
+	if !ok {
+		return ErrDeviceNotFound
+	}
+	return s.rendering.SetLoudness(ctx, device, loudness)
+}
+
 // SetVolume sets the volume on a device
 func (s *SonosCast) SetVolume(ctx context.Context, uuid string, volume int) error {
 	device, ok := s.GetDevice(uuid)
 	if !ok {
 		return ErrDeviceNotFound
 	}
-	return s.rendering.SetVolume(ctx, device, volume)
+	if err := s.rendering.SetVolume(ctx, device, volume); err != nil {
+		return err
+	}
+	s.state.invalidate(uuid)
+	return nil
 }
 
 // GetVolume gets the volume from a device
@@ -306,7 +914,11 @@ func (s *SonosCast) SetMute(ctx context.Context, uuid string, mute bool) error {
 	if !ok {
 		return ErrDeviceNotFound
 	}
-	return s.rendering.SetMute(ctx, device, mute)
+	if err := s.rendering.SetMute(ctx, device, mute); err != nil {
+		return err
+	}
+	s.state.invalidate(uuid)
+	return nil
 }
 
 // ToggleMute toggles mute on a device
@@ -315,14 +927,18 @@ func (s *SonosCast) ToggleMute(ctx context.Context, uuid string) (bool, error) {
 	if !ok {
 		return false, ErrDeviceNotFound
 	}
-	return s.rendering.ToggleMute(ctx, device)
+	muted, err := s.rendering.ToggleMute(ctx, device)
+	if err != nil {
+		return false, err
+	}
+	s.state.invalidate(uuid)
+	return muted, nil
 }
 
 // BuildTrackMetadata creates DIDL-Lite metadata for a track
-// streamURI and mimeType are required for Sonos to understand the content type
-// durationSecs is the track duration in seconds (pass 0 to omit)
-func (s *SonosCast) BuildTrackMetadata(id, title, artist, album, albumArtURL, streamURI, mimeType string, durationSecs float32) string {
-	return BuildDIDLMetadata(id, title, artist, album, albumArtURL, streamURI, mimeType, durationSecs)
+// track.StreamURI and track.MimeType are required for Sonos to understand the content type
+func (s *SonosCast) BuildTrackMetadata(track DIDLTrack) string {
+	return BuildDIDLMetadata(track)
 }
 
 // Discovery returns the underlying discovery service
@@ -344,14 +960,24 @@ func (s *SonosCast) Rendering() *RenderingControl {
 // This needs to be an absolute URL reachable from the LAN
 // Sonos speakers are on the local network, so we use HTTP and internal IP
 func (s *SonosCast) GetStreamBaseURL() string {
+	return s.streamBaseURL(nil)
+}
+
+// GetStreamBaseURLFor returns the stream base URL for a specific device.
+// When no BaseURL or concrete Address is configured, the server's LAN IP is
+// detected per device, on the interface that routes to the speaker's subnet
+func (s *SonosCast) GetStreamBaseURLFor(uuid string) string {
+	device, _ := s.GetDevice(uuid)
+	return s.streamBaseURL(device)
+}
+
+func (s *SonosCast) streamBaseURL(device *SonosDevice) string {
 	// Use configured BaseURL if set (should be LAN-accessible HTTP URL)
 	if conf.Server.BaseURL != "" {
 		return conf.Server.BaseURL
 	}
 
 	// Fallback: construct from Address and Port
-	// Note: conf.Server.Address may be "0.0.0.0" which won't work for Sonos
-	// In that case, the admin should set BaseURL explicitly
 	port := conf.Server.Port
 	if port == 0 {
 		port = 4533
@@ -359,11 +985,35 @@ func (s *SonosCast) GetStreamBaseURL() string {
 
 	address := conf.Server.Address
 	if address == "" || address == "0.0.0.0" {
-		// Can't determine LAN IP automatically - log warning
-		log.Warn("Sonos Cast: BaseURL not configured and Address is 0.0.0.0. Set ND_BASEURL to your LAN-accessible URL (e.g., http://192.168.1.x:4533)")
-		// Return localhost as fallback (will likely fail, but at least it's clear why)
-		address = "127.0.0.1"
+		// Address is a wildcard - ask the kernel which local IP routes to
+		// the speaker instead of requiring an explicit BaseURL
+		if ip := localIPFor(device); ip != "" {
+			address = ip
+		} else {
+			log.Warn("Sonos Cast: BaseURL not configured and no LAN IP could be detected. Set ND_BASEURL to your LAN-accessible URL (e.g., http://192.168.1.x:4533)")
+			// Return localhost as fallback (will likely fail, but at least it's clear why)
+			address = "127.0.0.1"
+		}
 	}
 
 	return fmt.Sprintf("http://%s:%d", address, port)
 }
+
+// localIPFor returns the server's IP on the interface that routes to the
+// given device (or to the SSDP multicast group, when no device is known).
+// Connecting a UDP socket sends no packets - it just resolves the route
+func localIPFor(device *SonosDevice) string {
+	target := ssdpMulticastAddr
+	if device != nil {
+		target = net.JoinHostPort(device.IP, strconv.Itoa(device.Port))
+	}
+	conn, err := net.Dial("udp4", target)
+	if err != nil {
+		return ""
+	}
+	defer conn.Close()
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && !addr.IP.IsLoopback() {
+		return addr.IP.String()
+	}
+	return ""
+}
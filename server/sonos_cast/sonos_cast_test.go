@@ -0,0 +1,239 @@
+package sonos_cast
+
+import (
+	"context"
+	"errors"
+
+	"github.com/navidrome/navidrome/conf/configtest"
+	"github.com/navidrome/navidrome/core/auth"
+	"github.com/navidrome/navidrome/server/sonos_cast/upnptest"
+	"github.com/navidrome/navidrome/tests"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SonosCast", func() {
+	var ctx context.Context
+	var service *SonosCast
+	var zp *upnptest.ZonePlayer
+
+	// register fetches the fake player's description through the discovery
+	// service and seeds the device cache, standing in for an SSDP scan
+	register := func(player *upnptest.ZonePlayer) *SonosDevice {
+		device, err := service.discovery.fetchDeviceDescription(ctx, player.Location())
+		Expect(err).ToNot(HaveOccurred())
+		service.discovery.cache.Set(device)
+		return device
+	}
+
+	// makeCoordinator marks a cached device as its own group coordinator, like
+	// the topology fallback in runDiscovery does for standalone speakers
+	makeCoordinator := func(device *SonosDevice) {
+		device.IsCoordinator = true
+		device.GroupID = device.UUID + ":1"
+		device.GroupMembers = []string{device.UUID}
+		service.discovery.cache.Set(device)
+	}
+
+	BeforeEach(func() {
+		DeferCleanup(configtest.SetupConfig())
+		ctx = context.Background()
+		// Stream tokens are signed and decoded through auth.TokenAuth, so the
+		// secret must be set up before anything touches a cast URI
+		auth.Init(&tests.MockDataStore{})
+		service = NewSonosCast()
+		zp = upnptest.NewZonePlayer("RINCON_TEST01", "Living Room")
+		DeferCleanup(zp.Close)
+	})
+
+	Describe("Device description", func() {
+		It("parses the fields Navidrome needs", func() {
+			device := register(zp)
+			Expect(device.UUID).To(Equal("RINCON_TEST01"))
+			Expect(device.RoomName).To(Equal("Living Room"))
+			Expect(device.ModelName).To(Equal("Sonos Play:1"))
+			Expect(device.SoftwareGen).To(Equal("S2"))
+			Expect(device.IP).ToNot(BeEmpty())
+			Expect(device.Port).ToNot(BeZero())
+		})
+
+		It("maps swGen 1 to S1", func() {
+			zp.SwGen = "1"
+			device := register(zp)
+			Expect(device.SoftwareGen).To(Equal("S1"))
+		})
+	})
+
+	Describe("Casting", func() {
+		BeforeEach(func() {
+			makeCoordinator(register(zp))
+		})
+
+		It("sets the transport URI with metadata and starts playback", func() {
+			metadata := service.BuildTrackMetadata(DIDLTrack{Title: "Song & Dance", Artist: "Artist"})
+			err := service.PlayURI(ctx, zp.UUID, "http://192.0.2.1/stream/token", metadata)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(zp.CurrentURI()).To(Equal("http://192.0.2.1/stream/token"))
+			// The DIDL document keeps its own XML escaping through the SOAP round-trip
+			Expect(zp.CurrentMetadata()).To(ContainSubstring("Song &amp; Dance"))
+			Expect(zp.TransportState()).To(Equal(StatePlaying))
+		})
+
+		It("controls the transport", func() {
+			Expect(service.PlayURI(ctx, zp.UUID, "http://192.0.2.1/stream/token", "")).To(Succeed())
+			Expect(service.Pause(ctx, zp.UUID)).To(Succeed())
+			Expect(zp.TransportState()).To(Equal(StatePaused))
+			Expect(service.Stop(ctx, zp.UUID)).To(Succeed())
+			Expect(zp.TransportState()).To(Equal(StateStopped))
+		})
+
+		It("reports the playback state", func() {
+			Expect(service.PlayURI(ctx, zp.UUID, "http://192.0.2.1/stream/token", "")).To(Succeed())
+			state, err := service.GetPlaybackState(ctx, zp.UUID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(state.State).To(Equal(StatePlaying))
+			Expect(state.CurrentTrack.URI).To(Equal("http://192.0.2.1/stream/token"))
+			// Not one of our signed stream URLs, so it classifies as a plain stream
+			Expect(state.Source).To(Equal(SourceStream))
+		})
+
+		It("manages the device queue", func() {
+			Expect(service.AddToQueue(ctx, zp.UUID, "http://192.0.2.1/stream/a", "", false)).To(Succeed())
+			Expect(service.AddToQueue(ctx, zp.UUID, "http://192.0.2.1/stream/b", "", false)).To(Succeed())
+			Expect(zp.Queue()).To(Equal([]string{"http://192.0.2.1/stream/a", "http://192.0.2.1/stream/b"}))
+
+			Expect(service.PlayFromQueue(ctx, zp.UUID, 1)).To(Succeed())
+			Expect(zp.CurrentURI()).To(Equal("x-rincon-queue:" + zp.UUID + "#0"))
+			Expect(zp.TransportState()).To(Equal(StatePlaying))
+
+			Expect(service.ClearQueue(ctx, zp.UUID)).To(Succeed())
+			Expect(zp.Queue()).To(BeEmpty())
+		})
+	})
+
+	Describe("Volume", func() {
+		BeforeEach(func() {
+			makeCoordinator(register(zp))
+		})
+
+		It("round-trips volume and mute", func() {
+			Expect(service.SetVolume(ctx, zp.UUID, 42)).To(Succeed())
+			Expect(zp.Volume()).To(Equal(42))
+
+			volume, err := service.GetVolume(ctx, zp.UUID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(volume).To(Equal(42))
+
+			Expect(service.SetMute(ctx, zp.UUID, true)).To(Succeed())
+			Expect(zp.Muted()).To(BeTrue())
+		})
+	})
+
+	Describe("Error handling", func() {
+		BeforeEach(func() {
+			makeCoordinator(register(zp))
+		})
+
+		It("surfaces SOAP faults as UPnP errors", func() {
+			zp.FailAction("Play", UPnPErrorTransitionNotAvailable)
+			err := service.Play(ctx, zp.UUID)
+			var upnpErr *UPnPError
+			Expect(errors.As(err, &upnpErr)).To(BeTrue())
+			Expect(upnpErr.Code).To(Equal(UPnPErrorTransitionNotAvailable))
+		})
+
+		It("puts unreachable devices in backoff", func() {
+			zp.Close()
+			Expect(service.Play(ctx, zp.UUID)).To(MatchError(ErrDeviceUnreachable))
+			// The failure puts the device in backoff, so the next call fails
+			// fast without touching the network
+			Expect(service.Play(ctx, zp.UUID)).To(MatchError(ErrDeviceUnreachable))
+		})
+
+		It("returns ErrDeviceNotFound for unknown devices", func() {
+			Expect(service.Play(ctx, "RINCON_UNKNOWN")).To(MatchError(ErrDeviceNotFound))
+		})
+	})
+
+	Describe("Zone groups", func() {
+		var kitchen *upnptest.ZonePlayer
+		var topology *upnptest.Topology
+		var living, kitchenDev *SonosDevice
+
+		BeforeEach(func() {
+			kitchen = upnptest.NewZonePlayer("RINCON_TEST02", "Kitchen")
+			DeferCleanup(kitchen.Close)
+			topology = upnptest.NewTopology(zp, kitchen)
+			living = register(zp)
+			kitchenDev = register(kitchen)
+		})
+
+		It("marks ungrouped players as their own coordinators", func() {
+			Expect(service.discovery.FetchZoneGroupTopology(ctx, living)).To(Succeed())
+			Expect(living.IsCoordinator).To(BeTrue())
+			Expect(kitchenDev.IsCoordinator).To(BeTrue())
+			Expect(living.GroupID).ToNot(Equal(kitchenDev.GroupID))
+		})
+
+		It("resolves group members to their coordinator", func() {
+			topology.Group(zp, kitchen)
+			Expect(service.discovery.FetchZoneGroupTopology(ctx, living)).To(Succeed())
+			Expect(living.IsCoordinator).To(BeTrue())
+			Expect(kitchenDev.IsCoordinator).To(BeFalse())
+			Expect(kitchenDev.GroupMembers).To(ConsistOf(zp.UUID, kitchen.UUID))
+
+			// Commands sent to a group member are redirected to the coordinator
+			Expect(service.Play(ctx, kitchen.UUID)).To(Succeed())
+			Expect(zp.TransportState()).To(Equal(StatePlaying))
+			Expect(kitchen.TransportState()).To(Equal(StateStopped))
+		})
+	})
+
+	Describe("Source classification", func() {
+		It("classifies the Sonos vendor URI schemes", func() {
+			Expect(classifySource("")).To(Equal(SourceNone))
+			Expect(classifySource("x-rincon-stream:RINCON_TEST01")).To(Equal(SourceLineIn))
+			Expect(classifySource("x-sonos-htastream:RINCON_TEST01:spdif")).To(Equal(SourceTV))
+			Expect(classifySource("x-rincon-queue:RINCON_TEST01#0")).To(Equal(SourceQueue))
+			Expect(classifySource("x-rincon:RINCON_TEST01")).To(Equal(SourceGroup))
+			Expect(classifySource("x-rincon-mp3radio://radio.example.com/live")).To(Equal(SourceRadio))
+			Expect(classifySource("x-sonosapi-stream:s12345?sid=254")).To(Equal(SourceRadio))
+			Expect(classifySource("x-sonos-spotify:spotify%3atrack%3aabc?sid=9")).To(Equal(SourceService))
+		})
+
+		It("recognizes our signed stream URLs", func() {
+			token := encodeStreamToken("track-1", streamOptions{})
+			uri := "http://192.0.2.1:4533/sonos/stream/" + token
+			Expect(classifySource(uri)).To(Equal(SourceNavidrome))
+		})
+
+		It("recognizes Subsonic-style URLs by their id parameter", func() {
+			Expect(classifySource("http://192.0.2.1:4533/rest/stream?id=track-1")).To(Equal(SourceNavidrome))
+		})
+
+		It("treats http URLs with an invalid token as a foreign stream", func() {
+			Expect(classifySource("http://192.0.2.1:4533/sonos/stream/not-a-token")).To(Equal(SourceStream))
+			Expect(classifySource("http://radio.example.com/stream.mp3")).To(Equal(SourceStream))
+		})
+
+		It("falls back to stream for unknown schemes", func() {
+			Expect(classifySource("mms://legacy.example.com/feed")).To(Equal(SourceStream))
+		})
+	})
+
+	Describe("SSDP discovery", func() {
+		It("finds a responding player on the multicast group", func() {
+			if err := zp.ServeSSDP(); err != nil {
+				Skip("multicast not available: " + err.Error())
+			}
+			devices, err := service.discovery.Scan(ctx, func(*SonosDevice) {})
+			Expect(err).ToNot(HaveOccurred())
+			if len(devices) == 0 {
+				Skip("no SSDP responses received - multicast loopback not routed")
+			}
+			device, ok := service.GetDevice(zp.UUID)
+			Expect(ok).To(BeTrue())
+			Expect(device.RoomName).To(Equal("Living Room"))
+		})
+	})
+})
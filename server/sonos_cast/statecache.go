@@ -0,0 +1,74 @@
+package sonos_cast
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// stateCacheTTL is how long a cached playback state stays fresh. The UI polls
+// state frequently, so even a short TTL cuts most of the SOAP traffic
+const stateCacheTTL = 1 * time.Second
+
+// stateCache caches per-device playback state and coalesces concurrent
+// fetches, so several browser tabs polling the same device result in a single
+// round of SOAP calls to the speaker
+type stateCache struct {
+	mu      sync.Mutex
+	entries map[string]stateCacheEntry
+	group   singleflight.Group
+}
+
+type stateCacheEntry struct {
+	state     *PlaybackState
+	fetchedAt time.Time
+}
+
+func newStateCache() *stateCache {
+	return &stateCache{
+		entries: make(map[string]stateCacheEntry),
+	}
+}
+
+// get returns the cached state for a device, or fetches it (once, regardless
+// of how many callers are waiting) when the cache is stale
+func (c *stateCache) get(uuid string, fetch func() (*PlaybackState, error)) (*PlaybackState, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[uuid]
+	c.mu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < stateCacheTTL {
+		return entry.state, nil
+	}
+
+	state, err, _ := c.group.Do(uuid, func() (interface{}, error) {
+		// Another waiter may have refreshed the entry while we queued
+		c.mu.Lock()
+		entry, ok := c.entries[uuid]
+		c.mu.Unlock()
+		if ok && time.Since(entry.fetchedAt) < stateCacheTTL {
+			return entry.state, nil
+		}
+
+		fresh, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.entries[uuid] = stateCacheEntry{state: fresh, fetchedAt: time.Now()}
+		c.mu.Unlock()
+		return fresh, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state.(*PlaybackState), nil
+}
+
+// invalidate drops the cached state for a device, forcing the next read to
+// hit the speaker. Called after control actions that change the state
+func (c *stateCache) invalidate(uuid string) {
+	c.mu.Lock()
+	delete(c.entries, uuid)
+	c.mu.Unlock()
+}
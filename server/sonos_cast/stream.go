@@ -0,0 +1,245 @@
+package sonos_cast
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/navidrome/navidrome/core/artwork"
+	"github.com/navidrome/navidrome/core/auth"
+	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+)
+
+// streamTokenTTL is how long a signed stream URL stays valid. Queued tracks
+// can start playing long after they were enqueued, so this needs to cover a
+// full listening session - but unlike the old Subsonic MD5 tokens, an expired
+// URL in the speaker's logs is useless
+const streamTokenTTL = 8 * time.Hour
+
+// sonosStreamInfo is the payload of a signed stream token
+type sonosStreamInfo struct {
+	id      string
+	format  string
+	bitRate int
+}
+
+// encodeStreamToken creates a short-lived signed token granting access to a
+// single track, optionally transcoded per the given stream options
+func encodeStreamToken(trackID string, opts streamOptions) string {
+	claims := map[string]any{"id": trackID}
+	if opts.format != "" {
+		claims["f"] = opts.format
+	}
+	if opts.bitRate > 0 {
+		claims["b"] = opts.bitRate
+	}
+	token, _ := auth.CreateExpiringPublicToken(time.Now().Add(streamTokenTTL), claims)
+	return token
+}
+
+// encodePersistentStreamToken is like encodeStreamToken, but the token never
+// expires. Only used for Sonos favorites, which must stay playable across
+// sessions - a favorite with an expired URL would just show an error in the
+// Sonos app
+func encodePersistentStreamToken(trackID string, opts streamOptions) string {
+	claims := map[string]any{"id": trackID}
+	if opts.format != "" {
+		claims["f"] = opts.format
+	}
+	if opts.bitRate > 0 {
+		claims["b"] = opts.bitRate
+	}
+	token, _ := auth.CreatePublicToken(claims)
+	return token
+}
+
+// decodeStreamToken validates a signed stream token (including expiry) and
+// extracts the track info from it
+func decodeStreamToken(tokenString string) (sonosStreamInfo, error) {
+	// classifySource probes arbitrary transport URIs through this path, which
+	// can happen before auth.Init has run - fail instead of panicking
+	if auth.TokenAuth == nil {
+		return sonosStreamInfo{}, errors.New("token auth not initialized")
+	}
+	token, err := auth.TokenAuth.Decode(tokenString)
+	if err != nil {
+		return sonosStreamInfo{}, err
+	}
+	if token == nil {
+		return sonosStreamInfo{}, errors.New("unauthorized")
+	}
+	err = jwt.Validate(token, jwt.WithRequiredClaim("id"))
+	if err != nil {
+		return sonosStreamInfo{}, err
+	}
+	claims, err := token.AsMap(context.Background())
+	if err != nil {
+		return sonosStreamInfo{}, err
+	}
+	id, ok := claims["id"].(string)
+	if !ok {
+		return sonosStreamInfo{}, errors.New("invalid id type")
+	}
+	info := sonosStreamInfo{id: id}
+	info.format, _ = claims["f"].(string)
+	if bitRate, ok := claims["b"].(float64); ok {
+		info.bitRate = int(bitRate)
+	}
+	return info, nil
+}
+
+// encodeShareStreamToken creates a signed stream token for a track inside a
+// public share. It expires together with the share (or after the standard TTL
+// for shares without an expiry), so cast URLs stop working when the share does
+func encodeShareStreamToken(share *model.Share, trackID string, opts streamOptions) string {
+	exp := time.Now().Add(streamTokenTTL)
+	if share.ExpiresAt != nil && !share.ExpiresAt.IsZero() {
+		exp = *share.ExpiresAt
+	}
+	claims := map[string]any{"id": trackID}
+	if opts.format != "" {
+		claims["f"] = opts.format
+	}
+	if opts.bitRate > 0 {
+		claims["b"] = opts.bitRate
+	}
+	token, _ := auth.CreateExpiringPublicToken(exp, claims)
+	return token
+}
+
+// encodeArtToken creates a short-lived signed token granting access to one
+// item's artwork. Only used inside DIDL metadata, so the URL the speaker and
+// the Sonos app cache carries no credentials and stops working after a while
+func encodeArtToken(artID string) string {
+	token, _ := auth.CreateExpiringPublicToken(time.Now().Add(streamTokenTTL), map[string]any{"id": artID})
+	return token
+}
+
+// encodePersistentArtToken is the non-expiring variant, used in Sonos
+// favorites - their artwork must stay resolvable across sessions
+func encodePersistentArtToken(artID string) string {
+	token, _ := auth.CreatePublicToken(map[string]any{"id": artID})
+	return token
+}
+
+// decodeArtToken validates a signed art token (including expiry) and returns
+// the artwork ID it grants access to
+func decodeArtToken(tokenString string) (model.ArtworkID, error) {
+	if auth.TokenAuth == nil {
+		return model.ArtworkID{}, errors.New("token auth not initialized")
+	}
+	token, err := auth.TokenAuth.Decode(tokenString)
+	if err != nil {
+		return model.ArtworkID{}, err
+	}
+	if token == nil {
+		return model.ArtworkID{}, errors.New("unauthorized")
+	}
+	err = jwt.Validate(token, jwt.WithRequiredClaim("id"))
+	if err != nil {
+		return model.ArtworkID{}, err
+	}
+	claims, err := token.AsMap(context.Background())
+	if err != nil {
+		return model.ArtworkID{}, err
+	}
+	id, ok := claims["id"].(string)
+	if !ok {
+		return model.ArtworkID{}, errors.New("invalid id type")
+	}
+	return model.ParseArtworkID(id)
+}
+
+// handleArt serves artwork for a signed art token. Like handleStream, the
+// token itself is the authorization - speakers can't log in
+func (a *API) handleArt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	artID, err := decodeArtToken(chi.URLParam(r, "token"))
+	if err != nil {
+		log.Warn(ctx, "Rejected Sonos art request with invalid token", err)
+		http.Error(w, "invalid or expired art token", http.StatusForbidden)
+		return
+	}
+
+	imgReader, lastUpdate, err := a.artwork.Get(ctx, artID, 0, false)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return
+	case errors.Is(err, model.ErrNotFound), errors.Is(err, artwork.ErrUnavailable):
+		http.Error(w, "artwork not found", http.StatusNotFound)
+		return
+	case err != nil:
+		log.Error(ctx, "Error retrieving artwork for Sonos", "artID", artID, err)
+		http.Error(w, "error retrieving artwork", http.StatusInternalServerError)
+		return
+	}
+
+	defer imgReader.Close()
+	w.Header().Set("Cache-Control", "public, max-age=315360000")
+	w.Header().Set("Last-Modified", lastUpdate.Format(time.RFC1123))
+	if _, err := io.Copy(w, imgReader); err != nil {
+		log.Warn(ctx, "Error sending artwork to Sonos", "artID", artID, err)
+	}
+}
+
+// handleStream serves the audio for a signed stream token. Like handleArt,
+// it is unauthenticated - the token itself is the authorization
+func (a *API) handleStream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	info, err := decodeStreamToken(chi.URLParam(r, "token"))
+	if err != nil {
+		log.Warn(ctx, "Rejected Sonos stream request with invalid token", err)
+		http.Error(w, "invalid or expired stream token", http.StatusForbidden)
+		return
+	}
+
+	stream, err := a.streamer.NewStream(ctx, info.id, info.format, info.bitRate, 0)
+	if err != nil {
+		log.Error(ctx, "Error starting Sonos stream", "id", info.id, err)
+		http.Error(w, "invalid request", http.StatusInternalServerError)
+		return
+	}
+
+	// Make sure the stream will be closed at the end, to avoid leakage
+	defer func() {
+		if err := stream.Close(); err != nil && log.IsGreaterOrEqualTo(log.LevelDebug) {
+			log.Error("Error closing Sonos stream", "id", info.id, "file", stream.Name(), err)
+		}
+	}()
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Content-Duration", strconv.FormatFloat(float64(stream.Duration()), 'G', -1, 32))
+
+	if stream.Seekable() {
+		// Raw files are seekable - ServeContent provides the Content-Length
+		// and Range support Sonos needs for scrubbing
+		http.ServeContent(w, r, stream.Name(), stream.ModTime(), stream)
+		return
+	}
+
+	// Transcoded streams have no known size, so ranges can't be supported.
+	// Send an estimated length so Sonos can still show a progress bar
+	w.Header().Set("Accept-Ranges", "none")
+	w.Header().Set("Content-Type", stream.ContentType())
+	w.Header().Set("Content-Length", strconv.Itoa(stream.EstimatedContentLength()))
+
+	if r.Method == http.MethodHead {
+		go func() { _, _ = io.Copy(io.Discard, stream) }()
+		return
+	}
+
+	c, err := io.Copy(w, stream)
+	if log.IsGreaterOrEqualTo(log.LevelDebug) {
+		if err != nil {
+			log.Error(ctx, "Error sending Sonos transcoded stream", "id", info.id, err)
+		} else {
+			log.Trace(ctx, "Success sending Sonos transcoded stream", "id", info.id, "size", c)
+		}
+	}
+}
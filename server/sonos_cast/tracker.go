@@ -0,0 +1,165 @@
+package sonos_cast
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/navidrome/navidrome/core/scrobbler"
+	"github.com/navidrome/navidrome/log"
+)
+
+const (
+	// How often to poll devices for playback position
+	trackerPollInterval = 10 * time.Second
+
+	// A track is scrobbled after half its duration or this much play time,
+	// whichever comes first
+	scrobbleMaxThreshold = 4 * time.Minute
+)
+
+// PlaybackTracker follows playback on Sonos devices that are casting Navidrome
+// content, reporting now-playing info and submitting scrobbles through the core
+// PlayTracker once the threshold is crossed
+type PlaybackTracker struct {
+	sonosCast *SonosCast
+	plays     scrobbler.PlayTracker
+	mu        sync.Mutex
+	sessions  map[string]*trackerSession // keyed by device UUID
+}
+
+type trackerSession struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	trackID   string
+	duration  int // seconds
+	scrobbled bool
+}
+
+// NewPlaybackTracker creates a new PlaybackTracker
+func NewPlaybackTracker(sonosCast *SonosCast, plays scrobbler.PlayTracker) *PlaybackTracker {
+	return &PlaybackTracker{
+		sonosCast: sonosCast,
+		plays:     plays,
+		sessions:  make(map[string]*trackerSession),
+	}
+}
+
+// Track starts (or replaces) the tracking session for a device. The context
+// must carry the authenticated user, as plays are attributed to them
+func (t *PlaybackTracker) Track(ctx context.Context, deviceID, trackID string, duration int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if old, ok := t.sessions[deviceID]; ok {
+		old.cancel()
+	}
+
+	// Detach from the request context, but keep its values (user, player, etc.)
+	sessionCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	session := &trackerSession{
+		ctx:      sessionCtx,
+		cancel:   cancel,
+		trackID:  trackID,
+		duration: duration,
+	}
+	t.sessions[deviceID] = session
+
+	log.Debug(ctx, "Started Sonos playback tracking", "deviceID", deviceID, "trackID", trackID)
+	go t.poll(deviceID, session)
+}
+
+// StopTracking cancels the tracking session for a device, if any
+func (t *PlaybackTracker) StopTracking(deviceID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if session, ok := t.sessions[deviceID]; ok {
+		session.cancel()
+		delete(t.sessions, deviceID)
+	}
+}
+
+// endSession cancels a session and removes it from the map, unless it has
+// already been replaced by a newer one
+func (t *PlaybackTracker) endSession(deviceID string, session *trackerSession) {
+	session.cancel()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessions[deviceID] == session {
+		delete(t.sessions, deviceID)
+	}
+}
+
+// poll periodically checks the device's transport state and position,
+// dispatching now-playing updates and the scrobble when due
+func (t *PlaybackTracker) poll(deviceID string, session *trackerSession) {
+	ticker := time.NewTicker(trackerPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-session.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		device, ok := t.sonosCast.GetDevice(deviceID)
+		if !ok {
+			log.Debug(session.ctx, "Device disappeared - stopping playback tracking", "deviceID", deviceID)
+			t.endSession(deviceID, session)
+			return
+		}
+
+		state, err := t.sonosCast.transport.GetTransportInfo(session.ctx, device)
+		if err != nil {
+			log.Debug(session.ctx, "Failed to get transport state for tracking", "device", device.RoomName, err)
+			continue
+		}
+		if state == StateStopped {
+			t.endSession(deviceID, session)
+			return
+		}
+		if state != StatePlaying {
+			continue
+		}
+
+		track, err := t.sonosCast.transport.GetPositionInfo(session.ctx, device)
+		if err != nil {
+			log.Debug(session.ctx, "Failed to get position info for tracking", "device", device.RoomName, err)
+			continue
+		}
+
+		// Make sure the device is still playing the track we cast - the user may
+		// have switched to another source from the Sonos app
+		if extractTrackIDFromURI(track.URI) != session.trackID {
+			log.Debug(session.ctx, "Device is playing something else - stopping playback tracking",
+				"device", device.RoomName, "uri", track.URI)
+			t.endSession(deviceID, session)
+			return
+		}
+
+		err = t.plays.NowPlaying(session.ctx, "sonos-"+deviceID, device.RoomName, session.trackID, track.Position)
+		if err != nil {
+			log.Warn(session.ctx, "Error reporting NowPlaying for Sonos playback", "trackID", session.trackID, err)
+		}
+
+		if !session.scrobbled && shouldScrobble(track.Position, session.duration) {
+			session.scrobbled = true
+			err = t.plays.Submit(session.ctx, []scrobbler.Submission{{TrackID: session.trackID, Timestamp: time.Now()}})
+			if err != nil {
+				log.Warn(session.ctx, "Error scrobbling Sonos playback", "trackID", session.trackID, err)
+			} else {
+				log.Debug(session.ctx, "Scrobbled Sonos playback", "device", device.RoomName, "trackID", session.trackID)
+			}
+		}
+	}
+}
+
+// shouldScrobble reports whether the played position crosses the scrobble
+// threshold: half the track or 4 minutes, whichever comes first
+func shouldScrobble(position, duration int) bool {
+	if position >= int(scrobbleMaxThreshold.Seconds()) {
+		return true
+	}
+	return duration > 0 && position >= duration/2
+}
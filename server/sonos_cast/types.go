@@ -18,15 +18,39 @@ type SonosDevice struct {
 	IsCoordinator bool      `json:"isCoordinator"`
 	GroupID       string    `json:"groupId"`
 	GroupMembers  []string  `json:"groupMembers,omitempty"` // UUIDs of group members
+	Invisible     bool      `json:"-"`                      // bonded satellite or hidden pair member
+	Reachable     bool      `json:"reachable"`              // false while the device is in failure backoff
 	LastSeen      time.Time `json:"lastSeen"`
+
+	// Battery info, only present for portable models (Roam, Move)
+	Battery *BatteryStatus `json:"battery,omitempty"`
+}
+
+// BatteryStatus holds the charge state of a portable Sonos speaker
+type BatteryStatus struct {
+	Level    int  `json:"level"` // percentage, 0-100
+	Charging bool `json:"charging"`
 }
 
 // PlaybackState represents the current playback state of a speaker
 type PlaybackState struct {
-	State        string `json:"state"` // PLAYING, PAUSED_PLAYBACK, STOPPED
-	CurrentTrack *Track `json:"currentTrack,omitempty"`
-	Volume       int    `json:"volume"`
-	Muted        bool   `json:"muted"`
+	State        string      `json:"state"`  // PLAYING, PAUSED_PLAYBACK, STOPPED
+	Source       string      `json:"source"` // what the device is playing from - see the Source constants
+	CurrentTrack *Track      `json:"currentTrack,omitempty"`
+	Volume       int         `json:"volume"`
+	Muted        bool        `json:"muted"`
+	Shuffle      bool        `json:"shuffle"`
+	Repeat       string      `json:"repeat"` // none, one, all
+	Crossfade    bool        `json:"crossfade"`
+	SleepTimer   int         `json:"sleepTimer,omitempty"` // seconds remaining, 0 when no timer is set
+	EQ           *EQSettings `json:"eq,omitempty"`
+}
+
+// EQSettings holds a device's tone controls
+type EQSettings struct {
+	Bass     int  `json:"bass"`   // -10 to 10
+	Treble   int  `json:"treble"` // -10 to 10
+	Loudness bool `json:"loudness"`
 }
 
 // Track represents currently playing track info
@@ -36,8 +60,8 @@ type Track struct {
 	Artist    string `json:"artist"`
 	Album     string `json:"album"`
 	AlbumArt  string `json:"albumArt"`
-	Duration  int    `json:"duration"`  // seconds
-	Position  int    `json:"position"`  // seconds
+	Duration  int    `json:"duration"` // seconds
+	Position  int    `json:"position"` // seconds
 	TrackNum  int    `json:"trackNum"`
 	QueueSize int    `json:"queueSize"`
 
@@ -136,6 +160,18 @@ type Service struct {
 	SCPDURL     string `xml:"SCPDURL"`
 }
 
+// XML types for the battery status endpoint (/status/batterystatus)
+
+type ZPSupportInfo struct {
+	XMLName xml.Name             `xml:"ZPSupportInfo"`
+	Data    []BatteryStatusEntry `xml:"LocalBatteryStatus>Data"`
+}
+
+type BatteryStatusEntry struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
 // XML types for ZoneGroupTopology
 
 type ZoneGroupState struct {
@@ -150,8 +186,17 @@ type ZoneGroup struct {
 }
 
 type ZoneMember struct {
+	UUID       string      `xml:"UUID,attr"`
+	Location   string      `xml:"Location,attr"`
+	ZoneName   string      `xml:"ZoneName,attr"`
+	Invisible  string      `xml:"Invisible,attr"` // "1" for hidden members (stereo pair right, Sub, ...)
+	Satellites []Satellite `xml:"Satellite"`      // bonded home-theater satellites
+}
+
+// Satellite is a bonded home-theater speaker (surrounds, Sub) listed under
+// its parent ZoneGroupMember. Satellites are never independently controllable
+type Satellite struct {
 	UUID     string `xml:"UUID,attr"`
-	Location string `xml:"Location,attr"`
 	ZoneName string `xml:"ZoneName,attr"`
 }
 
@@ -225,6 +270,126 @@ type PreviousAction struct {
 	InstanceID int      `xml:"InstanceID"`
 }
 
+type RemoveTrackFromQueueAction struct {
+	XMLName    xml.Name `xml:"u:RemoveTrackFromQueue"`
+	XmlnsU     string   `xml:"xmlns:u,attr"`
+	InstanceID int      `xml:"InstanceID"`
+	ObjectID   string   `xml:"ObjectID"` // Q:0/<track number>
+	UpdateID   int      `xml:"UpdateID"`
+}
+
+type ReorderTracksInQueueAction struct {
+	XMLName        xml.Name `xml:"u:ReorderTracksInQueue"`
+	XmlnsU         string   `xml:"xmlns:u,attr"`
+	InstanceID     int      `xml:"InstanceID"`
+	StartingIndex  int      `xml:"StartingIndex"` // 1-based
+	NumberOfTracks int      `xml:"NumberOfTracks"`
+	InsertBefore   int      `xml:"InsertBefore"` // 1-based
+	UpdateID       int      `xml:"UpdateID"`
+}
+
+type AddURIToQueueAction struct {
+	XMLName                         xml.Name `xml:"u:AddURIToQueue"`
+	XmlnsU                          string   `xml:"xmlns:u,attr"`
+	InstanceID                      int      `xml:"InstanceID"`
+	EnqueuedURI                     string   `xml:"EnqueuedURI"`
+	EnqueuedURIMetaData             string   `xml:"EnqueuedURIMetaData"`
+	DesiredFirstTrackNumberEnqueued int      `xml:"DesiredFirstTrackNumberEnqueued"`
+	EnqueueAsNext                   int      `xml:"EnqueueAsNext"`
+}
+
+type RemoveAllTracksFromQueueAction struct {
+	XMLName    xml.Name `xml:"u:RemoveAllTracksFromQueue"`
+	XmlnsU     string   `xml:"xmlns:u,attr"`
+	InstanceID int      `xml:"InstanceID"`
+}
+
+type SetPlayModeAction struct {
+	XMLName     xml.Name `xml:"u:SetPlayMode"`
+	XmlnsU      string   `xml:"xmlns:u,attr"`
+	InstanceID  int      `xml:"InstanceID"`
+	NewPlayMode string   `xml:"NewPlayMode"`
+}
+
+type GetTransportSettingsAction struct {
+	XMLName    xml.Name `xml:"u:GetTransportSettings"`
+	XmlnsU     string   `xml:"xmlns:u,attr"`
+	InstanceID int      `xml:"InstanceID"`
+}
+
+type SetCrossfadeModeAction struct {
+	XMLName       xml.Name `xml:"u:SetCrossfadeMode"`
+	XmlnsU        string   `xml:"xmlns:u,attr"`
+	InstanceID    int      `xml:"InstanceID"`
+	CrossfadeMode int      `xml:"CrossfadeMode"` // 0 or 1
+}
+
+type GetCrossfadeModeAction struct {
+	XMLName    xml.Name `xml:"u:GetCrossfadeMode"`
+	XmlnsU     string   `xml:"xmlns:u,attr"`
+	InstanceID int      `xml:"InstanceID"`
+}
+
+type ConfigureSleepTimerAction struct {
+	XMLName               xml.Name `xml:"u:ConfigureSleepTimer"`
+	XmlnsU                string   `xml:"xmlns:u,attr"`
+	InstanceID            int      `xml:"InstanceID"`
+	NewSleepTimerDuration string   `xml:"NewSleepTimerDuration"` // HH:MM:SS, empty to cancel
+}
+
+type GetRemainingSleepTimerDurationAction struct {
+	XMLName    xml.Name `xml:"u:GetRemainingSleepTimerDuration"`
+	XmlnsU     string   `xml:"xmlns:u,attr"`
+	InstanceID int      `xml:"InstanceID"`
+}
+
+// ContentDirectory SOAP actions
+
+type BrowseAction struct {
+	XMLName        xml.Name `xml:"u:Browse"`
+	XmlnsU         string   `xml:"xmlns:u,attr"`
+	ObjectID       string   `xml:"ObjectID"`
+	BrowseFlag     string   `xml:"BrowseFlag"`
+	Filter         string   `xml:"Filter"`
+	StartingIndex  int      `xml:"StartingIndex"`
+	RequestedCount int      `xml:"RequestedCount"`
+	SortCriteria   string   `xml:"SortCriteria"`
+}
+
+type BrowseResponse struct {
+	XMLName        xml.Name `xml:"BrowseResponse"`
+	Result         string   `xml:"Result"`
+	NumberReturned int      `xml:"NumberReturned"`
+	TotalMatches   int      `xml:"TotalMatches"`
+	UpdateID       int      `xml:"UpdateID"`
+}
+
+type CreateObjectAction struct {
+	XMLName     xml.Name `xml:"u:CreateObject"`
+	XmlnsU      string   `xml:"xmlns:u,attr"`
+	ContainerID string   `xml:"ContainerID"`
+	Elements    string   `xml:"Elements"`
+}
+
+type CreateObjectResponse struct {
+	XMLName  xml.Name `xml:"CreateObjectResponse"`
+	ObjectID string   `xml:"ObjectID"`
+	Result   string   `xml:"Result"`
+}
+
+type AddURIToQueueResponse struct {
+	XMLName                  xml.Name `xml:"AddURIToQueueResponse"`
+	FirstTrackNumberEnqueued int      `xml:"FirstTrackNumberEnqueued"`
+	NumTracksAdded           int      `xml:"NumTracksAdded"`
+	NewQueueLength           int      `xml:"NewQueueLength"`
+}
+
+type GetMediaInfoAction struct {
+	XMLName    xml.Name `xml:"u:GetMediaInfo"`
+	XmlnsU     string   `xml:"xmlns:u,attr"`
+	InstanceID int      `xml:"InstanceID"`
+}
+
 type GetPositionInfoAction struct {
 	XMLName    xml.Name `xml:"u:GetPositionInfo"`
 	XmlnsU     string   `xml:"xmlns:u,attr"`
@@ -251,12 +416,37 @@ type GetPositionInfoResponse struct {
 	AbsCount      int      `xml:"AbsCount"`
 }
 
+type GetMediaInfoResponse struct {
+	XMLName            xml.Name `xml:"GetMediaInfoResponse"`
+	NrTracks           int      `xml:"NrTracks"`
+	MediaDuration      string   `xml:"MediaDuration"`
+	CurrentURI         string   `xml:"CurrentURI"`
+	CurrentURIMetaData string   `xml:"CurrentURIMetaData"`
+}
+
 type GetTransportInfoResponse struct {
 	XMLName               xml.Name `xml:"GetTransportInfoResponse"`
 	CurrentTransportState string   `xml:"CurrentTransportState"`
 	CurrentSpeed          string   `xml:"CurrentTransportSpeed"`
 }
 
+type GetCrossfadeModeResponse struct {
+	XMLName       xml.Name `xml:"GetCrossfadeModeResponse"`
+	CrossfadeMode int      `xml:"CrossfadeMode"`
+}
+
+type GetRemainingSleepTimerDurationResponse struct {
+	XMLName                     xml.Name `xml:"GetRemainingSleepTimerDurationResponse"`
+	RemainingSleepTimerDuration string   `xml:"RemainingSleepTimerDuration"`
+	CurrentSleepTimerGeneration int      `xml:"CurrentSleepTimerGeneration"`
+}
+
+type GetTransportSettingsResponse struct {
+	XMLName     xml.Name `xml:"GetTransportSettingsResponse"`
+	PlayMode    string   `xml:"PlayMode"`
+	RecQualMode string   `xml:"RecQualityMode"`
+}
+
 // RenderingControl SOAP actions
 
 type GetVolumeAction struct {
@@ -289,6 +479,47 @@ type SetMuteAction struct {
 	DesiredMute int      `xml:"DesiredMute"` // 0 or 1
 }
 
+type GetBassAction struct {
+	XMLName    xml.Name `xml:"u:GetBass"`
+	XmlnsU     string   `xml:"xmlns:u,attr"`
+	InstanceID int      `xml:"InstanceID"`
+}
+
+type SetBassAction struct {
+	XMLName     xml.Name `xml:"u:SetBass"`
+	XmlnsU      string   `xml:"xmlns:u,attr"`
+	InstanceID  int      `xml:"InstanceID"`
+	DesiredBass int      `xml:"DesiredBass"` // -10 to 10
+}
+
+type GetTrebleAction struct {
+	XMLName    xml.Name `xml:"u:GetTreble"`
+	XmlnsU     string   `xml:"xmlns:u,attr"`
+	InstanceID int      `xml:"InstanceID"`
+}
+
+type SetTrebleAction struct {
+	XMLName       xml.Name `xml:"u:SetTreble"`
+	XmlnsU        string   `xml:"xmlns:u,attr"`
+	InstanceID    int      `xml:"InstanceID"`
+	DesiredTreble int      `xml:"DesiredTreble"` // -10 to 10
+}
+
+type GetLoudnessAction struct {
+	XMLName    xml.Name `xml:"u:GetLoudness"`
+	XmlnsU     string   `xml:"xmlns:u,attr"`
+	InstanceID int      `xml:"InstanceID"`
+	Channel    string   `xml:"Channel"`
+}
+
+type SetLoudnessAction struct {
+	XMLName         xml.Name `xml:"u:SetLoudness"`
+	XmlnsU          string   `xml:"xmlns:u,attr"`
+	InstanceID      int      `xml:"InstanceID"`
+	Channel         string   `xml:"Channel"`
+	DesiredLoudness int      `xml:"DesiredLoudness"` // 0 or 1
+}
+
 // RenderingControl SOAP responses
 
 type GetVolumeResponse struct {
@@ -301,6 +532,21 @@ type GetMuteResponse struct {
 	CurrentMute int      `xml:"CurrentMute"`
 }
 
+type GetBassResponse struct {
+	XMLName     xml.Name `xml:"GetBassResponse"`
+	CurrentBass int      `xml:"CurrentBass"`
+}
+
+type GetTrebleResponse struct {
+	XMLName       xml.Name `xml:"GetTrebleResponse"`
+	CurrentTreble int      `xml:"CurrentTreble"`
+}
+
+type GetLoudnessResponse struct {
+	XMLName         xml.Name `xml:"GetLoudnessResponse"`
+	CurrentLoudness int      `xml:"CurrentLoudness"`
+}
+
 // Constants
 const (
 	SonosPort = 1400
@@ -308,15 +554,47 @@ const (
 	// Service URNs
 	AVTransportURN       = "urn:schemas-upnp-org:service:AVTransport:1"
 	RenderingControlURN  = "urn:schemas-upnp-org:service:RenderingControl:1"
+	ContentDirectoryURN  = "urn:schemas-upnp-org:service:ContentDirectory:1"
 	ZoneGroupTopologyURN = "urn:upnp-org:serviceId:ZoneGroupTopology"
 
 	// Control URLs
 	AVTransportControlURL      = "/MediaRenderer/AVTransport/Control"
 	RenderingControlControlURL = "/MediaRenderer/RenderingControl/Control"
+	ContentDirectoryControlURL = "/MediaServer/ContentDirectory/Control"
 	ZoneGroupTopologyURL       = "/ZoneGroupTopology/Control"
 
+	// FavoritesContainerID is the ContentDirectory container backing the
+	// "My Sonos" favorites list
+	FavoritesContainerID = "FV:2"
+
 	// Transport states
 	StatePlaying = "PLAYING"
 	StatePaused  = "PAUSED_PLAYBACK"
 	StateStopped = "STOPPED"
+
+	// Playback sources, classified from the current transport URI. The UI
+	// should only show Navidrome track info for SourceNavidrome - anything
+	// else means the device was switched to another input
+	SourceNone      = "none"      // nothing loaded
+	SourceNavidrome = "navidrome" // one of our signed stream URLs
+	SourceLineIn    = "linein"    // analog line-in of this or another room
+	SourceTV        = "tv"        // home theater input (optical/HDMI)
+	SourceRadio     = "radio"     // internet radio station
+	SourceService   = "service"   // external music service (Spotify Connect, AirPlay, ...)
+	SourceQueue     = "queue"     // the device's own Sonos queue
+	SourceGroup     = "group"     // following another room's group coordinator
+	SourceStream    = "stream"    // any other direct http(s) stream
+
+	// Play modes (AVTransport CurrentPlayMode values)
+	PlayModeNormal          = "NORMAL"
+	PlayModeRepeatAll       = "REPEAT_ALL"
+	PlayModeRepeatOne       = "REPEAT_ONE"
+	PlayModeShuffleNoRepeat = "SHUFFLE_NOREPEAT"
+	PlayModeShuffle         = "SHUFFLE" // shuffle + repeat all
+	PlayModeShuffleRepOne   = "SHUFFLE_REPEAT_ONE"
+
+	// Repeat modes used by the REST API
+	RepeatNone = "none"
+	RepeatOne  = "one"
+	RepeatAll  = "all"
 )
@@ -0,0 +1,397 @@
+// Package upnptest implements a fake Sonos ZonePlayer for integration tests.
+// It serves the UPnP device description and the AVTransport, RenderingControl
+// and ZoneGroupTopology endpoints over a local httptest server, and can
+// optionally answer SSDP M-SEARCH probes with its location, so discovery,
+// casting, grouping and error paths can be exercised without real hardware
+package upnptest
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	xmlEscaper   = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+	xmlUnescaper = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&amp;", "&")
+)
+
+// ZonePlayer is a fake Sonos speaker. All state accessors are safe for
+// concurrent use
+type ZonePlayer struct {
+	UUID      string
+	RoomName  string
+	ModelName string
+	SwGen     string // "1" or "2"
+
+	mu             sync.Mutex
+	transportState string
+	currentURI     string
+	currentMeta    string
+	nextURI        string
+	volume         int
+	muted          bool
+	queue          []string
+	faults         map[string]int // action name -> UPnP error code
+
+	topology *Topology
+	srv      *httptest.Server
+	ssdp     *net.UDPConn
+}
+
+// NewZonePlayer starts a fake ZonePlayer listening on a random local port
+func NewZonePlayer(uuid, roomName string) *ZonePlayer {
+	z := &ZonePlayer{
+		UUID:           uuid,
+		RoomName:       roomName,
+		ModelName:      "Sonos Play:1",
+		SwGen:          "2",
+		transportState: "STOPPED",
+		volume:         30,
+		faults:         make(map[string]int),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/xml/device_description.xml", z.handleDescription)
+	mux.HandleFunc("/MediaRenderer/AVTransport/Control", z.handleAVTransport)
+	mux.HandleFunc("/MediaRenderer/RenderingControl/Control", z.handleRenderingControl)
+	mux.HandleFunc("/ZoneGroupTopology/Control", z.handleTopology)
+	z.srv = httptest.NewServer(mux)
+	return z
+}
+
+// Close shuts the fake player down
+func (z *ZonePlayer) Close() {
+	if z.ssdp != nil {
+		_ = z.ssdp.Close()
+	}
+	z.srv.Close()
+}
+
+// Location returns the URL of the player's device description
+func (z *ZonePlayer) Location() string {
+	return z.srv.URL + "/xml/device_description.xml"
+}
+
+// TransportState returns the current transport state (PLAYING, STOPPED, ...)
+func (z *ZonePlayer) TransportState() string {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return z.transportState
+}
+
+// CurrentURI returns the URI last set by SetAVTransportURI
+func (z *ZonePlayer) CurrentURI() string {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return z.currentURI
+}
+
+// CurrentMetadata returns the DIDL metadata last set by SetAVTransportURI
+func (z *ZonePlayer) CurrentMetadata() string {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return z.currentMeta
+}
+
+// NextURI returns the URI last set by SetNextAVTransportURI
+func (z *ZonePlayer) NextURI() string {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return z.nextURI
+}
+
+// Volume returns the current volume
+func (z *ZonePlayer) Volume() int {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return z.volume
+}
+
+// Muted returns the current mute state
+func (z *ZonePlayer) Muted() bool {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return z.muted
+}
+
+// Queue returns the URIs enqueued via AddURIToQueue
+func (z *ZonePlayer) Queue() []string {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return append([]string(nil), z.queue...)
+}
+
+// FailAction makes the given SOAP action return a fault with the given UPnP
+// error code until the fault is cleared with code 0
+func (z *ZonePlayer) FailAction(action string, code int) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if code == 0 {
+		delete(z.faults, action)
+		return
+	}
+	z.faults[action] = code
+}
+
+// ServeSSDP starts answering M-SEARCH probes for ZonePlayers on the SSDP
+// multicast group with this player's location. It returns an error when the
+// multicast socket cannot be opened (e.g. no multicast routing available)
+func (z *ZonePlayer) ServeSSDP() error {
+	addr, err := net.ResolveUDPAddr("udp4", "239.255.255.250:1900")
+	if err != nil {
+		return err
+	}
+	conn, err := net.ListenMulticastUDP("udp4", nil, addr)
+	if err != nil {
+		return err
+	}
+	z.ssdp = conn
+
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, src, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			msg := string(buf[:n])
+			if !strings.HasPrefix(msg, "M-SEARCH") || !strings.Contains(msg, "ZonePlayer") {
+				continue
+			}
+			resp := fmt.Sprintf("HTTP/1.1 200 OK\r\n"+
+				"CACHE-CONTROL: max-age = 1800\r\n"+
+				"EXT:\r\n"+
+				"LOCATION: %s\r\n"+
+				"ST: urn:schemas-upnp-org:device:ZonePlayer:1\r\n"+
+				"USN: uuid:%s::urn:schemas-upnp-org:device:ZonePlayer:1\r\n"+
+				"\r\n", z.Location(), z.UUID)
+			_, _ = conn.WriteToUDP([]byte(resp), src)
+		}
+	}()
+	return nil
+}
+
+// Topology describes the zone groups shared by a set of fake players. Every
+// player starts as its own group; Group merges players into one
+type Topology struct {
+	mu     sync.Mutex
+	groups [][]*ZonePlayer // first member of each group is its coordinator
+}
+
+// NewTopology links the given players into a shared topology, each in its own
+// group. All players answer GetZoneGroupState with the same full picture,
+// like real Sonos devices do
+func NewTopology(players ...*ZonePlayer) *Topology {
+	t := &Topology{}
+	for _, p := range players {
+		t.groups = append(t.groups, []*ZonePlayer{p})
+		p.topology = t
+	}
+	return t
+}
+
+// Group merges the given players into one zone group, with the first player
+// as its coordinator
+func (t *Topology) Group(players ...*ZonePlayer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	merged := make(map[*ZonePlayer]bool, len(players))
+	for _, p := range players {
+		merged[p] = true
+	}
+	var groups [][]*ZonePlayer
+	for _, group := range t.groups {
+		var rest []*ZonePlayer
+		for _, p := range group {
+			if !merged[p] {
+				rest = append(rest, p)
+			}
+		}
+		if len(rest) > 0 {
+			groups = append(groups, rest)
+		}
+	}
+	t.groups = append(groups, players)
+}
+
+// render builds the ZoneGroupState XML for the whole topology
+func (t *Topology) render() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var sb strings.Builder
+	sb.WriteString("<ZoneGroupState><ZoneGroups>")
+	for _, group := range t.groups {
+		coordinator := group[0]
+		fmt.Fprintf(&sb, `<ZoneGroup Coordinator="%s" ID="%s:1">`, coordinator.UUID, coordinator.UUID)
+		for _, p := range group {
+			fmt.Fprintf(&sb, `<ZoneGroupMember UUID="%s" Location="%s" ZoneName="%s"/>`,
+				p.UUID, p.Location(), xmlEscaper.Replace(p.RoomName))
+		}
+		sb.WriteString("</ZoneGroup>")
+	}
+	sb.WriteString("</ZoneGroups></ZoneGroupState>")
+	return sb.String()
+}
+
+func (z *ZonePlayer) handleDescription(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:ZonePlayer:1</deviceType>
+    <friendlyName>%s - %s</friendlyName>
+    <manufacturer>Sonos, Inc.</manufacturer>
+    <modelName>%s</modelName>
+    <modelNumber>S1</modelNumber>
+    <UDN>uuid:%s</UDN>
+    <roomName>%s</roomName>
+    <swGen>%s</swGen>
+  </device>
+</root>`, xmlEscaper.Replace(z.RoomName), z.ModelName, z.ModelName, z.UUID, xmlEscaper.Replace(z.RoomName), z.SwGen)
+}
+
+func (z *ZonePlayer) handleAVTransport(w http.ResponseWriter, r *http.Request) {
+	action, body := readAction(r)
+	if z.maybeFault(w, action) {
+		return
+	}
+
+	z.mu.Lock()
+	var inner string
+	switch action {
+	case "SetAVTransportURI":
+		z.currentURI = xmlValue(body, "CurrentURI")
+		z.currentMeta = xmlValue(body, "CurrentURIMetaData")
+	case "SetNextAVTransportURI":
+		z.nextURI = xmlValue(body, "NextURI")
+	case "Play":
+		z.transportState = "PLAYING"
+	case "Pause":
+		z.transportState = "PAUSED_PLAYBACK"
+	case "Stop":
+		z.transportState = "STOPPED"
+	case "AddURIToQueue":
+		z.queue = append(z.queue, xmlValue(body, "EnqueuedURI"))
+		inner = fmt.Sprintf("<FirstTrackNumberEnqueued>%d</FirstTrackNumberEnqueued><NumTracksAdded>1</NumTracksAdded><NewQueueLength>%d</NewQueueLength>",
+			len(z.queue), len(z.queue))
+	case "RemoveAllTracksFromQueue":
+		z.queue = nil
+	case "GetTransportInfo":
+		inner = fmt.Sprintf("<CurrentTransportState>%s</CurrentTransportState><CurrentTransportStatus>OK</CurrentTransportStatus><CurrentSpeed>1</CurrentSpeed>", z.transportState)
+	case "GetPositionInfo":
+		inner = fmt.Sprintf("<Track>1</Track><TrackDuration>0:03:00</TrackDuration><TrackMetaData></TrackMetaData><TrackURI>%s</TrackURI><RelTime>0:00:10</RelTime><AbsTime>NOT_IMPLEMENTED</AbsTime><RelCount>0</RelCount><AbsCount>0</AbsCount>",
+			xmlEscaper.Replace(z.currentURI))
+	case "GetMediaInfo":
+		inner = fmt.Sprintf("<NrTracks>%d</NrTracks><MediaDuration>0:03:00</MediaDuration><CurrentURI>%s</CurrentURI><CurrentURIMetaData></CurrentURIMetaData>",
+			len(z.queue), xmlEscaper.Replace(z.currentURI))
+	case "GetTransportSettings":
+		inner = "<PlayMode>NORMAL</PlayMode><RecQualityMode>NOT_IMPLEMENTED</RecQualityMode>"
+	}
+	z.mu.Unlock()
+
+	soapOK(w, action, "urn:schemas-upnp-org:service:AVTransport:1", inner)
+}
+
+func (z *ZonePlayer) handleRenderingControl(w http.ResponseWriter, r *http.Request) {
+	action, body := readAction(r)
+	if z.maybeFault(w, action) {
+		return
+	}
+
+	z.mu.Lock()
+	var inner string
+	switch action {
+	case "GetVolume":
+		inner = fmt.Sprintf("<CurrentVolume>%d</CurrentVolume>", z.volume)
+	case "SetVolume":
+		z.volume, _ = strconv.Atoi(xmlValue(body, "DesiredVolume"))
+	case "GetMute":
+		muted := 0
+		if z.muted {
+			muted = 1
+		}
+		inner = fmt.Sprintf("<CurrentMute>%d</CurrentMute>", muted)
+	case "SetMute":
+		z.muted = xmlValue(body, "DesiredMute") == "1"
+	}
+	z.mu.Unlock()
+
+	soapOK(w, action, "urn:schemas-upnp-org:service:RenderingControl:1", inner)
+}
+
+func (z *ZonePlayer) handleTopology(w http.ResponseWriter, r *http.Request) {
+	action, _ := readAction(r)
+	if z.maybeFault(w, action) {
+		return
+	}
+
+	state := "<ZoneGroupState><ZoneGroups>" +
+		fmt.Sprintf(`<ZoneGroup Coordinator="%s" ID="%s:1"><ZoneGroupMember UUID="%s" Location="%s" ZoneName="%s"/></ZoneGroup>`,
+			z.UUID, z.UUID, z.UUID, z.Location(), xmlEscaper.Replace(z.RoomName)) +
+		"</ZoneGroups></ZoneGroupState>"
+	if z.topology != nil {
+		state = z.topology.render()
+	}
+
+	inner := "<ZoneGroupState>" + xmlEscaper.Replace(state) + "</ZoneGroupState>"
+	soapOK(w, "GetZoneGroupState", "urn:upnp-org:serviceId:ZoneGroupTopology", inner)
+}
+
+// maybeFault writes a SOAP fault when a failure was armed for the action
+func (z *ZonePlayer) maybeFault(w http.ResponseWriter, action string) bool {
+	z.mu.Lock()
+	code, ok := z.faults[action]
+	z.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	fmt.Fprintf(w, `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body><s:Fault><faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring><detail><UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>%d</errorCode></UPnPError></detail></s:Fault></s:Body></s:Envelope>`, code)
+	return true
+}
+
+// readAction extracts the action name from the SOAPACTION header and returns
+// it together with the request body
+func readAction(r *http.Request) (string, string) {
+	action := r.Header.Get("SOAPACTION")
+	if idx := strings.LastIndex(action, "#"); idx != -1 {
+		action = action[idx+1:]
+	}
+	action = strings.Trim(action, `"`)
+
+	buf := new(strings.Builder)
+	_, _ = io.Copy(buf, r.Body)
+	return action, buf.String()
+}
+
+// soapOK writes a successful SOAP response wrapping the given inner XML in
+// the action's response element
+func soapOK(w http.ResponseWriter, action, urn, inner string) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	fmt.Fprintf(w, `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body><u:%sResponse xmlns:u="%s">%s</u:%sResponse></s:Body></s:Envelope>`,
+		action, urn, inner, action)
+}
+
+// xmlValue extracts and unescapes the text content of the first occurrence
+// of the given element
+func xmlValue(body, tag string) string {
+	start := strings.Index(body, "<"+tag+">")
+	if start == -1 {
+		return ""
+	}
+	start += len(tag) + 2
+	end := strings.Index(body[start:], "</"+tag+">")
+	if end == -1 {
+		return ""
+	}
+	return xmlUnescaper.Replace(body[start : start+end])
+}
@@ -200,7 +200,7 @@ func (api *Router) routes() http.Handler {
 			h501(r, "getShares", "createShare", "updateShare", "deleteShare")
 		}
 
-		if conf.Server.Jukebox.Enabled {
+		if conf.Server.Jukebox.Enabled || sonosJukeboxEnabled() {
 			r.Group(func(r chi.Router) {
 				r.Use(getPlayer(api.players))
 				h(r, "jukeboxControl", api.JukeboxControl)
@@ -1,12 +1,15 @@
 package subsonic
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
 	"github.com/navidrome/navidrome/conf"
 	"github.com/navidrome/navidrome/core/playback"
 	"github.com/navidrome/navidrome/log"
+	"github.com/navidrome/navidrome/model"
+	"github.com/navidrome/navidrome/server/sonos_cast"
 	"github.com/navidrome/navidrome/server/subsonic/responses"
 	"github.com/navidrome/navidrome/utils/req"
 	"github.com/navidrome/navidrome/utils/slice"
@@ -26,12 +29,35 @@ const (
 	ActionSetGain = "setGain"
 )
 
+// jukeboxDevice is the command set jukeboxControl needs from a playback
+// backend. It is implemented by the local MPV playback device and by the
+// Sonos jukebox adapter
+type jukeboxDevice interface {
+	Get(ctx context.Context) (model.MediaFiles, playback.DeviceStatus, error)
+	Status(ctx context.Context) (playback.DeviceStatus, error)
+	Set(ctx context.Context, ids []string) (playback.DeviceStatus, error)
+	Start(ctx context.Context) (playback.DeviceStatus, error)
+	Stop(ctx context.Context) (playback.DeviceStatus, error)
+	Skip(ctx context.Context, index int, offset int) (playback.DeviceStatus, error)
+	Add(ctx context.Context, ids []string) (playback.DeviceStatus, error)
+	Clear(ctx context.Context) (playback.DeviceStatus, error)
+	Remove(ctx context.Context, index int) (playback.DeviceStatus, error)
+	Shuffle(ctx context.Context) (playback.DeviceStatus, error)
+	SetGain(ctx context.Context, gain float32) (playback.DeviceStatus, error)
+}
+
+// sonosJukeboxEnabled reports whether jukeboxControl should target a Sonos
+// room instead of a local audio device
+func sonosJukeboxEnabled() bool {
+	return conf.Server.SonosCast.Enabled && conf.Server.SonosCast.JukeboxRoom != ""
+}
+
 func (api *Router) JukeboxControl(r *http.Request) (*responses.Subsonic, error) {
 	ctx := r.Context()
 	user := getUser(ctx)
 	p := req.Params(r)
 
-	if !conf.Server.Jukebox.Enabled {
+	if !conf.Server.Jukebox.Enabled && !sonosJukeboxEnabled() {
 		return nil, newError(responses.ErrorGeneric, "Jukebox is disabled")
 	}
 
@@ -44,9 +70,14 @@ func (api *Router) JukeboxControl(r *http.Request) (*responses.Subsonic, error)
 		return nil, err
 	}
 
-	pb, err := api.playback.GetDeviceForUser(user.UserName)
-	if err != nil {
-		return nil, err
+	var pb jukeboxDevice
+	if sonosJukeboxEnabled() {
+		pb = sonos_cast.GetJukeboxDevice(api.ds)
+	} else {
+		pb, err = api.playback.GetDeviceForUser(user.UserName)
+		if err != nil {
+			return nil, err
+		}
 	}
 	log.Info(ctx, "JukeboxControl request received", "action", actionString)
 
@@ -24,10 +24,12 @@ type MockDataStore struct {
 	MockedShare          model.ShareRepository
 	MockedTranscoding    model.TranscodingRepository
 	MockedUserProps      model.UserPropsRepository
-	MockedScrobbleBuffer    model.ScrobbleBufferRepository
-	MockedScrobble          model.ScrobbleRepository
-	MockedRadio             model.RadioRepository
-	scrobbleBufferMu        sync.Mutex
+	MockedScrobbleBuffer model.ScrobbleBufferRepository
+	MockedScrobble       model.ScrobbleRepository
+	MockedCastAudit      model.CastAuditRepository
+	MockedFingerprint    model.FingerprintRepository
+	MockedRadio          model.RadioRepository
+	scrobbleBufferMu     sync.Mutex
 	repoMu               sync.Mutex
 
 	// GC tracking
@@ -226,6 +228,28 @@ func (db *MockDataStore) Scrobble(ctx context.Context) model.ScrobbleRepository
 	return db.MockedScrobble
 }
 
+func (db *MockDataStore) CastAudit(ctx context.Context) model.CastAuditRepository {
+	if db.MockedCastAudit == nil {
+		if db.RealDS != nil {
+			db.MockedCastAudit = db.RealDS.CastAudit(ctx)
+		} else {
+			db.MockedCastAudit = struct{ model.CastAuditRepository }{}
+		}
+	}
+	return db.MockedCastAudit
+}
+
+func (db *MockDataStore) Fingerprint(ctx context.Context) model.FingerprintRepository {
+	if db.MockedFingerprint == nil {
+		if db.RealDS != nil {
+			db.MockedFingerprint = db.RealDS.Fingerprint(ctx)
+		} else {
+			db.MockedFingerprint = struct{ model.FingerprintRepository }{}
+		}
+	}
+	return db.MockedFingerprint
+}
+
 func (db *MockDataStore) Radio(ctx context.Context) model.RadioRepository {
 	if db.MockedRadio == nil {
 		if db.RealDS != nil {
@@ -28,6 +28,14 @@ func (r *MockedGenreRepo) GetAll(...model.QueryOptions) (model.Genres, error) {
 	return all, nil
 }
 
+func (r *MockedGenreRepo) CountAll(...model.QueryOptions) (int64, error) {
+	if r.Error != nil {
+		return 0, r.Error
+	}
+	r.init()
+	return int64(len(r.Data)), nil
+}
+
 func (r *MockedGenreRepo) Put(g *model.Genre) error {
 	if r.Error != nil {
 		return r.Error
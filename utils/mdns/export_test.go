@@ -0,0 +1,13 @@
+package mdns
+
+var (
+	ParseResponse = parseResponse
+	ParseName     = parseName
+	AppendName    = appendName
+)
+
+const (
+	TypeA   = typeA
+	TypeTXT = typeTXT
+	TypeSRV = typeSRV
+)
@@ -0,0 +1,307 @@
+// Package mdns implements the small subset of multicast DNS (RFC 6762) needed
+// to discover cast receivers on the local network: sending a one-shot PTR
+// query for a service type and collecting the SRV/TXT/A records from the
+// responses. It intentionally avoids a full mDNS responder or cache
+package mdns
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/navidrome/navidrome/log"
+)
+
+const multicastAddr = "224.0.0.251:5353"
+
+// DNS record types used by service discovery responses
+const (
+	typeA   = 1
+	typePTR = 12
+	typeTXT = 16
+	typeSRV = 33
+)
+
+// Entry is one discovered service instance
+type Entry struct {
+	Instance string // instance name, without the service suffix
+	Host     string // SRV target hostname
+	IP       string
+	Port     int
+	TXT      map[string]string
+}
+
+// Query sends a one-shot mDNS PTR query for the given service (e.g.
+// "_googlecast._tcp.local.") and collects responses until the timeout
+// expires. localAddr selects the interface to bind to; pass nil to bind to
+// all interfaces
+func Query(ctx context.Context, service string, localAddr *net.UDPAddr, timeout time.Duration) ([]Entry, error) {
+	if localAddr == nil {
+		localAddr = &net.UDPAddr{IP: net.IPv4zero, Port: 0}
+	}
+	conn, err := net.ListenUDP("udp4", localAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create UDP listener: %w", err)
+	}
+	defer conn.Close()
+
+	dest, err := net.ResolveUDPAddr("udp4", multicastAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve multicast address: %w", err)
+	}
+
+	if _, err = conn.WriteToUDP(buildQuery(service), dest); err != nil {
+		return nil, fmt.Errorf("failed to send mDNS query: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	conn.SetReadDeadline(deadline)
+
+	var entries []Entry
+	seen := make(map[string]bool)
+	buf := make([]byte, 9000) // mDNS packets can exceed the usual 512-byte DNS limit
+
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				break // Expected timeout
+			}
+			log.Warn(ctx, "Error reading mDNS response", err)
+			break
+		}
+
+		found, err := parseResponse(buf[:n], service)
+		if err != nil {
+			log.Trace(ctx, "Ignoring unparsable mDNS packet", err)
+			continue
+		}
+		for _, entry := range found {
+			if seen[entry.Instance] {
+				continue
+			}
+			seen[entry.Instance] = true
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// buildQuery builds a standard one-question mDNS PTR query
+func buildQuery(service string) []byte {
+	var msg []byte
+
+	// Header: ID 0, no flags, one question
+	header := make([]byte, 12)
+	binary.BigEndian.PutUint16(header[4:6], 1) // QDCOUNT
+	msg = append(msg, header...)
+
+	// Question: PTR, class IN
+	msg = appendName(msg, service)
+	msg = binary.BigEndian.AppendUint16(msg, typePTR)
+	msg = binary.BigEndian.AppendUint16(msg, 1)
+
+	return msg
+}
+
+// appendName encodes a dotted name as DNS labels
+func appendName(msg []byte, name string) []byte {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	return append(msg, 0)
+}
+
+// record is one parsed resource record from an mDNS response. The rdata
+// offset into the full packet is kept so names inside rdata (e.g. SRV
+// targets) can be decompressed against the whole message
+type record struct {
+	name    string
+	rtype   uint16
+	data    []byte
+	rdataAt int
+	msg     []byte
+}
+
+// parseResponse extracts all service instances of the given type announced in
+// a single mDNS response packet
+func parseResponse(msg []byte, service string) ([]Entry, error) {
+	records, err := parseRecords(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group SRV and TXT records by instance name
+	byName := make(map[string]*Entry)
+	instance := func(name string) *Entry {
+		entry, ok := byName[name]
+		if !ok {
+			entry = &Entry{
+				Instance: strings.TrimSuffix(name, "."+service),
+				TXT:      make(map[string]string),
+			}
+			byName[name] = entry
+		}
+		return entry
+	}
+
+	for _, rec := range records {
+		if !strings.HasSuffix(rec.name, service) || rec.name == service {
+			continue
+		}
+		switch rec.rtype {
+		case typeSRV:
+			if len(rec.data) < 6 {
+				continue
+			}
+			entry := instance(rec.name)
+			entry.Port = int(binary.BigEndian.Uint16(rec.data[4:6]))
+			entry.Host, _, _ = parseName(rec.msg, rec.rdataAt+6)
+		case typeTXT:
+			entry := instance(rec.name)
+			for key, value := range parseTXT(rec.data) {
+				entry.TXT[key] = value
+			}
+		}
+	}
+
+	// Resolve addresses from the A records published for the SRV targets
+	addrs := make(map[string]string)
+	for _, rec := range records {
+		if rec.rtype == typeA && len(rec.data) == 4 {
+			addrs[rec.name] = net.IP(rec.data).String()
+		}
+	}
+
+	var entries []Entry
+	for _, entry := range byName {
+		if ip, ok := addrs[entry.Host]; ok {
+			entry.IP = ip
+		} else {
+			// Fall back to any A record in the packet
+			for _, ip := range addrs {
+				entry.IP = ip
+				break
+			}
+		}
+		if entry.IP != "" && entry.Port > 0 {
+			entries = append(entries, *entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// parseRecords parses all resource records (answers, authority and
+// additional) from a DNS message, skipping the question section
+func parseRecords(msg []byte) ([]record, error) {
+	if len(msg) < 12 {
+		return nil, fmt.Errorf("short DNS message")
+	}
+	qdCount := int(binary.BigEndian.Uint16(msg[4:6]))
+	rrCount := int(binary.BigEndian.Uint16(msg[6:8])) +
+		int(binary.BigEndian.Uint16(msg[8:10])) +
+		int(binary.BigEndian.Uint16(msg[10:12]))
+
+	off := 12
+
+	// Skip questions
+	for i := 0; i < qdCount; i++ {
+		_, next, err := parseName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		off = next + 4 // QTYPE + QCLASS
+	}
+
+	var records []record
+	for i := 0; i < rrCount && off < len(msg); i++ {
+		name, next, err := parseName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		off = next
+		if off+10 > len(msg) {
+			return nil, fmt.Errorf("truncated resource record")
+		}
+		rtype := binary.BigEndian.Uint16(msg[off : off+2])
+		rdLen := int(binary.BigEndian.Uint16(msg[off+8 : off+10]))
+		off += 10
+		if off+rdLen > len(msg) {
+			return nil, fmt.Errorf("truncated rdata")
+		}
+		records = append(records, record{
+			name:    name,
+			rtype:   rtype,
+			data:    msg[off : off+rdLen],
+			rdataAt: off,
+			msg:     msg,
+		})
+		off += rdLen
+	}
+
+	return records, nil
+}
+
+// parseName decodes a (possibly compressed) DNS name starting at off,
+// returning the dotted name and the offset just past it
+func parseName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	next := -1 // offset after the first compression pointer, if any
+	hops := 0
+
+	for {
+		if off >= len(msg) {
+			return "", 0, fmt.Errorf("name runs past end of message")
+		}
+		length := int(msg[off])
+		switch {
+		case length == 0:
+			if next < 0 {
+				next = off + 1
+			}
+			return strings.Join(labels, ".") + ".", next, nil
+		case length&0xC0 == 0xC0:
+			// Compression pointer
+			if off+1 >= len(msg) {
+				return "", 0, fmt.Errorf("truncated compression pointer")
+			}
+			if hops++; hops > 32 {
+				return "", 0, fmt.Errorf("compression pointer loop")
+			}
+			if next < 0 {
+				next = off + 2
+			}
+			off = int(msg[off]&0x3F)<<8 | int(msg[off+1])
+		default:
+			if off+1+length > len(msg) {
+				return "", 0, fmt.Errorf("truncated label")
+			}
+			labels = append(labels, string(msg[off+1:off+1+length]))
+			off += 1 + length
+		}
+	}
+}
+
+// parseTXT splits TXT rdata (length-prefixed strings) into key=value pairs
+func parseTXT(data []byte) map[string]string {
+	result := make(map[string]string)
+	for off := 0; off < len(data); {
+		length := int(data[off])
+		off++
+		if off+length > len(data) {
+			break
+		}
+		entry := string(data[off : off+length])
+		off += length
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			result[key] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,180 @@
+package mdns_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/navidrome/navidrome/tests"
+	"github.com/navidrome/navidrome/utils/mdns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMDNS(t *testing.T) {
+	tests.Init(t, false)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "mDNS Suite")
+}
+
+const testService = "_googlecast._tcp.local."
+
+// appendRecord appends one resource record (class IN, TTL 0) to a DNS message
+func appendRecord(msg []byte, name string, rtype uint16, rdata []byte) []byte {
+	msg = mdns.AppendName(msg, name)
+	msg = binary.BigEndian.AppendUint16(msg, rtype)
+	msg = binary.BigEndian.AppendUint16(msg, 1) // class IN
+	msg = binary.BigEndian.AppendUint32(msg, 0) // TTL
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(rdata)))
+	return append(msg, rdata...)
+}
+
+// srvData builds SRV rdata with an uncompressed target name
+func srvData(port uint16, target string) []byte {
+	var data []byte
+	data = binary.BigEndian.AppendUint16(data, 0) // priority
+	data = binary.BigEndian.AppendUint16(data, 0) // weight
+	data = binary.BigEndian.AppendUint16(data, port)
+	return mdns.AppendName(data, target)
+}
+
+// txtData builds TXT rdata from length-prefixed strings
+func txtData(entries ...string) []byte {
+	var data []byte
+	for _, entry := range entries {
+		data = append(data, byte(len(entry)))
+		data = append(data, entry...)
+	}
+	return data
+}
+
+// response builds a DNS message with the given records in the answer section
+func response(records ...func([]byte) []byte) []byte {
+	msg := make([]byte, 12)
+	binary.BigEndian.PutUint16(msg[6:8], uint16(len(records))) // ANCOUNT
+	for _, appendRec := range records {
+		msg = appendRec(msg)
+	}
+	return msg
+}
+
+func rr(name string, rtype uint16, rdata []byte) func([]byte) []byte {
+	return func(msg []byte) []byte { return appendRecord(msg, name, rtype, rdata) }
+}
+
+var _ = Describe("parseResponse", func() {
+	It("assembles an entry from SRV, TXT and A records", func() {
+		msg := response(
+			rr("Living Room."+testService, mdns.TypeSRV, srvData(8009, "device.local.")),
+			rr("Living Room."+testService, mdns.TypeTXT, txtData("fn=Living Room", "md=Chromecast")),
+			rr("device.local.", mdns.TypeA, []byte{192, 168, 1, 50}),
+		)
+		entries, err := mdns.ParseResponse(msg, testService)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Instance).To(Equal("Living Room"))
+		Expect(entries[0].Host).To(Equal("device.local."))
+		Expect(entries[0].Port).To(Equal(8009))
+		Expect(entries[0].IP).To(Equal("192.168.1.50"))
+		Expect(entries[0].TXT).To(HaveKeyWithValue("fn", "Living Room"))
+		Expect(entries[0].TXT).To(HaveKeyWithValue("md", "Chromecast"))
+	})
+
+	It("ignores records for other service types", func() {
+		msg := response(
+			rr("Printer._ipp._tcp.local.", mdns.TypeSRV, srvData(631, "printer.local.")),
+			rr("printer.local.", mdns.TypeA, []byte{192, 168, 1, 51}),
+		)
+		entries, err := mdns.ParseResponse(msg, testService)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("falls back to any A record when none matches the SRV target", func() {
+		msg := response(
+			rr("Kitchen."+testService, mdns.TypeSRV, srvData(8009, "kitchen.local.")),
+			rr("other.local.", mdns.TypeA, []byte{192, 168, 1, 52}),
+		)
+		entries, err := mdns.ParseResponse(msg, testService)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].IP).To(Equal("192.168.1.52"))
+	})
+
+	It("drops instances without an address or port", func() {
+		msg := response(
+			rr("Kitchen."+testService, mdns.TypeTXT, txtData("fn=Kitchen")),
+		)
+		entries, err := mdns.ParseResponse(msg, testService)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("skips malformed TXT entries without a separator", func() {
+		msg := response(
+			rr("Den."+testService, mdns.TypeSRV, srvData(8009, "den.local.")),
+			rr("Den."+testService, mdns.TypeTXT, txtData("noequals", "fn=Den")),
+			rr("den.local.", mdns.TypeA, []byte{10, 0, 0, 9}),
+		)
+		entries, err := mdns.ParseResponse(msg, testService)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].TXT).To(Equal(map[string]string{"fn": "Den"}))
+	})
+
+	It("rejects a message shorter than the DNS header", func() {
+		_, err := mdns.ParseResponse([]byte{0, 0, 0}, testService)
+		Expect(err).To(MatchError(ContainSubstring("short DNS message")))
+	})
+
+	It("rejects a truncated resource record", func() {
+		msg := response(rr("Den."+testService, mdns.TypeSRV, srvData(8009, "den.local.")))
+		_, err := mdns.ParseResponse(msg[:len(msg)-4], testService)
+		Expect(err).To(MatchError(ContainSubstring("truncated")))
+	})
+})
+
+var _ = Describe("parseName", func() {
+	It("decodes a plain sequence of labels", func() {
+		msg := mdns.AppendName(nil, "device.local.")
+		name, next, err := mdns.ParseName(msg, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(name).To(Equal("device.local."))
+		Expect(next).To(Equal(len(msg)))
+	})
+
+	It("follows compression pointers", func() {
+		msg := mdns.AppendName(nil, "local.")
+		start := len(msg)
+		msg = append(msg, 6)
+		msg = append(msg, "device"...)
+		msg = append(msg, 0xC0, 0) // pointer back to "local."
+		name, next, err := mdns.ParseName(msg, start)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(name).To(Equal("device.local."))
+		// The offset after the name is right past the pointer, not past the
+		// target it dereferences
+		Expect(next).To(Equal(len(msg)))
+	})
+
+	It("rejects a compression pointer loop", func() {
+		msg := []byte{0xC0, 0} // points at itself
+		_, _, err := mdns.ParseName(msg, 0)
+		Expect(err).To(MatchError(ContainSubstring("compression pointer loop")))
+	})
+
+	It("rejects a truncated compression pointer", func() {
+		_, _, err := mdns.ParseName([]byte{0xC0}, 0)
+		Expect(err).To(MatchError(ContainSubstring("truncated compression pointer")))
+	})
+
+	It("rejects a label running past the end of the message", func() {
+		_, _, err := mdns.ParseName([]byte{5, 'a', 'b'}, 0)
+		Expect(err).To(MatchError(ContainSubstring("truncated label")))
+	})
+
+	It("rejects a name without a terminating root label", func() {
+		msg := []byte{3, 'f', 'o', 'o'}
+		_, _, err := mdns.ParseName(msg, 0)
+		Expect(err).To(MatchError(ContainSubstring("runs past end")))
+	})
+})